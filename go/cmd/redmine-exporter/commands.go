@@ -0,0 +1,575 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/tktomaru/redmine-exporter/internal/config"
+	"github.com/tktomaru/redmine-exporter/internal/formatter"
+	"github.com/tktomaru/redmine-exporter/internal/redmine"
+)
+
+// 終了コード
+// 複数のプロファイルを実行した場合は、0以外で最も小さい終了コードを返す（エラーが一部取得・対象なしより優先）
+const (
+	exitOK          = 0
+	exitError       = 1 // 通信エラー・出力エラーなど
+	exitConfigError = 2 // 設定ファイル・コマンドラインの誤り
+	exitAuthError   = 3 // 認証・権限のエラー（HTTP 401/403）
+	exitPartial     = 4 // 一部のチケットのコメント取得に失敗（レポートは出力済み）
+	exitEmpty       = 5 // 対象のチケットがない（レポートは出力しない）
+)
+
+// exitStatus はメッセージを表示済みの結果（終了コードのみを返す）
+type exitStatus int
+
+func (s exitStatus) Error() string {
+	return fmt.Sprintf("終了コード %d", int(s))
+}
+
+var (
+	errFailed  error = exitStatus(exitError)   // 失敗（メッセージは表示済み）
+	errPartial error = exitStatus(exitPartial) // 一部のチケットのコメント取得に失敗（警告は表示済み）
+	errEmpty   error = exitStatus(exitEmpty)   // 対象のチケットがない
+)
+
+// configError は設定ファイル・コマンドラインの誤りによるエラー
+type configError struct {
+	err error
+}
+
+func (e *configError) Error() string {
+	return e.err.Error()
+}
+
+func (e *configError) Unwrap() error {
+	return e.err
+}
+
+// exitCode はエラーに対応する終了コードを返す
+func exitCode(err error) int {
+	var status exitStatus
+	var cfgErr *configError
+	var validationErr *config.ValidationError
+	var httpErr *redmine.HTTPError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &status):
+		return int(status)
+	case errors.As(err, &cfgErr), errors.As(err, &validationErr):
+		return exitConfigError
+	case errors.As(err, &httpErr) && (httpErr.StatusCode == http.StatusUnauthorized || httpErr.StatusCode == http.StatusForbidden):
+		return exitAuthError
+	}
+	return exitError
+}
+
+// command はサブコマンドの定義
+type command struct {
+	name     string            // コマンド名（"state show" のようにスペース区切り）
+	args     string            // 位置引数の説明（空は位置引数なし）
+	summary  string            // コマンド一覧に表示する説明
+	examples []string          // ヘルプに表示する使用例
+	flags    []flagGroup       // 使用するフラグ（-c と --profile は共通）
+	notes    func(w io.Writer) // ヘルプのオプション一覧の後に表示する補足（nil可）
+	run      func(ctx *commandContext) error
+}
+
+// flagGroup は関連するフラグをまとめて登録する
+type flagGroup func(fs *flag.FlagSet, ctx *commandContext)
+
+// commandContext はコマンドの実行に必要なフラグの値
+type commandContext struct {
+	configPath   string
+	profile      string
+	customFields customFieldFlags
+	effective    bool   // config show --effective
+	convertTo    string // config convert --to
+	withCache    bool   // state reset --cache
+	showVersion  bool   // export -v（従来の呼び出し方との互換）
+
+	args         []string          // 位置引数
+	overrides    map[string]string // 指定されたフラグの値（設定ファイルのキーごと）
+	profiles     []string          // 実行するプロファイル（未指定の場合は [""]）
+	multiProfile bool
+}
+
+// commands はサブコマンドの一覧（ヘルプの表示順）
+var commands = []*command{
+	{
+		name:    "export",
+		summary: "チケットを取得してレポートを出力（コマンド省略時の動作）",
+		examples: []string{
+			"redmine-exporter export -o output.xlsx",
+			"redmine-exporter export -o weekly.md --week last --comments last --comments-since start",
+			"redmine-exporter -o output.md --mode full  （従来の呼び出し方）",
+		},
+		flags: []flagGroup{func(fs *flag.FlagSet, ctx *commandContext) {
+			fs.BoolVar(&ctx.showVersion, "v", false, "バージョン情報を表示")
+		}, connectionFlags, runFlags, destinationFlags, outputFlags, tableFlags, templateFlags, periodFlags, commentFlags, groupingFlags, filterFlags, stateFlags, statsFlags},
+		notes: exportNotes,
+		run:   runExport,
+	},
+	{
+		name:    "stats",
+		summary: "チケットを取得して統計情報を表示（ファイルは出力しない）",
+		examples: []string{
+			"redmine-exporter stats --week last",
+			"redmine-exporter stats --week last --include-metrics --time-entries",
+		},
+		flags: []flagGroup{connectionFlags, runFlags, periodFlags, groupingFlags, filterFlags, stateFlags, metricsFlags},
+		run:   runStats,
+	},
+	{
+		name:     "state show",
+		summary:  "Stateファイル（前回の実行日時）とキャッシュを表示",
+		examples: []string{"redmine-exporter state show --state .state.json"},
+		flags:    []flagGroup{statePathFlags},
+		run:      runStateShow,
+	},
+	{
+		name:    "state reset",
+		summary: "Stateファイルを削除して差分運用をやり直す（--cache でキャッシュも削除）",
+		examples: []string{
+			"redmine-exporter state reset --state .state.json",
+			"redmine-exporter state reset --profile teamA --cache",
+		},
+		flags: []flagGroup{statePathFlags, func(fs *flag.FlagSet, ctx *commandContext) {
+			fs.BoolVar(&ctx.withCache, "cache", false, "キャッシュディレクトリも削除")
+		}},
+		run: runStateReset,
+	},
+	{
+		name:    "template lint",
+		args:    "[テンプレート...]",
+		summary: "テンプレートの構文と参照するフィールドをサンプルデータでチェック",
+		examples: []string{
+			"redmine-exporter template lint templates/weekly.md.tmpl",
+			"redmine-exporter template lint --profile teamA  （設定ファイルの [Template] Path）",
+		},
+		flags: []flagGroup{templateFlags},
+		run:   runTemplateLint,
+	},
+	{
+		name:    "template render",
+		summary: "テンプレートでレポートを出力（-o 未指定時は標準出力）",
+		examples: []string{
+			"redmine-exporter template render --template weekly.tmpl --week last",
+			"redmine-exporter template render --template weekly.tmpl --offline --state .state.json  （キャッシュで調整）",
+		},
+		flags: []flagGroup{connectionFlags, runFlags, templateFlags, func(fs *flag.FlagSet, ctx *commandContext) {
+			fs.String("o", "", "出力ファイルのパス（未指定時は標準出力） [Output] Path")
+		}, outputFlags, periodFlags, commentFlags, groupingFlags, filterFlags, stateFlags, statsFlags},
+		run: runTemplateRender,
+	},
+	{
+		name:    "config show",
+		summary: "設定ファイルの値を表示（--effective で環境変数・フラグを適用した最終的な値）",
+		examples: []string{
+			"redmine-exporter config show --effective --profile teamA",
+		},
+		flags: append([]flagGroup{func(fs *flag.FlagSet, ctx *commandContext) {
+			fs.BoolVar(&ctx.effective, "effective", false, "環境変数・コマンドラインの値を適用した最終的な設定を表示")
+		}}, settingFlags...),
+		notes: configNotes,
+		run: func(ctx *commandContext) error {
+			return showConfig(os.Stdout, ctx.configPath, ctx.profiles, ctx.overrides, ctx.effective)
+		},
+	},
+	{
+		name:    "config convert",
+		summary: "INI形式の設定ファイルをYAML/TOML形式に変換",
+		examples: []string{
+			"redmine-exporter config convert -c redmine.config -o redmine.yaml",
+			"redmine-exporter config convert -c redmine.config --to toml",
+		},
+		flags: []flagGroup{func(fs *flag.FlagSet, ctx *commandContext) {
+			fs.String("o", "", "変換先のファイル（既存のファイルは上書きしない、未指定時は標準出力）")
+			fs.StringVar(&ctx.convertTo, "to", "", "変換先の形式 (yaml, toml) ※未指定時は -o の拡張子で判定")
+		}},
+		notes: configNotes,
+		run: func(ctx *commandContext) error {
+			return convertConfig(ctx.configPath, ctx.overrides["Output.Path"], ctx.convertTo)
+		},
+	},
+	{
+		name:    "config validate",
+		summary: "設定の誤りを行番号付きで表示（エラーがあれば終了コード2）",
+		examples: []string{
+			"redmine-exporter config validate -c redmine.config",
+		},
+		flags: settingFlags,
+		notes: configNotes,
+		run: func(ctx *commandContext) error {
+			errs, err := validateConfig(os.Stdout, ctx.configPath, ctx.profiles, ctx.overrides)
+			if err != nil {
+				return err
+			}
+			if errs > 0 {
+				return exitStatus(exitConfigError)
+			}
+			return nil
+		},
+	},
+	{
+		name:    "doctor",
+		summary: "Redmineへの接続・REST API・APIキー・FilterUrlの件数・コメントの取得・時刻のずれを確認",
+		examples: []string{
+			"redmine-exporter doctor -c redmine.config --profile teamA",
+		},
+		flags: []flagGroup{connectionFlags, filterFlags},
+		run: func(ctx *commandContext) error {
+			ok, err := runDoctor(os.Stdout, ctx.configPath, ctx.profiles, ctx.overrides)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return errFailed
+			}
+			return nil
+		},
+	},
+}
+
+// settingFlags は設定ファイルのキーに対応するすべてのフラグ（config show / validate 用）
+var settingFlags = []flagGroup{connectionFlags, runFlags, destinationFlags, outputFlags, tableFlags, templateFlags, periodFlags, commentFlags, groupingFlags, filterFlags, stateFlags, statsFlags}
+
+// findCommand は引数からサブコマンドを探し、コマンドと残りの引数を返す
+// コマンド名を省略した場合（引数なし、またはフラグで始まる場合）は export として扱う
+func findCommand(args []string) (*command, []string, error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return lookupCommand("export"), args, nil
+	}
+
+	name := args[0]
+	if sub := subcommandNames(name); len(sub) > 0 {
+		if len(args) < 2 || lookupCommand(name+" "+args[1]) == nil {
+			return nil, nil, fmt.Errorf("%s のサブコマンドを指定してください (%s)", name, strings.Join(sub, ", "))
+		}
+		return lookupCommand(name + " " + args[1]), args[2:], nil
+	}
+	if cmd := lookupCommand(name); cmd != nil {
+		return cmd, args[1:], nil
+	}
+	return nil, nil, fmt.Errorf("未知のコマンドです: %s（redmine-exporter help でコマンドの一覧を表示）", name)
+}
+
+// lookupCommand は名前が一致するコマンドを返す（見つからない場合はnil）
+func lookupCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// subcommandNames はコマンドのグループ（config など）のサブコマンド名を返す
+func subcommandNames(group string) []string {
+	var names []string
+	for _, cmd := range commands {
+		if g, sub, ok := strings.Cut(cmd.name, " "); ok && g == group {
+			names = append(names, sub)
+		}
+	}
+	return names
+}
+
+// newFlagSet はコマンドのフラグを登録したFlagSetを作成する
+func (cmd *command) newFlagSet(ctx *commandContext, output io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("redmine-exporter "+cmd.name, flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&ctx.configPath, "c", "redmine.config", "設定ファイルのパス（.yaml / .toml はYAML/TOML形式）")
+	fs.StringVar(&ctx.profile, "profile", "", "設定ファイルのプロファイル（カンマ区切りで複数指定可） 例: teamA,teamB")
+	for _, group := range cmd.flags {
+		group(fs, ctx)
+	}
+	fs.Usage = func() { cmd.usage(fs, output) }
+	return fs
+}
+
+// usage はコマンドのヘルプを表示する
+func (cmd *command) usage(fs *flag.FlagSet, w io.Writer) {
+	fmt.Fprintf(w, "%s\n\n", cmd.summary)
+	fmt.Fprintf(w, "使い方:\n  redmine-exporter %s [オプション]", cmd.name)
+	if cmd.args != "" {
+		fmt.Fprintf(w, " %s", cmd.args)
+	}
+	fmt.Fprintln(w)
+	if len(cmd.examples) > 0 {
+		fmt.Fprintf(w, "\n例:\n")
+		for _, ex := range cmd.examples {
+			fmt.Fprintf(w, "  %s\n", ex)
+		}
+	}
+	fmt.Fprintf(w, "\nオプション:\n")
+	fs.PrintDefaults()
+	if cmd.notes != nil {
+		cmd.notes(w)
+	}
+}
+
+// parse はフラグを解析してコマンドの実行に必要な値を返す
+func (cmd *command) parse(args []string, output io.Writer) (*commandContext, error) {
+	ctx := &commandContext{}
+	fs := cmd.newFlagSet(ctx, output)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	ctx.args = fs.Args()
+	if len(ctx.args) > 0 && cmd.args == "" {
+		return nil, fmt.Errorf("不明な引数です: %s（オプションは引数より前に指定してください）", strings.Join(ctx.args, " "))
+	}
+
+	// 指定されたフラグは設定ファイル・環境変数の値を上書きする
+	ctx.overrides = flagOverrides(fs, ctx.customFields)
+
+	// 実行するプロファイル（未指定の場合はデフォルトの設定のみ）
+	ctx.profiles = config.ParseProfiles(ctx.profile)
+	ctx.multiProfile = len(ctx.profiles) > 1
+	if len(ctx.profiles) == 0 {
+		ctx.profiles = []string{""}
+	}
+	return ctx, nil
+}
+
+// execute は引数に対応するコマンドを実行し、終了コードを返す
+func execute(args []string, stdout, stderr io.Writer) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "help", "-h", "-help", "--help":
+			if len(args) > 1 {
+				cmd, _, err := findCommand(args[1:])
+				if err != nil {
+					fmt.Fprintf(stderr, "エラー: %v\n", err)
+					return exitConfigError
+				}
+				cmd.usage(cmd.newFlagSet(&commandContext{}, stdout), stdout)
+				return 0
+			}
+			printUsage(stdout)
+			return 0
+		case "version", "-v", "--version":
+			fmt.Fprintf(stdout, "Redmine Exporter v%s\n", version)
+			return 0
+		}
+	}
+
+	cmd, rest, err := findCommand(args)
+	if err != nil {
+		fmt.Fprintf(stderr, "エラー: %v\n", err)
+		return exitConfigError
+	}
+	ctx, err := cmd.parse(rest, stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "エラー: %v\n", err)
+		return exitConfigError
+	}
+
+	err = cmd.run(ctx)
+	var status exitStatus
+	if err != nil && !errors.As(err, &status) {
+		fmt.Fprintf(stderr, "エラー: %v\n", err)
+	}
+	return exitCode(err)
+}
+
+// printUsage はコマンドの一覧を表示する
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "Redmine Exporter v%s\n\n", version)
+	fmt.Fprintf(w, "使い方:\n")
+	fmt.Fprintf(w, "  redmine-exporter <コマンド> [オプション]\n")
+	fmt.Fprintf(w, "  redmine-exporter [オプション]  （export と同じ、従来の呼び出し方）\n\n")
+	fmt.Fprintf(w, "コマンド:\n")
+	width := 0
+	for _, cmd := range commands {
+		if len(cmd.name) > width {
+			width = len(cmd.name)
+		}
+	}
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-*s  %s\n", width, cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(w, "  %-*s  %s\n", width, "help", "コマンドのヘルプを表示（redmine-exporter help export）")
+	fmt.Fprintf(w, "  %-*s  %s\n", width, "version", "バージョン情報を表示")
+	fmt.Fprintf(w, "\n各コマンドのオプションは redmine-exporter <コマンド> -h で表示します\n")
+	fmt.Fprintf(w, "-c と --profile 以外のフラグは、ヘルプの [セクション] キー で設定ファイルにも指定できます\n")
+	fmt.Fprintf(w, "\n終了コード:\n")
+	fmt.Fprintf(w, "  %d  成功\n", exitOK)
+	fmt.Fprintf(w, "  %d  エラー（通信エラー、出力エラーなど）\n", exitError)
+	fmt.Fprintf(w, "  %d  設定ファイル・コマンドラインの誤り\n", exitConfigError)
+	fmt.Fprintf(w, "  %d  認証・権限のエラー（HTTP 401/403）\n", exitAuthError)
+	fmt.Fprintf(w, "  %d  一部のチケットのコメント取得に失敗（レポートは出力済み）\n", exitPartial)
+	fmt.Fprintf(w, "  %d  対象のチケットがない（レポートは出力しない）\n", exitEmpty)
+}
+
+// 以下のフラグは設定ファイルのキーに対応する（flagKeys）
+
+// connectionFlags はRedmineへの接続に関するフラグ
+func connectionFlags(fs *flag.FlagSet, ctx *commandContext) {
+	fs.String("api-key-file", "", "APIキーを記載したファイルのパス [Redmine] ApiKeyFile")
+	fs.Int("concurrency", config.DefaultConcurrency, "ジャーナル取得の並列数 [Redmine] Concurrency")
+	fs.Int("max-retries", config.DefaultMaxRetries, "一時的なAPIエラー（429/502/503/504、接続リセット）の最大リトライ回数 [Redmine] MaxRetries")
+	fs.Bool("verbose", false, "詳細ログを出力 [Log] Verbose")
+}
+
+// runFlags は進捗表示・実行結果のサマリーのフラグ
+func runFlags(fs *flag.FlagSet, ctx *commandContext) {
+	fs.Bool("quiet", false, "進捗表示を抑制（警告・エラーのみ表示） [Log] Quiet")
+	fs.String("summary-json", "", "実行結果のサマリー（件数・期間・エラー・所要時間・出力先）を出力するJSONファイル [Output] SummaryJSON")
+}
+
+// destinationFlags は出力先のフラグ
+func destinationFlags(fs *flag.FlagSet, ctx *commandContext) {
+	fs.String("o", "", "出力ファイルのパス（必須） [Output] Path")
+	fs.Bool("stdout", false, "標準出力に出力 [Output] Stdout")
+}
+
+// outputFlags は出力モード・タグのフラグ
+func outputFlags(fs *flag.FlagSet, ctx *commandContext) {
+	fs.String("mode", "summary", "出力モード (summary, full, tags) [Output] Mode")
+	fs.String("tags", "要約", "抽出するタグ名（カンマ区切り、個別上限指定可） 例: 要約:5,進捗,課題:2 [Output] TagNames")
+	fs.Bool("include-comments", false, "コメントからもタグを抽出する [Output] IncludeComments")
+	fs.String("tags-order", "newest", "タグの表示順序 (newest, oldest) ※コメントから抽出されたタグの並び順 [Output] TagsOrder")
+	fs.Bool("history", false, "変更履歴（ステータス・担当者などの変更）を出力する（Markdownのfullモード・Excel・テンプレート） [Output] History")
+}
+
+// tableFlags は表形式（Excel/CSV/TSV）の出力のフラグ
+func tableFlags(fs *flag.FlagSet, ctx *commandContext) {
+	fs.String("columns", "", "表形式（Excel/CSV/TSV）の出力列（カンマ区切り） 例: id,subject,status,cf:顧客,tag:進捗 [Output] Columns")
+	fs.String("csv-encoding", "utf-8", "CSV/TSVの文字コード (utf-8, utf-8-bom, sjis) [Output] CSVEncoding")
+}
+
+// templateFlags はテンプレートのフラグ
+func templateFlags(fs *flag.FlagSet, ctx *commandContext) {
+	fs.String("template", "", "テンプレートファイルのパス (.tmpl) [Template] Path")
+}
+
+// periodFlags は期間（週報）のフラグ
+func periodFlags(fs *flag.FlagSet, ctx *commandContext) {
+	fs.String("week", "", "週指定 (last, this, YYYY-WW) 例: last, 2025-01 [Period] Week")
+	fs.String("week-start", "mon", "週の起点 (mon, sun) [Period] WeekStart")
+	fs.String("date-field", "updated_on", "日時フィールド (updated_on, created_on, start_date, due_date) [Period] DateField")
+	fs.String("since", "", "開始日時 (auto, YYYY-MM-DD) [Period] Since")
+	fs.String("until", "", "終了日時 (auto, YYYY-MM-DD) [Period] Until")
+	fs.Int("weeks", 0, "週ごとの推移を集計する週数（--to-week の週までのn週間） [Period] Weeks")
+	fs.String("from-week", "", "推移の開始週 (last, this, YYYY-WW) [Period] FromWeek")
+	fs.String("to-week", "", "推移の終了週 (last, this, YYYY-WW、未指定時は --week、それもない場合は this) [Period] ToWeek")
+	fs.String("period", "", "期間指定 (month:last, YYYY-MM, Q3, FY2025-H1, last:14d, sprint:<バージョン> など) [Period] Range")
+	fs.Int("fiscal-year-start", 4, "年度の開始月 (1〜12、四半期・半期・年度の起点) [Period] FiscalYearStart")
+}
+
+// commentFlags はコメント制御のフラグ
+func commentFlags(fs *flag.FlagSet, ctx *commandContext) {
+	fs.String("comments", "", "コメント抽出モード (last, all, n:3) ※n:3はタグごとの上限にもなる [Comments] Mode")
+	fs.String("comments-since", "", "コメント抽出の開始日時 (auto, start, YYYY-MM-DD) [Comments] Since")
+	fs.String("comments-by", "", "コメント抽出対象ユーザー [Comments] By")
+	fs.Bool("prefer-comments", false, "説明文よりコメントを優先 [Comments] PreferComments")
+}
+
+// groupingFlags はグルーピング・ソートのフラグ
+func groupingFlags(fs *flag.FlagSet, ctx *commandContext) {
+	fs.String("group-by", "", "グルーピング方法 (assignee, status, tracker, project, priority, cf:<カスタムフィールド名>) [Grouping] GroupBy")
+	fs.String("sort", "", "ソート方法 (field または field:asc/desc、カンマ区切りで複数キー, 例: updated_on, due_date:desc, due_date,priority:desc, cf:顧客) [Grouping] Sort")
+}
+
+// filterFlags はチケットの絞り込みのフラグ（FilterUrlに追加）
+func filterFlags(fs *flag.FlagSet, ctx *commandContext) {
+	fs.String("project", "", "プロジェクト（IDまたは識別子） [Filter] Project")
+	fs.Bool("subprojects", true, "--project 指定時にサブプロジェクトのチケットを含める [Filter] Subprojects")
+	fs.String("tracker", "", "トラッカーID（カンマ区切り） [Filter] Tracker")
+	fs.String("status", "", "ステータス (open, closed, *, またはステータスID（カンマ区切り）) [Filter] Status")
+	fs.String("assignee", "", "担当者のユーザーID（カンマ区切り、me は自分） [Filter] Assignee")
+	fs.String("target-version", "", "対象バージョンID（カンマ区切り） [Filter] TargetVersion")
+	fs.String("category", "", "カテゴリID（カンマ区切り） [Filter] Category")
+	fs.String("author", "", "作成者のユーザーID（カンマ区切り、me は自分） [Filter] Author")
+	fs.String("query", "", "Redmineの保存済みクエリ（IDまたは名前） ※期間・コメントの条件は併用可 [Filter] Query")
+	fs.Var(&ctx.customFields, "cf", "カスタムフィールドの値（ID=値、複数の値はカンマ区切り、繰り返し指定可） 例: --cf 3=A社,B社 [Filter] CustomField1, ...")
+}
+
+// statePathFlags はStateファイル・キャッシュの場所のフラグ
+func statePathFlags(fs *flag.FlagSet, ctx *commandContext) {
+	fs.String("state", "", "Stateファイルのパス（差分運用） [State] File")
+	fs.String("cache-dir", "", "チケット・コメントのキャッシュディレクトリ（未指定時は --state の隣） [State] CacheDir")
+}
+
+// stateFlags は差分運用・キャッシュのフラグ
+func stateFlags(fs *flag.FlagSet, ctx *commandContext) {
+	statePathFlags(fs, ctx)
+	fs.Bool("offline", false, "Redmineにアクセスせずキャッシュのみでレポートを作成 [State] Offline")
+}
+
+// metricsFlags は統計の詳細のフラグ
+func metricsFlags(fs *flag.FlagSet, ctx *commandContext) {
+	fs.Bool("include-metrics", false, "詳細メトリクスを含める [Stats] IncludeMetrics")
+	fs.Bool("time-entries", false, "期間内の作業時間を取得してチケット別・作業者別に集計 [Stats] TimeEntries")
+	fs.Bool("transitions", false, "更新履歴のステータス変更から期間内の完了・再オープン・着手を集計（チケットごとに更新履歴を取得） [Stats] Transitions")
+	fs.String("started-statuses", "進行中", "着手とみなすステータス名（カンマ区切り） [Stats] StartedStatuses")
+}
+
+// statsFlags は統計のフラグ（export では --stats で統計を標準エラー出力に表示）
+func statsFlags(fs *flag.FlagSet, ctx *commandContext) {
+	fs.Bool("stats", false, "統計情報を標準エラー出力に表示（統計のみの場合は stats コマンド） [Stats] Show")
+	metricsFlags(fs, ctx)
+}
+
+// exportNotes は export コマンドのヘルプの補足
+func exportNotes(w io.Writer) {
+	fmt.Fprintf(w, "\n対応する出力形式:\n")
+	fmt.Fprintf(w, "  .md   - Markdown形式\n")
+	fmt.Fprintf(w, "  .txt  - テキスト形式\n")
+	fmt.Fprintf(w, "  .xlsx - Excel形式\n")
+	fmt.Fprintf(w, "  .html - HTML形式（CSS埋め込みの1ファイル、メール向け）\n")
+	fmt.Fprintf(w, "  .csv  - CSV形式（--csv-encoding で utf-8 / utf-8-bom / sjis を選択）\n")
+	fmt.Fprintf(w, "  .tsv  - TSV形式\n")
+	fmt.Fprintf(w, "  .json - JSON形式（スキーマバージョン %s）\n", formatter.JSONSchemaVersion)
+	fmt.Fprintf(w, "  .jsonl - JSON Lines形式（1行1チケット）\n")
+	fmt.Fprintf(w, "\n出力モード:\n")
+	fmt.Fprintf(w, "  summary - 要約のみ出力（デフォルト）\n")
+	fmt.Fprintf(w, "  full    - すべてのフィールドを出力\n")
+	fmt.Fprintf(w, "  tags    - 指定したタグの内容を抽出\n")
+	fmt.Fprintf(w, "\n出力列（Excel/CSV/TSV）:\n")
+	fmt.Fprintf(w, "  --columns \"id,subject,status,assignee,cf:顧客,tag:進捗\" で列を指定\n")
+	fmt.Fprintf(w, "  指定可能な列: %s, cf:<カスタムフィールド名>, tag:<タグ名>\n", strings.Join(formatter.ColumnKeys, ", "))
+	fmt.Fprintf(w, "\nタグ機能:\n")
+	fmt.Fprintf(w, "  --tags \"要約:3,進捗:5,課題\" でタグごとに個別の上限を指定\n")
+	fmt.Fprintf(w, "  --tags-order newest / oldest でコメントのタグの並び順を指定\n")
+	fmt.Fprintf(w, "  --comments n:3 がすべてのタグの共通上限（個別指定と比較して小さい方を採用）\n")
+	fmt.Fprintf(w, "  例: --comments n:3 --tags \"要約:5,進捗\" → 要約は3件、進捗は3件\n")
+	fmt.Fprintf(w, "\nプロファイル:\n")
+	fmt.Fprintf(w, "  --profile teamA で [Redmine.teamA] / [Output.teamA] などの設定を使用（未指定のキーはデフォルトを引き継ぐ）\n")
+	fmt.Fprintf(w, "  --profile teamA,teamB で複数のプロファイルを実行（weekly.md → weekly.teamA.md, weekly.teamB.md）\n")
+	fmt.Fprintf(w, "  -o weekly-{profile}.md のように {profile} で出力ファイル名を指定可能（--state, --cache-dir も同様）\n")
+	fmt.Fprintf(w, "\nチケットの絞り込み:\n")
+	fmt.Fprintf(w, "  FilterUrlと同じパラメータはフラグの指定で上書き、limit/offset は自動で付与\n")
+	fmt.Fprintf(w, "  --query \"今週の対応中\" でRedmineの保存済みクエリを使用（IDでも指定可）\n")
+	fmt.Fprintf(w, "\n週報・差分運用:\n")
+	fmt.Fprintf(w, "  --week last で先週分、--comments-since start で週の開始以降のコメントのみ\n")
+	fmt.Fprintf(w, "  --state .state.json --since auto で前回の成功実行以降のチケットのみ取得\n")
+	fmt.Fprintf(w, "  --state 指定時は .state.cache/ にチケットとコメントをキャッシュ（--offline で同じ FilterUrl のキャッシュのみから作成）\n")
+	fmt.Fprintf(w, "\nグルーピング・ソート:\n")
+	fmt.Fprintf(w, "  --sort updated_on はデフォルト降順、due_date などはデフォルト昇順\n")
+	fmt.Fprintf(w, "  --sort due_date,priority:desc で期日順、同じ期日は優先度の高い順（カンマ区切りで複数キー）\n")
+	fmt.Fprintf(w, "  --group-by cf:顧客 / --sort cf:工数見積:desc でカスタムフィールドを使用\n")
+}
+
+// configNotes は config コマンドのヘルプの補足
+func configNotes(w io.Writer) {
+	fmt.Fprintf(w, "\nAPIキー・設定値の指定方法（優先順）:\n")
+	fmt.Fprintf(w, "  1. コマンドライン（--api-key-file など）\n")
+	fmt.Fprintf(w, "  2. 環境変数（REDMINE_API_KEY, REDMINE_API_KEY_FILE, REDMINE_BASE_URL, REDMINE_OUTPUT_MODE, REDMINE_PERIOD_WEEK など）\n")
+	fmt.Fprintf(w, "  3. 設定ファイル（ApiKey=${MY_KEY} のように環境変数を参照可能、ApiKeyFile= でファイルから読み込み）\n")
+	fmt.Fprintf(w, "  例: --week last → [Period] Week=last, --group-by assignee → [Grouping] GroupBy=assignee\n")
+	fmt.Fprintf(w, "\n設定ファイルの形式:\n")
+	fmt.Fprintf(w, "  -c redmine.yaml / -c redmine.toml でYAML/TOML形式の設定ファイルを使用（拡張子で判定、それ以外はINI形式）\n")
+	fmt.Fprintf(w, "  YAML/TOMLではリスト（Patterns, TagNames, Sort など）・タグごとの上限（Name/Limit）・Profiles を直接記述可能\n")
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tktomaru/redmine-exporter/internal/cache"
+	"github.com/tktomaru/redmine-exporter/internal/config"
+	"github.com/tktomaru/redmine-exporter/internal/redmine"
+	"github.com/tktomaru/redmine-exporter/internal/state"
+)
+
+func TestFindCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		want     string   // コマンド名
+		wantRest []string // 残りの引数
+		wantErr  string
+	}{
+		{name: "引数なしはexport", args: nil, want: "export"},
+		{name: "従来のフラグのみの呼び出しはexport", args: []string{"-o", "out.md", "--week", "last"}, want: "export", wantRest: []string{"-o", "out.md", "--week", "last"}},
+		{name: "export", args: []string{"export", "-o", "out.md"}, want: "export", wantRest: []string{"-o", "out.md"}},
+		{name: "サブコマンド", args: []string{"state", "show", "--state", "s.json"}, want: "state show", wantRest: []string{"--state", "s.json"}},
+		{name: "位置引数", args: []string{"template", "lint", "a.tmpl"}, want: "template lint", wantRest: []string{"a.tmpl"}},
+		{name: "サブコマンドなし", args: []string{"config"}, wantErr: "config のサブコマンドを指定してください (show, convert, validate)"},
+		{name: "未知のサブコマンド", args: []string{"state", "clear"}, wantErr: "state のサブコマンドを指定してください (show, reset)"},
+		{name: "未知のコマンド", args: []string{"exprot"}, wantErr: "未知のコマンドです: exprot"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, rest, err := findCommand(tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("findCommand() error = %v; want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if cmd.name != tt.want {
+				t.Errorf("コマンド = %s; want %s", cmd.name, tt.want)
+			}
+			if len(rest) != 0 || len(tt.wantRest) != 0 {
+				if !reflect.DeepEqual(rest, tt.wantRest) {
+					t.Errorf("残りの引数 = %v; want %v", rest, tt.wantRest)
+				}
+			}
+		})
+	}
+}
+
+func TestCommandFlags(t *testing.T) {
+	// コマンド固有のフラグ以外は、すべて設定ファイルのキーに対応すること
+	commandOnly := map[string]bool{"c": true, "profile": true, "v": true, "effective": true, "to": true, "cache": true}
+	for _, cmd := range commands {
+		fs := cmd.newFlagSet(&commandContext{}, &bytes.Buffer{})
+		fs.VisitAll(func(f *flag.Flag) {
+			if _, ok := flagKeys[f.Name]; !ok && !commandOnly[f.Name] {
+				t.Errorf("%s: --%s に対応する設定キーがない", cmd.name, f.Name)
+			}
+		})
+	}
+
+	// 従来の呼び出し方（export）ではすべての設定キーのフラグを使用できること
+	fs := lookupCommand("export").newFlagSet(&commandContext{}, &bytes.Buffer{})
+	for name := range flagKeys {
+		if fs.Lookup(name) == nil {
+			t.Errorf("export: --%s がない", name)
+		}
+	}
+}
+
+func TestCommandParse(t *testing.T) {
+	ctx, err := lookupCommand("stats").parse([]string{"-c", "x.config", "--profile", "teamA,teamB", "--week", "last", "--cf", "3=A社"}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("parse()でエラー: %v", err)
+	}
+	if ctx.configPath != "x.config" {
+		t.Errorf("configPath = %s; want x.config", ctx.configPath)
+	}
+	if !reflect.DeepEqual(ctx.profiles, []string{"teamA", "teamB"}) || !ctx.multiProfile {
+		t.Errorf("profiles = %v (multiProfile=%v); want [teamA teamB]", ctx.profiles, ctx.multiProfile)
+	}
+	want := map[string]string{"Period.Week": "last", "Filter.CustomField": "3=A社"}
+	if !reflect.DeepEqual(ctx.overrides, want) {
+		t.Errorf("overrides = %v; want %v", ctx.overrides, want)
+	}
+
+	// コマンドにないフラグ・位置引数はエラー
+	if _, err := lookupCommand("stats").parse([]string{"-o", "out.md"}, &bytes.Buffer{}); err == nil {
+		t.Error("stats で -o がエラーにならなかった")
+	}
+	if _, err := lookupCommand("doctor").parse([]string{"extra"}, &bytes.Buffer{}); err == nil {
+		t.Error("doctor で位置引数がエラーにならなかった")
+	}
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{name: "ヘルプ", args: []string{"help"}, wantStdout: "template render"},
+		{name: "コマンドのヘルプ", args: []string{"help", "state", "reset"}, wantStdout: "redmine-exporter state reset [オプション]"},
+		{name: "-h", args: []string{"stats", "-h"}, wantStderr: "--include-metrics"},
+		{name: "バージョン", args: []string{"version"}, wantStdout: "Redmine Exporter v" + version},
+		{name: "未知のコマンド", args: []string{"foo"}, wantCode: exitConfigError, wantStderr: "未知のコマンドです: foo"},
+		{name: "未知のフラグ", args: []string{"doctor", "-o", "x"}, wantCode: exitConfigError, wantStderr: "flag provided but not defined: -o"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := execute(tt.args, &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Errorf("終了コード = %d; want %d (stderr: %s)", code, tt.wantCode, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.wantStdout) {
+				t.Errorf("標準出力に %q が含まれていない:\n%s", tt.wantStdout, stdout.String())
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("標準エラー出力に %q が含まれていない:\n%s", tt.wantStderr, stderr.String())
+			}
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "成功", err: nil, want: exitOK},
+		{name: "その他のエラー", err: errors.New("出力エラー"), want: exitError},
+		{name: "設定の誤り", err: &configError{errors.New("出力ファイルを指定してください")}, want: exitConfigError},
+		{name: "設定のチェック", err: &config.ValidationError{}, want: exitConfigError},
+		{name: "認証エラー", err: fmt.Errorf("チケット取得エラー: %w", &redmine.HTTPError{StatusCode: 401}), want: exitAuthError},
+		{name: "権限エラー", err: &redmine.HTTPError{StatusCode: 403}, want: exitAuthError},
+		{name: "その他のHTTPエラー", err: &redmine.HTTPError{StatusCode: 500}, want: exitError},
+		{name: "一部取得", err: errPartial, want: exitPartial},
+		{name: "対象なし", err: errEmpty, want: exitEmpty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode(%v) = %d; want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForEachProfile(t *testing.T) {
+	// 複数プロファイルでは0以外で最も小さい終了コードを返す
+	results := map[string]error{"a": errEmpty, "b": errPartial, "c": nil}
+	ctx := &commandContext{profiles: []string{"a", "b", "c"}, multiProfile: true, overrides: map[string]string{"Log.Quiet": "true"}}
+	err := forEachProfile(ctx, func(profile string) error { return results[profile] })
+	if got := exitCode(err); got != exitPartial {
+		t.Errorf("終了コード = %d; want %d", got, exitPartial)
+	}
+
+	results["c"] = &configError{errors.New("設定の誤り")}
+	err = forEachProfile(ctx, func(profile string) error { return results[profile] })
+	if got := exitCode(err); got != exitConfigError {
+		t.Errorf("終了コード = %d; want %d", got, exitConfigError)
+	}
+}
+
+func TestWithSummary(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name       string
+		err        error
+		result     *fetchResult
+		wantStatus string
+		wantCode   int
+		wantError  string
+	}{
+		{
+			name:       "成功",
+			result:     &fetchResult{fetched: 3, ticketCount: 2, dateFilter: &redmine.DateFilter{Field: "updated_on"}},
+			wantStatus: summaryStatusSuccess,
+		},
+		{
+			name: "一部取得",
+			err:  errPartial,
+			result: &fetchResult{fetched: 3, ticketCount: 3, partial: &redmine.PartialFetchError{Errors: []redmine.IssueError{
+				{IssueID: 12, Err: errors.New("HTTP 500")},
+			}}},
+			wantStatus: summaryStatusPartial,
+			wantCode:   exitPartial,
+		},
+		{
+			name:       "認証エラー",
+			err:        &redmine.HTTPError{StatusCode: 401, Body: "Unauthorized"},
+			wantStatus: summaryStatusError,
+			wantCode:   exitAuthError,
+			wantError:  "HTTP 401: Unauthorized",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name, "summary.json")
+			ctx := &commandContext{overrides: map[string]string{"Output.SummaryJSON": path}}
+			err := withSummary(ctx, "export", "", func(summary *runSummary) error {
+				if tt.result != nil {
+					summary.setResult(tt.result)
+					summary.setOutput("weekly.xlsx", "weekly.xlsx", false)
+				}
+				return tt.err
+			})
+			if err != tt.err {
+				t.Errorf("withSummary() = %v; want %v", err, tt.err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("サマリーが出力されていない: %v", err)
+			}
+			var got map[string]interface{}
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("サマリーのJSONが不正: %v", err)
+			}
+			if got["status"] != tt.wantStatus || got["exit_code"] != float64(tt.wantCode) {
+				t.Errorf("status = %v, exit_code = %v; want %s, %d", got["status"], got["exit_code"], tt.wantStatus, tt.wantCode)
+			}
+			if tt.wantError != "" && got["error"] != tt.wantError {
+				t.Errorf("error = %v; want %s", got["error"], tt.wantError)
+			}
+			if tt.result == nil {
+				if got["output"] != nil || got["period"] != nil {
+					t.Errorf("output = %v, period = %v; want null", got["output"], got["period"])
+				}
+				return
+			}
+			counts := got["counts"].(map[string]interface{})
+			if counts["fetched"] != float64(tt.result.fetched) || counts["output"] != float64(tt.result.ticketCount) {
+				t.Errorf("counts = %v", counts)
+			}
+			issueErrors := got["issue_errors"].([]interface{})
+			if tt.result.partial == nil {
+				if len(issueErrors) != 0 {
+					t.Errorf("issue_errors = %v; want []", issueErrors)
+				}
+			} else if len(issueErrors) != 1 || issueErrors[0].(map[string]interface{})["issue_id"] != float64(12) {
+				t.Errorf("issue_errors = %v; want #12", issueErrors)
+			}
+			if output := got["output"].(map[string]interface{}); output["format"] != "xlsx" {
+				t.Errorf("output = %v; want xlsx", output)
+			}
+		})
+	}
+}
+
+func TestShowAndResetState(t *testing.T) {
+	dir := t.TempDir()
+	stateFile := filepath.Join(dir, ".state.json")
+	cacheDir := cache.DefaultDir(stateFile)
+
+	// 未作成
+	var b bytes.Buffer
+	if err := showState(&b, stateFile, cacheDir); err != nil {
+		t.Fatalf("showState()でエラー: %v", err)
+	}
+	if !strings.Contains(b.String(), "（未作成）") || !strings.Contains(b.String(), "（なし）") {
+		t.Errorf("showState() = %s; want 未作成・キャッシュなし", b.String())
+	}
+
+	// Stateファイルとキャッシュを作成
+	mgr := state.NewManager(stateFile)
+	st := &state.State{LastRun: time.Now(), LastSuccessRun: time.Now(), Version: "1.0.0"}
+	mgr.SetFilterConfig(st, "week", "last")
+	mgr.SetFilterConfig(st, "date_field", "updated_on")
+	if err := mgr.Save(st); err != nil {
+		t.Fatalf("Stateの保存に失敗: %v", err)
+	}
+	if err := cache.NewStore(cacheDir).Put(&redmine.Issue{ID: 1}); err != nil {
+		t.Fatalf("キャッシュの保存に失敗: %v", err)
+	}
+
+	b.Reset()
+	if err := showState(&b, stateFile, cacheDir); err != nil {
+		t.Fatalf("showState()でエラー: %v", err)
+	}
+	for _, want := range []string{"バージョン: 1.0.0", "フィルタ設定: date_field=updated_on, week=last", "（1 件）"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("showState() に %q が含まれていない:\n%s", want, b.String())
+		}
+	}
+
+	// キャッシュは --cache 指定時のみ削除
+	b.Reset()
+	if err := resetState(&b, stateFile, cacheDir, false); err != nil {
+		t.Fatalf("resetState()でエラー: %v", err)
+	}
+	if _, err := os.Stat(stateFile); !os.IsNotExist(err) {
+		t.Error("Stateファイルが削除されていない")
+	}
+	if _, err := os.Stat(cacheDir); err != nil {
+		t.Error("--cache なしでキャッシュが削除された")
+	}
+	if err := resetState(&b, stateFile, cacheDir, true); err != nil {
+		t.Fatalf("resetState()でエラー: %v", err)
+	}
+	if _, err := os.Stat(cacheDir); !os.IsNotExist(err) {
+		t.Error("キャッシュが削除されていない")
+	}
+	if !strings.Contains(b.String(), "Stateファイルはありません") {
+		t.Errorf("resetState() = %s; want Stateファイルはありません", b.String())
+	}
+}
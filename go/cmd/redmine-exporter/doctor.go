@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/tktomaru/redmine-exporter/internal/config"
+	"github.com/tktomaru/redmine-exporter/internal/doctor"
+	"github.com/tktomaru/redmine-exporter/internal/filter"
+)
+
+// runDoctor はプロファイルごとにRedmineへの接続・権限・時刻をチェックしてチェックリストを表示する
+// すべてのプロファイルで失敗した項目がなければtrueを返す
+func runDoctor(w io.Writer, configPath string, profiles []string, overrides map[string]string) (bool, error) {
+	ok := true
+	for i, p := range profiles {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		cfg, err := readConfig(configPath, config.LoadOptions{Profile: p, Overrides: overrides})
+		if err != nil {
+			return false, err
+		}
+
+		if p != "" {
+			fmt.Fprintf(w, "Redmine の診断: %s（プロファイル: %s）\n\n", cfg.Redmine.BaseURL, p)
+		} else {
+			fmt.Fprintf(w, "Redmine の診断: %s\n\n", cfg.Redmine.BaseURL)
+		}
+		report := diagnose(cfg, configPath)
+		report.Print(w)
+		if report.Failed() {
+			ok = false
+		}
+	}
+	return ok, nil
+}
+
+// diagnose は設定のチェックとRedmineへの接続のチェックを行う
+// 接続先・APIキーが未設定の場合はRedmineへの接続は行わない
+func diagnose(cfg *config.Config, configPath string) *doctor.Report {
+	report := &doctor.Report{}
+	if errs := config.Errors(checkConfig(cfg)); len(errs) > 0 {
+		report.Add("設定", doctor.StatusFail, "redmine-exporter config validate で詳細を確認してください",
+			"%s に %d 件のエラーがあります（%s）", configPath, len(errs), errs[0])
+	} else {
+		report.Add("設定", doctor.StatusPass, "", "%s", configPath)
+	}
+	if cfg.Redmine.BaseURL == "" || cfg.Redmine.APIKey == "" {
+		return report
+	}
+
+	issueFilter, err := parseIssueFilter(cfg.Filter)
+	if err != nil {
+		report.Add("絞り込み条件", doctor.StatusFail, "", "%v", err)
+		issueFilter = nil
+	}
+
+	// 週・期間の計算と同じタイムゾーン
+	loc, err := time.LoadLocation(filter.TimeZone)
+	if err != nil {
+		loc = nil
+	}
+
+	result := doctor.Run(newClient(cfg), doctor.Options{
+		BaseURL:   cfg.Redmine.BaseURL,
+		FilterURL: cfg.Redmine.FilterURL,
+		Filter:    issueFilter,
+		Query:     cfg.Filter.Query,
+		Location:  loc,
+	})
+	report.Checks = append(report.Checks, result.Checks...)
+	return report
+}
@@ -1,8 +1,12 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -10,6 +14,7 @@ import (
 	"time"
 	_ "time/tzdata" // Windows対応: タイムゾーンデータベースをバイナリに埋め込む
 
+	"github.com/tktomaru/redmine-exporter/internal/cache"
 	"github.com/tktomaru/redmine-exporter/internal/config"
 	"github.com/tktomaru/redmine-exporter/internal/filter"
 	"github.com/tktomaru/redmine-exporter/internal/formatter"
@@ -114,147 +119,470 @@ func parseCommentsLimit(commentsMode string) (int, error) {
 	return 0, fmt.Errorf("不正なコメントモード: %s", commentsMode)
 }
 
+// customFieldFlags は繰り返し指定できる --cf フラグの値（"ID=値" 形式）
+type customFieldFlags []string
+
+func (c *customFieldFlags) String() string {
+	return strings.Join(*c, " ")
+}
+
+func (c *customFieldFlags) Set(value string) error {
+	*c = append(*c, value)
+	return nil
+}
+
+// newClient は設定の接続先・並列数・リトライ方針でAPIクライアントを作成する
+func newClient(cfg *config.Config) *redmine.Client {
+	client := redmine.NewClient(cfg.Redmine.BaseURL, cfg.Redmine.APIKey)
+	client.SetConcurrency(cfg.Redmine.Concurrency)
+	retryPolicy := redmine.DefaultRetryPolicy()
+	retryPolicy.MaxRetries = cfg.Redmine.MaxRetries
+	retryPolicy.InitialBackoff = cfg.Redmine.RetryBackoff
+	retryPolicy.MaxBackoff = cfg.Redmine.RetryMaxBackoff
+	client.SetRetryPolicy(retryPolicy)
+	return client
+}
+
+// parseIssueFilter は絞り込み条件（[Filter] セクション・フラグ）からFilterBuilderを構築する
+// 複数の値はカンマ区切りで指定し、いずれかに一致するチケットを対象とする
+func parseIssueFilter(f config.FilterConfig) (*redmine.FilterBuilder, error) {
+	fb := redmine.NewFilterBuilder()
+
+	if f.Project != "" {
+		if err := fb.SetProject(f.Project, f.Subprojects); err != nil {
+			return nil, fmt.Errorf("Project (--project): %w", err)
+		}
+	}
+
+	lists := []struct {
+		name  string
+		value string
+		set   func(...string) error
+	}{
+		{name: "Tracker (--tracker)", value: f.Tracker, set: fb.SetTrackers},
+		{name: "Status (--status)", value: f.Status, set: fb.SetStatus},
+		{name: "Assignee (--assignee)", value: f.Assignee, set: fb.SetAssignees},
+		{name: "TargetVersion (--target-version)", value: f.TargetVersion, set: fb.SetVersions},
+		{name: "Category (--category)", value: f.Category, set: fb.SetCategories},
+		{name: "Author (--author)", value: f.Author, set: fb.SetAuthors},
+	}
+	for _, l := range lists {
+		if l.value == "" {
+			continue
+		}
+		if err := l.set(strings.Split(l.value, ",")...); err != nil {
+			return nil, fmt.Errorf("%s: %w", l.name, err)
+		}
+	}
+
+	for _, cf := range f.CustomFields {
+		idStr, values, ok := strings.Cut(cf, "=")
+		if !ok {
+			return nil, fmt.Errorf("カスタムフィールド (--cf) の形式エラー: %s (ID=値 の形式で指定)", cf)
+		}
+		id, err := strconv.Atoi(strings.TrimSpace(idStr))
+		if err != nil {
+			return nil, fmt.Errorf("カスタムフィールド (--cf) のIDが不正です: %s", idStr)
+		}
+		if err := fb.SetCustomField(id, strings.Split(values, ",")...); err != nil {
+			return nil, fmt.Errorf("カスタムフィールド (--cf): %w", err)
+		}
+	}
+
+	return fb, nil
+}
+
+// flagKeys はコマンドラインフラグと設定ファイルのキー（「セクション.キー」）の対応
+// 指定されたフラグだけが設定ファイル・環境変数の値を上書きする（フラグのデフォルト値はヘルプ表示用）
+var flagKeys = map[string]string{
+	"api-key-file":      "Redmine.ApiKeyFile",
+	"concurrency":       "Redmine.Concurrency",
+	"max-retries":       "Redmine.MaxRetries",
+	"o":                 "Output.Path",
+	"stdout":            "Output.Stdout",
+	"mode":              "Output.Mode",
+	"tags":              "Output.TagNames",
+	"tags-order":        "Output.TagsOrder",
+	"include-comments":  "Output.IncludeComments",
+	"history":           "Output.History",
+	"columns":           "Output.Columns",
+	"csv-encoding":      "Output.CSVEncoding",
+	"summary-json":      "Output.SummaryJSON",
+	"week":              "Period.Week",
+	"week-start":        "Period.WeekStart",
+	"date-field":        "Period.DateField",
+	"since":             "Period.Since",
+	"until":             "Period.Until",
+	"weeks":             "Period.Weeks",
+	"from-week":         "Period.FromWeek",
+	"to-week":           "Period.ToWeek",
+	"period":            "Period.Range",
+	"fiscal-year-start": "Period.FiscalYearStart",
+	"comments":          "Comments.Mode",
+	"comments-since":    "Comments.Since",
+	"comments-by":       "Comments.By",
+	"prefer-comments":   "Comments.PreferComments",
+	"group-by":          "Grouping.GroupBy",
+	"sort":              "Grouping.Sort",
+	"project":           "Filter.Project",
+	"subprojects":       "Filter.Subprojects",
+	"tracker":           "Filter.Tracker",
+	"status":            "Filter.Status",
+	"assignee":          "Filter.Assignee",
+	"target-version":    "Filter.TargetVersion",
+	"category":          "Filter.Category",
+	"author":            "Filter.Author",
+	"cf":                "Filter.CustomField",
+	"query":             "Filter.Query",
+	"state":             "State.File",
+	"cache-dir":         "State.CacheDir",
+	"offline":           "State.Offline",
+	"template":          "Template.Path",
+	"stats":             "Stats.Show",
+	"include-metrics":   "Stats.IncludeMetrics",
+	"time-entries":      "Stats.TimeEntries",
+	"transitions":       "Stats.Transitions",
+	"started-statuses":  "Stats.StartedStatuses",
+	"verbose":           "Log.Verbose",
+	"quiet":             "Log.Quiet",
+}
+
+// flagOverrides はコマンドラインで指定されたフラグの値を設定ファイルのキーごとに返す
+func flagOverrides(fs *flag.FlagSet, customFields customFieldFlags) map[string]string {
+	overrides := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		key, ok := flagKeys[f.Name]
+		if !ok {
+			return
+		}
+		if f.Name == "cf" {
+			// 連番のキー（CustomField1, ...）は改行区切りで渡す
+			overrides[key] = strings.Join(customFields, "\n")
+			return
+		}
+		overrides[key] = f.Value.String()
+	})
+	return overrides
+}
+
 func main() {
-	// コマンドライン引数の定義
-	var (
-		configPath      = flag.String("c", "redmine.config", "設定ファイルのパス")
-		outputPath      = flag.String("o", "", "出力ファイルのパス（必須）")
-		showVersion     = flag.Bool("v", false, "バージョン情報を表示")
-		verbose         = flag.Bool("verbose", false, "詳細ログを出力")
-		mode            = flag.String("mode", "", "出力モード (summary, full, tags) ※設定ファイルより優先")
-		tags            = flag.String("tags", "", "抽出するタグ名（カンマ区切り、個別上限指定可） 例: 要約:5,進捗,課題:2")
-		includeComments = flag.Bool("include-comments", false, "コメントからもタグを抽出する")
-		tagsOrder       = flag.String("tags-order", "newest", "タグの表示順序 (newest, oldest) ※コメントから抽出されたタグの並び順")
-
-		// 週報機能（フェーズ1）
-		week      = flag.String("week", "", "週指定 (last, this, YYYY-WW) 例: last, 2025-01")
-		weekStart = flag.String("week-start", "mon", "週の起点 (mon, sun)")
-		dateField = flag.String("date-field", "updated_on", "日時フィールド (updated_on, created_on, start_date, due_date)")
-
-		// コメント制御（フェーズ2）
-		comments       = flag.String("comments", "", "コメント抽出モード (last, all, n:3) ※n:3はタグごとの上限にもなる")
-		commentsSince  = flag.String("comments-since", "", "コメント抽出の開始日時 (auto, start, YYYY-MM-DD)")
-		commentsBy     = flag.String("comments-by", "", "コメント抽出対象ユーザー")
-		preferComments = flag.Bool("prefer-comments", false, "説明文よりコメントを優先")
-
-		// グルーピング・ソート（フェーズ3）
-		groupBy = flag.String("group-by", "", "グルーピング方法 (assignee, status, tracker, project, priority)")
-		sortBy  = flag.String("sort", "", "ソート方法 (field または field:asc/desc, 例: updated_on, updated_on:asc, due_date:desc)")
-
-		// State管理（フェーズ4）
-		stateFile = flag.String("state", "", "Stateファイルのパス（差分運用）")
-		since     = flag.String("since", "", "開始日時 (auto, YYYY-MM-DD)")
-		until     = flag.String("until", "", "終了日時 (auto, YYYY-MM-DD)")
-
-		// テンプレート機能（フェーズ5）
-		templatePath = flag.String("template", "", "テンプレートファイルのパス (.tmpl)")
-		stdout       = flag.Bool("stdout", false, "標準出力に出力")
-
-		// 統計・メトリクス（フェーズ6）
-		showStats      = flag.Bool("stats", false, "統計情報を表示")
-		includeMetrics = flag.Bool("include-metrics", false, "詳細メトリクスを含める")
-	)
-
-	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Redmine Exporter v%s\n\n", version)
-		fmt.Fprintf(os.Stderr, "使い方:\n")
-		fmt.Fprintf(os.Stderr, "  redmine-exporter -o output.xlsx\n")
-		fmt.Fprintf(os.Stderr, "  redmine-exporter -o output.md --mode full\n")
-		fmt.Fprintf(os.Stderr, "  redmine-exporter -o output.txt --mode tags --tags \"要約,進捗,課題\" --comments n:3 --include-comments\n")
-		fmt.Fprintf(os.Stderr, "  redmine-exporter -o weekly.md --week last --week-start mon\n")
-		fmt.Fprintf(os.Stderr, "  redmine-exporter -o weekly.md --week last --comments last --comments-since start\n\n")
-		fmt.Fprintf(os.Stderr, "オプション:\n")
-		flag.PrintDefaults()
-		fmt.Fprintf(os.Stderr, "\n対応する出力形式:\n")
-		fmt.Fprintf(os.Stderr, "  .md   - Markdown形式\n")
-		fmt.Fprintf(os.Stderr, "  .txt  - テキスト形式\n")
-		fmt.Fprintf(os.Stderr, "  .xlsx - Excel形式\n")
-		fmt.Fprintf(os.Stderr, "\n出力モード:\n")
-		fmt.Fprintf(os.Stderr, "  summary - 要約のみ出力（デフォルト）\n")
-		fmt.Fprintf(os.Stderr, "  full    - すべてのフィールドを出力\n")
-		fmt.Fprintf(os.Stderr, "  tags    - 指定したタグの内容を抽出\n")
-		fmt.Fprintf(os.Stderr, "\nタグ機能:\n")
-		fmt.Fprintf(os.Stderr, "  --tags \"要約,進捗,課題\" でタグを指定\n")
-		fmt.Fprintf(os.Stderr, "  --tags \"要約:3,進捗:5,課題\" でタグごとに個別の上限を指定\n")
-		fmt.Fprintf(os.Stderr, "  --tags-order newest でコメントのタグを新しい順に表示（デフォルト）\n")
-		fmt.Fprintf(os.Stderr, "  --tags-order oldest でコメントのタグを古い順に表示\n")
-		fmt.Fprintf(os.Stderr, "  --comments n:3 がすべてのタグの共通上限（個別指定と比較して小さい方を採用）\n")
-		fmt.Fprintf(os.Stderr, "  例: --comments n:3 --tags \"要約:5,進捗\" → 要約は3件、進捗は3件\n")
-		fmt.Fprintf(os.Stderr, "\n週報機能:\n")
-		fmt.Fprintf(os.Stderr, "  --week last で先週分のチケットを一発で取得\n")
-		fmt.Fprintf(os.Stderr, "  --week-start で週の起点を月曜/日曜で切り替え\n")
-		fmt.Fprintf(os.Stderr, "  --date-field で更新日時/作成日時などでフィルタ\n")
-		fmt.Fprintf(os.Stderr, "\nコメント制御:\n")
-		fmt.Fprintf(os.Stderr, "  --comments last で最新コメントのみ抽出\n")
-		fmt.Fprintf(os.Stderr, "  --comments n:3 で最新3件のコメントを抽出\n")
-		fmt.Fprintf(os.Stderr, "  --comments-since auto/start で週の開始以降のコメントのみ\n")
-		fmt.Fprintf(os.Stderr, "  --comments-by で特定ユーザーのコメントのみ抽出\n")
-		fmt.Fprintf(os.Stderr, "\nグルーピング・ソート:\n")
-		fmt.Fprintf(os.Stderr, "  --group-by assignee で担当者別にグルーピング\n")
-		fmt.Fprintf(os.Stderr, "  --group-by status でステータス別にグルーピング\n")
-		fmt.Fprintf(os.Stderr, "  --sort updated_on で更新日時順にソート（デフォルト：降順）\n")
-		fmt.Fprintf(os.Stderr, "  --sort updated_on:asc で昇順、updated_on:desc で降順\n")
-		fmt.Fprintf(os.Stderr, "  --sort due_date で期日順にソート（デフォルト：昇順）\n")
-		fmt.Fprintf(os.Stderr, "  対応フィールド: updated_on, created_on, due_date, start_date, priority, id\n")
-		fmt.Fprintf(os.Stderr, "\n差分運用（State管理）:\n")
-		fmt.Fprintf(os.Stderr, "  --state .state.json でState管理を有効化\n")
-		fmt.Fprintf(os.Stderr, "  --since auto で前回実行以降のチケットのみ取得\n")
-		fmt.Fprintf(os.Stderr, "  --until auto で現在時刻までのチケットを取得\n")
-		fmt.Fprintf(os.Stderr, "\nテンプレート機能:\n")
-		fmt.Fprintf(os.Stderr, "  --template weekly.tmpl でカスタムテンプレートを使用\n")
-		fmt.Fprintf(os.Stderr, "  --stdout で標準出力に出力（ファイル作成なし）\n")
-		fmt.Fprintf(os.Stderr, "\n統計・メトリクス:\n")
-		fmt.Fprintf(os.Stderr, "  --stats で統計情報を表示（総件数、ステータス別など）\n")
-		fmt.Fprintf(os.Stderr, "  --include-metrics で詳細メトリクスを含める（期限切れ、コメント統計など）\n")
-	}
-
-	flag.Parse()
-
-	// バージョン表示
-	if *showVersion {
+	os.Exit(execute(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// progressOut は進捗表示の出力先
+// 標準出力はレポート（--stdout）・統計（stats）の出力に使うため、進捗は標準エラー出力に表示する
+var progressOut io.Writer = os.Stderr
+
+// progressf は進捗を表示する（--quiet の場合は表示しない）
+func progressf(format string, args ...interface{}) {
+	fmt.Fprintf(progressOut, format, args...)
+}
+
+// setQuiet は進捗表示を抑制するかどうかを設定する
+func setQuiet(quiet bool) {
+	if quiet {
+		progressOut = io.Discard
+	} else {
+		progressOut = os.Stderr
+	}
+}
+
+// quiet はコマンドラインで --quiet が指定されているかどうかを返す
+func (ctx *commandContext) quiet() bool {
+	quiet, _ := strconv.ParseBool(ctx.overrides["Log.Quiet"])
+	return quiet
+}
+
+// forEachProfile はプロファイルごとにfnを実行する
+// 複数プロファイルの場合は1つが失敗しても残りを実行し、0以外で最も小さい終了コードのexitStatusを返す
+func forEachProfile(ctx *commandContext, fn func(profile string) error) error {
+	failed := 0
+	code := exitOK
+	for _, p := range ctx.profiles {
+		setQuiet(ctx.quiet())
+		if ctx.multiProfile {
+			progressf("\n=== プロファイル: %s ===\n", p)
+		}
+		err := fn(p)
+		var status exitStatus
+		if err != nil && !errors.As(err, &status) {
+			if p != "" {
+				fmt.Fprintf(os.Stderr, "エラー（プロファイル %s）: %v\n", p, err)
+			} else {
+				fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+			}
+		}
+		c := exitCode(err)
+		if c != exitOK && c != exitPartial && c != exitEmpty {
+			failed++
+		}
+		if c != exitOK && (code == exitOK || c < code) {
+			code = c
+		}
+	}
+	if failed > 0 && ctx.multiProfile {
+		fmt.Fprintf(os.Stderr, "%d / %d 件のプロファイルが失敗しました\n", failed, len(ctx.profiles))
+	}
+	if code != exitOK {
+		return exitStatus(code)
+	}
+	return nil
+}
+
+// runExport はプロファイルごとにチケットを取得してレポートを出力する（export コマンド）
+func runExport(ctx *commandContext) error {
+	if ctx.showVersion {
 		fmt.Printf("Redmine Exporter v%s\n", version)
-		os.Exit(0)
+		return nil
+	}
+	if ctx.multiProfile && ctx.overrides["Output.Stdout"] == "true" {
+		return fmt.Errorf("複数のプロファイルを指定した場合は --stdout を使用できません")
+	}
+	return forEachProfile(ctx, func(profile string) error {
+		return withSummary(ctx, "export", profile, func(summary *runSummary) error {
+			cfg, err := loadProfile(ctx, profile, summary)
+			if err != nil {
+				return err
+			}
+			if err := checkDestination(cfg, ctx.multiProfile); err != nil {
+				return err
+			}
+			return run(cfg, summary)
+		})
+	})
+}
+
+// runTemplateRender はテンプレートでレポートを出力する（template render コマンド）
+// -o が指定されていない場合は設定ファイルの [Output] Path によらず標準出力に出力する
+func runTemplateRender(ctx *commandContext) error {
+	if _, ok := ctx.overrides["Output.Path"]; !ok && ctx.multiProfile {
+		return fmt.Errorf("複数のプロファイルを指定した場合は -o で出力ファイルを指定してください")
+	}
+	return forEachProfile(ctx, func(profile string) error {
+		return withSummary(ctx, "template render", profile, func(summary *runSummary) error {
+			cfg, err := loadProfile(ctx, profile, summary)
+			if err != nil {
+				return err
+			}
+			if cfg.Template.Path == "" {
+				return &configError{fmt.Errorf("テンプレートを指定してください (--template または [Template] Path)")}
+			}
+			if _, ok := ctx.overrides["Output.Path"]; !ok {
+				cfg.Output.Path, cfg.Output.Stdout = "", true
+			} else {
+				cfg.Output.Stdout = false
+			}
+			return run(cfg, summary)
+		})
+	})
+}
+
+// runStats はプロファイルごとにチケットを取得して統計情報を標準出力に表示する（stats コマンド）
+// Stateファイルは --since auto の読み込みのみに使用し、更新しない
+func runStats(ctx *commandContext) error {
+	return forEachProfile(ctx, func(profile string) error {
+		return withSummary(ctx, "stats", profile, func(summary *runSummary) error {
+			cfg, err := loadProfile(ctx, profile, summary)
+			if err != nil {
+				return err
+			}
+
+			var stateData *state.State
+			if cfg.State.File != "" {
+				stateData, err = state.NewManager(cfg.State.File).Load()
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "警告: %v\n", err)
+				}
+			}
+			result, err := fetchIssues(cfg, stateData, true)
+			if err != nil {
+				return err
+			}
+			summary.setResult(result)
+			weeklyStats, start, end := result.calculateStats()
+			fmt.Printf("\n集計期間: %s 〜 %s\n", start.Format("2006/01/02"), end.Format("2006/01/02"))
+			printStats(os.Stdout, weeklyStats)
+			if cfg.Stats.IncludeMetrics {
+				printMetrics(os.Stdout, weeklyStats)
+			}
+			if trend := result.trend; trend != nil {
+				printTrend(os.Stdout, trend)
+			}
+			return result.exitStatus()
+		})
+	})
+}
+
+// convertConfig はINI形式の設定ファイルをYAML/TOML形式に変換する
+// outputが空の場合は標準出力に出力し、既存のファイルは上書きしない
+func convertConfig(configPath, output, to string) error {
+	format := strings.ToLower(to)
+	if format == "" {
+		if output == "" {
+			return fmt.Errorf("-o または --to で変換先の形式を指定してください (yaml, toml)")
+		}
+		format = config.DetectFormat(output)
+		if format == config.FormatINI {
+			return fmt.Errorf("-o の拡張子から変換先の形式を判定できません: %s (.yaml, .yml, .toml)", output)
+		}
+	}
+	if format == "yml" {
+		format = config.FormatYAML
+	}
+
+	if output == "" {
+		return config.Convert(configPath, format, os.Stdout)
 	}
 
-	// 出力パスのチェック（stdoutモードでない場合のみ）
-	if *outputPath == "" && !*stdout {
-		fmt.Fprintln(os.Stderr, "エラー: 出力ファイルを指定してください (-o) または --stdout を使用してください")
-		flag.Usage()
-		os.Exit(1)
+	if _, err := os.Stat(output); err == nil {
+		return fmt.Errorf("出力先のファイルが既に存在します: %s", output)
+	}
+	var b bytes.Buffer
+	if err := config.Convert(configPath, format, &b); err != nil {
+		return err
+	}
+	if err := os.WriteFile(output, b.Bytes(), 0644); err != nil {
+		return fmt.Errorf("ファイル書き込みエラー: %w", err)
+	}
+	fmt.Printf("設定ファイルを変換しました: %s → %s\n", configPath, output)
+	return nil
+}
+
+// showConfig はプロファイルごとの設定を表示する
+// effectiveがfalseの場合は設定ファイルの値のみ、trueの場合は環境変数・コマンドラインの値を適用した最終的な設定を表示する
+func showConfig(w io.Writer, configPath string, profiles []string, overrides map[string]string, effective bool) error {
+	multiProfile := len(profiles) > 1
+	for i, p := range profiles {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		cfg, err := readConfig(configPath, config.LoadOptions{Profile: p, Overrides: overrides, FileOnly: !effective})
+		if err != nil {
+			return err
+		}
+		if effective {
+			applyProfilePaths(cfg, multiProfile)
+		}
+		if err := cfg.Show(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readConfig は設定ファイルを読み込む（失敗した場合は *configError を返す）
+func readConfig(configPath string, opts config.LoadOptions) (*config.Config, error) {
+	cfg, err := config.ReadConfig(configPath, opts)
+	if err != nil {
+		return nil, &configError{fmt.Errorf("設定ファイルの読み込みに失敗: %w", err)}
+	}
+	return cfg, nil
+}
+
+// loadProfile はプロファイルの設定を読み込み、チェックして進捗表示・ロガーを初期化する
+// 出力ファイルなどのパスはプロファイルごとに分け、サマリーの出力先を設定の値にする
+// 警告は標準エラー出力に表示し、エラーがあれば *config.ValidationError を返す
+func loadProfile(ctx *commandContext, profile string, summary *runSummary) (*config.Config, error) {
+	cfg, err := readConfig(ctx.configPath, config.LoadOptions{Profile: profile, Overrides: ctx.overrides})
+	if err != nil {
+		return nil, err
+	}
+	applyProfilePaths(cfg, ctx.multiProfile)
+	summary.path = cfg.Output.SummaryJSON
+	summary.Offline = cfg.State.Offline
+
+	setQuiet(cfg.Log.Quiet)
+	if profile != "" {
+		progressf("設定ファイルを読み込みました: %s (プロファイル: %s)\n", ctx.configPath, profile)
+	} else {
+		progressf("設定ファイルを読み込みました: %s\n", ctx.configPath)
+	}
+	if err := printWarnings(os.Stderr, checkConfig(cfg)); err != nil {
+		return nil, err
 	}
 
 	// ロガーの初期化
-	if *verbose {
+	if cfg.Log.Verbose {
 		logger.Enable()
+	} else {
+		logger.Disable()
 	}
+	logger.Section("設定ファイル読み込み")
+	logger.Info("BaseURL: %s", cfg.Redmine.BaseURL)
+	logger.Info("ApiKey: %s (取得元: %s)", config.MaskSecret(cfg.Redmine.APIKey), cfg.Source("Redmine.ApiKey"))
+	logger.Info("FilterURL: %s", cfg.Redmine.FilterURL)
+	logger.Info("TitleCleaningパターン数: %d", len(cfg.TitleCleaning.Patterns))
+	logger.Info("並列数: %d, 最大リトライ回数: %d", cfg.Redmine.Concurrency, cfg.Redmine.MaxRetries)
+	logger.Info("出力モード: %s", cfg.Output.Mode)
+	return cfg, nil
+}
 
-	// 実行
-	if err := run(*configPath, *outputPath, *mode, *tags, *includeComments, *tagsOrder, *week, *weekStart, *dateField, *comments, *commentsSince, *commentsBy, *preferComments, *groupBy, *sortBy, *stateFile, *since, *until, *templatePath, *stdout, *showStats, *includeMetrics); err != nil {
-		fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
-		os.Exit(1)
+// checkDestination は出力先（ファイルまたは標準出力）が指定されているかチェックする
+func checkDestination(cfg *config.Config, multiProfile bool) error {
+	if cfg.Output.Stdout && multiProfile {
+		return &configError{fmt.Errorf("複数のプロファイルを指定した場合は標準出力（--stdout、[Output] Stdout）を使用できません")}
 	}
+	if cfg.Output.Path == "" && !cfg.Output.Stdout {
+		return &configError{fmt.Errorf("出力ファイルを指定してください (-o または [Output] Path)、または --stdout を使用してください")}
+	}
+	return nil
+}
+
+// sourceOrDefault はキーの値の取得元を返す（デフォルト値の場合は「デフォルト」）
+func sourceOrDefault(cfg *config.Config, key string) string {
+	if src := cfg.Source(key); src != "" {
+		return src
+	}
+	return "デフォルト"
+}
+
+// applyProfilePaths は出力ファイル・サマリー・Stateファイル・キャッシュディレクトリのパスをプロファイルごとに分ける
+func applyProfilePaths(cfg *config.Config, multiProfile bool) {
+	cfg.Output.Path = profilePath(cfg.Output.Path, cfg.Profile, multiProfile)
+	cfg.Output.SummaryJSON = profilePath(cfg.Output.SummaryJSON, cfg.Profile, multiProfile)
+	cfg.State.File = profilePath(cfg.State.File, cfg.Profile, multiProfile)
+	cfg.State.CacheDir = profilePath(cfg.State.CacheDir, cfg.Profile, multiProfile)
 }
 
-func run(configPath, outputPath, modeFlag, tagsFlag string, includeCommentsFlag bool, tagsOrderFlag, weekFlag, weekStartFlag, dateFieldFlag, commentsMode, commentsSinceFlag, commentsByFlag string, preferCommentsFlag bool, groupByFlag, sortByFlag, stateFileFlag, sinceFlag, untilFlag, templatePathFlag string, stdoutFlag, showStatsFlag, includeMetricsFlag bool) error {
+// profilePath はプロファイルごとのファイルパスを返す
+// パスに {profile} が含まれる場合はプロファイル名に置き換え、
+// 含まれない場合は複数プロファイルの実行時のみ拡張子の前にプロファイル名を挿入する
+// 例: weekly.md → weekly.teamA.md
+func profilePath(path, profile string, multiProfile bool) string {
+	if path == "" {
+		return ""
+	}
+	if strings.Contains(path, "{profile}") {
+		return strings.ReplaceAll(path, "{profile}", profile)
+	}
+	if !multiProfile || profile == "" {
+		return path
+	}
+	ext := filepath.Ext(path)
+	if ext == filepath.Base(path) {
+		ext = "" // .cache のようなドットで始まる名前は拡張子として扱わない
+	}
+	return strings.TrimSuffix(path, ext) + "." + profile + ext
+}
+
+// run は設定に従ってチケットを取得し、レポートを出力する
+// 取得件数・出力先はsummaryに記録する
+// 対象のチケットがない場合はerrEmpty、一部のチケットのコメント取得に失敗した場合は出力後にerrPartialを返す
+func run(cfg *config.Config, summary *runSummary) error {
 	// 0. State管理の初期化（指定されている場合）
 	var stateMgr *state.Manager
 	var stateData *state.State
-	var fileLock *state.FileLock
-
-	// 統計計算用の期間（週報機能や差分運用で設定される）
-	var statsWeekStart, statsWeekEnd time.Time
 
-	if stateFileFlag != "" {
+	if cfg.State.File != "" {
 		// ファイルロック取得
-		lock, err := state.AcquireLock(stateFileFlag, 10*time.Second)
+		fileLock, err := state.AcquireLock(cfg.State.File, 10*time.Second)
 		if err != nil {
 			return fmt.Errorf("ファイルロック取得エラー: %w", err)
 		}
-		fileLock = lock
 		defer fileLock.Release()
 
 		// State読み込み
-		stateMgr = state.NewManager(stateFileFlag)
+		stateMgr = state.NewManager(cfg.State.File)
 		stateData, err = stateMgr.Load()
 		if err != nil {
 			// State破損の場合は警告を表示
@@ -265,82 +593,473 @@ func run(configPath, outputPath, modeFlag, tagsFlag string, includeCommentsFlag
 		stateMgr.UpdateLastRun(stateData)
 	}
 
-	// 1. 設定ファイル読み込み
-	fmt.Printf("設定ファイルを読み込んでいます: %s\n", configPath)
-	logger.Section("設定ファイル読み込み")
-	cfg, err := config.LoadConfig(configPath)
+	// 1〜4. チケットの取得・処理
+	result, err := fetchIssues(cfg, stateData, false)
 	if err != nil {
-		return fmt.Errorf("設定ファイルの読み込みに失敗: %w", err)
+		return err
 	}
-	logger.Info("BaseURL: %s", cfg.Redmine.BaseURL)
-	logger.Info("FilterURL: %s", cfg.Redmine.FilterURL)
-	logger.Info("TitleCleaningパターン数: %d", len(cfg.TitleCleaning.Patterns))
+	summary.setResult(result)
+	roots := result.roots
+	if result.ticketCount == 0 {
+		progressf("出力するチケットがありません\n")
+		return errEmpty
+	}
+
+	// 5. フォーマッター選択
+	formatterOutputPath := formatterPath(cfg)
 
-	// コマンドラインフラグで設定を上書き
-	if modeFlag != "" {
-		logger.Info("出力モードを上書き: %s → %s", cfg.Output.Mode, modeFlag)
-		cfg.Output.Mode = modeFlag
+	fmtr, err := formatter.DetectFormatter(formatterOutputPath, cfg.Output.Mode, cfg.Output.TagNames, cfg.Template.Path)
+	if err != nil {
+		return err
 	}
-	logger.Info("出力モード: %s", cfg.Output.Mode)
 
-	// コメント件数の上限を取得
-	commentsMax := 0
-	if commentsMode != "" {
-		var err error
-		commentsMax, err = parseCommentsLimit(commentsMode)
+	// 表形式の出力列・CSVの文字コード
+	if len(cfg.Output.Columns) > 0 {
+		if setter, ok := fmtr.(formatter.ColumnsSetter); ok {
+			if err := setter.SetColumns(cfg.Output.Columns); err != nil {
+				return fmt.Errorf("出力列の設定エラー: %w", err)
+			}
+		} else {
+			logger.Info("出力列の指定はExcel/CSV/TSV以外では無視されます")
+		}
+	}
+	if csvFmtr, ok := fmtr.(*formatter.CSVFormatter); ok {
+		if err := csvFmtr.SetEncoding(cfg.Output.CSVEncoding); err != nil {
+			return err
+		}
+	}
+	if setter, ok := fmtr.(formatter.BaseURLSetter); ok {
+		setter.SetBaseURL(cfg.Redmine.BaseURL)
+	}
+	if setter, ok := fmtr.(formatter.StatusesSetter); ok {
+		setter.SetStatuses(result.statsOpts.Statuses)
+	}
+
+	// 5.5. 統計計算（--stats / --include-metrics / --time-entries / --transitions / --weeks が指定されている場合）
+	if needsStats(cfg) {
+		// 統計を計算（統計期間が設定されていない場合は、デフォルト期間を使用）
+		weeklyStats, statsWeekStart, statsWeekEnd := result.calculateStats()
+
+		// 統計を出力できるフォーマッター（テンプレート、JSONなど）の場合は統計を設定
+		if setter, ok := fmtr.(formatter.StatsSetter); ok {
+			setter.SetStats(weeklyStats, statsWeekStart, statsWeekEnd)
+		}
+
+		// --stats フラグが指定されている場合は、標準エラー出力に統計を表示
+		if cfg.Stats.Show {
+			printStats(os.Stderr, weeklyStats)
+		}
+
+		// --include-metrics フラグが指定されている場合は詳細メトリクスを表示
+		if cfg.Stats.IncludeMetrics {
+			printMetrics(os.Stderr, weeklyStats)
+		}
+
+		// 週ごとの推移（--weeks / --from-week）
+		if trend := result.trend; trend != nil {
+			if setter, ok := fmtr.(formatter.TrendSetter); ok {
+				setter.SetTrend(trend)
+			} else {
+				logger.Info("推移はExcel/HTML以外の出力では使用されません")
+			}
+			if cfg.Stats.Show {
+				printTrend(os.Stderr, trend)
+			}
+		}
+	}
+
+	// 6. 出力
+	if cfg.Output.Stdout {
+		// 標準出力に出力
+		progressf("標準出力に出力中...\n")
+		if err := fmtr.Format(roots, os.Stdout); err != nil {
+			return fmt.Errorf("出力エラー: %w", err)
+		}
+		progressf("出力完了: %d 件のチケット\n", result.ticketCount)
+	} else {
+		// ファイルに出力
+		progressf("ファイルに出力中: %s\n", cfg.Output.Path)
+
+		// 出力ディレクトリが存在しない場合は作成
+		dir := filepath.Dir(cfg.Output.Path)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("ディレクトリ作成エラー: %w", err)
+		}
+
+		file, err := os.Create(cfg.Output.Path)
 		if err != nil {
-			return fmt.Errorf("コメント設定のパースエラー: %w", err)
+			return fmt.Errorf("ファイル作成エラー: %w", err)
+		}
+		defer file.Close()
+
+		if err := fmtr.Format(roots, file); err != nil {
+			return fmt.Errorf("出力エラー: %w", err)
 		}
-		logger.Info("コメントモード: %s (上限: %d)", commentsMode, commentsMax)
+
+		progressf("出力完了: %d 件のチケット\n", result.ticketCount)
 	}
+	summary.setOutput(cfg.Output.Path, formatterOutputPath, cfg.Output.Stdout)
 
-	// タグのパース（件数制限をサポート、commentsが上限）
-	logger.Section("タグ設定")
-	var tagConfigs []processor.TagConfig
-	if tagsFlag != "" {
-		var err error
-		tagConfigs, cfg.Output.TagNames, err = parseTags(tagsFlag, commentsMax)
+	// 7. State保存（成功時のみ）
+	// オフライン実行はRedmineの最新状態を見ていないため、前回成功日時を進めない
+	if stateMgr != nil && stateData != nil && !cfg.State.Offline {
+		stateMgr.UpdateLastSuccessRun(stateData)
+		stateData.Version = version
+
+		// フィルタ設定を記録
+		if cfg.Period.Week != "" {
+			stateMgr.SetFilterConfig(stateData, "week", cfg.Period.Week)
+		}
+		if cfg.Period.Range != "" {
+			stateMgr.SetFilterConfig(stateData, "period", cfg.Period.Range)
+		}
+		if cfg.Period.DateField != "" {
+			stateMgr.SetFilterConfig(stateData, "date_field", cfg.Period.DateField)
+		}
+
+		if err := stateMgr.Save(stateData); err != nil {
+			fmt.Fprintf(os.Stderr, "警告: State保存エラー: %v\n", err)
+		} else {
+			progressf("State保存完了\n")
+		}
+	}
+
+	return result.exitStatus()
+}
+
+// printStats は統計情報（総件数、ステータス別・担当者別の件数、作業時間）を表示する
+func printStats(w io.Writer, weeklyStats *stats.WeeklyStats) {
+	fmt.Fprintf(w, "\n=== 統計情報 ===\n")
+	fmt.Fprintf(w, "総チケット数: %d\n", weeklyStats.TotalIssues)
+	fmt.Fprintf(w, "\nステータス別:\n")
+	for status, count := range weeklyStats.ByStatus {
+		fmt.Fprintf(w, "  %s: %d\n", status, count)
+	}
+	fmt.Fprintf(w, "\n担当者別:\n")
+	for assignee, count := range weeklyStats.ByAssignee {
+		fmt.Fprintf(w, "  %s: %d\n", assignee, count)
+	}
+	if weeklyStats.Time != nil {
+		fmt.Fprintf(w, "\n作業時間: 合計 %.2fh\n", weeklyStats.Time.TotalHours)
+		for _, h := range stats.SortedHours(weeklyStats.Time.ByUser) {
+			fmt.Fprintf(w, "  %s: %.2fh\n", h.Name, h.Hours)
+		}
+	}
+}
+
+// printMetrics は詳細メトリクス（新規・更新・完了、期限切れ、コメント統計、期間内のステータス変更）を表示する
+func printMetrics(w io.Writer, weeklyStats *stats.WeeklyStats) {
+	fmt.Fprintf(w, "\n=== 詳細メトリクス ===\n")
+	fmt.Fprintf(w, "新規作成: %d\n", weeklyStats.NewIssues)
+	fmt.Fprintf(w, "更新: %d\n", weeklyStats.UpdatedIssues)
+	fmt.Fprintf(w, "完了: %d\n", weeklyStats.ClosedIssues)
+	if weeklyStats.Transitions {
+		fmt.Fprintf(w, "再オープン: %d\n", len(weeklyStats.ReopenedInPeriod))
+		fmt.Fprintf(w, "着手: %d\n", len(weeklyStats.StartedInPeriod))
+	}
+	fmt.Fprintf(w, "期限切れ: %d\n", len(weeklyStats.OverdueTasks))
+	fmt.Fprintf(w, "期限間近（7日以内）: %d\n", len(weeklyStats.DueSoonTasks))
+	fmt.Fprintf(w, "\nコメント統計:\n")
+	fmt.Fprintf(w, "  総コメント数: %d\n", weeklyStats.CommentStats.TotalComments)
+	fmt.Fprintf(w, "  コメントのあるチケット数: %d\n", weeklyStats.CommentStats.IssuesWithComments)
+
+	// リードタイム・サイクルタイム（期間内に完了したチケット）
+	if flow := weeklyStats.Flow; flow != nil {
+		printFlow(w, "リードタイム（作成 → 完了）", flow.LeadTime, flow, func(g stats.FlowGroup) stats.DurationStats { return g.LeadTime })
+		if flow.CycleTime.Count > 0 {
+			printFlow(w, "サイクルタイム（着手 → 完了）", flow.CycleTime, flow, func(g stats.FlowGroup) stats.DurationStats { return g.CycleTime })
+		}
+	}
+
+	// 期間内のステータス変更（--transitions 指定時）
+	if weeklyStats.Transitions {
+		lists := []struct {
+			title  string
+			issues []*redmine.Issue
+		}{
+			{title: "期間内に完了", issues: weeklyStats.ClosedInPeriod},
+			{title: "期間内に再オープン", issues: weeklyStats.ReopenedInPeriod},
+			{title: "期間内に着手", issues: weeklyStats.StartedInPeriod},
+		}
+		for _, l := range lists {
+			if len(l.issues) == 0 {
+				continue
+			}
+			fmt.Fprintf(w, "\n%s:\n", l.title)
+			for _, issue := range l.issues {
+				fmt.Fprintf(w, "  #%d %s [%s]\n", issue.ID, issue.CleanedSubject, issue.Status.Name)
+			}
+		}
+	}
+}
+
+// printTrend は週ごとの作成・完了・未完了（週末時点）・期限切れ（週末時点）の件数を表示する
+func printTrend(w io.Writer, trend []stats.WeekTrend) {
+	fmt.Fprintf(w, "\n=== 週ごとの推移 ===\n")
+	for _, week := range trend {
+		fmt.Fprintf(w, "  %s  作成 %4d  完了 %4d  未完了 %4d  期限切れ %4d\n", week.Label(), week.Created, week.Closed, week.Open, week.Overdue)
+	}
+}
+
+// printFlow はリードタイム・サイクルタイムの全体・トラッカー別・担当者別を表示する
+func printFlow(w io.Writer, title string, total stats.DurationStats, flow *stats.FlowStats, pick func(stats.FlowGroup) stats.DurationStats) {
+	fmt.Fprintf(w, "\n%s:\n", title)
+	fmt.Fprintf(w, "  全体: %s\n", total)
+	for _, group := range []struct {
+		label  string
+		groups []stats.FlowGroup
+	}{
+		{label: "トラッカー別", groups: flow.ByTracker},
+		{label: "担当者別", groups: flow.ByAssignee},
+	} {
+		fmt.Fprintf(w, "  %s:\n", group.label)
+		for _, g := range group.groups {
+			if d := pick(g); d.Count > 0 {
+				fmt.Fprintf(w, "    %s: %s\n", g.Name, d)
+			}
+		}
+	}
+}
+
+// fetchResult は取得・処理したチケットと統計の集計期間
+type fetchResult struct {
+	roots       []*redmine.Issue
+	fetched     int       // 取得したチケット数（期間フィルタ適用後）
+	ticketCount int       // 出力するチケット数（親チケットは子チケットの数で数える）
+	timeEntries int       // 取得した作業時間の件数
+	periodStart time.Time // 期間の指定がない場合はゼロ値
+	periodEnd   time.Time
+	dateFilter  *redmine.DateFilter        // 期間フィルタ（指定がない場合はnil）
+	partial     *redmine.PartialFetchError // コメントの取得に失敗したチケット（nilは失敗なし）
+	statsOpts   stats.Options              // 完了・着手の判定方法（--transitions）
+	trend       []stats.WeekTrend          // 週ごとの推移（--weeks / --from-week、指定がない場合はnil、期間フィルタ前の全チケットから計算）
+}
+
+// calculateStats は集計期間の統計を計算する
+func (r *fetchResult) calculateStats() (*stats.WeeklyStats, time.Time, time.Time) {
+	start, end := r.statsPeriod()
+	return stats.CalculateWithOptions(r.roots, start, end, r.statsOpts), start, end
+}
+
+// exitStatus は取得結果に対応する終了コード（一部取得・対象なし）を返す
+func (r *fetchResult) exitStatus() error {
+	switch {
+	case r.partial != nil:
+		return errPartial
+	case r.ticketCount == 0:
+		return errEmpty
+	}
+	return nil
+}
+
+// statsPeriod は統計の集計期間を返す（期間の指定がない場合は過去7日間）
+func (r *fetchResult) statsPeriod() (time.Time, time.Time) {
+	start, end := r.periodStart, r.periodEnd
+	if start.IsZero() {
+		start = time.Now().AddDate(0, 0, -7)
+	}
+	if end.IsZero() {
+		end = time.Now()
+	}
+	return start, end
+}
+
+// fetchEnumerations は更新履歴のIDを名前に変換するための一覧（ステータスの is_closed を含む）を作成する
+// ステータス・トラッカー・優先度はRedmineから取得し、取得できない場合（オフラインなど）は
+// 取得したチケットに含まれる名前だけで変換する（完了の判定はステータス名で行う）
+func fetchEnumerations(client *redmine.Client, issues []*redmine.Issue, offline bool) *redmine.Enumerations {
+	enums := redmine.NewEnumerations()
+	if !offline {
+		fetched, err := client.FetchEnumerations()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "警告: %v（チケットに含まれない名前はIDで表示し、完了はステータス名で判定します）\n", err)
+		} else {
+			enums = fetched
+		}
+	}
+	enums.Learn(issues)
+	return enums
+}
+
+// buildHistory はジャーナルの変更詳細からチケットごとの変更履歴を作成する
+// 期間フィルタが指定されている場合は期間内の変更のみとする
+func buildHistory(enums *redmine.Enumerations, issues []*redmine.Issue, dateFilter *redmine.DateFilter) {
+	var from, to time.Time
+	if dateFilter != nil {
+		from, to = dateFilter.Start, dateFilter.End
+	}
+	entries := 0
+	for _, issue := range issues {
+		issue.History = enums.BuildHistory(issue, from, to)
+		entries += len(issue.History)
+	}
+	logger.Info("変更履歴: %d件", entries)
+}
+
+// trendWeeks は推移を集計する各週を返す
+// 終了週は [Period] ToWeek、未指定時は Week、それもない場合は今週
+func trendWeeks(cfg *config.Config) ([]filter.WeekRange, error) {
+	wc, err := filter.NewWeekCalculator(cfg.Period.WeekStart, filter.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+	toWeek := cfg.Period.ToWeek
+	if toWeek == "" {
+		toWeek = cfg.Period.Week
+	}
+	if toWeek == "" {
+		toWeek = "this"
+	}
+	if cfg.Period.FromWeek != "" {
+		return wc.GetWeekRanges(cfg.Period.FromWeek, toWeek)
+	}
+	return wc.GetLastWeeks(toWeek, cfg.Period.Weeks)
+}
+
+// periodCalculator は期間指定（[Period] Range）の計算に使うPeriodCalculatorを作成する
+// clientがnilの場合は sprint: の期間を取得できない（設定の検証用）
+func periodCalculator(cfg *config.Config, client *redmine.Client) (*filter.PeriodCalculator, error) {
+	pc, err := filter.NewPeriodCalculator(cfg.Period.WeekStart, filter.TimeZone, cfg.Period.FiscalYearStart)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return pc, nil
+	}
+
+	pc.SetSprintResolver(func(version string) (time.Time, time.Time, error) {
+		if cfg.State.Offline {
+			return time.Time{}, time.Time{}, &configError{fmt.Errorf("--offline ではRedmineのバージョンを取得できないため、sprint: を使用できません")}
+		}
+		project := sprintProject(cfg)
+		if project == "" {
+			return time.Time{}, time.Time{}, &configError{fmt.Errorf("sprint: を使用するには --project または FilterUrl の project_id でプロジェクトを指定してください")}
+		}
+		versions, err := client.FetchVersions(project)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		start, end, v, err := redmine.SprintRange(versions, version, pc.Location())
 		if err != nil {
-			return fmt.Errorf("タグのパースエラー: %w", err)
+			return time.Time{}, time.Time{}, err
 		}
-		logger.Info("タグをコマンドラインフラグから設定: %v", cfg.Output.TagNames)
-		for _, tc := range tagConfigs {
-			logger.Info("  - %s (上限: %d件)", tc.Name, tc.Limit)
+		logger.Info("スプリント: %s (プロジェクト: %s)", v, project)
+		return start, end, nil
+	})
+	return pc, nil
+}
+
+// sprintProject は sprint: のバージョンを取得するプロジェクトを返す
+// --project を優先し、ない場合はFilterUrlの project_id を使用する
+func sprintProject(cfg *config.Config) string {
+	if cfg.Filter.Project != "" {
+		return strings.TrimSpace(cfg.Filter.Project)
+	}
+	u, err := url.Parse(cfg.Redmine.FilterURL)
+	if err != nil {
+		return ""
+	}
+	return u.Query().Get("project_id")
+}
+
+// formatterPath は出力形式の判定に使うパスを返す
+// stdoutモードの場合、outputPathが空の可能性があるため、テンプレートパスまたはデフォルトを使用
+func formatterPath(cfg *config.Config) string {
+	if cfg.Output.Stdout && cfg.Output.Path == "" {
+		// stdoutモードでoutputPathが空の場合、拡張子判定用にダミーパス
+		if cfg.Template.Path != "" {
+			return cfg.Template.Path
 		}
-	} else if len(cfg.Output.TagNames) > 0 {
-		// 設定ファイルから読み込んだ場合はcommentsMaxを適用
-		tagConfigs = make([]processor.TagConfig, len(cfg.Output.TagNames))
-		for i, name := range cfg.Output.TagNames {
-			tagConfigs[i] = processor.TagConfig{Name: name, Limit: commentsMax}
+		return "stdout.md" // デフォルトはMarkdown
+	}
+	return cfg.Output.Path
+}
+
+// needsStats は export で統計を計算するかを返す
+// （--stats / --include-metrics / --time-entries / --transitions / --weeks が指定されている場合）
+func needsStats(cfg *config.Config) bool {
+	return cfg.Stats.Show || cfg.Stats.IncludeMetrics || cfg.Stats.TimeEntries || cfg.Stats.Transitions || cfg.Period.Trend()
+}
+
+// needsStatuses は完了の判定にステータスの一覧（is_closed）が必要かを返す
+// 統計を計算する場合と、完了したチケットを色分けする形式（Excel、HTML）で出力する場合
+func needsStatuses(cfg *config.Config, withStats bool) bool {
+	if withStats || needsStats(cfg) {
+		return true
+	}
+	if cfg.Template.Path != "" {
+		return false
+	}
+	switch strings.ToLower(filepath.Ext(formatterPath(cfg))) {
+	case ".xlsx", ".html":
+		return true
+	}
+	return false
+}
+
+// fetchIssues は期間・絞り込み条件に従ってチケットを取得し、
+// コメントのフィルタ・タイトルの整形・ソート・グルーピングを行う
+// stateDataは --since auto の前回成功日時に使用する（nil可）
+// withStatsは出力の形式によらず統計を計算するか（stats コマンド）
+func fetchIssues(cfg *config.Config, stateData *state.State, withStats bool) (*fetchResult, error) {
+	// 絞り込み条件（[Filter] / --project など）
+	issueFilter, err := parseIssueFilter(cfg.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// 統計計算用の期間（週報機能や差分運用で設定される）
+	var statsWeekStart, statsWeekEnd time.Time
+
+	// コメント件数の上限を取得
+	commentsMax := 0
+	if cfg.Comments.Mode != "" {
+		var err error
+		commentsMax, err = parseCommentsLimit(cfg.Comments.Mode)
+		if err != nil {
+			return nil, fmt.Errorf("コメント設定のパースエラー: %w", err)
 		}
-		logger.Info("タグを設定ファイルから読み込み: %v", cfg.Output.TagNames)
+		logger.Info("コメントモード: %s (上限: %d)", cfg.Comments.Mode, commentsMax)
 	}
 
-	if includeCommentsFlag {
-		cfg.Output.IncludeComments = true
+	// タグのパース（件数制限をサポート、commentsが上限）
+	logger.Section("タグ設定")
+	tagConfigs, tagNames, err := parseTags(strings.Join(cfg.Output.TagNames, ","), commentsMax)
+	if err != nil {
+		return nil, fmt.Errorf("タグのパースエラー: %w", err)
+	}
+	cfg.Output.TagNames = tagNames
+	logger.Info("タグ: %v (取得元: %s)", cfg.Output.TagNames, sourceOrDefault(cfg, "Output.TagNames"))
+	for _, tc := range tagConfigs {
+		logger.Info("  - %s (上限: %d件)", tc.Name, tc.Limit)
+	}
+	if cfg.Output.IncludeComments {
 		logger.Info("コメントからもタグを抽出: 有効")
 	}
 
+	// 2. Redmine APIクライアント作成（sprint: の期間の取得にも使用）
+	client := newClient(cfg)
+
 	// 週報フィルタの構築
 	logger.Section("期間フィルタ")
 	var dateFilter *redmine.DateFilter
-	if weekFlag != "" {
+	if cfg.Period.Week != "" {
 		// WeekCalculatorを作成
-		wc, err := filter.NewWeekCalculator(weekStartFlag, "Asia/Tokyo")
+		wc, err := filter.NewWeekCalculator(cfg.Period.WeekStart, filter.TimeZone)
 		if err != nil {
-			return fmt.Errorf("週計算エラー: %w", err)
+			return nil, fmt.Errorf("週計算エラー: %w", err)
 		}
-		logger.Info("週指定: %s (起点: %s)", weekFlag, weekStartFlag)
+		logger.Info("週指定: %s (起点: %s)", cfg.Period.Week, cfg.Period.WeekStart)
 
 		// 週の期間を取得
-		start, end, err := wc.GetWeekRange(weekFlag)
+		start, end, err := wc.GetWeekRange(cfg.Period.Week)
 		if err != nil {
-			return fmt.Errorf("週範囲計算エラー: %w", err)
+			return nil, fmt.Errorf("週範囲計算エラー: %w", err)
 		}
 
 		// DateFilterを構築
 		dateFilter = &redmine.DateFilter{
-			Field: dateFieldFlag,
+			Field: cfg.Period.DateField,
 			Start: start,
 			End:   end,
 		}
@@ -349,41 +1068,95 @@ func run(configPath, outputPath, modeFlag, tagsFlag string, includeCommentsFlag
 		statsWeekStart = start
 		statsWeekEnd = end
 
-		logger.Info("フィルタフィールド: %s", dateFieldFlag)
+		logger.Info("フィルタフィールド: %s", cfg.Period.DateField)
+		logger.Info("期間: %s 〜 %s", start.Format("2006/01/02 15:04:05"), end.Format("2006/01/02 15:04:05"))
+		progressf("期間フィルタ: %s %s 〜 %s\n", cfg.Period.DateField, start.Format("2006/01/02"), end.Format("2006/01/02"))
+	}
+
+	// 週ごとの推移（--weeks / --from-week）
+	// 週末時点の未完了・期限切れには期間中に更新されていないチケットも含める必要があるため、
+	// 最終週の終了日までに作成された全チケットを取得し、推移の計算後に期間フィルタで絞り込む
+	var trendPeriods []stats.Period
+	var trendFetchFilter *redmine.DateFilter
+	if cfg.Period.Trend() {
+		weeks, err := trendWeeks(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("推移の週範囲計算エラー: %w", err)
+		}
+		for _, w := range weeks {
+			trendPeriods = append(trendPeriods, stats.Period{Start: w.Start, End: w.End})
+		}
+
+		start, end := weeks[0].Start, weeks[len(weeks)-1].End
+		dateFilter = &redmine.DateFilter{
+			Field: cfg.Period.DateField,
+			Start: start,
+			End:   end,
+		}
+		trendFetchFilter = &redmine.DateFilter{Field: "created_on", End: end}
+		statsWeekStart = start
+		statsWeekEnd = end
+
+		logger.Info("推移: %d週間 (%s 〜 %s)", len(weeks), start.Format("2006/01/02"), end.Format("2006/01/02"))
+		progressf("期間フィルタ: %s %s 〜 %s（%d週間の推移）\n", cfg.Period.DateField, start.Format("2006/01/02"), end.Format("2006/01/02"), len(weeks))
+	}
+
+	// 期間指定（--period）: 月・四半期・半期・年度・直近n日・スプリント
+	if cfg.Period.Range != "" {
+		pc, err := periodCalculator(cfg, client)
+		if err != nil {
+			return nil, fmt.Errorf("期間計算エラー: %w", err)
+		}
+		logger.Info("期間指定: %s (年度の開始月: %d月)", cfg.Period.Range, cfg.Period.FiscalYearStart)
+
+		start, end, err := pc.GetRange(cfg.Period.Range)
+		if err != nil {
+			return nil, fmt.Errorf("期間範囲計算エラー: %w", err)
+		}
+
+		dateFilter = &redmine.DateFilter{
+			Field: cfg.Period.DateField,
+			Start: start,
+			End:   end,
+		}
+		statsWeekStart = start
+		statsWeekEnd = end
+
+		logger.Info("フィルタフィールド: %s", cfg.Period.DateField)
 		logger.Info("期間: %s 〜 %s", start.Format("2006/01/02 15:04:05"), end.Format("2006/01/02 15:04:05"))
-		fmt.Printf("期間フィルタ: %s %s 〜 %s\n", dateFieldFlag, start.Format("2006/01/02"), end.Format("2006/01/02"))
+		progressf("期間フィルタ: %s %s 〜 %s（%s）\n", cfg.Period.DateField, start.Format("2006/01/02"), end.Format("2006/01/02"), cfg.Period.Range)
 	}
 
 	// since/untilフラグの処理（State管理との連携）
-	if sinceFlag != "" || untilFlag != "" {
+	if cfg.Period.Since != "" || cfg.Period.Until != "" {
 		var start, end time.Time
 
 		// since処理
-		if sinceFlag == "auto" {
+		if cfg.Period.Since == "auto" {
 			if stateData != nil && !stateData.LastSuccessRun.IsZero() {
 				start = stateData.LastSuccessRun
-				fmt.Printf("差分運用: 前回成功実行 %s 以降のチケットを取得\n", start.Format("2006/01/02 15:04:05"))
+				progressf("差分運用: 前回成功実行 %s 以降のチケットを取得\n", start.Format("2006/01/02 15:04:05"))
 			} else {
-				return fmt.Errorf("--since auto を使用するには --state でStateファイルを指定し、過去に成功実行が必要です")
+				return nil, &configError{fmt.Errorf("--since auto を使用するには --state でStateファイルを指定し、過去に成功実行が必要です")}
 			}
-		} else if sinceFlag != "" {
+		} else if cfg.Period.Since != "" {
 			var err error
-			start, err = time.Parse("2006-01-02", sinceFlag)
+			start, err = time.Parse("2006-01-02", cfg.Period.Since)
 			if err != nil {
-				return fmt.Errorf("--since の日付形式エラー: %w", err)
+				return nil, fmt.Errorf("--since の日付形式エラー: %w", err)
 			}
 		} else if dateFilter != nil {
 			start = dateFilter.Start
 		}
 
 		// until処理
-		if untilFlag == "auto" {
+		if cfg.Period.Until == "auto" {
 			end = time.Now()
-		} else if untilFlag != "" {
+		} else if cfg.Period.Until != "" {
 			var err error
-			end, err = time.Parse("2006-01-02", untilFlag)
+			end, err = time.Parse("2006-01-02", cfg.Period.Until)
 			if err != nil {
-				return fmt.Errorf("--until の日付形式エラー: %w", err)
+				return nil, fmt.Errorf("--until の日付形式エラー: %w", err)
 			}
 			// 終了日を23:59:59に設定
 			end = time.Date(end.Year(), end.Month(), end.Day(), 23, 59, 59, 0, end.Location())
@@ -395,7 +1168,7 @@ func run(configPath, outputPath, modeFlag, tagsFlag string, includeCommentsFlag
 
 		// DateFilterを作成/更新
 		dateFilter = &redmine.DateFilter{
-			Field: dateFieldFlag,
+			Field: cfg.Period.DateField,
 			Start: start,
 			End:   end,
 		}
@@ -404,38 +1177,139 @@ func run(configPath, outputPath, modeFlag, tagsFlag string, includeCommentsFlag
 		statsWeekStart = start
 		statsWeekEnd = end
 
-		fmt.Printf("期間フィルタ: %s %s 〜 %s\n", dateFieldFlag, start.Format("2006/01/02 15:04:05"), end.Format("2006/01/02 15:04:05"))
+		progressf("期間フィルタ: %s %s 〜 %s\n", cfg.Period.DateField, start.Format("2006/01/02 15:04:05"), end.Format("2006/01/02 15:04:05"))
 	}
 
-	// 2. Redmine APIクライアント作成
-	client := redmine.NewClient(cfg.Redmine.BaseURL, cfg.Redmine.APIKey)
+	// 保存済みクエリ（--query）をIDまたは名前で検索
+	if cfg.Filter.Query != "" {
+		if cfg.State.Offline {
+			fmt.Fprintln(os.Stderr, "警告: --offline では保存済みクエリを使用できないため、--query を無視します")
+		} else {
+			query, err := client.ResolveQuery(cfg.Filter.Query)
+			if err != nil {
+				return nil, err
+			}
+			if !issueFilter.IsEmpty() {
+				fmt.Fprintln(os.Stderr, "警告: --query 指定時は保存済みクエリの条件が優先され、絞り込み条件のフラグは無視されます")
+			}
+			issueFilter.SetQuery(query)
+			logger.Info("保存済みクエリ: %s", query)
+			progressf("保存済みクエリ: %s\n", query)
+		}
+	}
+
+	// 保存済みクエリと絞り込み条件のフラグをFilterUrlに追加
+	if !issueFilter.IsEmpty() {
+		filterURL, err := issueFilter.Merge(cfg.Redmine.FilterURL)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("FilterURLに絞り込み条件を追加: %s → %s", cfg.Redmine.FilterURL, filterURL)
+		cfg.Redmine.FilterURL = filterURL
+	}
+
+	// キャッシュディレクトリ（未指定の場合はStateファイルの隣）
+	cacheDir := cfg.State.CacheDir
+	if cacheDir == "" && cfg.State.File != "" {
+		cacheDir = cache.DefaultDir(cfg.State.File)
+	}
+	var cacheStore *cache.Store
+	if cacheDir != "" {
+		cacheStore = cache.NewStore(cacheDir)
+		client.SetCache(cacheStore)
+		logger.Info("キャッシュディレクトリ: %s", cacheDir)
+	} else if cfg.State.Offline {
+		return nil, &configError{fmt.Errorf("--offline を使用するには --cache-dir または --state でキャッシュの場所を指定してください")}
+	}
 
 	// 3. 全チケット取得（進捗表示付き）
 	// コメント関連の機能を使用する場合は、必ずjournalsを取得
 	needsJournals := cfg.Output.IncludeComments ||
-		commentsMode != "" ||
-		commentsSinceFlag != "" ||
-		commentsByFlag != "" ||
-		preferCommentsFlag
-
-	// デバッグ情報
-	fmt.Fprintf(os.Stderr, "[DEBUG] needsJournals=%v (IncludeComments=%v, mode=%s)\n",
-		needsJournals, cfg.Output.IncludeComments, commentsMode)
-
-	fmt.Println("Redmineからチケットを取得中...")
-	issues, err := client.FetchAllIssues(cfg.Redmine.FilterURL, needsJournals, dateFilter, func(current, total int) {
-		if total > 0 {
-			fmt.Printf("\r取得中... (%d / %d)", current, total)
+		cfg.Comments.Mode != "" ||
+		cfg.Comments.Since != "" ||
+		cfg.Comments.By != "" ||
+		cfg.Comments.PreferComments ||
+		cfg.Output.History ||
+		cfg.Stats.Transitions
+
+	logger.Debug("needsJournals=%v (IncludeComments=%v, mode=%s)", needsJournals, cfg.Output.IncludeComments, cfg.Comments.Mode)
+
+	// 取得時の期間フィルタ（推移の場合は最終週の終了日までに作成されたチケット）
+	fetchFilter := dateFilter
+	if trendFetchFilter != nil {
+		fetchFilter = trendFetchFilter
+	}
+
+	var issues []*redmine.Issue
+	var partialErr *redmine.PartialFetchError
+	timeEntries := 0
+	if cfg.State.Offline {
+		// オフライン: APIにアクセスせずキャッシュだけでレポートを作成
+		// 同じ接続先・FilterUrl（絞り込み条件のフラグを含む）でオンライン実行したときの一覧を使用し、
+		// 期間フィルタはローカルで適用する
+		progressf("キャッシュからチケットを読み込み中: %s\n", cacheDir)
+		cached, withoutJournals, err := cacheStore.LoadList(client.CacheScope(cfg.Redmine.FilterURL))
+		if err != nil {
+			return nil, fmt.Errorf("キャッシュ読み込みエラー: %w", err)
+		}
+		if needsJournals && withoutJournals > 0 {
+			fmt.Fprintf(os.Stderr, "警告: %d 件のチケットはコメントがキャッシュされていません（コメントを使う設定でオンラインで一度実行してください）\n", withoutJournals)
+		}
+		for _, issue := range cached {
+			if fetchFilter == nil || fetchFilter.Match(issue) {
+				issues = append(issues, issue)
+			}
+		}
+		logger.Info("キャッシュ: %d件中%d件が期間フィルタに一致", len(cached), len(issues))
+		progressf("読み込み完了: %d 件のチケット\n", len(issues))
+	} else {
+		progressf("Redmineからチケットを取得中...\n")
+		fetched, err := client.FetchAllIssues(cfg.Redmine.FilterURL, needsJournals, fetchFilter, func(current, total int) {
+			if total > 0 {
+				progressf("\r取得中... (%d / %d)", current, total)
+			} else {
+				progressf("\r取得中... (%d)", current)
+			}
+		})
+		if errors.As(err, &partialErr) {
+			// 一部のジャーナル取得に失敗してもエクスポートは続行し、失敗したチケットを報告する
+			fmt.Fprintf(os.Stderr, "\n警告: %v\n", partialErr)
+			for _, ie := range partialErr.Errors {
+				fmt.Fprintf(os.Stderr, "  #%d: %v\n", ie.IssueID, ie.Err)
+			}
+		} else if err != nil {
+			return nil, fmt.Errorf("チケット取得エラー: %w", err)
+		}
+		issues = fetched
+		progressf("\r取得完了: %d 件のチケット\n", len(issues))
+	}
+
+	// 3.2. 作業時間の取得（--time-entries が指定されている場合）
+	if cfg.Stats.TimeEntries {
+		// 期間が設定されていない場合は、統計と同じく過去7日間を使用
+		if statsWeekStart.IsZero() {
+			statsWeekStart = time.Now().AddDate(0, 0, -7)
+		}
+		if statsWeekEnd.IsZero() {
+			statsWeekEnd = time.Now()
+		}
+
+		if cfg.State.Offline {
+			fmt.Fprintln(os.Stderr, "警告: --offline では作業時間を取得できないため、工数集計をスキップします")
 		} else {
-			fmt.Printf("\r取得中... (%d)", current)
+			progressf("Redmineから作業時間を取得中...\n")
+			entries, err := client.FetchTimeEntries(statsWeekStart, statsWeekEnd, cfg.Redmine.FilterURL)
+			if err != nil {
+				return nil, fmt.Errorf("作業時間取得エラー: %w", err)
+			}
+			attached := redmine.AttachTimeEntries(issues, entries)
+			timeEntries = len(entries)
+			logger.Info("作業時間: %d件中%d件を対象チケットに紐付け", len(entries), attached)
+			progressf("作業時間取得完了: %d 件（対象チケット分: %d 件）\n", len(entries), attached)
 		}
-	})
-	if err != nil {
-		return fmt.Errorf("チケット取得エラー: %w", err)
 	}
-	fmt.Printf("\r取得完了: %d 件のチケット\n", len(issues))
 
-	// デバッグ：ジャーナル情報を表示
+	// ジャーナル情報（詳細ログ）
 	if needsJournals {
 		totalJournals := 0
 		journalsWithNotes := 0
@@ -447,38 +1321,69 @@ func run(configPath, outputPath, modeFlag, tagsFlag string, includeCommentsFlag
 				}
 			}
 		}
-		fmt.Fprintf(os.Stderr, "[DEBUG] 取得したジャーナル: 合計%d件 (Notes有り: %d件)\n",
-			totalJournals, journalsWithNotes)
+		logger.Debug("取得したジャーナル: 合計%d件 (Notes有り: %d件)", totalJournals, journalsWithNotes)
+	}
+
+	// 3.3. 更新履歴の解析（--history / --transitions が指定されている場合）と、完了の判定に使うステータスの一覧の取得
+	// コメントフィルタはノートのないジャーナルを除外するため、フィルタの前に解析する
+	statsOptions := stats.Options{StartedStatuses: cfg.Stats.StartedStatuses}
+	if cfg.Output.History || needsStatuses(cfg, withStats) {
+		enums := fetchEnumerations(client, issues, cfg.State.Offline)
+		statsOptions.Statuses = enums
+		if cfg.Output.History {
+			buildHistory(enums, issues, dateFilter)
+		}
+		if cfg.Stats.Transitions {
+			for _, issue := range issues {
+				issue.StatusChanges = enums.StatusChanges(issue)
+			}
+			statsOptions.Transitions = true
+		}
+	}
+
+	// 3.4. 週ごとの推移の計算（取得した全チケットを使用）と、レポートに出力するチケットの期間での絞り込み
+	var trend []stats.WeekTrend
+	if len(trendPeriods) > 0 {
+		trend = stats.CalculateTrend(issues, trendPeriods, statsOptions)
+
+		inPeriod := make([]*redmine.Issue, 0, len(issues))
+		for _, issue := range issues {
+			if dateFilter.Match(issue) {
+				inPeriod = append(inPeriod, issue)
+			}
+		}
+		logger.Info("推移: %d件のチケットから計算、うち%d件が期間フィルタに一致", len(issues), len(inPeriod))
+		issues = inPeriod
 	}
 
 	// 3.5. コメントフィルタの適用
-	if commentsMode != "" || commentsSinceFlag != "" || commentsByFlag != "" {
-		fmt.Println("コメントをフィルタリング中...")
+	if cfg.Comments.Mode != "" || cfg.Comments.Since != "" || cfg.Comments.By != "" {
+		progressf("コメントをフィルタリング中...\n")
 		logger.Section("コメントフィルタ")
 
 		// commentsSinceの解釈（"auto" または "start" の場合は週の開始日を使用）
 		var commentsSinceDate *time.Time
-		if (commentsSinceFlag == "auto" || commentsSinceFlag == "start") && dateFilter != nil {
+		if (cfg.Comments.Since == "auto" || cfg.Comments.Since == "start") && dateFilter != nil {
 			commentsSinceDate = &dateFilter.Start
 			logger.Info("コメント開始日時: %s (週の開始日)", commentsSinceDate.Format("2006/01/02 15:04:05"))
-		} else if commentsSinceFlag != "" && commentsSinceFlag != "auto" && commentsSinceFlag != "start" {
+		} else if cfg.Comments.Since != "" && cfg.Comments.Since != "auto" && cfg.Comments.Since != "start" {
 			// YYYY-MM-DD形式をパース
-			t, err := time.Parse("2006-01-02", commentsSinceFlag)
+			t, err := time.Parse("2006-01-02", cfg.Comments.Since)
 			if err != nil {
-				return fmt.Errorf("コメント開始日時の解析エラー: %w", err)
+				return nil, fmt.Errorf("コメント開始日時の解析エラー: %w", err)
 			}
 			commentsSinceDate = &t
 			logger.Info("コメント開始日時: %s", commentsSinceDate.Format("2006/01/02"))
 		}
 
-		if commentsByFlag != "" {
-			logger.Info("コメントユーザーフィルタ: %s", commentsByFlag)
+		if cfg.Comments.By != "" {
+			logger.Info("コメントユーザーフィルタ: %s", cfg.Comments.By)
 		}
 
 		// CommentFilterを作成
-		commentFilter, err := filter.NewCommentFilter(commentsMode, commentsSinceDate, commentsByFlag)
+		commentFilter, err := filter.NewCommentFilter(cfg.Comments.Mode, commentsSinceDate, cfg.Comments.By)
 		if err != nil {
-			return fmt.Errorf("コメントフィルタ作成エラー: %w", err)
+			return nil, fmt.Errorf("コメントフィルタ作成エラー: %w", err)
 		}
 
 		// 各チケットのジャーナルをフィルタリング
@@ -493,23 +1398,23 @@ func run(configPath, outputPath, modeFlag, tagsFlag string, includeCommentsFlag
 		}
 
 		logger.Info("フィルタリング前: %d件 → フィルタリング後: %d件 (削減: %d件)", totalBefore, totalAfter, totalBefore-totalAfter)
-		fmt.Println("コメントフィルタリング完了")
+		progressf("コメントフィルタリング完了\n")
 	}
 
 	// 4. データ処理
-	fmt.Println("チケットを処理中...")
+	progressf("チケットを処理中...\n")
 	logger.Section("データ処理")
 	logger.Info("入力チケット数: %d件", len(issues))
-	proc, err := processor.NewProcessor(cfg.TitleCleaning.Patterns, tagConfigs, cfg.Output.Mode, preferCommentsFlag, cfg.Output.IncludeComments, tagsOrderFlag)
+	proc, err := processor.NewProcessor(cfg.TitleCleaning.Patterns, tagConfigs, cfg.Output.Mode, cfg.Comments.PreferComments, cfg.Output.IncludeComments, cfg.Output.TagsOrder)
 	if err != nil {
-		return fmt.Errorf("プロセッサー初期化エラー: %w", err)
+		return nil, fmt.Errorf("プロセッサー初期化エラー: %w", err)
 	}
 	roots := proc.Process(issues)
 	logger.Info("処理後のルートチケット数: %d件", len(roots))
 
 	// 4.5. グルーピング・ソート
-	if sortByFlag != "" || groupByFlag != "" {
-		fmt.Println("チケットをソート・グルーピング中...")
+	if cfg.Grouping.Sort != "" || cfg.Grouping.GroupBy != "" {
+		progressf("チケットをソート・グルーピング中...\n")
 		logger.Section("ソート・グルーピング")
 
 		// ルートチケットと子チケットをフラットなリストに展開
@@ -521,25 +1426,25 @@ func run(configPath, outputPath, modeFlag, tagsFlag string, includeCommentsFlag
 		logger.Info("フラット化後のチケット数: %d件", len(allIssues))
 
 		// ソート
-		if sortByFlag != "" {
-			logger.Info("ソート実行: %s", sortByFlag)
-			sorter := processor.NewSorter(sortByFlag)
+		if cfg.Grouping.Sort != "" {
+			logger.Info("ソート実行: %s", cfg.Grouping.Sort)
+			sorter := processor.NewSorter(cfg.Grouping.Sort)
 			if sorter != nil {
 				sorter.Sort(allIssues)
 			}
 		}
 
 		// グルーピング
-		if groupByFlag != "" {
-			logger.Info("グルーピング実行: %s", groupByFlag)
-			grouper := processor.NewGrouper(groupByFlag)
+		if cfg.Grouping.GroupBy != "" {
+			logger.Info("グルーピング実行: %s", cfg.Grouping.GroupBy)
+			grouper := processor.NewGrouper(cfg.Grouping.GroupBy)
 			if grouper != nil {
 				grouped := grouper.Group(allIssues)
 				logger.Info("グループ数: %d", len(grouped.Keys))
 
 				// グルーピング後、各グループ内でもソートを適用
-				if sortByFlag != "" {
-					sorter := processor.NewSorter(sortByFlag)
+				if cfg.Grouping.Sort != "" {
+					sorter := processor.NewSorter(cfg.Grouping.Sort)
 					if sorter != nil {
 						for _, key := range grouped.Keys {
 							sorter.Sort(grouped.Groups[key])
@@ -574,124 +1479,16 @@ func run(configPath, outputPath, modeFlag, tagsFlag string, includeCommentsFlag
 		}
 	}
 
-	if ticketCount == 0 {
-		fmt.Println("出力するチケットがありません")
-		return nil
-	}
-
-	// 5. フォーマッター選択
-	// stdoutモードの場合、outputPathが空の可能性があるため、テンプレートパスまたはデフォルトを使用
-	formatterOutputPath := outputPath
-	if stdoutFlag && formatterOutputPath == "" {
-		// stdoutモードでoutputPathが空の場合、拡張子判定用にダミーパス
-		if templatePathFlag != "" {
-			formatterOutputPath = templatePathFlag
-		} else {
-			formatterOutputPath = "stdout.md" // デフォルトはMarkdown
-		}
-	}
-
-	fmtr, err := formatter.DetectFormatter(formatterOutputPath, cfg.Output.Mode, cfg.Output.TagNames, templatePathFlag)
-	if err != nil {
-		return err
-	}
-
-	// 5.5. 統計計算（--stats または --include-metrics が指定されている場合）
-	if showStatsFlag || includeMetricsFlag {
-		// 統計期間が設定されていない場合は、デフォルト期間を使用
-		if statsWeekStart.IsZero() {
-			statsWeekStart = time.Now().AddDate(0, 0, -7) // 過去7日間
-		}
-		if statsWeekEnd.IsZero() {
-			statsWeekEnd = time.Now()
-		}
-
-		// 統計を計算
-		weeklyStats := stats.Calculate(roots, statsWeekStart, statsWeekEnd)
-
-		// テンプレートフォーマッターの場合は統計を設定
-		if tmplFmtr, ok := fmtr.(*formatter.TemplateFormatter); ok {
-			tmplFmtr.SetStats(weeklyStats, statsWeekStart, statsWeekEnd)
-		}
-
-		// --stats フラグが指定されている場合は、標準エラー出力に統計を表示
-		if showStatsFlag {
-			fmt.Fprintf(os.Stderr, "\n=== 統計情報 ===\n")
-			fmt.Fprintf(os.Stderr, "総チケット数: %d\n", weeklyStats.TotalIssues)
-			fmt.Fprintf(os.Stderr, "\nステータス別:\n")
-			for status, count := range weeklyStats.ByStatus {
-				fmt.Fprintf(os.Stderr, "  %s: %d\n", status, count)
-			}
-			fmt.Fprintf(os.Stderr, "\n担当者別:\n")
-			for assignee, count := range weeklyStats.ByAssignee {
-				fmt.Fprintf(os.Stderr, "  %s: %d\n", assignee, count)
-			}
-		}
-
-		// --include-metrics フラグが指定されている場合は詳細メトリクスを表示
-		if includeMetricsFlag {
-			fmt.Fprintf(os.Stderr, "\n=== 詳細メトリクス ===\n")
-			fmt.Fprintf(os.Stderr, "新規作成: %d\n", weeklyStats.NewIssues)
-			fmt.Fprintf(os.Stderr, "更新: %d\n", weeklyStats.UpdatedIssues)
-			fmt.Fprintf(os.Stderr, "完了: %d\n", weeklyStats.ClosedIssues)
-			fmt.Fprintf(os.Stderr, "期限切れ: %d\n", len(weeklyStats.OverdueTasks))
-			fmt.Fprintf(os.Stderr, "期限間近（7日以内）: %d\n", len(weeklyStats.DueSoonTasks))
-			fmt.Fprintf(os.Stderr, "\nコメント統計:\n")
-			fmt.Fprintf(os.Stderr, "  総コメント数: %d\n", weeklyStats.CommentStats.TotalComments)
-			fmt.Fprintf(os.Stderr, "  コメントのあるチケット数: %d\n", weeklyStats.CommentStats.IssuesWithComments)
-		}
-	}
-
-	// 6. 出力
-	if stdoutFlag {
-		// 標準出力に出力
-		fmt.Fprintln(os.Stderr, "標準出力に出力中...")
-		if err := fmtr.Format(roots, os.Stdout); err != nil {
-			return fmt.Errorf("出力エラー: %w", err)
-		}
-		fmt.Fprintf(os.Stderr, "出力完了: %d 件のチケット\n", ticketCount)
-	} else {
-		// ファイルに出力
-		fmt.Printf("ファイルに出力中: %s\n", outputPath)
-
-		// 出力ディレクトリが存在しない場合は作成
-		dir := filepath.Dir(outputPath)
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("ディレクトリ作成エラー: %w", err)
-		}
-
-		file, err := os.Create(outputPath)
-		if err != nil {
-			return fmt.Errorf("ファイル作成エラー: %w", err)
-		}
-		defer file.Close()
-
-		if err := fmtr.Format(roots, file); err != nil {
-			return fmt.Errorf("出力エラー: %w", err)
-		}
-
-		fmt.Printf("出力完了: %d 件のチケット\n", ticketCount)
-	}
-
-	// 7. State保存（成功時のみ）
-	if stateMgr != nil && stateData != nil {
-		stateMgr.UpdateLastSuccessRun(stateData)
-		stateData.Version = version
-
-		// フィルタ設定を記録
-		if weekFlag != "" {
-			stateMgr.SetFilterConfig(stateData, "week", weekFlag)
-		}
-		if dateFieldFlag != "" {
-			stateMgr.SetFilterConfig(stateData, "date_field", dateFieldFlag)
-		}
-
-		if err := stateMgr.Save(stateData); err != nil {
-			fmt.Fprintf(os.Stderr, "警告: State保存エラー: %v\n", err)
-		} else {
-			fmt.Println("State保存完了")
-		}
-	}
-
-	return nil
+	return &fetchResult{
+		roots:       roots,
+		fetched:     len(issues),
+		ticketCount: ticketCount,
+		timeEntries: timeEntries,
+		periodStart: statsWeekStart,
+		periodEnd:   statsWeekEnd,
+		dateFilter:  dateFilter,
+		partial:     partialErr,
+		statsOpts:   statsOptions,
+		trend:       trend,
+	}, nil
 }
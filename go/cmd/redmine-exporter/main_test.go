@@ -1,7 +1,16 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
+
+	"github.com/tktomaru/redmine-exporter/internal/config"
 )
 
 func TestParseTags(t *testing.T) {
@@ -90,3 +99,228 @@ func TestParseCommentsLimit(t *testing.T) {
 		})
 	}
 }
+
+func TestParseIssueFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		flags   config.FilterConfig
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "指定なし",
+			flags: config.FilterConfig{Subprojects: true},
+			want:  "",
+		},
+		{
+			name:  "プロジェクトとステータス",
+			flags: config.FilterConfig{Project: "myproj", Subprojects: false, Status: "open"},
+			want:  "project_id=myproj&status_id=open&subproject_id=%21%2A",
+		},
+		{
+			name:  "カンマ区切りの複数指定",
+			flags: config.FilterConfig{Tracker: "1,2", Assignee: "me,5", TargetVersion: "3", Category: "4", Author: "6"},
+			want:  "assigned_to_id=me%7C5&author_id=6&category_id=4&fixed_version_id=3&tracker_id=1%7C2",
+		},
+		{
+			name:  "カスタムフィールド",
+			flags: config.FilterConfig{CustomFields: []string{"3=A社,B社", "5=高"}},
+			want:  "cf_3=A%E7%A4%BE%7CB%E7%A4%BE&cf_5=%E9%AB%98",
+		},
+		{
+			name:    "トラッカーIDが不正",
+			flags:   config.FilterConfig{Tracker: "バグ"},
+			wantErr: true,
+		},
+		{
+			name:    "カスタムフィールドの形式エラー",
+			flags:   config.FilterConfig{CustomFields: []string{"3"}},
+			wantErr: true,
+		},
+		{
+			name:    "カスタムフィールドIDが不正",
+			flags:   config.FilterConfig{CustomFields: []string{"顧客=A社"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb, err := parseIssueFilter(tt.flags)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("エラーが返されなかった")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if got := fb.Build(); got != tt.want {
+				t.Errorf("Build() = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfilePath(t *testing.T) {
+	tests := []struct {
+		name         string
+		path         string
+		profile      string
+		multiProfile bool
+		want         string
+	}{
+		{name: "プロファイルなし", path: "weekly.md", profile: "", want: "weekly.md"},
+		{name: "単一プロファイル", path: "weekly.md", profile: "teamA", want: "weekly.md"},
+		{name: "複数プロファイル", path: "out/weekly.xlsx", profile: "teamA", multiProfile: true, want: "out/weekly.teamA.xlsx"},
+		{name: "拡張子なし", path: "cache", profile: "teamA", multiProfile: true, want: "cache.teamA"},
+		{name: "ドットで始まる名前", path: ".cache", profile: "teamA", multiProfile: true, want: ".cache.teamA"},
+		{name: "プレースホルダー", path: "weekly-{profile}.md", profile: "teamB", want: "weekly-teamB.md"},
+		{name: "空のパス", path: "", profile: "teamA", multiProfile: true, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := profilePath(tt.path, tt.profile, tt.multiProfile); got != tt.want {
+				t.Errorf("profilePath() = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlagKeys(t *testing.T) {
+	// フラグに対応するキーはすべて設定ファイルのキーであること
+	for name, key := range flagKeys {
+		if !config.KnownKey(key) {
+			t.Errorf("--%s: 未知の設定キー %s", name, key)
+		}
+	}
+}
+
+func TestFlagOverrides(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var customFields customFieldFlags
+	fs.String("c", "redmine.config", "")
+	fs.String("mode", "summary", "")
+	fs.String("week", "", "")
+	fs.Bool("subprojects", true, "")
+	fs.Int("concurrency", config.DefaultConcurrency, "")
+	fs.Var(&customFields, "cf", "")
+
+	args := []string{"-c", "other.config", "--week", "last", "--subprojects=false", "--cf", "3=A社,B社", "--cf", "5=高"}
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("フラグのパースに失敗: %v", err)
+	}
+
+	// 指定されたフラグのみ（デフォルト値のフラグと設定ファイルに対応しないフラグは含めない）
+	want := map[string]string{
+		"Period.Week":        "last",
+		"Filter.Subprojects": "false",
+		"Filter.CustomField": "3=A社,B社\n5=高",
+	}
+	if got := flagOverrides(fs, customFields); !reflect.DeepEqual(got, want) {
+		t.Errorf("flagOverrides() = %v; want %v", got, want)
+	}
+}
+
+func TestNeedsStatuses(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       func(c *config.Config)
+		withStats bool
+		want      bool
+	}{
+		{name: "Markdown", cfg: func(c *config.Config) { c.Output.Path = "weekly.md" }, want: false},
+		{name: "CSV", cfg: func(c *config.Config) { c.Output.Path = "weekly.csv" }, want: false},
+		{name: "Excel（行の色分け）", cfg: func(c *config.Config) { c.Output.Path = "weekly.xlsx" }, want: true},
+		{name: "HTML（ステータスのバッジ）", cfg: func(c *config.Config) { c.Output.Path = "weekly.html" }, want: true},
+		{name: "テンプレート優先", cfg: func(c *config.Config) { c.Output.Path = "weekly.xlsx"; c.Template.Path = "t.tmpl" }, want: false},
+		{name: "--stats", cfg: func(c *config.Config) { c.Output.Path = "weekly.md"; c.Stats.Show = true }, want: true},
+		{name: "stats コマンド", cfg: func(c *config.Config) { c.Output.Path = "weekly.md" }, withStats: true, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			tt.cfg(cfg)
+			if got := needsStatuses(cfg, tt.withStats); got != tt.want {
+				t.Errorf("needsStatuses() = %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchIssues_TrendIncludesUntouchedOpenIssues(t *testing.T) {
+	// 期間中に更新されていない未完了のチケットも、週末時点の未完了・期限切れに含めること
+	var gotCreatedOn, gotUpdatedOn string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/issue_statuses.json":
+			json.NewEncoder(w).Encode(map[string]interface{}{"issue_statuses": []map[string]interface{}{
+				{"id": 1, "name": "新規", "is_closed": false},
+				{"id": 2, "name": "進行中", "is_closed": false},
+			}})
+			return
+		case "/trackers.json", "/enumerations/issue_priorities.json":
+			w.Write([]byte("{}"))
+			return
+		}
+		gotCreatedOn = r.URL.Query().Get("created_on")
+		gotUpdatedOn = r.URL.Query().Get("updated_on")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issues": []map[string]interface{}{
+				// 期間より前に作成・更新され、期日を過ぎて未完了
+				{"id": 1, "subject": "放置", "status": map[string]interface{}{"id": 1, "name": "新規"},
+					"created_on": "2025-12-01T00:00:00Z", "updated_on": "2025-12-01T00:00:00Z", "due_date": "2025-12-20"},
+				// 1週目に作成・更新
+				{"id": 2, "subject": "対応中", "status": map[string]interface{}{"id": 2, "name": "進行中"},
+					"created_on": "2026-01-06T00:00:00Z", "updated_on": "2026-01-07T00:00:00Z"},
+			},
+			"total_count": 2,
+		})
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "trend.config")
+	content := "[Redmine]\nBaseUrl=" + server.URL + "\nApiKey=key\nFilterUrl=/issues.json?status_id=*\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("設定ファイルの作成に失敗: %v", err)
+	}
+	cfg, err := config.ReadConfig(path, config.LoadOptions{Overrides: map[string]string{
+		"Period.FromWeek": "2026-02",
+		"Period.ToWeek":   "2026-03",
+	}})
+	if err != nil {
+		t.Fatalf("ReadConfig()でエラー: %v", err)
+	}
+
+	setQuiet(true)
+	defer setQuiet(false)
+	result, err := fetchIssues(cfg, nil, false)
+	if err != nil {
+		t.Fatalf("fetchIssues()でエラー: %v", err)
+	}
+
+	if gotCreatedOn != "<=2026-01-18" || gotUpdatedOn != "" {
+		t.Errorf("取得時のフィルタ = created_on %q, updated_on %q; want created_on <=2026-01-18 のみ", gotCreatedOn, gotUpdatedOn)
+	}
+	// --transitions なしでも、統計の完了の判定にステータスの is_closed を使用する
+	if result.statsOpts.Statuses == nil || !result.statsOpts.Statuses.ClosedKnown {
+		t.Errorf("ステータスの一覧を取得していない: %+v", result.statsOpts.Statuses)
+	}
+	if len(result.trend) != 2 {
+		t.Fatalf("推移 = %d週; want 2", len(result.trend))
+	}
+	for i, w := range result.trend {
+		if w.Open != 2 || w.Overdue != 1 {
+			t.Errorf("週%d = 未完了%d 期限切れ%d; want 2 1", i+1, w.Open, w.Overdue)
+		}
+	}
+	if result.trend[0].Created != 1 {
+		t.Errorf("週1の作成 = %d; want 1", result.trend[0].Created)
+	}
+	// レポートに出力するのは期間フィルタ（updated_on）に一致するチケットのみ
+	if len(result.roots) != 1 || result.roots[0].ID != 2 {
+		t.Errorf("出力するチケット = %d件; want #2 のみ", len(result.roots))
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tktomaru/redmine-exporter/internal/cache"
+	"github.com/tktomaru/redmine-exporter/internal/config"
+	"github.com/tktomaru/redmine-exporter/internal/filter"
+	"github.com/tktomaru/redmine-exporter/internal/state"
+)
+
+// stateConfig はプロファイルの設定を読み込み、Stateファイルとキャッシュディレクトリのパスを返す
+func stateConfig(ctx *commandContext, profile string) (stateFile, cacheDir string, err error) {
+	cfg, err := readConfig(ctx.configPath, config.LoadOptions{Profile: profile, Overrides: ctx.overrides})
+	if err != nil {
+		return "", "", err
+	}
+	applyProfilePaths(cfg, ctx.multiProfile)
+	if cfg.State.File == "" {
+		return "", "", &configError{fmt.Errorf("Stateファイルを指定してください (--state または [State] File)")}
+	}
+
+	cacheDir = cfg.State.CacheDir
+	if cacheDir == "" {
+		cacheDir = cache.DefaultDir(cfg.State.File)
+	}
+	return cfg.State.File, cacheDir, nil
+}
+
+// runStateShow はStateファイルの内容とキャッシュの件数を表示する（state show コマンド）
+func runStateShow(ctx *commandContext) error {
+	return forEachProfile(ctx, func(profile string) error {
+		stateFile, cacheDir, err := stateConfig(ctx, profile)
+		if err != nil {
+			return err
+		}
+		return showState(os.Stdout, stateFile, cacheDir)
+	})
+}
+
+// showState はStateファイルの内容とキャッシュの件数を表示する
+func showState(w io.Writer, stateFile, cacheDir string) error {
+	if _, err := os.Stat(stateFile); os.IsNotExist(err) {
+		fmt.Fprintf(w, "Stateファイル: %s（未作成）\n", stateFile)
+	} else {
+		st, err := state.NewManager(stateFile).Load()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Stateファイル: %s\n", stateFile)
+		fmt.Fprintf(w, "  最終実行: %s\n", formatStateTime(st.LastRun))
+		fmt.Fprintf(w, "  最終成功実行: %s（--since auto の開始日時）\n", formatStateTime(st.LastSuccessRun))
+		if st.Version != "" {
+			fmt.Fprintf(w, "  バージョン: %s\n", st.Version)
+		}
+		if len(st.FilterConfig) > 0 {
+			keys := make([]string, 0, len(st.FilterConfig))
+			for k := range st.FilterConfig {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			pairs := make([]string, len(keys))
+			for i, k := range keys {
+				pairs[i] = k + "=" + st.FilterConfig[k]
+			}
+			fmt.Fprintf(w, "  フィルタ設定: %s\n", strings.Join(pairs, ", "))
+		}
+	}
+
+	if n := cache.NewStore(cacheDir).Count(); n > 0 {
+		fmt.Fprintf(w, "キャッシュ: %s（%d 件）\n", cacheDir, n)
+	} else {
+		fmt.Fprintf(w, "キャッシュ: %s（なし）\n", cacheDir)
+	}
+	return nil
+}
+
+// formatStateTime はStateの日時を表示用にフォーマットする（レポートの日時と同じタイムゾーン）
+func formatStateTime(t time.Time) string {
+	if t.IsZero() {
+		return "なし"
+	}
+	return t.In(filter.Location()).Format("2006/01/02 15:04:05")
+}
+
+// runStateReset はStateファイル（--cache 指定時はキャッシュも）を削除する（state reset コマンド）
+// 実行中のエクスポートと競合しないよう、Stateファイルのロックを取得してから削除する
+func runStateReset(ctx *commandContext) error {
+	return forEachProfile(ctx, func(profile string) error {
+		stateFile, cacheDir, err := stateConfig(ctx, profile)
+		if err != nil {
+			return err
+		}
+		return resetState(os.Stdout, stateFile, cacheDir, ctx.withCache)
+	})
+}
+
+// resetState はStateファイルを削除し、withCacheがtrueの場合はキャッシュディレクトリも削除する
+func resetState(w io.Writer, stateFile, cacheDir string, withCache bool) error {
+	lock, err := state.AcquireLock(stateFile, 10*time.Second)
+	if err != nil {
+		return fmt.Errorf("ファイルロック取得エラー: %w", err)
+	}
+	defer lock.Release()
+
+	if err := os.Remove(stateFile); err == nil {
+		fmt.Fprintf(w, "Stateファイルを削除しました: %s（次回の --since auto は期間の指定が必要です）\n", stateFile)
+	} else if os.IsNotExist(err) {
+		fmt.Fprintf(w, "Stateファイルはありません: %s\n", stateFile)
+	} else {
+		return fmt.Errorf("Stateファイル削除エラー: %w", err)
+	}
+
+	if !withCache {
+		return nil
+	}
+	if _, err := os.Stat(cacheDir); os.IsNotExist(err) {
+		fmt.Fprintf(w, "キャッシュはありません: %s\n", cacheDir)
+		return nil
+	}
+	if err := os.RemoveAll(cacheDir); err != nil {
+		return fmt.Errorf("キャッシュ削除エラー: %w", err)
+	}
+	fmt.Fprintf(w, "キャッシュを削除しました: %s\n", cacheDir)
+	return nil
+}
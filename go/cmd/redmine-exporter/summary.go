@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// summarySchemaVersion は実行サマリー（--summary-json）のスキーマバージョン
+// JSON出力と同じく、フィールドの追加ではバージョンを上げない
+const summarySchemaVersion = "1"
+
+// 実行サマリーの status
+const (
+	summaryStatusSuccess = "success"
+	summaryStatusPartial = "partial" // 一部のチケットのコメント取得に失敗（レポートは出力済み）
+	summaryStatusEmpty   = "empty"   // 対象のチケットがない
+	summaryStatusError   = "error"
+)
+
+// runSummary は1プロファイル分の実行結果（--summary-json で出力）
+type runSummary struct {
+	SchemaVersion   string              `json:"schema_version"`
+	Version         string              `json:"version"` // redmine-exporterのバージョン
+	Command         string              `json:"command"` // export, stats, template render
+	Profile         string              `json:"profile"`
+	Status          string              `json:"status"`
+	ExitCode        int                 `json:"exit_code"`
+	Error           string              `json:"error,omitempty"` // status が error の場合のメッセージ
+	StartedAt       time.Time           `json:"started_at"`
+	FinishedAt      time.Time           `json:"finished_at"`
+	DurationSeconds float64             `json:"duration_seconds"`
+	Offline         bool                `json:"offline"`
+	Period          *summaryPeriod      `json:"period"` // 期間の指定がない場合はnull
+	Counts          summaryCounts       `json:"counts"`
+	Output          *summaryOutput      `json:"output"` // 出力していない場合はnull
+	IssueErrors     []summaryIssueError `json:"issue_errors"`
+
+	path string // 出力先（空の場合は出力しない）
+}
+
+// summaryPeriod は期間フィルタ
+type summaryPeriod struct {
+	Field string    `json:"field"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// summaryCounts は件数
+type summaryCounts struct {
+	Fetched     int `json:"fetched"`      // 取得したチケット数（期間フィルタ適用後）
+	Output      int `json:"output"`       // 出力したチケット数（親チケットは子チケットの数で数える）
+	IssueErrors int `json:"issue_errors"` // コメントの取得に失敗したチケット数
+	TimeEntries int `json:"time_entries"` // 取得した作業時間の件数（--time-entries 指定時）
+}
+
+// summaryOutput はレポートの出力先
+type summaryOutput struct {
+	Path   string `json:"path"` // 標準出力の場合は空
+	Format string `json:"format"`
+	Stdout bool   `json:"stdout"`
+}
+
+// summaryIssueError はチケットごとの取得エラー
+type summaryIssueError struct {
+	IssueID int    `json:"issue_id"`
+	Message string `json:"message"`
+}
+
+// newRunSummary は実行開始時点のサマリーを作成する
+func newRunSummary(command, profile string) *runSummary {
+	return &runSummary{
+		SchemaVersion: summarySchemaVersion,
+		Version:       version,
+		Command:       command,
+		Profile:       profile,
+		StartedAt:     time.Now(),
+		IssueErrors:   []summaryIssueError{},
+	}
+}
+
+// setResult はチケットの取得結果を記録する
+func (s *runSummary) setResult(result *fetchResult) {
+	s.Counts.Fetched = result.fetched
+	s.Counts.Output = result.ticketCount
+	s.Counts.TimeEntries = result.timeEntries
+	if f := result.dateFilter; f != nil {
+		s.Period = &summaryPeriod{Field: f.Field, Start: f.Start, End: f.End}
+	}
+	if result.partial != nil {
+		for _, ie := range result.partial.Errors {
+			s.IssueErrors = append(s.IssueErrors, summaryIssueError{IssueID: ie.IssueID, Message: ie.Err.Error()})
+		}
+		s.Counts.IssueErrors = len(result.partial.Errors)
+	}
+}
+
+// setOutput はレポートの出力先を記録する
+// formatterPathは出力形式の判定に使ったパス（標準出力の場合はテンプレートまたはダミーのパス）
+func (s *runSummary) setOutput(path, formatterPath string, stdout bool) {
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(formatterPath)), ".")
+	if format == "tmpl" {
+		format = "template"
+	}
+	if stdout {
+		path = ""
+	}
+	s.Output = &summaryOutput{Path: path, Format: format, Stdout: stdout}
+}
+
+// finish は実行結果（エラーと終了コード）を記録する
+func (s *runSummary) finish(err error, code int) {
+	s.FinishedAt = time.Now()
+	s.DurationSeconds = math.Round(s.FinishedAt.Sub(s.StartedAt).Seconds()*1000) / 1000
+	s.ExitCode = code
+	switch code {
+	case exitOK:
+		s.Status = summaryStatusSuccess
+	case exitPartial:
+		s.Status = summaryStatusPartial
+	case exitEmpty:
+		s.Status = summaryStatusEmpty
+	default:
+		s.Status = summaryStatusError
+		if err != nil {
+			s.Error = err.Error()
+		}
+	}
+}
+
+// write はサマリーをJSONファイルに出力する（出力先が未指定の場合は何もしない）
+func (s *runSummary) write() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("サマリーの作成に失敗: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("ディレクトリ作成エラー: %w", err)
+	}
+	if err := os.WriteFile(s.path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("サマリーの書き込みに失敗: %w", err)
+	}
+	return nil
+}
+
+// withSummary はfnを実行し、結果をサマリーに記録して --summary-json のファイルに出力する
+// 設定の読み込みに失敗した場合も、コマンドラインの --summary-json が指定されていれば出力する
+func withSummary(ctx *commandContext, command, profile string, fn func(summary *runSummary) error) error {
+	summary := newRunSummary(command, profile)
+	summary.path = profilePath(ctx.overrides["Output.SummaryJSON"], profile, ctx.multiProfile)
+
+	err := fn(summary)
+	summary.finish(err, exitCode(err))
+	if werr := summary.write(); werr != nil {
+		if err == nil || exitCode(err) == exitPartial || exitCode(err) == exitEmpty {
+			return werr
+		}
+		fmt.Fprintf(os.Stderr, "警告: %v\n", werr)
+	}
+	return err
+}
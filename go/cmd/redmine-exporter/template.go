@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tktomaru/redmine-exporter/internal/config"
+	"github.com/tktomaru/redmine-exporter/internal/formatter"
+)
+
+// runTemplateLint はテンプレートをチェックする（template lint コマンド）
+// 引数でテンプレートを指定しない場合は設定ファイルの [Template] Path をチェックする
+func runTemplateLint(ctx *commandContext) error {
+	paths := ctx.args
+	if path, ok := ctx.overrides["Template.Path"]; ok && len(paths) == 0 {
+		paths = []string{path}
+	}
+	if len(paths) == 0 {
+		for _, p := range ctx.profiles {
+			cfg, err := readConfig(ctx.configPath, config.LoadOptions{Profile: p, Overrides: ctx.overrides})
+			if err != nil {
+				return err
+			}
+			if cfg.Template.Path != "" {
+				paths = append(paths, cfg.Template.Path)
+			}
+		}
+		if len(paths) == 0 {
+			return &configError{fmt.Errorf("テンプレートを指定してください（引数、--template または [Template] Path）")}
+		}
+	}
+
+	if failed := lintTemplates(os.Stdout, paths); failed > 0 {
+		return errFailed
+	}
+	return nil
+}
+
+// lintTemplates はテンプレートごとの結果を表示し、エラーのあったテンプレートの数を返す
+func lintTemplates(w io.Writer, paths []string) int {
+	failed := 0
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		if seen[path] {
+			continue // 複数のプロファイルで同じテンプレート
+		}
+		seen[path] = true
+		if err := formatter.LintTemplate(path); err != nil {
+			fmt.Fprintf(w, "エラー: %s: %v\n", path, err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(w, "OK: %s\n", path)
+	}
+	return failed
+}
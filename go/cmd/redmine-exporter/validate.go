@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tktomaru/redmine-exporter/internal/config"
+	"github.com/tktomaru/redmine-exporter/internal/filter"
+	"github.com/tktomaru/redmine-exporter/internal/formatter"
+	"github.com/tktomaru/redmine-exporter/internal/processor"
+)
+
+// checkConfig は設定ファイルのチェック（config.Check）に加えて、
+// 実行時に使う値（出力形式、ソート・グルーピング、コメント・週の指定など）をチェックする
+func checkConfig(cfg *config.Config) []config.Diagnostic {
+	diags := cfg.Check()
+	add := func(id string, severity config.Severity, format string, args ...interface{}) {
+		diags = append(diags, cfg.Diagnose(id, severity, format, args...))
+	}
+
+	// 出力形式・テンプレート
+	if cfg.Template.Path != "" {
+		if _, err := formatter.NewTemplateFormatter(cfg.Template.Path); err != nil {
+			add("Template.Path", config.SeverityError, "テンプレートを読み込めません: %v", err)
+		}
+	} else if cfg.Output.Path != "" && !cfg.Output.Stdout {
+		if _, err := formatter.DetectFormatter(cfg.Output.Path, cfg.Output.Mode, cfg.Output.TagNames, ""); err != nil {
+			add("Output.Path", config.SeverityError, "%v", err)
+		}
+	}
+
+	// 出力列（Excel/CSV/TSVのみ）
+	if len(cfg.Output.Columns) > 0 {
+		if _, err := formatter.ParseColumns(cfg.Output.Columns); err != nil {
+			add("Output.Columns", config.SeverityError, "%v", err)
+		} else if !isTableOutput(cfg) {
+			add("Output.Columns", config.SeverityWarning, "Excel/CSV/TSV 以外の出力では使用されません")
+		}
+	}
+	if cfg.Output.History && !isHistoryOutput(cfg) {
+		add("Output.History", config.SeverityWarning, "Markdownのfullモード・Excel・テンプレート以外の出力では使用されません")
+	}
+	if _, err := formatter.ParseEncoding(cfg.Output.CSVEncoding); err != nil {
+		add("Output.CSVEncoding", config.SeverityError, "%v", err)
+	}
+	if cfg.Output.SummaryJSON != "" && cfg.Output.SummaryJSON == cfg.Output.Path && !cfg.Output.Stdout {
+		add("Output.SummaryJSON", config.SeverityError, "出力ファイル（[Output] Path）と同じファイルは指定できません: %s", cfg.Output.SummaryJSON)
+	}
+
+	// 週の指定
+	if cfg.Period.Week != "" {
+		wc, err := filter.NewWeekCalculator(cfg.Period.WeekStart, filter.TimeZone)
+		if err == nil {
+			_, _, err = wc.GetWeekRange(cfg.Period.Week)
+		}
+		if err != nil {
+			add("Period.Week", config.SeverityError, "週の指定が不正です: %s (last, this, YYYY-WW)", cfg.Period.Week)
+		}
+	}
+	if cfg.Period.Trend() && !(cfg.Period.Weeks > 0 && cfg.Period.FromWeek != "") {
+		if _, err := trendWeeks(cfg); err != nil {
+			key := "Period.Weeks"
+			if cfg.Period.FromWeek != "" {
+				key = "Period.FromWeek"
+			}
+			add(key, config.SeverityError, "推移の週の指定が不正です: %v", err)
+		}
+	}
+	if cfg.Period.Range != "" {
+		if version, ok := strings.CutPrefix(cfg.Period.Range, "sprint:"); ok {
+			// スプリントの期間はRedmineのバージョンから取得するため、ここではプロジェクトの指定のみ確認する
+			switch {
+			case strings.TrimSpace(version) == "":
+				add("Period.Range", config.SeverityError, "バージョンが指定されていません: %s (sprint:<バージョンのIDまたは名前>)", cfg.Period.Range)
+			case cfg.State.Offline:
+				add("Period.Range", config.SeverityError, "Offline（--offline）ではRedmineのバージョンを取得できないため、sprint: を使用できません")
+			case sprintProject(cfg) == "":
+				add("Period.Range", config.SeverityError, "sprint: を使用するには --project または FilterUrl の project_id でプロジェクトを指定してください")
+			}
+		} else if pc, err := periodCalculator(cfg, nil); err == nil {
+			if _, _, err := pc.GetRange(cfg.Period.Range); err != nil {
+				add("Period.Range", config.SeverityError, "%v", err)
+			}
+		}
+	}
+
+	// コメント
+	if _, err := parseCommentsLimit(cfg.Comments.Mode); err != nil {
+		add("Comments.Mode", config.SeverityError, "%v (last, all, n:3)", err)
+	}
+	switch since := cfg.Comments.Since; since {
+	case "", "auto", "start":
+		if since != "" && cfg.Period.Week == "" && cfg.Period.Range == "" && cfg.Period.Since == "" && cfg.Period.Until == "" && !cfg.Period.Trend() {
+			add("Comments.Since", config.SeverityWarning, "期間（[Period] Week / Range / Since / Until）が指定されていないため無視されます")
+		}
+	default:
+		if _, err := time.Parse("2006-01-02", since); err != nil {
+			add("Comments.Since", config.SeverityError, "日付の形式が不正です: %s (auto, start, YYYY-MM-DD)", since)
+		}
+	}
+
+	// グルーピング・ソート
+	if cfg.Grouping.GroupBy != "" && processor.NewGrouper(cfg.Grouping.GroupBy) == nil {
+		add("Grouping.GroupBy", config.SeverityError, "未対応のグルーピング方法です: %s (assignee, status, tracker, project, priority, cf:<カスタムフィールド名>)", cfg.Grouping.GroupBy)
+	}
+	if cfg.Grouping.Sort != "" && processor.NewSorter(cfg.Grouping.Sort) == nil {
+		add("Grouping.Sort", config.SeverityError, "未対応のソート方法です: %s (updated_on, created_on, due_date, start_date, priority, id, cf:<カスタムフィールド名>、:asc/:desc)", cfg.Grouping.Sort)
+	}
+
+	return diags
+}
+
+// isTableOutput は出力が表形式（Excel/CSV/TSV）かどうかを判定
+func isTableOutput(cfg *config.Config) bool {
+	if cfg.Template.Path != "" || cfg.Output.Stdout {
+		return false
+	}
+	switch strings.ToLower(filepath.Ext(cfg.Output.Path)) {
+	case ".xlsx", ".csv", ".tsv":
+		return true
+	}
+	return false
+}
+
+// isHistoryOutput は出力が変更履歴を含む形式（Markdownのfullモード・Excel・テンプレート）かどうかを判定
+func isHistoryOutput(cfg *config.Config) bool {
+	if cfg.Template.Path != "" {
+		return true
+	}
+	path := cfg.Output.Path
+	if cfg.Output.Stdout {
+		path = "stdout.md" // 標準出力はMarkdown
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xlsx", ".tmpl":
+		return true
+	case ".md":
+		return cfg.Output.Mode == "full"
+	}
+	return false
+}
+
+// printWarnings は警告を標準エラー出力に表示し、エラーがあれば *config.ValidationError を返す
+func printWarnings(w io.Writer, diags []config.Diagnostic) error {
+	for _, d := range diags {
+		if d.Severity == config.SeverityWarning {
+			fmt.Fprintf(w, "警告: %s\n", d)
+		}
+	}
+	if errs := config.Errors(diags); len(errs) > 0 {
+		return &config.ValidationError{Diagnostics: errs}
+	}
+	return nil
+}
+
+// validateConfig は設定ファイルをチェックして問題を一覧表示し、エラーの件数を返す
+// プロファイルが未指定の場合はデフォルトと定義済みのすべてのプロファイルをチェックする
+func validateConfig(w io.Writer, configPath string, profiles []string, overrides map[string]string) (int, error) {
+	if len(profiles) == 1 && profiles[0] == "" {
+		defined, err := config.ListProfiles(configPath)
+		if err != nil {
+			return 0, err
+		}
+		profiles = append(profiles, defined...)
+	}
+
+	errors, warnings := 0, 0
+	seen := make(map[string]bool)
+	report := func(severity config.Severity, msg string) {
+		line := fmt.Sprintf("%s: %s", severity, msg)
+		if seen[line] {
+			return // デフォルトのセクションの問題はプロファイルごとに重複して見つかる
+		}
+		seen[line] = true
+		fmt.Fprintln(w, line)
+		if severity == config.SeverityError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+
+	for _, p := range profiles {
+		cfg, err := config.ReadConfig(configPath, config.LoadOptions{Profile: p, Overrides: overrides})
+		if err != nil {
+			if p != "" {
+				err = fmt.Errorf("プロファイル %s: %w", p, err)
+			}
+			report(config.SeverityError, err.Error())
+			continue
+		}
+		for _, d := range checkConfig(cfg) {
+			report(d.Severity, d.String())
+		}
+	}
+
+	if errors == 0 && warnings == 0 {
+		fmt.Fprintf(w, "%s: 問題は見つかりませんでした\n", configPath)
+	} else {
+		fmt.Fprintf(w, "%s: エラー %d 件、警告 %d 件\n", configPath, errors, warnings)
+	}
+	return errors, nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tktomaru/redmine-exporter/internal/config"
+)
+
+func TestCheckConfig(t *testing.T) {
+	const base = "[Redmine]\nBaseUrl=https://redmine.example.com\nApiKey=key\nFilterUrl=/issues.json\n"
+
+	tests := []struct {
+		name      string
+		content   string
+		overrides map[string]string
+		want      []string // 含まれるべき問題（「重要度: 内容」の一部）
+	}{
+		{
+			name:    "問題なし",
+			content: base + "[Output]\nPath=weekly.xlsx\nColumns=id,subject\n[Grouping]\nGroupBy=assignee\nSort=due_date,priority:desc\n",
+		},
+		{
+			name:    "未対応のグルーピング・ソート",
+			content: base + "[Grouping]\nGroupBy=owner\nSort=due_date,foo\n",
+			want:    []string{"エラー: check.config:6: [Grouping] GroupBy: 未対応のグルーピング方法です: owner", "エラー: check.config:7: [Grouping] Sort: 未対応のソート方法です"},
+		},
+		{
+			name:    "出力形式と出力列",
+			content: base + "[Output]\nPath=weekly.pdf\nColumns=id,foo\nCSVEncoding=euc-jp\n",
+			want:    []string{"[Output] Path: 未対応の拡張子", "[Output] Columns:", "[Output] CSVEncoding: 未対応の文字コード"},
+		},
+		{
+			name:      "サマリーと出力ファイルが同じ",
+			content:   base + "[Output]\nPath=weekly.json\n",
+			overrides: map[string]string{"Output.SummaryJSON": "weekly.json"},
+			want:      []string{"エラー: [Output] SummaryJSON（コマンドライン）: 出力ファイル（[Output] Path）と同じファイルは指定できません"},
+		},
+		{
+			name:    "表形式以外の出力列は警告",
+			content: base + "[Output]\nPath=weekly.md\nColumns=id,subject\n",
+			want:    []string{"警告: check.config:7: [Output] Columns: Excel/CSV/TSV 以外の出力では使用されません"},
+		},
+		{
+			name:      "変更履歴を出力しない形式は警告",
+			content:   base + "[Output]\nPath=weekly.md\nHistory=true\n",
+			overrides: map[string]string{"Output.Mode": "full", "Output.Path": "weekly.csv"},
+			want:      []string{"警告: check.config:7: [Output] History: Markdownのfullモード・Excel・テンプレート以外の出力では使用されません"},
+		},
+		{
+			name:      "コメント・週の指定（コマンドライン）",
+			content:   base,
+			overrides: map[string]string{"Comments.Mode": "n:x", "Period.Week": "next", "Comments.Since": "start"},
+			want:      []string{"[Comments] Mode（コマンドライン）", "[Period] Week（コマンドライン）: 週の指定が不正です"},
+		},
+		{
+			name:      "推移の週の指定（コマンドライン）",
+			content:   base,
+			overrides: map[string]string{"Period.FromWeek": "2025-10", "Period.ToWeek": "2025-02"},
+			want:      []string{"エラー: [Period] FromWeek（コマンドライン）: 推移の週の指定が不正です: 開始週 (2025-10) が終了週 (2025-02) より後です"},
+		},
+		{
+			name:      "期間指定（コマンドライン）",
+			content:   base,
+			overrides: map[string]string{"Period.Range": "2025-13"},
+			want:      []string{"エラー: [Period] Range（コマンドライン）: 月の形式エラー: 2025-13"},
+		},
+		{
+			name:      "スプリントはプロジェクトが必要",
+			content:   base,
+			overrides: map[string]string{"Period.Range": "sprint:Sprint 12"},
+			want:      []string{"エラー: [Period] Range（コマンドライン）: sprint: を使用するには --project"},
+		},
+		{
+			name:    "スプリントのプロジェクトはFilterUrlから判定",
+			content: "[Redmine]\nBaseUrl=https://redmine.example.com\nApiKey=key\nFilterUrl=/issues.json?project_id=myproj\n[Period]\nRange=sprint:Sprint 12\n",
+		},
+		{
+			name:      "期間なしのComments.Sinceは警告",
+			content:   base,
+			overrides: map[string]string{"Comments.Since": "start"},
+			want:      []string{"警告: [Comments] Since（コマンドライン）: 期間"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "check.config")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("設定ファイルの作成に失敗: %v", err)
+			}
+			cfg, err := config.ReadConfig(path, config.LoadOptions{Overrides: tt.overrides})
+			if err != nil {
+				t.Fatalf("ReadConfig()でエラー: %v", err)
+			}
+
+			var lines []string
+			for _, d := range checkConfig(cfg) {
+				lines = append(lines, d.Severity.String()+": "+strings.ReplaceAll(d.String(), path, "check.config"))
+			}
+			got := strings.Join(lines, "\n")
+
+			if len(tt.want) == 0 && got != "" {
+				t.Errorf("checkConfig() = %s; want 問題なし", got)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("checkConfig() に %q が含まれていない:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tktomaru/redmine-exporter/internal/redmine"
+)
+
+// entry はキャッシュファイル1件分の内容
+type entry struct {
+	CachedAt time.Time      `json:"cached_at"`           // キャッシュ保存日時
+	Issue    *redmine.Issue `json:"issue"`               // チケット
+	ListOnly bool           `json:"list_only,omitempty"` // 一覧の取得結果のみ（journalsを取得していない）
+}
+
+// list は一覧の取得結果（オフライン実行用）
+type list struct {
+	Scope    string    `json:"scope"`     // 接続先とFilterUrl
+	CachedAt time.Time `json:"cached_at"` // キャッシュ保存日時
+	IssueIDs []int     `json:"issue_ids"` // 一覧に含まれたチケット（取得順）
+}
+
+// Store はチケットをディレクトリにJSONで保存するキャッシュ
+// 1チケット1ファイル（issues/<ID>.json）で保存し、一覧の取得結果は接続先・FilterUrlごとに
+// lists/<キー>.json にチケットIDを保存する
+type Store struct {
+	dir string
+}
+
+// NewStore は新しいStoreを作成
+func NewStore(dir string) *Store {
+	return &Store{
+		dir: dir,
+	}
+}
+
+// DefaultDir はStateファイルの隣に置くキャッシュディレクトリのパスを返す
+// 例: ".state.json" → ".state.cache"
+func DefaultDir(stateFile string) string {
+	return strings.TrimSuffix(stateFile, filepath.Ext(stateFile)) + ".cache"
+}
+
+// Dir はキャッシュディレクトリのパスを返す
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// issuePath はチケットのキャッシュファイルのパスを返す
+func (s *Store) issuePath(issueID int) string {
+	return filepath.Join(s.dir, "issues", strconv.Itoa(issueID)+".json")
+}
+
+// listPath は一覧のキャッシュファイルのパスを返す
+func (s *Store) listPath(scope string) string {
+	sum := sha256.Sum256([]byte(scope))
+	return filepath.Join(s.dir, "lists", hex.EncodeToString(sum[:8])+".json")
+}
+
+// Get はキャッシュからjournals付きのチケットを読み込む
+// ファイルがない、破損している、または一覧の取得結果のみの場合はfalseを返す
+func (s *Store) Get(issueID int) (*redmine.Issue, bool) {
+	e, err := s.read(s.issuePath(issueID))
+	if err != nil || e.ListOnly {
+		return nil, false
+	}
+	return e.Issue, true
+}
+
+// Put はjournals付きのチケットをキャッシュに保存
+func (s *Store) Put(issue *redmine.Issue) error {
+	if issue == nil {
+		return nil
+	}
+	return s.write(s.issuePath(issue.ID), entry{CachedAt: time.Now(), Issue: issue})
+}
+
+// PutList は一覧の取得結果を保存する（オフライン実行用）
+// scopeは接続先とFilterUrlなど一覧を区別するキーで、同じscopeの前回の一覧は置き換える
+// journals付きのキャッシュが一覧のupdated_on以降の場合は、チケットのキャッシュを上書きしない
+func (s *Store) PutList(scope string, issues []*redmine.Issue) error {
+	ids := make([]int, 0, len(issues))
+	for _, issue := range issues {
+		ids = append(ids, issue.ID)
+		if cached, ok := s.Get(issue.ID); ok && !isNewer(issue, cached) {
+			continue
+		}
+		if err := s.write(s.issuePath(issue.ID), entry{CachedAt: time.Now(), Issue: issue, ListOnly: true}); err != nil {
+			return err
+		}
+	}
+	return s.write(s.listPath(scope), list{Scope: scope, CachedAt: time.Now(), IssueIDs: ids})
+}
+
+// LoadList はPutListで保存した一覧のチケットを取得順で返す（オフライン実行用）
+// withoutJournalsはjournalsを取得していないチケットの件数
+func (s *Store) LoadList(scope string) (issues []*redmine.Issue, withoutJournals int, err error) {
+	data, err := os.ReadFile(s.listPath(scope))
+	if os.IsNotExist(err) {
+		if s.Count() == 0 {
+			return nil, 0, fmt.Errorf("キャッシュが空です: %s", s.dir)
+		}
+		return nil, 0, fmt.Errorf("キャッシュにこの接続先・FilterUrlの一覧がありません: %s（同じ条件でオンラインで一度実行してください）", s.dir)
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("キャッシュ読み込みエラー: %w", err)
+	}
+	var l list
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, 0, fmt.Errorf("キャッシュ破損 (%s): %w", s.listPath(scope), err)
+	}
+	if l.Scope != scope {
+		return nil, 0, fmt.Errorf("キャッシュにこの接続先・FilterUrlの一覧がありません: %s（同じ条件でオンラインで一度実行してください）", s.dir)
+	}
+
+	issues = make([]*redmine.Issue, 0, len(l.IssueIDs))
+	for _, id := range l.IssueIDs {
+		e, err := s.read(s.issuePath(id))
+		if err != nil {
+			return nil, 0, err
+		}
+		if e.ListOnly {
+			withoutJournals++
+		}
+		issues = append(issues, e.Issue)
+	}
+	return issues, withoutJournals, nil
+}
+
+// Count はキャッシュ済みのチケット数を返す（ディレクトリがない場合は0）
+func (s *Store) Count() int {
+	files, _ := filepath.Glob(filepath.Join(s.dir, "issues", "*.json"))
+	return len(files)
+}
+
+// isNewer は一覧のチケットがキャッシュより新しいか（updated_onが後か）を判定
+func isNewer(issue, cached *redmine.Issue) bool {
+	if issue.UpdatedOn == nil || cached.UpdatedOn == nil {
+		return true
+	}
+	return issue.UpdatedOn.After(cached.UpdatedOn.Time)
+}
+
+// write は値をJSONでファイルに保存する
+// 一時ファイル経由で保存（並列書き込みや中断時の破損を防ぐ）
+func (s *Store) write(path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("キャッシュJSONエラー: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("キャッシュディレクトリ作成エラー: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("キャッシュ保存エラー: %w", err)
+	}
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpFile.Name())
+		return fmt.Errorf("キャッシュ保存エラー: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		os.Remove(tmpFile.Name())
+		return fmt.Errorf("キャッシュ保存エラー: %w", err)
+	}
+	if err := os.Rename(tmpFile.Name(), path); err != nil {
+		os.Remove(tmpFile.Name())
+		return fmt.Errorf("キャッシュファイル更新エラー: %w", err)
+	}
+	return nil
+}
+
+// read はキャッシュファイルを読み込む
+func (s *Store) read(path string) (*entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("キャッシュ読み込みエラー: %w", err)
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("キャッシュ破損 (%s): %w", path, err)
+	}
+	if e.Issue == nil {
+		return nil, fmt.Errorf("キャッシュ破損 (%s): issueがありません", path)
+	}
+	return &e, nil
+}
@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tktomaru/redmine-exporter/internal/redmine"
+)
+
+func TestDefaultDir(t *testing.T) {
+	tests := []struct {
+		stateFile string
+		want      string
+	}{
+		{stateFile: ".state.json", want: ".state.cache"},
+		{stateFile: "/var/lib/exporter/weekly.state", want: "/var/lib/exporter/weekly.cache"},
+		{stateFile: "state", want: "state.cache"},
+	}
+
+	for _, tt := range tests {
+		if got := DefaultDir(tt.stateFile); got != tt.want {
+			t.Errorf("DefaultDir(%q) = %q; want %q", tt.stateFile, got, tt.want)
+		}
+	}
+}
+
+func TestStore_PutAndGet(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "cache"))
+
+	updatedOn := time.Date(2026, 1, 15, 10, 30, 0, 0, time.UTC)
+	issue := &redmine.Issue{
+		ID:        42,
+		Subject:   "キャッシュテスト",
+		Status:    redmine.IDName{ID: 2, Name: "進行中"},
+		StartDate: &redmine.Date{Time: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)},
+		UpdatedOn: &redmine.DateTime{Time: updatedOn},
+		Journals: []redmine.Journal{
+			{ID: 1, User: redmine.IDName{Name: "佐藤"}, Notes: "[進捗]半分完了[/進捗]", CreatedOn: "2026-01-14T09:00:00Z"},
+		},
+	}
+
+	if err := store.Put(issue); err != nil {
+		t.Fatalf("Put()でエラー: %v", err)
+	}
+
+	got, ok := store.Get(42)
+	if !ok {
+		t.Fatal("Get()でキャッシュが見つからない")
+	}
+
+	if got.Subject != issue.Subject || got.Status.Name != "進行中" {
+		t.Errorf("Get() = %+v; want %+v", got, issue)
+	}
+	if got.StartDate == nil || !got.StartDate.Time.Equal(issue.StartDate.Time) {
+		t.Errorf("StartDate = %v; want %v", got.StartDate, issue.StartDate)
+	}
+	if got.DueDate != nil {
+		t.Errorf("DueDate = %v; want nil", got.DueDate)
+	}
+	if got.UpdatedOn == nil || !got.UpdatedOn.Time.Equal(updatedOn) {
+		t.Errorf("UpdatedOn = %v; want %v", got.UpdatedOn, updatedOn)
+	}
+	if len(got.Journals) != 1 || got.Journals[0].Notes != "[進捗]半分完了[/進捗]" {
+		t.Errorf("Journals = %+v", got.Journals)
+	}
+
+	if _, ok := store.Get(99); ok {
+		t.Error("存在しないチケットでGet()がtrueを返した")
+	}
+}
+
+func TestStore_GetCorrupted(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(dir)
+
+	if err := os.MkdirAll(filepath.Join(dir, "issues"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "issues", "1.json"), []byte("{invalid"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := store.Get(1); ok {
+		t.Error("破損したキャッシュでGet()がtrueを返した")
+	}
+}
+
+func TestStore_PutListAndLoadList(t *testing.T) {
+	store := NewStore(t.TempDir())
+	const scope = "https://redmine.example.com /issues.json?project_id=1"
+	at := func(day int) *redmine.DateTime {
+		return &redmine.DateTime{Time: time.Date(2026, 1, day, 0, 0, 0, 0, time.UTC)}
+	}
+
+	if _, _, err := store.LoadList(scope); err == nil {
+		t.Error("空のキャッシュでLoadList()がエラーを返さなかった")
+	}
+	if n := store.Count(); n != 0 {
+		t.Errorf("空のキャッシュでCount() = %d; want 0", n)
+	}
+
+	// #7はjournals付きのキャッシュが最新
+	if err := store.Put(&redmine.Issue{ID: 7, UpdatedOn: at(10), Journals: []redmine.Journal{{ID: 1, Notes: "コメント"}}}); err != nil {
+		t.Fatalf("Put()でエラー: %v", err)
+	}
+	list := []*redmine.Issue{
+		{ID: 10, Subject: "チケット", UpdatedOn: at(10)},
+		{ID: 2, Subject: "チケット", UpdatedOn: at(10)},
+		{ID: 7, Subject: "チケット", UpdatedOn: at(10)},
+	}
+	if err := store.PutList(scope, list); err != nil {
+		t.Fatalf("PutList()でエラー: %v", err)
+	}
+
+	if n := store.Count(); n != 3 {
+		t.Errorf("Count() = %d; want 3", n)
+	}
+	// 一覧の取得結果のみのチケットはjournalsのキャッシュとして使わない
+	if _, ok := store.Get(2); ok {
+		t.Error("一覧の取得結果のみのチケットでGet()がtrueを返した")
+	}
+	if got, ok := store.Get(7); !ok || len(got.Journals) != 1 {
+		t.Errorf("PutList()でjournals付きのキャッシュが上書きされた: %+v, %v", got, ok)
+	}
+
+	issues, withoutJournals, err := store.LoadList(scope)
+	if err != nil {
+		t.Fatalf("LoadList()でエラー: %v", err)
+	}
+	wantIDs := []int{10, 2, 7}
+	if len(issues) != len(wantIDs) {
+		t.Fatalf("len(issues) = %d; want %d", len(issues), len(wantIDs))
+	}
+	for i, id := range wantIDs {
+		if issues[i].ID != id {
+			t.Errorf("issues[%d].ID = %d; want %d", i, issues[i].ID, id)
+		}
+	}
+	if withoutJournals != 2 {
+		t.Errorf("withoutJournals = %d; want 2", withoutJournals)
+	}
+
+	// 別の接続先・FilterUrlの一覧は読み込まない
+	if _, _, err := store.LoadList("https://redmine.example.com /issues.json?project_id=2"); err == nil {
+		t.Error("別のFilterUrlでLoadList()がエラーを返さなかった")
+	}
+}
@@ -2,25 +2,57 @@ package config
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"gopkg.in/ini.v1"
 )
 
 // Config はアプリケーション設定を保持
 type Config struct {
+	Profile       string            // 読み込んだプロファイル名（デフォルトの場合は空）
+	Sources       map[string]string // キー（"Redmine.ApiKey" など）ごとの値の取得元（値そのものは含まない）
 	Redmine       RedmineConfig
 	TitleCleaning TitleCleaningConfig
 	Output        OutputConfig
+	Period        PeriodConfig
+	Comments      CommentsConfig
+	Grouping      GroupingConfig
+	Filter        FilterConfig
+	State         StateConfig
+	Template      TemplateConfig
+	Stats         StatsConfig
+	Log           LogConfig
+
+	file     string            // 読み込んだ設定ファイルのパス（問題の行番号の表示用）
+	defined  map[string]string // 設定ファイルの値を使うキーと、値を定義しているセクション
+	lines    lineIndex         // 設定ファイルの行番号（初回の参照時に読み込む）
+	problems []Diagnostic      // 読み込み時に見つかった問題（未知のキー、形式エラーなど）
 }
 
 // RedmineConfig はRedmine接続設定
 type RedmineConfig struct {
-	BaseURL   string
-	APIKey    string
-	FilterURL string
+	BaseURL     string
+	APIKey      string
+	APIKeyFile  string // APIキーを記載したファイルのパス（読み込んだキーはAPIKeyに格納）
+	FilterURL   string
+	Concurrency int // ジャーナル取得の並列数
+
+	// API呼び出しのリトライ設定
+	MaxRetries      int           // 一時的なエラー時の最大リトライ回数（0はリトライなし）
+	RetryBackoff    time.Duration // 1回目のリトライまでの待機時間
+	RetryMaxBackoff time.Duration // 待機時間の上限
 }
 
+// RedmineConfigのデフォルト値
+const (
+	DefaultConcurrency     = 4
+	DefaultMaxRetries      = 3
+	DefaultRetryBackoff    = 1 * time.Second
+	DefaultRetryMaxBackoff = 30 * time.Second
+)
+
 // TitleCleaningConfig はタイトルクリーニング設定
 type TitleCleaningConfig struct {
 	Patterns []string
@@ -28,42 +60,208 @@ type TitleCleaningConfig struct {
 
 // OutputConfig は出力設定
 type OutputConfig struct {
+	Path            string   // 出力ファイルのパス（-o）
+	Stdout          bool     // 標準出力に出力するか
 	Mode            string   // summary, full, tags
-	TagNames        []string // 抽出するタグ名のリスト
+	TagNames        []string // 抽出するタグ名のリスト（"要約:3" のように個別の上限も指定可）
+	TagsOrder       string   // コメントから抽出したタグの表示順（newest, oldest）
 	IncludeComments bool     // コメントからも抽出するか
+	History         bool     // 変更履歴（ステータス・担当者などの変更）を出力するか
+	Columns         []string // 表形式（Excel/CSV/TSV）の出力列（空の場合はモードに応じた標準の列構成）
+	CSVEncoding     string   // CSV/TSVの文字コード（utf-8, utf-8-bom, sjis）
+	SummaryJSON     string   // 実行結果のサマリー（JSON）の出力先（空の場合は出力しない）
+}
+
+// PeriodConfig は期間フィルタの設定
+type PeriodConfig struct {
+	Week      string // 週指定（last, this, YYYY-WW）
+	WeekStart string // 週の起点（mon, sun）
+	DateField string // 期間の判定に使う日時フィールド（updated_on, created_on, start_date, due_date）
+	Since     string // 開始日（auto, YYYY-MM-DD）
+	Until     string // 終了日（auto, YYYY-MM-DD）
+	Weeks     int    // 推移の週数（ToWeekまでのn週間、0は推移なし）
+	FromWeek  string // 推移の開始週（last, this, YYYY-WW）
+	ToWeek    string // 推移の終了週（未指定時はWeek、それもない場合は this）
+	Range     string // 期間指定（month:last, YYYY-MM, Q3, FY2025-H1, last:14d, sprint:<バージョン> など）
+
+	FiscalYearStart int // 年度の開始月（1〜12、四半期・半期・年度の起点）
+}
+
+// Trend は週ごとの推移を集計するかどうかを返す
+func (p PeriodConfig) Trend() bool {
+	return p.Weeks > 0 || p.FromWeek != ""
+}
+
+// CommentsConfig はコメント（ジャーナル）の抽出設定
+type CommentsConfig struct {
+	Mode           string // 抽出モード（last, all, n:3）
+	Since          string // 抽出の開始日（auto, start, YYYY-MM-DD）
+	By             string // 抽出対象のユーザー
+	PreferComments bool   // 説明文よりコメントを優先するか
+}
+
+// GroupingConfig はグルーピング・ソートの設定
+type GroupingConfig struct {
+	GroupBy string // グルーピング方法（assignee, status, cf:<名前> など）
+	Sort    string // ソート方法（field または field:asc/desc）
+}
+
+// FilterConfig はチケットの絞り込み条件（FilterUrlに追加）
+// 複数の値はカンマ区切りで指定する
+type FilterConfig struct {
+	Project       string
+	Subprojects   bool // Project指定時にサブプロジェクトを含めるか
+	Tracker       string
+	Status        string
+	Assignee      string
+	TargetVersion string
+	Category      string
+	Author        string
+	CustomFields  []string // "ID=値1,値2" 形式（CustomField1, CustomField2, ... と連番で指定）
+	Query         string   // 保存済みクエリ（IDまたは名前）
+}
+
+// StateConfig は差分運用（State管理）とキャッシュの設定
+type StateConfig struct {
+	File     string // Stateファイルのパス
+	CacheDir string // キャッシュディレクトリ（空の場合はStateファイルの隣）
+	Offline  bool   // キャッシュのみでレポートを作成するか
+}
+
+// TemplateConfig はテンプレート出力の設定
+type TemplateConfig struct {
+	Path string // テンプレートファイルのパス（.tmpl）
+}
+
+// StatsConfig は統計・メトリクスの設定
+type StatsConfig struct {
+	Show            bool     // 統計情報を表示するか
+	IncludeMetrics  bool     // 詳細メトリクスを含めるか
+	TimeEntries     bool     // 期間内の作業時間を集計するか
+	Transitions     bool     // ジャーナルのステータス変更から期間内の完了・再オープン・着手を判定するか
+	StartedStatuses []string // 着手とみなすステータス名（期間内の着手の判定）
 }
 
+// LogConfig はログ出力の設定
+type LogConfig struct {
+	Verbose bool // 詳細ログを出力するか
+	Quiet   bool // 進捗表示を抑制するか（警告・エラーは表示する）
+}
+
+// profileSections はプロファイルごとに上書きできるセクション
+// [Redmine.teamA] のように「セクション名.プロファイル名」で定義し、未指定のキーはデフォルトのセクションから引き継ぐ
+var profileSections = []string{"Redmine", "TitleCleaning", "Output", "Period", "Comments", "Grouping", "Filter", "State", "Template", "Stats", "Log"}
+
 // LoadConfig は指定されたパスから設定ファイルを読み込む
 func LoadConfig(path string) (*Config, error) {
-	cfg, err := ini.Load(path)
+	return LoadProfile(path, "")
+}
+
+// LoadProfile は指定されたパスから設定ファイルを読み込み、プロファイルの設定を適用する
+// profileが空の場合はデフォルト（[Redmine], [TitleCleaning], [Output]）のみを使用する
+func LoadProfile(path, profile string) (*Config, error) {
+	return LoadWithOptions(path, LoadOptions{Profile: profile})
+}
+
+// LoadWithOptions は設定ファイルを読み込み、プロファイル・環境変数・コマンドラインの値を適用する
+// 値の優先順位は コマンドライン > 環境変数（REDMINE_API_KEY など） > 設定ファイル
+// 設定ファイルの値の ${VAR} は環境変数の値に展開し、ApiKeyFile を指定した場合はファイルからAPIキーを読み込む
+func LoadWithOptions(path string, opts LoadOptions) (*Config, error) {
+	config, err := ReadConfig(path, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// バリデーション
+	if err := config.Validate(); err != nil {
+		if config.Profile != "" {
+			return nil, fmt.Errorf("プロファイル %s: %w", config.Profile, err)
+		}
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// ReadConfig は LoadWithOptions と同じ手順で設定を読み込むが、妥当性のチェックは行わない（config show 用）
+func ReadConfig(path string, opts LoadOptions) (*Config, error) {
+	cfg, err := loadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("設定ファイルの読み込みに失敗: %w", err)
 	}
 
-	config := &Config{}
+	profile := opts.Profile
+	if profile != "" && !hasProfile(cfg, profile) {
+		return nil, fmt.Errorf("プロファイルが見つかりません: %s (定義済み: %s)", profile, strings.Join(profileNames(cfg), ", "))
+	}
 
-	// [Redmine]セクション
-	config.Redmine.BaseURL = cfg.Section("Redmine").Key("BaseUrl").String()
-	config.Redmine.APIKey = cfg.Section("Redmine").Key("ApiKey").String()
-	config.Redmine.FilterURL = cfg.Section("Redmine").Key("FilterUrl").String()
+	// FileOnlyの場合は ${VAR} も展開せず、設定ファイルに書かれたとおりの値を使う
+	var expanded map[string][]string
+	if !opts.FileOnly {
+		expanded, err = expandEnvVars(cfg, profile)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	// [TitleCleaning]セクション - Pattern1, Pattern2, ... を動的に読み込む
-	section := cfg.Section("TitleCleaning")
-	patterns := []string{}
-	for i := 1; ; i++ {
-		key := fmt.Sprintf("Pattern%d", i)
-		if !section.HasKey(key) {
-			break
+	// sectionNameはプロファイルのセクション名（なければデフォルトのセクションの値を引き継ぐ）を返す
+	sectionName := func(name string) string {
+		if profile == "" {
+			return name
 		}
-		pattern := section.Key(key).String()
-		if pattern != "" {
-			patterns = append(patterns, pattern)
+		return name + "." + profile
+	}
+	section := func(name string) *ini.Section {
+		return cfg.Section(sectionName(name))
+	}
+
+	sources := applySources(cfg, sectionName, opts, expanded)
+	config := &Config{Profile: profile, Sources: make(map[string]string, len(sources)), file: path, defined: make(map[string]string)}
+	for id, src := range sources {
+		config.Sources[id] = src.desc
+		if src.rank == rankFile {
+			config.defined[id] = src.section
 		}
 	}
-	config.TitleCleaning.Patterns = patterns
+	config.checkKeys(cfg, profile)
+	config.checkValueTypes(section)
+
+	// [Redmine]セクション
+	redmineSection := section("Redmine")
+	config.Redmine.BaseURL = redmineSection.Key("BaseUrl").String()
+	config.Redmine.APIKey = redmineSection.Key("ApiKey").String()
+	config.Redmine.APIKeyFile = redmineSection.Key("ApiKeyFile").String()
+
+	// ApiKeyFile がApiKeyと同じかより優先度の高い取得元で指定されている場合はファイルから読み込む
+	if keyFile := config.Redmine.APIKeyFile; keyFile != "" && !opts.FileOnly {
+		fileSrc := sources["Redmine.ApiKeyFile"]
+		if fileSrc.rank >= sources["Redmine.ApiKey"].rank {
+			baseDir := ""
+			if fileSrc.rank == rankFile {
+				baseDir = filepath.Dir(path) // 設定ファイルの相対パスは設定ファイルの場所から
+			}
+			apiKey, err := readSecretFile(keyFile, baseDir)
+			if err != nil {
+				return nil, fmt.Errorf("ApiKeyFileの読み込みに失敗（取得元: %s）: %w", fileSrc.desc, err)
+			}
+			config.Redmine.APIKey = apiKey
+			config.Sources["Redmine.ApiKey"] = fmt.Sprintf("ApiKeyFile %s（取得元: %s）", keyFile, fileSrc.desc)
+		}
+	}
+	config.Redmine.FilterURL = redmineSection.Key("FilterUrl").String()
+	config.Redmine.Concurrency = redmineSection.Key("Concurrency").MustInt(DefaultConcurrency)
+	config.Redmine.MaxRetries = redmineSection.Key("MaxRetries").MustInt(DefaultMaxRetries)
+	config.Redmine.RetryBackoff = redmineSection.Key("RetryBackoff").MustDuration(DefaultRetryBackoff)
+	config.Redmine.RetryMaxBackoff = redmineSection.Key("RetryMaxBackoff").MustDuration(DefaultRetryMaxBackoff)
+
+	// [TitleCleaning]セクション - Pattern1, Pattern2, ... を動的に読み込む
+	config.TitleCleaning.Patterns = numberedValues(section("TitleCleaning"), "Pattern")
+	config.recordNumberedSource(cfg, sectionName("TitleCleaning"), "TitleCleaning", "Pattern")
 
 	// [Output]セクション
-	outputSection := cfg.Section("Output")
+	outputSection := section("Output")
+	config.Output.Path = outputSection.Key("Path").String()
+	config.Output.Stdout = outputSection.Key("Stdout").MustBool(false)
 	config.Output.Mode = outputSection.Key("Mode").MustString("summary")
 
 	// TagNames - カンマ区切りのリストを読み込む
@@ -74,16 +272,171 @@ func LoadConfig(path string) (*Config, error) {
 		config.Output.TagNames = []string{"要約"}
 	}
 
+	config.Output.TagsOrder = outputSection.Key("TagsOrder").MustString("newest")
 	config.Output.IncludeComments = outputSection.Key("IncludeComments").MustBool(false)
+	config.Output.History = outputSection.Key("History").MustBool(false)
+	config.Output.Columns = splitAndTrim(outputSection.Key("Columns").String(), ",")
+	config.Output.CSVEncoding = outputSection.Key("CSVEncoding").MustString("utf-8")
+	config.Output.SummaryJSON = outputSection.Key("SummaryJSON").String()
 
-	// バリデーション
-	if err := config.Validate(); err != nil {
-		return nil, err
+	// [Period]セクション
+	periodSection := section("Period")
+	config.Period.Week = periodSection.Key("Week").String()
+	config.Period.WeekStart = periodSection.Key("WeekStart").MustString("mon")
+	config.Period.DateField = periodSection.Key("DateField").MustString("updated_on")
+	config.Period.Since = periodSection.Key("Since").String()
+	config.Period.Until = periodSection.Key("Until").String()
+	config.Period.Weeks = periodSection.Key("Weeks").MustInt(0)
+	config.Period.FromWeek = periodSection.Key("FromWeek").String()
+	config.Period.ToWeek = periodSection.Key("ToWeek").String()
+	config.Period.Range = periodSection.Key("Range").String()
+	config.Period.FiscalYearStart = periodSection.Key("FiscalYearStart").MustInt(4)
+
+	// [Comments]セクション
+	commentsSection := section("Comments")
+	config.Comments.Mode = commentsSection.Key("Mode").String()
+	config.Comments.Since = commentsSection.Key("Since").String()
+	config.Comments.By = commentsSection.Key("By").String()
+	config.Comments.PreferComments = commentsSection.Key("PreferComments").MustBool(false)
+
+	// [Grouping]セクション
+	groupingSection := section("Grouping")
+	config.Grouping.GroupBy = groupingSection.Key("GroupBy").String()
+	config.Grouping.Sort = groupingSection.Key("Sort").String()
+
+	// [Filter]セクション - カスタムフィールドは CustomField1, CustomField2, ... と連番で指定
+	filterSection := section("Filter")
+	config.Filter.Project = filterSection.Key("Project").String()
+	config.Filter.Subprojects = filterSection.Key("Subprojects").MustBool(true)
+	config.Filter.Tracker = filterSection.Key("Tracker").String()
+	config.Filter.Status = filterSection.Key("Status").String()
+	config.Filter.Assignee = filterSection.Key("Assignee").String()
+	config.Filter.TargetVersion = filterSection.Key("TargetVersion").String()
+	config.Filter.Category = filterSection.Key("Category").String()
+	config.Filter.Author = filterSection.Key("Author").String()
+	config.Filter.Query = filterSection.Key("Query").String()
+	config.Filter.CustomFields = numberedValues(filterSection, "CustomField")
+	config.recordNumberedSource(cfg, sectionName("Filter"), "Filter", "CustomField")
+
+	// コマンドラインのカスタムフィールド（改行区切り）は設定ファイルの値をすべて置き換える
+	if v := opts.Overrides["Filter.CustomField"]; v != "" && !opts.FileOnly {
+		config.Filter.CustomFields = splitAndTrim(v, "\n")
+		config.Sources["Filter.CustomField"] = "コマンドライン"
+		delete(config.defined, "Filter.CustomField")
 	}
 
+	// [State]セクション
+	stateSection := section("State")
+	config.State.File = stateSection.Key("File").String()
+	config.State.CacheDir = stateSection.Key("CacheDir").String()
+	config.State.Offline = stateSection.Key("Offline").MustBool(false)
+
+	// [Template]セクション
+	config.Template.Path = section("Template").Key("Path").String()
+
+	// [Stats]セクション
+	statsSection := section("Stats")
+	config.Stats.Show = statsSection.Key("Show").MustBool(false)
+	config.Stats.IncludeMetrics = statsSection.Key("IncludeMetrics").MustBool(false)
+	config.Stats.TimeEntries = statsSection.Key("TimeEntries").MustBool(false)
+	config.Stats.Transitions = statsSection.Key("Transitions").MustBool(false)
+	config.Stats.StartedStatuses = splitAndTrim(statsSection.Key("StartedStatuses").MustString("進行中"), ",")
+
+	// [Log]セクション
+	logSection := section("Log")
+	config.Log.Verbose = logSection.Key("Verbose").MustBool(false)
+	config.Log.Quiet = logSection.Key("Quiet").MustBool(false)
+
 	return config, nil
 }
 
+// numberedValues は Pattern1, Pattern2, ... のような連番のキーの値を読み込む（番号が途切れたところで終了、空の値は除く）
+func numberedValues(section *ini.Section, prefix string) []string {
+	values := []string{}
+	for i := 1; ; i++ {
+		key := fmt.Sprintf("%s%d", prefix, i)
+		if !section.HasKey(key) {
+			break
+		}
+		if value := section.Key(key).String(); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// recordNumberedSource は連番のキーを定義しているセクションを取得元として記録する
+func (c *Config) recordNumberedSource(cfg *ini.File, name, section, prefix string) {
+	if defined := definedSection(cfg, name, prefix+"1"); defined != "" {
+		c.Sources[section+"."+prefix] = fmt.Sprintf("設定ファイル [%s]", defined)
+		c.defined[section+"."+prefix] = defined
+	}
+}
+
+// Source はキー（"Redmine.ApiKey" など）の値の取得元を返す（デフォルト値の場合は空）
+func (c *Config) Source(key string) string {
+	return c.Sources[key]
+}
+
+// missingError は必須項目が空の場合のエラー
+func (c *Config) missingError(section, key string) error {
+	if src := c.Source(section + "." + key); src != "" {
+		return fmt.Errorf("%sが空です（取得元: %s）", key, src)
+	}
+	if section == "Redmine" && key == "ApiKey" {
+		return fmt.Errorf("ApiKeyが設定されていません（環境変数 %s、ApiKeyFile、ApiKey のいずれかで指定してください）", EnvName(section, key))
+	}
+	return fmt.Errorf("%sが設定されていません（環境変数 %s または設定ファイルで指定してください）", key, EnvName(section, key))
+}
+
+// ListProfiles は設定ファイルに定義されているプロファイル名を定義順に返す
+func ListProfiles(path string) ([]string, error) {
+	cfg, err := loadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("設定ファイルの読み込みに失敗: %w", err)
+	}
+	return profileNames(cfg), nil
+}
+
+// profileNames は「セクション名.プロファイル名」のセクションからプロファイル名を定義順に集める
+func profileNames(cfg *ini.File) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, name := range cfg.SectionStrings() {
+		for _, base := range profileSections {
+			profile, ok := strings.CutPrefix(name, base+".")
+			if ok && profile != "" && !seen[profile] {
+				seen[profile] = true
+				names = append(names, profile)
+			}
+		}
+	}
+	return names
+}
+
+// hasProfile はプロファイルのセクションが1つ以上定義されているかを判定
+func hasProfile(cfg *ini.File, profile string) bool {
+	for _, base := range profileSections {
+		if _, err := cfg.GetSection(base + "." + profile); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseProfiles はカンマ区切りのプロファイル指定を分割する（重複は除く）
+func ParseProfiles(spec string) []string {
+	var profiles []string
+	seen := make(map[string]bool)
+	for _, p := range splitAndTrim(spec, ",") {
+		if !seen[p] {
+			seen[p] = true
+			profiles = append(profiles, p)
+		}
+	}
+	return profiles
+}
+
 // splitAndTrim はカンマ区切りの文字列を分割してトリムする
 func splitAndTrim(s, sep string) []string {
 	if s == "" {
@@ -99,17 +452,3 @@ func splitAndTrim(s, sep string) []string {
 	}
 	return result
 }
-
-// Validate は設定値の妥当性をチェック
-func (c *Config) Validate() error {
-	if c.Redmine.BaseURL == "" {
-		return fmt.Errorf("BaseUrlが設定されていません")
-	}
-	if c.Redmine.APIKey == "" {
-		return fmt.Errorf("ApiKeyが設定されていません")
-	}
-	if c.Redmine.FilterURL == "" {
-		return fmt.Errorf("FilterUrlが設定されていません")
-	}
-	return nil
-}
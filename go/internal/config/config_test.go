@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -59,6 +60,106 @@ Pattern3=【重要】
 	}
 }
 
+func TestLoadConfigConcurrency(t *testing.T) {
+	tests := []struct {
+		name    string
+		extra   string
+		want    int
+		wantErr bool
+	}{
+		{name: "未指定はデフォルト", extra: "", want: DefaultConcurrency},
+		{name: "指定あり", extra: "Concurrency=8\n", want: 8},
+		{name: "負数はエラー", extra: "Concurrency=-1\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "test.config")
+			configContent := "[Redmine]\nBaseUrl=https://test.example.com\nApiKey=key\nFilterUrl=/issues.json\n" + tt.extra
+			if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+				t.Fatalf("設定ファイルの作成に失敗: %v", err)
+			}
+
+			cfg, err := LoadConfig(configPath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.Redmine.Concurrency != tt.want {
+				t.Errorf("Concurrency = %d; want %d", cfg.Redmine.Concurrency, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigRetry(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "test.config")
+	configContent := `[Redmine]
+BaseUrl=https://test.example.com
+ApiKey=key
+FilterUrl=/issues.json
+MaxRetries=5
+RetryBackoff=500ms
+RetryMaxBackoff=1m
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("設定ファイルの作成に失敗: %v", err)
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig()でエラー: %v", err)
+	}
+
+	if cfg.Redmine.MaxRetries != 5 {
+		t.Errorf("MaxRetries = %d; want 5", cfg.Redmine.MaxRetries)
+	}
+	if cfg.Redmine.RetryBackoff != 500*time.Millisecond {
+		t.Errorf("RetryBackoff = %v; want 500ms", cfg.Redmine.RetryBackoff)
+	}
+	if cfg.Redmine.RetryMaxBackoff != time.Minute {
+		t.Errorf("RetryMaxBackoff = %v; want 1m", cfg.Redmine.RetryMaxBackoff)
+	}
+}
+
+func TestLoadConfigColumns(t *testing.T) {
+	tests := []struct {
+		name         string
+		output       string
+		wantColumns  []string
+		wantEncoding string
+	}{
+		{name: "未指定", output: "", wantColumns: []string{}, wantEncoding: "utf-8"},
+		{name: "指定あり", output: "Columns=id, subject ,cf:顧客,tag:進捗\nCSVEncoding=sjis\n", wantColumns: []string{"id", "subject", "cf:顧客", "tag:進捗"}, wantEncoding: "sjis"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "test.config")
+			configContent := "[Redmine]\nBaseUrl=https://test.example.com\nApiKey=key\nFilterUrl=/issues.json\n[Output]\n" + tt.output
+			if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+				t.Fatalf("設定ファイルの作成に失敗: %v", err)
+			}
+
+			cfg, err := LoadConfig(configPath)
+			if err != nil {
+				t.Fatalf("LoadConfig()でエラー: %v", err)
+			}
+
+			if len(cfg.Output.Columns) != len(tt.wantColumns) {
+				t.Fatalf("Columns = %v; want %v", cfg.Output.Columns, tt.wantColumns)
+			}
+			for i, want := range tt.wantColumns {
+				if cfg.Output.Columns[i] != want {
+					t.Errorf("Columns[%d] = %q; want %q", i, cfg.Output.Columns[i], want)
+				}
+			}
+			if cfg.Output.CSVEncoding != tt.wantEncoding {
+				t.Errorf("CSVEncoding = %q; want %q", cfg.Output.CSVEncoding, tt.wantEncoding)
+			}
+		})
+	}
+}
+
 func TestLoadConfigFileNotFound(t *testing.T) {
 	_, err := LoadConfig("/nonexistent/path/config.ini")
 	if err == nil {
@@ -147,3 +248,167 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+// profileConfig はプロファイルのテスト用設定ファイル
+const profileConfig = `[Redmine]
+BaseUrl=https://redmine.example.com
+ApiKey=default_key
+FilterUrl=/issues.json?status_id=*
+
+[TitleCleaning]
+Pattern1=^\[.*?\]\s*
+
+[Output]
+Mode=summary
+TagNames=要約
+
+[Redmine.teamA]
+FilterUrl=/issues.json?project_id=teama
+
+[Output.teamA]
+Mode=tags
+TagNames=進捗,課題
+
+[Redmine.teamB]
+BaseUrl=https://other.example.com
+ApiKey=teamb_key
+`
+
+func TestLoadProfile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "profile.config")
+	if err := os.WriteFile(configPath, []byte(profileConfig), 0644); err != nil {
+		t.Fatalf("設定ファイルの作成に失敗: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		profile      string
+		wantBaseURL  string
+		wantAPIKey   string
+		wantFilter   string
+		wantMode     string
+		wantTagNames []string
+		wantErr      bool
+	}{
+		{
+			name:         "デフォルト",
+			profile:      "",
+			wantBaseURL:  "https://redmine.example.com",
+			wantAPIKey:   "default_key",
+			wantFilter:   "/issues.json?status_id=*",
+			wantMode:     "summary",
+			wantTagNames: []string{"要約"},
+		},
+		{
+			name:         "RedmineとOutputを上書き",
+			profile:      "teamA",
+			wantBaseURL:  "https://redmine.example.com",
+			wantAPIKey:   "default_key",
+			wantFilter:   "/issues.json?project_id=teama",
+			wantMode:     "tags",
+			wantTagNames: []string{"進捗", "課題"},
+		},
+		{
+			name:         "Redmineのみ上書き",
+			profile:      "teamB",
+			wantBaseURL:  "https://other.example.com",
+			wantAPIKey:   "teamb_key",
+			wantFilter:   "/issues.json?status_id=*",
+			wantMode:     "summary",
+			wantTagNames: []string{"要約"},
+		},
+		{
+			name:    "存在しないプロファイル",
+			profile: "teamC",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := LoadProfile(configPath, tt.profile)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("エラーが返されなかった")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadProfile()でエラー: %v", err)
+			}
+
+			if cfg.Profile != tt.profile {
+				t.Errorf("Profile = %q; want %q", cfg.Profile, tt.profile)
+			}
+			if cfg.Redmine.BaseURL != tt.wantBaseURL {
+				t.Errorf("BaseURL = %q; want %q", cfg.Redmine.BaseURL, tt.wantBaseURL)
+			}
+			if cfg.Redmine.APIKey != tt.wantAPIKey {
+				t.Errorf("APIKey = %q; want %q", cfg.Redmine.APIKey, tt.wantAPIKey)
+			}
+			if cfg.Redmine.FilterURL != tt.wantFilter {
+				t.Errorf("FilterURL = %q; want %q", cfg.Redmine.FilterURL, tt.wantFilter)
+			}
+			if cfg.Output.Mode != tt.wantMode {
+				t.Errorf("Mode = %q; want %q", cfg.Output.Mode, tt.wantMode)
+			}
+			if len(cfg.Output.TagNames) != len(tt.wantTagNames) {
+				t.Fatalf("TagNames = %v; want %v", cfg.Output.TagNames, tt.wantTagNames)
+			}
+			for i, want := range tt.wantTagNames {
+				if cfg.Output.TagNames[i] != want {
+					t.Errorf("TagNames[%d] = %q; want %q", i, cfg.Output.TagNames[i], want)
+				}
+			}
+			// TitleCleaningはプロファイルで上書きしていないためデフォルトを引き継ぐ
+			if len(cfg.TitleCleaning.Patterns) != 1 {
+				t.Errorf("Patterns = %v; want 1件", cfg.TitleCleaning.Patterns)
+			}
+		})
+	}
+}
+
+func TestListProfiles(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "profile.config")
+	if err := os.WriteFile(configPath, []byte(profileConfig), 0644); err != nil {
+		t.Fatalf("設定ファイルの作成に失敗: %v", err)
+	}
+
+	profiles, err := ListProfiles(configPath)
+	if err != nil {
+		t.Fatalf("ListProfiles()でエラー: %v", err)
+	}
+	want := []string{"teamA", "teamB"}
+	if len(profiles) != len(want) {
+		t.Fatalf("ListProfiles() = %v; want %v", profiles, want)
+	}
+	for i := range want {
+		if profiles[i] != want[i] {
+			t.Errorf("ListProfiles()[%d] = %q; want %q", i, profiles[i], want[i])
+		}
+	}
+}
+
+func TestParseProfiles(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []string
+	}{
+		{spec: "", want: nil},
+		{spec: "teamA", want: []string{"teamA"}},
+		{spec: " teamA , teamB,teamA,", want: []string{"teamA", "teamB"}},
+	}
+
+	for _, tt := range tests {
+		got := ParseProfiles(tt.spec)
+		if len(got) != len(tt.want) {
+			t.Errorf("ParseProfiles(%q) = %v; want %v", tt.spec, got, tt.want)
+			continue
+		}
+		for i := range tt.want {
+			if got[i] != tt.want[i] {
+				t.Errorf("ParseProfiles(%q)[%d] = %q; want %q", tt.spec, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/ini.v1"
+	"gopkg.in/yaml.v3"
+)
+
+// convSection は変換する1セクション分のキーと値（キーの順序を保持する）
+type convSection struct {
+	Name string
+	Keys []convKey
+}
+
+// convKey は変換するキーと値
+// 値は string, bool, int, []string, []tagSpec, []customFieldSpec のいずれか
+type convKey struct {
+	Key   string
+	Value interface{}
+}
+
+// tagSpec はYAML/TOMLでのタグの指定
+type tagSpec struct {
+	Name  string `yaml:"Name"`
+	Limit int    `yaml:"Limit,omitempty"`
+}
+
+// customFieldSpec はYAML/TOMLでのカスタムフィールドの指定（ID: [値, ...]）
+type customFieldSpec struct {
+	ID     string
+	Values []string
+}
+
+// Convert はINI形式の設定ファイルをYAML/TOML形式に変換して書き込む
+// ${VAR} の参照は展開せずにそのまま残す（INIのコメントは引き継がない）
+func Convert(path, format string, w io.Writer) error {
+	if DetectFormat(path) != FormatINI {
+		return fmt.Errorf("変換元はINI形式の設定ファイルを指定してください: %s", path)
+	}
+	file, err := ini.Load(path)
+	if err != nil {
+		return fmt.Errorf("設定ファイルの読み込みに失敗: %w", err)
+	}
+
+	sections, profiles, err := convertSections(file)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case FormatYAML:
+		return writeYAML(w, sections, profiles)
+	case FormatTOML:
+		return writeTOML(w, sections, profiles)
+	}
+	return fmt.Errorf("未対応の変換先の形式: %s (%s, %s のみ対応)", format, FormatYAML, FormatTOML)
+}
+
+// convertSections はINIのセクションを変換用の形式にする
+// プロファイルのセクション（[Redmine.teamA]）はプロファイル名ごとにまとめる（プロファイルは定義順）
+func convertSections(file *ini.File) ([]convSection, []convSection, error) {
+	var sections []convSection
+	var profiles []convSection
+	profileIndex := make(map[string]int)
+
+	for _, sec := range file.Sections() {
+		if sec.Name() == ini.DefaultSection && len(sec.Keys()) == 0 {
+			continue
+		}
+		base, profile, _ := strings.Cut(sec.Name(), ".")
+		keys, err := convertKeys(sec, base)
+		if err != nil {
+			return nil, nil, err
+		}
+		converted := convSection{Name: base, Keys: keys}
+
+		if profile == "" {
+			sections = append(sections, converted)
+			continue
+		}
+		i, ok := profileIndex[profile]
+		if !ok {
+			i = len(profiles)
+			profileIndex[profile] = i
+			profiles = append(profiles, convSection{Name: profile})
+		}
+		profiles[i].Keys = append(profiles[i].Keys, convKey{Key: base, Value: converted})
+	}
+	return sections, profiles, nil
+}
+
+// convertKeys はセクションのキーを設定の一覧の順に変換する（一覧にないキーは末尾に定義順で追加）
+func convertKeys(sec *ini.Section, base string) ([]convKey, error) {
+	own := make(map[string]bool)
+	for _, name := range sec.KeyStrings() {
+		own[name] = true
+	}
+	used := make(map[string]bool)
+
+	var keys []convKey
+	for _, s := range settings {
+		if s.Section != base {
+			continue
+		}
+
+		if s.numbered() {
+			var values []string
+			for i := 1; own[fmt.Sprintf("%s%d", s.Key, i)]; i++ {
+				name := fmt.Sprintf("%s%d", s.Key, i)
+				used[name] = true
+				if v := sec.Key(name).Value(); v != "" {
+					values = append(values, v)
+				}
+			}
+			if len(values) == 0 {
+				continue
+			}
+			value, err := numberedValue(s, values)
+			if err != nil {
+				return nil, fmt.Errorf("[%s] %s: %w", sec.Name(), s.Key, err)
+			}
+			keys = append(keys, convKey{Key: s.Key + "s", Value: value})
+			continue
+		}
+
+		if !own[s.Key] {
+			continue
+		}
+		used[s.Key] = true
+		keys = append(keys, convKey{Key: s.Key, Value: typedValue(s, sec.Key(s.Key).Value())})
+	}
+
+	for _, name := range sec.KeyStrings() {
+		if !used[name] {
+			keys = append(keys, convKey{Key: name, Value: sec.Key(name).Value()})
+		}
+	}
+	return keys, nil
+}
+
+// numberedValue は連番のキーの値をYAML/TOMLの値にする
+// カスタムフィールド（"3=A社,B社"）はIDごとの値の配列にする
+func numberedValue(s setting, values []string) (interface{}, error) {
+	if s.Section != "Filter" {
+		return values, nil
+	}
+	specs := make([]customFieldSpec, 0, len(values))
+	for _, v := range values {
+		id, list, ok := strings.Cut(v, "=")
+		if !ok {
+			return nil, fmt.Errorf("形式エラー: %s (ID=値 の形式で指定)", v)
+		}
+		specs = append(specs, customFieldSpec{ID: strings.TrimSpace(id), Values: splitAndTrim(list, ",")})
+	}
+	return specs, nil
+}
+
+// typedValue はINIの値を設定の種類に応じた型にする（変換できない値は文字列のまま）
+func typedValue(s setting, value string) interface{} {
+	if strings.Contains(value, "${") {
+		return value
+	}
+	switch s.kind {
+	case kindBool:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case kindInt:
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	case kindList:
+		if items := splitAndTrim(value, ","); len(items) > 1 {
+			return items
+		}
+	case kindTags:
+		return tagSpecs(value)
+	}
+	return value
+}
+
+// tagSpecs はタグの指定（"要約:3,進捗"）をYAML/TOMLの値にする
+// 件数の指定がなければ名前の配列、あればすべてを Name/Limit の配列にする
+func tagSpecs(value string) interface{} {
+	names := splitAndTrim(value, ",")
+	specs := make([]tagSpec, 0, len(names))
+	withLimit := false
+	for _, item := range names {
+		name, limitStr, ok := strings.Cut(item, ":")
+		if !ok {
+			specs = append(specs, tagSpec{Name: name})
+			continue
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(limitStr))
+		if err != nil {
+			return names // 件数の形式エラーは読み込み時に報告するため、そのまま残す
+		}
+		specs = append(specs, tagSpec{Name: strings.TrimSpace(name), Limit: limit})
+		withLimit = true
+	}
+	if !withLimit {
+		return names
+	}
+	return specs
+}
+
+// writeYAML は変換したセクションをYAML形式で書き込む（キーの順序を保持する）
+func writeYAML(w io.Writer, sections, profiles []convSection) error {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	for _, sec := range sections {
+		node, err := yamlSection(sec.Keys)
+		if err != nil {
+			return err
+		}
+		root.Content = append(root.Content, yamlString(sec.Name), node)
+	}
+
+	if len(profiles) > 0 {
+		profilesNode := &yaml.Node{Kind: yaml.MappingNode}
+		for _, p := range profiles {
+			node, err := yamlSection(p.Keys)
+			if err != nil {
+				return err
+			}
+			profilesNode.Content = append(profilesNode.Content, yamlString(p.Name), node)
+		}
+		root.Content = append(root.Content, yamlString(profilesKey), profilesNode)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return fmt.Errorf("YAML出力エラー: %w", err)
+	}
+	return enc.Close()
+}
+
+// yamlSection はキーと値をYAMLのマッピングにする
+func yamlSection(keys []convKey) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, kv := range keys {
+		var value *yaml.Node
+		switch v := kv.Value.(type) {
+		case convSection:
+			child, err := yamlSection(v.Keys)
+			if err != nil {
+				return nil, err
+			}
+			value = child
+		case []customFieldSpec:
+			value = &yaml.Node{Kind: yaml.MappingNode}
+			for _, cf := range v {
+				values := &yaml.Node{}
+				if err := values.Encode(cf.Values); err != nil {
+					return nil, err
+				}
+				value.Content = append(value.Content, yamlString(cf.ID), values)
+			}
+		default:
+			value = &yaml.Node{}
+			if err := value.Encode(v); err != nil {
+				return nil, fmt.Errorf("YAML出力エラー: %s: %w", kv.Key, err)
+			}
+		}
+		node.Content = append(node.Content, yamlString(kv.Key), value)
+	}
+	return node, nil
+}
+
+// yamlString は文字列のYAMLノード
+func yamlString(s string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}
+}
+
+// writeTOML は変換したセクションをTOML形式で書き込む
+// セクションの順序は保持し、セクション内のキーは名前順になる（エンコーダーの仕様）
+func writeTOML(w io.Writer, sections, profiles []convSection) error {
+	enc := toml.NewEncoder(w)
+	enc.Indent = ""
+	for _, sec := range sections {
+		if err := enc.Encode(map[string]interface{}{sec.Name: tomlSection(sec.Keys)}); err != nil {
+			return fmt.Errorf("TOML出力エラー: %w", err)
+		}
+	}
+
+	if len(profiles) > 0 {
+		all := make(map[string]interface{}, len(profiles))
+		for _, p := range profiles {
+			all[p.Name] = tomlSection(p.Keys)
+		}
+		if err := enc.Encode(map[string]interface{}{profilesKey: all}); err != nil {
+			return fmt.Errorf("TOML出力エラー: %w", err)
+		}
+	}
+	return nil
+}
+
+// tomlSection はキーと値をTOMLのテーブルにする
+func tomlSection(keys []convKey) map[string]interface{} {
+	table := make(map[string]interface{}, len(keys))
+	for _, kv := range keys {
+		switch v := kv.Value.(type) {
+		case convSection:
+			table[kv.Key] = tomlSection(v.Keys)
+		case []customFieldSpec:
+			fields := make(map[string]interface{}, len(v))
+			for _, cf := range v {
+				fields[cf.ID] = cf.Values
+			}
+			table[kv.Key] = fields
+		case []tagSpec:
+			// 件数の指定がないタグは Limit を出力しない
+			tags := make([]map[string]interface{}, 0, len(v))
+			for _, tag := range v {
+				item := map[string]interface{}{"Name": tag.Name}
+				if tag.Limit > 0 {
+					item["Limit"] = tag.Limit
+				}
+				tags = append(tags, item)
+			}
+			table[kv.Key] = tags
+		default:
+			table[kv.Key] = v
+		}
+	}
+	return table
+}
@@ -0,0 +1,321 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/ini.v1"
+	"gopkg.in/yaml.v3"
+)
+
+// 設定ファイルの形式
+const (
+	FormatINI  = "ini"  // VBA版と共通の形式（デフォルト）
+	FormatYAML = "yaml" // .yaml / .yml
+	FormatTOML = "toml" // .toml
+)
+
+// profilesKey はYAML/TOMLでプロファイルをまとめるキー（Profiles.<プロファイル名>.<セクション名>）
+const profilesKey = "Profiles"
+
+// DetectFormat は拡張子から設定ファイルの形式を判定する（.yaml/.yml/.toml 以外はINI）
+func DetectFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	}
+	return FormatINI
+}
+
+// loadFile は設定ファイルを読み込む
+// YAML/TOMLはINIと同じセクション・キーに変換するため、プロファイル・環境変数の処理は形式によらず共通
+func loadFile(path string) (*ini.File, error) {
+	format := DetectFormat(path)
+	if format == FormatINI {
+		return ini.Load(path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc := map[string]interface{}{}
+	switch format {
+	case FormatYAML:
+		err = yaml.Unmarshal(data, &doc)
+	case FormatTOML:
+		err = toml.Unmarshal(data, &doc)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s の構文エラー: %w", strings.ToUpper(format), err)
+	}
+	return structuredToINI(doc)
+}
+
+// structuredToINI はYAML/TOMLの内容をINIのセクション・キーに変換する
+// セクション名・キー名は大文字小文字とアンダースコアを区別しない（base_url → BaseUrl）
+func structuredToINI(doc map[string]interface{}) (*ini.File, error) {
+	file := ini.Empty()
+	for _, name := range sortedKeys(doc) {
+		if strings.EqualFold(name, profilesKey) {
+			profiles, ok := asMap(doc[name])
+			if !ok {
+				return nil, fmt.Errorf("%s はプロファイル名ごとのマップで指定してください", profilesKey)
+			}
+			for _, profile := range sortedKeys(profiles) {
+				sections, ok := asMap(profiles[profile])
+				if !ok {
+					return nil, fmt.Errorf("%s.%s はセクションごとのマップで指定してください", profilesKey, profile)
+				}
+				for _, sec := range sortedKeys(sections) {
+					base := canonicalSection(sec)
+					if err := addSection(file, base+"."+profile, base, sections[sec]); err != nil {
+						return nil, err
+					}
+				}
+			}
+			continue
+		}
+
+		// "Redmine.teamA" のようなINIと同じ形式のプロファイルのセクションも受け付ける
+		base, profile, _ := strings.Cut(name, ".")
+		base = canonicalSection(base)
+		sectionName := base
+		if profile != "" {
+			sectionName += "." + profile
+		}
+		if err := addSection(file, sectionName, base, doc[name]); err != nil {
+			return nil, err
+		}
+	}
+	return file, nil
+}
+
+// addSection はYAML/TOMLの1セクション分のキーをINIのセクションに追加する
+func addSection(file *ini.File, name, base string, value interface{}) error {
+	keys, ok := asMap(value)
+	if !ok {
+		return fmt.Errorf("[%s] はキーと値のマップで指定してください", name)
+	}
+
+	section, err := file.NewSection(name)
+	if err != nil {
+		return err
+	}
+	for _, k := range sortedKeys(keys) {
+		s, known := findSetting(base, k)
+		v := keys[k]
+
+		// 連番のキー（Patterns → Pattern1, Pattern2, ...）
+		if known && s.numbered() {
+			values, err := numberedItems(v)
+			if err != nil {
+				return fmt.Errorf("[%s] %s: %w", name, k, err)
+			}
+			for i, item := range values {
+				section.NewKey(fmt.Sprintf("%s%d", s.Key, i+1), item)
+			}
+			continue
+		}
+
+		key := k
+		if known {
+			key = s.Key
+		}
+		str, err := iniValue(v)
+		if err != nil {
+			return fmt.Errorf("[%s] %s: %w", name, k, err)
+		}
+		section.NewKey(key, str)
+	}
+	return nil
+}
+
+// numberedItems は連番のキーの値（配列、またはカスタムフィールドIDごとのマップ）を1件ずつの文字列にする
+// マップの場合は "ID=値1,値2" の形式にする（Filter.CustomFields）
+func numberedItems(v interface{}) ([]string, error) {
+	if m, ok := asMap(v); ok {
+		var items []string
+		for _, id := range sortedKeys(m) {
+			values, err := iniValue(m[id])
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, id+"="+values)
+		}
+		return items, nil
+	}
+
+	list, ok := asList(v)
+	if !ok {
+		list = []interface{}{v}
+	}
+	items := make([]string, 0, len(list))
+	for _, item := range list {
+		s, err := scalarString(item)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, s)
+	}
+	return items, nil
+}
+
+// iniValue はYAML/TOMLの値をINIの値（リストはカンマ区切り）に変換する
+// タグの指定（{Name: 要約, Limit: 3}）は "要約:3" にする
+func iniValue(v interface{}) (string, error) {
+	list, ok := asList(v)
+	if !ok {
+		return scalarString(v)
+	}
+
+	items := make([]string, 0, len(list))
+	for _, item := range list {
+		if m, ok := asMap(item); ok {
+			tag, err := tagItem(m)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, tag)
+			continue
+		}
+		s, err := scalarString(item)
+		if err != nil {
+			return "", err
+		}
+		items = append(items, s)
+	}
+	return strings.Join(items, ","), nil
+}
+
+// tagItem はタグの指定（Name と省略可能な Limit）を "名前:件数" の形式にする
+func tagItem(m map[string]interface{}) (string, error) {
+	var name, limit string
+	for k, v := range m {
+		s, err := scalarString(v)
+		if err != nil {
+			return "", err
+		}
+		switch normalizeName(k) {
+		case "name":
+			name = s
+		case "limit":
+			limit = s
+		default:
+			return "", fmt.Errorf("タグの指定に未対応のキーがあります: %s（Name, Limit のみ）", k)
+		}
+	}
+	if name == "" {
+		return "", fmt.Errorf("タグの Name が指定されていません")
+	}
+	if limit == "" {
+		return name, nil
+	}
+	return name + ":" + limit, nil
+}
+
+// scalarString はYAML/TOMLのスカラー値を文字列にする
+func scalarString(v interface{}) (string, error) {
+	switch val := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return val, nil
+	case bool:
+		return strconv.FormatBool(val), nil
+	case int:
+		return strconv.Itoa(val), nil
+	case int64:
+		return strconv.FormatInt(val, 10), nil
+	case uint64:
+		return strconv.FormatUint(val, 10), nil
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), nil
+	case time.Time:
+		// 日付のみの値（Since: 2025-01-06）は日付の形式に戻す
+		if val.Hour() == 0 && val.Minute() == 0 && val.Second() == 0 && val.Nanosecond() == 0 {
+			return val.Format("2006-01-02"), nil
+		}
+		return val.Format(time.RFC3339), nil
+	}
+	return "", fmt.Errorf("未対応の値です: %v", v)
+}
+
+// asMap はYAML/TOMLのマップを文字列キーのマップにする
+func asMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(m))
+		for k, val := range m {
+			result[fmt.Sprint(k)] = val
+		}
+		return result, true
+	}
+	return nil, false
+}
+
+// asList はYAML/TOMLの配列を []interface{} にする（TOMLのテーブルの配列は []map[string]interface{} になる）
+func asList(v interface{}) ([]interface{}, bool) {
+	switch l := v.(type) {
+	case []interface{}:
+		return l, true
+	case []map[string]interface{}:
+		list := make([]interface{}, 0, len(l))
+		for _, m := range l {
+			list = append(list, m)
+		}
+		return list, true
+	}
+	return nil, false
+}
+
+// sortedKeys はマップのキーを昇順で返す
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// normalizeName は大文字小文字とアンダースコアを区別せずに比較するための名前
+func normalizeName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+// canonicalSection はセクション名をINIの表記（Redmine, TitleCleaning など）にそろえる
+func canonicalSection(name string) string {
+	for _, s := range settings {
+		if normalizeName(s.Section) == normalizeName(name) {
+			return s.Section
+		}
+	}
+	return name
+}
+
+// findSetting はセクションとキー名に対応する設定を探す
+// 連番のキーは複数形（Patterns, CustomFields）でも指定できる
+func findSetting(section, key string) (setting, bool) {
+	n := normalizeName(key)
+	for _, s := range settings {
+		if s.Section != section {
+			continue
+		}
+		k := normalizeName(s.Key)
+		if n == k || (s.numbered() && n == k+"s") {
+			return s, true
+		}
+	}
+	return setting{}, false
+}
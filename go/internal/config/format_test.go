@@ -0,0 +1,342 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const yamlConfig = `redmine:
+  base_url: ${TEST_YAML_BASE_URL}
+  api_key: 0123456789abcdef0123
+  filter_url: /issues.json
+  concurrency: 8
+TitleCleaning:
+  Patterns:
+    - '^\[.*?\]\s*'
+    - '\s+$'
+Output:
+  TagNames:
+    - Name: 要約
+      Limit: 3
+    - 進捗
+  IncludeComments: true
+Period:
+  Since: 2025-01-06
+Grouping:
+  GroupBy: assignee
+  Sort: [due_date, "priority:desc"]
+Filter:
+  Status: [open]
+  Tracker: [1, 2]
+  CustomFields:
+    "3": [A社, B社]
+    "5": 高
+Profiles:
+  teamA:
+    Output:
+      Mode: full
+    Redmine:
+      FilterUrl: /issues.json?project_id=a
+`
+
+const tomlConfig = `[Redmine]
+BaseUrl = "${TEST_YAML_BASE_URL}"
+ApiKey = "0123456789abcdef0123"
+FilterUrl = "/issues.json"
+Concurrency = 8
+
+[TitleCleaning]
+Patterns = ['^\[.*?\]\s*', '\s+$']
+
+[Output]
+IncludeComments = true
+TagNames = [{ Name = "要約", Limit = 3 }, { Name = "進捗" }]
+
+[Period]
+Since = 2025-01-06
+
+[Grouping]
+GroupBy = "assignee"
+Sort = ["due_date", "priority:desc"]
+
+[Filter]
+Status = ["open"]
+Tracker = [1, 2]
+
+[Filter.CustomFields]
+3 = ["A社", "B社"]
+5 = "高"
+
+[Profiles.teamA.Output]
+Mode = "full"
+
+[Profiles.teamA.Redmine]
+FilterUrl = "/issues.json?project_id=a"
+`
+
+const iniConfig = `[Redmine]
+BaseUrl=${TEST_YAML_BASE_URL}
+ApiKey=0123456789abcdef0123
+FilterUrl=/issues.json
+Concurrency=8
+
+[TitleCleaning]
+Pattern1=^\[.*?\]\s*
+Pattern2=\s+$
+
+[Output]
+TagNames=要約:3,進捗
+IncludeComments=true
+
+[Period]
+Since=2025-01-06
+
+[Grouping]
+GroupBy=assignee
+Sort=due_date,priority:desc
+
+[Filter]
+Status=open
+Tracker=1,2
+CustomField1=3=A社,B社
+CustomField2=5=高
+
+[Output.teamA]
+Mode=full
+
+[Redmine.teamA]
+FilterUrl=/issues.json?project_id=a
+`
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("設定ファイルの作成に失敗: %v", err)
+	}
+	return path
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"redmine.config", FormatINI},
+		{"redmine.ini", FormatINI},
+		{"redmine.yaml", FormatYAML},
+		{"conf/Redmine.YML", FormatYAML},
+		{"redmine.toml", FormatTOML},
+	}
+	for _, tt := range tests {
+		if got := DetectFormat(tt.path); got != tt.want {
+			t.Errorf("DetectFormat(%q) = %q; want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestLoadWithOptions_StructuredFormats(t *testing.T) {
+	t.Setenv("TEST_YAML_BASE_URL", "https://redmine.example.com")
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"INI", "redmine.config", iniConfig},
+		{"YAML", "redmine.yaml", yamlConfig},
+		{"TOML", "redmine.toml", tomlConfig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfigFile(t, tt.file, tt.content)
+
+			cfg, err := LoadWithOptions(path, LoadOptions{})
+			if err != nil {
+				t.Fatalf("LoadWithOptions()でエラー: %v", err)
+			}
+			if cfg.Redmine.BaseURL != "https://redmine.example.com" || cfg.Redmine.Concurrency != 8 {
+				t.Errorf("Redmine = %+v", cfg.Redmine)
+			}
+			if !reflect.DeepEqual(cfg.TitleCleaning.Patterns, []string{`^\[.*?\]\s*`, `\s+$`}) {
+				t.Errorf("Patterns = %q", cfg.TitleCleaning.Patterns)
+			}
+			if !reflect.DeepEqual(cfg.Output.TagNames, []string{"要約:3", "進捗"}) {
+				t.Errorf("TagNames = %v", cfg.Output.TagNames)
+			}
+			if !cfg.Output.IncludeComments || cfg.Output.Mode != "summary" {
+				t.Errorf("Output = %+v", cfg.Output)
+			}
+			if cfg.Period.Since != "2025-01-06" {
+				t.Errorf("Since = %q; want 2025-01-06", cfg.Period.Since)
+			}
+			if cfg.Grouping != (GroupingConfig{GroupBy: "assignee", Sort: "due_date,priority:desc"}) {
+				t.Errorf("Grouping = %+v", cfg.Grouping)
+			}
+			if cfg.Filter.Status != "open" || cfg.Filter.Tracker != "1,2" {
+				t.Errorf("Filter = %+v", cfg.Filter)
+			}
+			if !reflect.DeepEqual(cfg.Filter.CustomFields, []string{"3=A社,B社", "5=高"}) {
+				t.Errorf("CustomFields = %v", cfg.Filter.CustomFields)
+			}
+
+			profile, err := LoadWithOptions(path, LoadOptions{Profile: "teamA"})
+			if err != nil {
+				t.Fatalf("LoadWithOptions(teamA)でエラー: %v", err)
+			}
+			if profile.Output.Mode != "full" || profile.Redmine.FilterURL != "/issues.json?project_id=a" {
+				t.Errorf("プロファイルの値が反映されていない: Mode = %q, FilterURL = %q", profile.Output.Mode, profile.Redmine.FilterURL)
+			}
+			if profile.Redmine.Concurrency != 8 {
+				t.Errorf("Concurrency = %d; want 8（デフォルトを引き継ぐ）", profile.Redmine.Concurrency)
+			}
+
+			if got := profile.Source("Output.Mode"); got != "設定ファイル [Output.teamA]" {
+				t.Errorf("Source(Output.Mode) = %q", got)
+			}
+
+			names, err := ListProfiles(path)
+			if err != nil {
+				t.Fatalf("ListProfiles()でエラー: %v", err)
+			}
+			if !reflect.DeepEqual(names, []string{"teamA"}) {
+				t.Errorf("ListProfiles() = %v; want [teamA]", names)
+			}
+		})
+	}
+}
+
+func TestLoadWithOptions_StructuredFormatErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "YAMLの構文エラー",
+			file:    "bad.yaml",
+			content: "Redmine:\n  BaseUrl: [\n",
+			wantErr: "YAML の構文エラー",
+		},
+		{
+			name:    "TOMLの構文エラー",
+			file:    "bad.toml",
+			content: "[Redmine\n",
+			wantErr: "TOML の構文エラー",
+		},
+		{
+			name:    "セクションがマップではない",
+			file:    "section.yaml",
+			content: "Redmine: https://redmine.example.com\n",
+			wantErr: "[Redmine] はキーと値のマップで指定してください",
+		},
+		{
+			name:    "タグに未対応のキー",
+			file:    "tags.yaml",
+			content: "Output:\n  TagNames:\n    - Name: 要約\n      Max: 3\n",
+			wantErr: "未対応のキー",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfigFile(t, tt.file, tt.content)
+			_, err := LoadWithOptions(path, LoadOptions{})
+			if err == nil {
+				t.Fatal("エラーが返されなかった")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("エラー = %v; want %q を含む", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	t.Setenv("TEST_YAML_BASE_URL", "https://redmine.example.com")
+	src := writeConfigFile(t, "redmine.config", iniConfig)
+
+	want, err := LoadWithOptions(src, LoadOptions{Profile: "teamA"})
+	if err != nil {
+		t.Fatalf("LoadWithOptions()でエラー: %v", err)
+	}
+
+	tests := []struct {
+		format   string
+		file     string
+		contains []string
+	}{
+		{
+			format: FormatYAML,
+			file:   "converted.yaml",
+			contains: []string{
+				"BaseUrl: ${TEST_YAML_BASE_URL}\n",
+				"Concurrency: 8\n",
+				"  Patterns:\n",
+				"    - Name: 要約\n      Limit: 3\n    - Name: 進捗\n",
+				"IncludeComments: true\n",
+				"Profiles:\n  teamA:\n",
+			},
+		},
+		{
+			format: FormatTOML,
+			file:   "converted.toml",
+			contains: []string{
+				"BaseUrl = \"${TEST_YAML_BASE_URL}\"\n",
+				"Concurrency = 8\n",
+				"Sort = [\"due_date\", \"priority:desc\"]\n",
+				"[Filter.CustomFields]\n",
+				"[Profiles.teamA.Output]\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var b strings.Builder
+			if err := Convert(src, tt.format, &b); err != nil {
+				t.Fatalf("Convert()でエラー: %v", err)
+			}
+			out := b.String()
+			for _, s := range tt.contains {
+				if !strings.Contains(out, s) {
+					t.Errorf("出力に %q が含まれていない:\n%s", s, out)
+				}
+			}
+
+			// 変換したファイルを読み込むと元のINIと同じ設定になる
+			path := writeConfigFile(t, tt.file, out)
+			got, err := LoadWithOptions(path, LoadOptions{Profile: "teamA"})
+			if err != nil {
+				t.Fatalf("変換後のLoadWithOptions()でエラー: %v\n%s", err, out)
+			}
+			if !reflect.DeepEqual(got.Redmine, want.Redmine) ||
+				!reflect.DeepEqual(got.TitleCleaning, want.TitleCleaning) ||
+				!reflect.DeepEqual(got.Output, want.Output) ||
+				got.Period != want.Period || got.Grouping != want.Grouping ||
+				!reflect.DeepEqual(got.Filter, want.Filter) {
+				t.Errorf("変換後の設定が異なる:\ngot  %+v\nwant %+v", got, want)
+			}
+		})
+	}
+
+	t.Run("未対応の形式", func(t *testing.T) {
+		var b strings.Builder
+		if err := Convert(src, "json", &b); err == nil {
+			t.Error("エラーが返されなかった")
+		}
+	})
+
+	t.Run("変換元がINI以外", func(t *testing.T) {
+		var b strings.Builder
+		yamlPath := writeConfigFile(t, "redmine.yaml", yamlConfig)
+		if err := Convert(yamlPath, FormatTOML, &b); err == nil {
+			t.Error("エラーが返されなかった")
+		}
+	})
+}
@@ -0,0 +1,242 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// lineIndex は設定ファイルのセクション・キーと行番号の対応
+// キーは「INIのセクション名 + "\x00" + キー名」（セクション自体の行はキー名を空にする）
+type lineIndex map[string]int
+
+// lineKey は lineIndex のキー
+func lineKey(section, key string) string {
+	return section + "\x00" + key
+}
+
+// lookup はキーが定義されている行番号を返す（見つからない場合は0）
+// YAML/TOMLの配列で指定した連番のキー（Pattern2）は、見つからなければ配列のキーの行を返す
+func (idx lineIndex) lookup(section, key string) int {
+	if line, ok := idx[lineKey(section, key)]; ok {
+		return line
+	}
+	if base := strings.TrimRight(key, "0123456789"); base != key {
+		if line, ok := idx[lineKey(section, base)]; ok {
+			return line
+		}
+	}
+	return 0
+}
+
+// readLineIndex は設定ファイルを読み込んで行番号の対応を作る（読み込めない場合は空）
+func readLineIndex(path string) lineIndex {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return lineIndex{}
+	}
+	switch DetectFormat(path) {
+	case FormatYAML:
+		return yamlLineIndex(data)
+	case FormatTOML:
+		return tomlLineIndex(string(data))
+	}
+	return iniLineIndex(string(data))
+}
+
+// iniLineIndex はINI形式の行番号の対応を作る
+func iniLineIndex(content string) lineIndex {
+	idx := lineIndex{}
+	section := "DEFAULT"
+	scanLines(content, func(n int, line string) {
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			idx.add(section, "", n)
+			return
+		}
+		if i := strings.IndexAny(line, "=:"); i > 0 {
+			idx.add(section, strings.TrimSpace(line[:i]), n)
+		}
+	})
+	return idx
+}
+
+// tomlHeaderPattern はTOMLのテーブルの見出し（[a.b] / [[a.b]]）
+var tomlHeaderPattern = regexp.MustCompile(`^\[\[?\s*([^\]]+?)\s*\]\]?$`)
+
+// tomlLineIndex はTOML形式の行番号の対応を作る
+// [Profiles.teamA.Output] は Output.teamA、[Filter.CustomFields] は Filter の CustomFields キーとして扱う
+func tomlLineIndex(content string) lineIndex {
+	idx := lineIndex{}
+	section, base, subKey := "", "", ""
+	scanLines(content, func(n int, line string) {
+		if m := tomlHeaderPattern.FindStringSubmatch(line); m != nil {
+			section, base, subKey = structuredSection(splitTOMLKey(m[1]))
+			if subKey != "" {
+				idx.add(section, canonicalKey(base, subKey), n)
+			} else if section != "" {
+				idx.add(section, "", n)
+			}
+			return
+		}
+		i := strings.Index(line, "=")
+		if i <= 0 || section == "" || subKey != "" {
+			return
+		}
+		key := strings.Trim(strings.TrimSpace(line[:i]), `"'`)
+		idx.add(section, canonicalKey(base, key), n)
+	})
+	return idx
+}
+
+// splitTOMLKey はTOMLのドット区切りのキーを分割する（引用符は除く）
+func splitTOMLKey(key string) []string {
+	parts := strings.Split(key, ".")
+	for i, p := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(p), `"'`)
+	}
+	return parts
+}
+
+// structuredSection はYAML/TOMLのキーのパスをINIのセクション名・セクション・キーに変換する
+// 例: Output → Output, Profiles.teamA.Output → Output.teamA, Filter.CustomFields → Filter と CustomFields
+func structuredSection(path []string) (section, base, subKey string) {
+	if len(path) >= 2 && strings.EqualFold(path[0], profilesKey) {
+		if len(path) < 3 {
+			return "", "", ""
+		}
+		base = canonicalSection(path[2])
+		section = base + "." + path[1]
+		if len(path) > 3 {
+			subKey = path[3]
+		}
+		return section, base, subKey
+	}
+	if len(path) == 0 {
+		return "", "", ""
+	}
+	base = canonicalSection(path[0])
+	section = base
+	if len(path) > 1 {
+		subKey = path[1]
+	}
+	return section, base, subKey
+}
+
+// canonicalKey はYAML/TOMLのキー名をINIのキー名にする（Patterns → Pattern、未知のキーはそのまま）
+func canonicalKey(section, key string) string {
+	if s, ok := findSetting(section, key); ok {
+		return s.Key
+	}
+	return key
+}
+
+// yamlLineIndex はYAML形式の行番号の対応を作る
+// 連番のキーは配列の要素ごと（Pattern1, Pattern2, ...）に行番号を記録する
+func yamlLineIndex(data []byte) lineIndex {
+	idx := lineIndex{}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return idx
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return idx
+	}
+
+	var addSection func(section, base string, node *yaml.Node)
+	addSection = func(section, base string, node *yaml.Node) {
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k, v := node.Content[i], node.Content[i+1]
+			s, known := findSetting(base, k.Value)
+			if !known {
+				idx.add(section, k.Value, k.Line)
+				continue
+			}
+			idx.add(section, s.Key, k.Line)
+			if !s.numbered() {
+				continue
+			}
+			// 連番のキーの要素（マップの場合は読み込み時と同じキーの昇順）
+			switch v.Kind {
+			case yaml.SequenceNode:
+				for j, item := range v.Content {
+					idx.add(section, fmt.Sprintf("%s%d", s.Key, j+1), item.Line)
+				}
+			case yaml.MappingNode:
+				lines := make(map[string]int, len(v.Content)/2)
+				ids := make([]string, 0, len(v.Content)/2)
+				for j := 0; j+1 < len(v.Content); j += 2 {
+					lines[v.Content[j].Value] = v.Content[j].Line
+					ids = append(ids, v.Content[j].Value)
+				}
+				sort.Strings(ids)
+				for j, id := range ids {
+					idx.add(section, fmt.Sprintf("%s%d", s.Key, j+1), lines[id])
+				}
+			}
+		}
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		k, v := root.Content[i], root.Content[i+1]
+		if strings.EqualFold(k.Value, profilesKey) && v.Kind == yaml.MappingNode {
+			for j := 0; j+1 < len(v.Content); j += 2 {
+				profile, sections := v.Content[j], v.Content[j+1]
+				if sections.Kind != yaml.MappingNode {
+					continue
+				}
+				for l := 0; l+1 < len(sections.Content); l += 2 {
+					base := canonicalSection(sections.Content[l].Value)
+					section := base + "." + profile.Value
+					idx.add(section, "", sections.Content[l].Line)
+					addSection(section, base, sections.Content[l+1])
+				}
+			}
+			continue
+		}
+
+		name, profile, _ := strings.Cut(k.Value, ".")
+		base := canonicalSection(name)
+		section := base
+		if profile != "" {
+			section += "." + profile
+		}
+		idx.add(section, "", k.Line)
+		addSection(section, base, v)
+	}
+	return idx
+}
+
+// add は最初に見つかった行番号を記録する
+func (idx lineIndex) add(section, key string, line int) {
+	k := lineKey(section, key)
+	if _, ok := idx[k]; !ok {
+		idx[k] = line
+	}
+}
+
+// scanLines は空行・コメント行を除いた各行（前後の空白を除く）を行番号とともに渡す
+func scanLines(content string, fn func(n int, line string)) {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	n := 0
+	for scanner.Scan() {
+		n++
+		line := strings.TrimSpace(scanner.Text())
+		if n == 1 {
+			line = strings.TrimPrefix(line, "\ufeff") // BOM
+		}
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fn(n, line)
+	}
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// setting は設定ファイルのキー
+// 連番のキー以外は環境変数・コマンドラインで上書きでき、すべて config show で表示する
+type setting struct {
+	Section string
+	Key     string                   // 連番のキーの場合は番号を除いた名前（Pattern, CustomField）
+	value   func(c *Config) string   // 表示用の値
+	values  func(c *Config) []string // 連番のキーの値（Pattern1, Pattern2, ...）
+	kind    settingKind              // 値の種類（YAML/TOMLとの変換用）
+	secret  bool                     // 値を伏せ字で表示する
+}
+
+// settingKind は設定値の種類
+type settingKind int
+
+const (
+	kindString settingKind = iota
+	kindBool
+	kindInt
+	kindList     // カンマ区切りのリスト（YAML/TOMLでは配列）
+	kindDuration // 時間（1s, 500ms）
+	kindTags     // タグ名のリスト（"要約:3" のように個別の上限を指定可、YAML/TOMLでは Name/Limit の配列も可）
+)
+
+// id はキーの識別子（"Redmine.ApiKey" のような「セクション.キー」）
+func (s setting) id() string {
+	return s.Section + "." + s.Key
+}
+
+// numbered は連番のキーかどうかを判定
+func (s setting) numbered() bool {
+	return s.values != nil
+}
+
+// settings は設定ファイルのキーの一覧（config show の表示順）
+var settings = []setting{
+	{Section: "Redmine", Key: "BaseUrl", value: func(c *Config) string { return c.Redmine.BaseURL }},
+	{Section: "Redmine", Key: "ApiKey", value: func(c *Config) string { return c.Redmine.APIKey }, secret: true},
+	{Section: "Redmine", Key: "ApiKeyFile", value: func(c *Config) string { return c.Redmine.APIKeyFile }},
+	{Section: "Redmine", Key: "FilterUrl", value: func(c *Config) string { return c.Redmine.FilterURL }},
+	{Section: "Redmine", Key: "Concurrency", value: func(c *Config) string { return strconv.Itoa(c.Redmine.Concurrency) }, kind: kindInt},
+	{Section: "Redmine", Key: "MaxRetries", value: func(c *Config) string { return strconv.Itoa(c.Redmine.MaxRetries) }, kind: kindInt},
+	{Section: "Redmine", Key: "RetryBackoff", value: func(c *Config) string { return c.Redmine.RetryBackoff.String() }, kind: kindDuration},
+	{Section: "Redmine", Key: "RetryMaxBackoff", value: func(c *Config) string { return c.Redmine.RetryMaxBackoff.String() }, kind: kindDuration},
+
+	{Section: "TitleCleaning", Key: "Pattern", values: func(c *Config) []string { return c.TitleCleaning.Patterns }},
+
+	{Section: "Output", Key: "Path", value: func(c *Config) string { return c.Output.Path }},
+	{Section: "Output", Key: "Stdout", value: func(c *Config) string { return strconv.FormatBool(c.Output.Stdout) }, kind: kindBool},
+	{Section: "Output", Key: "Mode", value: func(c *Config) string { return c.Output.Mode }},
+	{Section: "Output", Key: "TagNames", value: func(c *Config) string { return strings.Join(c.Output.TagNames, ",") }, kind: kindTags},
+	{Section: "Output", Key: "TagsOrder", value: func(c *Config) string { return c.Output.TagsOrder }},
+	{Section: "Output", Key: "IncludeComments", value: func(c *Config) string { return strconv.FormatBool(c.Output.IncludeComments) }, kind: kindBool},
+	{Section: "Output", Key: "History", value: func(c *Config) string { return strconv.FormatBool(c.Output.History) }, kind: kindBool},
+	{Section: "Output", Key: "Columns", value: func(c *Config) string { return strings.Join(c.Output.Columns, ",") }, kind: kindList},
+	{Section: "Output", Key: "CSVEncoding", value: func(c *Config) string { return c.Output.CSVEncoding }},
+	{Section: "Output", Key: "SummaryJSON", value: func(c *Config) string { return c.Output.SummaryJSON }},
+
+	{Section: "Period", Key: "Week", value: func(c *Config) string { return c.Period.Week }},
+	{Section: "Period", Key: "WeekStart", value: func(c *Config) string { return c.Period.WeekStart }},
+	{Section: "Period", Key: "DateField", value: func(c *Config) string { return c.Period.DateField }},
+	{Section: "Period", Key: "Since", value: func(c *Config) string { return c.Period.Since }},
+	{Section: "Period", Key: "Until", value: func(c *Config) string { return c.Period.Until }},
+	{Section: "Period", Key: "Weeks", value: func(c *Config) string { return strconv.Itoa(c.Period.Weeks) }, kind: kindInt},
+	{Section: "Period", Key: "FromWeek", value: func(c *Config) string { return c.Period.FromWeek }},
+	{Section: "Period", Key: "ToWeek", value: func(c *Config) string { return c.Period.ToWeek }},
+	{Section: "Period", Key: "Range", value: func(c *Config) string { return c.Period.Range }},
+	{Section: "Period", Key: "FiscalYearStart", value: func(c *Config) string { return strconv.Itoa(c.Period.FiscalYearStart) }, kind: kindInt},
+
+	{Section: "Comments", Key: "Mode", value: func(c *Config) string { return c.Comments.Mode }},
+	{Section: "Comments", Key: "Since", value: func(c *Config) string { return c.Comments.Since }},
+	{Section: "Comments", Key: "By", value: func(c *Config) string { return c.Comments.By }},
+	{Section: "Comments", Key: "PreferComments", value: func(c *Config) string { return strconv.FormatBool(c.Comments.PreferComments) }, kind: kindBool},
+
+	{Section: "Grouping", Key: "GroupBy", value: func(c *Config) string { return c.Grouping.GroupBy }},
+	{Section: "Grouping", Key: "Sort", value: func(c *Config) string { return c.Grouping.Sort }, kind: kindList},
+
+	{Section: "Filter", Key: "Project", value: func(c *Config) string { return c.Filter.Project }},
+	{Section: "Filter", Key: "Subprojects", value: func(c *Config) string { return strconv.FormatBool(c.Filter.Subprojects) }, kind: kindBool},
+	{Section: "Filter", Key: "Tracker", value: func(c *Config) string { return c.Filter.Tracker }, kind: kindList},
+	{Section: "Filter", Key: "Status", value: func(c *Config) string { return c.Filter.Status }, kind: kindList},
+	{Section: "Filter", Key: "Assignee", value: func(c *Config) string { return c.Filter.Assignee }, kind: kindList},
+	{Section: "Filter", Key: "TargetVersion", value: func(c *Config) string { return c.Filter.TargetVersion }, kind: kindList},
+	{Section: "Filter", Key: "Category", value: func(c *Config) string { return c.Filter.Category }, kind: kindList},
+	{Section: "Filter", Key: "Author", value: func(c *Config) string { return c.Filter.Author }, kind: kindList},
+	{Section: "Filter", Key: "CustomField", values: func(c *Config) []string { return c.Filter.CustomFields }},
+	{Section: "Filter", Key: "Query", value: func(c *Config) string { return c.Filter.Query }},
+
+	{Section: "State", Key: "File", value: func(c *Config) string { return c.State.File }},
+	{Section: "State", Key: "CacheDir", value: func(c *Config) string { return c.State.CacheDir }},
+	{Section: "State", Key: "Offline", value: func(c *Config) string { return strconv.FormatBool(c.State.Offline) }, kind: kindBool},
+
+	{Section: "Template", Key: "Path", value: func(c *Config) string { return c.Template.Path }},
+
+	{Section: "Stats", Key: "Show", value: func(c *Config) string { return strconv.FormatBool(c.Stats.Show) }, kind: kindBool},
+	{Section: "Stats", Key: "IncludeMetrics", value: func(c *Config) string { return strconv.FormatBool(c.Stats.IncludeMetrics) }, kind: kindBool},
+	{Section: "Stats", Key: "TimeEntries", value: func(c *Config) string { return strconv.FormatBool(c.Stats.TimeEntries) }, kind: kindBool},
+	{Section: "Stats", Key: "Transitions", value: func(c *Config) string { return strconv.FormatBool(c.Stats.Transitions) }, kind: kindBool},
+	{Section: "Stats", Key: "StartedStatuses", value: func(c *Config) string { return strings.Join(c.Stats.StartedStatuses, ",") }, kind: kindList},
+
+	{Section: "Log", Key: "Verbose", value: func(c *Config) string { return strconv.FormatBool(c.Log.Verbose) }, kind: kindBool},
+	{Section: "Log", Key: "Quiet", value: func(c *Config) string { return strconv.FormatBool(c.Log.Quiet) }, kind: kindBool},
+}
+
+// KnownKey はキー（"Output.Mode" のような「セクション.キー」）が設定ファイルのキーかどうかを判定
+func KnownKey(id string) bool {
+	for _, s := range settings {
+		if s.id() == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Show は設定をiniファイルの形式で出力する（各値の後ろにコメントで取得元を表示し、APIキーは伏せ字にする）
+func (c *Config) Show(w io.Writer) error {
+	var b strings.Builder
+	if c.Profile != "" {
+		fmt.Fprintf(&b, "; プロファイル: %s\n", c.Profile)
+	}
+
+	section := ""
+	for _, s := range settings {
+		if s.Section != section {
+			if section != "" {
+				b.WriteString("\n")
+			}
+			section = s.Section
+			fmt.Fprintf(&b, "[%s]\n", section)
+		}
+
+		source := c.Source(s.id())
+		if source == "" {
+			source = "デフォルト"
+		}
+
+		if s.numbered() {
+			for i, v := range s.values(c) {
+				writeSetting(&b, fmt.Sprintf("%s%d", s.Key, i+1), v, source)
+			}
+			continue
+		}
+
+		value := s.value(c)
+		if s.secret {
+			value = MaskSecret(value)
+		}
+		writeSetting(&b, s.Key, value, source)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeSetting は「キー = 値 ; 取得元」の1行を書き込む
+func writeSetting(b *strings.Builder, key, value, source string) {
+	if value == "" {
+		fmt.Fprintf(b, "%s = ; %s\n", key, source)
+		return
+	}
+	fmt.Fprintf(b, "%s = %s ; %s\n", key, value, source)
+}
@@ -0,0 +1,238 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const runConfig = `[Redmine]
+BaseUrl=https://redmine.example.com
+ApiKey=0123456789abcdef0123
+FilterUrl=/issues.json
+
+[Output]
+Path=weekly.md
+TagNames=要約:3,進捗
+
+[Period]
+Week=last
+WeekStart=sun
+
+[Comments]
+Mode=n:3
+Since=start
+
+[Grouping]
+GroupBy=assignee
+Sort=updated_on:desc
+
+[Filter]
+Project=myproj
+Subprojects=false
+Status=open
+CustomField1=3=A社,B社
+CustomField2=5=高
+
+[State]
+File=.state.json
+
+[Stats]
+Show=true
+
+[Period.teamA]
+Week=this
+`
+
+func TestReadConfig_RunSections(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "run.config")
+	if err := os.WriteFile(configPath, []byte(runConfig), 0644); err != nil {
+		t.Fatalf("設定ファイルの作成に失敗: %v", err)
+	}
+
+	t.Run("設定ファイルの値とデフォルト値", func(t *testing.T) {
+		cfg, err := LoadWithOptions(configPath, LoadOptions{})
+		if err != nil {
+			t.Fatalf("LoadWithOptions()でエラー: %v", err)
+		}
+
+		if cfg.Output.Path != "weekly.md" || cfg.Output.Stdout {
+			t.Errorf("Output = %+v", cfg.Output)
+		}
+		if !reflect.DeepEqual(cfg.Output.TagNames, []string{"要約:3", "進捗"}) {
+			t.Errorf("TagNames = %v", cfg.Output.TagNames)
+		}
+		if cfg.Output.TagsOrder != "newest" {
+			t.Errorf("TagsOrder = %q; want newest", cfg.Output.TagsOrder)
+		}
+		want := PeriodConfig{Week: "last", WeekStart: "sun", DateField: "updated_on", FiscalYearStart: 4}
+		if cfg.Period != want {
+			t.Errorf("Period = %+v; want %+v", cfg.Period, want)
+		}
+		if cfg.Comments.Mode != "n:3" || cfg.Comments.Since != "start" || cfg.Comments.PreferComments {
+			t.Errorf("Comments = %+v", cfg.Comments)
+		}
+		if cfg.Grouping != (GroupingConfig{GroupBy: "assignee", Sort: "updated_on:desc"}) {
+			t.Errorf("Grouping = %+v", cfg.Grouping)
+		}
+		if cfg.Filter.Project != "myproj" || cfg.Filter.Subprojects || cfg.Filter.Status != "open" {
+			t.Errorf("Filter = %+v", cfg.Filter)
+		}
+		if !reflect.DeepEqual(cfg.Filter.CustomFields, []string{"3=A社,B社", "5=高"}) {
+			t.Errorf("CustomFields = %v", cfg.Filter.CustomFields)
+		}
+		if cfg.State.File != ".state.json" || cfg.State.Offline {
+			t.Errorf("State = %+v", cfg.State)
+		}
+		if !cfg.Stats.Show || cfg.Stats.TimeEntries {
+			t.Errorf("Stats = %+v", cfg.Stats)
+		}
+		if cfg.Log.Verbose {
+			t.Error("Verbose = true; want false")
+		}
+	})
+
+	t.Run("プロファイル・環境変数・コマンドラインで上書き", func(t *testing.T) {
+		t.Setenv("REDMINE_GROUPING_GROUP_BY", "status")
+		t.Setenv("REDMINE_STATS_TIME_ENTRIES", "true")
+
+		cfg, err := LoadWithOptions(configPath, LoadOptions{
+			Profile: "teamA",
+			Overrides: map[string]string{
+				"Grouping.GroupBy":   "tracker",
+				"Filter.Subprojects": "true",
+				"Filter.CustomField": "7=x\n8=y",
+				"Log.Verbose":        "true",
+			},
+		})
+		if err != nil {
+			t.Fatalf("LoadWithOptions()でエラー: %v", err)
+		}
+
+		if cfg.Period.Week != "this" || cfg.Period.WeekStart != "sun" {
+			t.Errorf("Period = %+v; want プロファイルのWeekとデフォルトのWeekStart", cfg.Period)
+		}
+		if cfg.Grouping.GroupBy != "tracker" {
+			t.Errorf("GroupBy = %q; want tracker（コマンドライン）", cfg.Grouping.GroupBy)
+		}
+		if !cfg.Stats.TimeEntries {
+			t.Error("TimeEntries = false; want true（環境変数）")
+		}
+		if !cfg.Filter.Subprojects || !cfg.Log.Verbose {
+			t.Errorf("Subprojects = %v, Verbose = %v; want true", cfg.Filter.Subprojects, cfg.Log.Verbose)
+		}
+		if !reflect.DeepEqual(cfg.Filter.CustomFields, []string{"7=x", "8=y"}) {
+			t.Errorf("CustomFields = %v; want コマンドラインの値のみ", cfg.Filter.CustomFields)
+		}
+
+		sources := map[string]string{
+			"Period.Week":        "設定ファイル [Period.teamA]",
+			"Period.WeekStart":   "設定ファイル [Period]",
+			"Grouping.GroupBy":   "コマンドライン",
+			"Stats.TimeEntries":  "環境変数 REDMINE_STATS_TIME_ENTRIES",
+			"Filter.CustomField": "コマンドライン",
+			"Comments.By":        "",
+		}
+		for key, want := range sources {
+			if got := cfg.Source(key); got != want {
+				t.Errorf("Source(%s) = %q; want %q", key, got, want)
+			}
+		}
+	})
+}
+
+func TestConfigShow(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "show.config")
+	content := `[Redmine]
+BaseUrl=${TEST_SHOW_BASE_URL}
+ApiKey=0123456789abcdef0123
+FilterUrl=/issues.json
+
+[TitleCleaning]
+Pattern1=^\[.*?\]\s*
+
+[Period]
+Week=last
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("設定ファイルの作成に失敗: %v", err)
+	}
+	t.Setenv("TEST_SHOW_BASE_URL", "https://redmine.example.com")
+	t.Setenv("REDMINE_OUTPUT_MODE", "full")
+
+	tests := []struct {
+		name    string
+		opts    LoadOptions
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "設定ファイルの値のみ",
+			opts: LoadOptions{FileOnly: true, Overrides: map[string]string{"Period.Week": "this"}},
+			want: []string{
+				"[Redmine]\nBaseUrl = ${TEST_SHOW_BASE_URL} ; 設定ファイル [Redmine]\n",
+				"ApiKey = ********0123 ; 設定ファイル [Redmine]\n",
+				"Pattern1 = ^\\[.*?\\]\\s* ; 設定ファイル [TitleCleaning]\n",
+				"Mode = summary ; デフォルト\n",
+				"[Period]\nWeek = last ; 設定ファイル [Period]\n",
+			},
+			notWant: []string{"0123456789abcdef"},
+		},
+		{
+			name: "環境変数・コマンドラインを適用",
+			opts: LoadOptions{Overrides: map[string]string{"Period.Week": "this"}},
+			want: []string{
+				"BaseUrl = https://redmine.example.com ; 設定ファイル [Redmine]（環境変数 TEST_SHOW_BASE_URL を展開）\n",
+				"Mode = full ; 環境変数 REDMINE_OUTPUT_MODE\n",
+				"Week = this ; コマンドライン\n",
+				"[Log]\nVerbose = false ; デフォルト\n",
+			},
+			notWant: []string{"0123456789abcdef"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ReadConfig(configPath, tt.opts)
+			if err != nil {
+				t.Fatalf("ReadConfig()でエラー: %v", err)
+			}
+			var b strings.Builder
+			if err := cfg.Show(&b); err != nil {
+				t.Fatalf("Show()でエラー: %v", err)
+			}
+			got := b.String()
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("出力に %q が含まれていない:\n%s", want, got)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(got, s) {
+					t.Errorf("出力に %q が含まれている", s)
+				}
+			}
+		})
+	}
+}
+
+func TestReadConfig_NoValidation(t *testing.T) {
+	// config show では必須項目が空でも表示できる
+	configPath := filepath.Join(t.TempDir(), "empty.config")
+	if err := os.WriteFile(configPath, []byte("[Period]\nWeek=last\n"), 0644); err != nil {
+		t.Fatalf("設定ファイルの作成に失敗: %v", err)
+	}
+
+	cfg, err := ReadConfig(configPath, LoadOptions{})
+	if err != nil {
+		t.Fatalf("ReadConfig()でエラー: %v", err)
+	}
+	if cfg.Period.Week != "last" {
+		t.Errorf("Week = %q; want last", cfg.Period.Week)
+	}
+	if _, err := LoadWithOptions(configPath, LoadOptions{}); err == nil {
+		t.Error("LoadWithOptions()で必須項目のエラーが返されなかった")
+	}
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"gopkg.in/ini.v1"
+)
+
+// 設定値の取得元の優先度（大きいほど優先）: コマンドライン > 環境変数 > 設定ファイル
+const (
+	rankFile = iota + 1
+	rankEnv
+	rankCLI
+)
+
+// LoadOptions は設定ファイル読み込み時のオプション
+type LoadOptions struct {
+	Profile string // プロファイル名（空の場合はデフォルトのみ）
+
+	// Overrides はコマンドラインで指定された値（キーは "Redmine.ApiKeyFile" のような「セクション.キー」）
+	// 環境変数・設定ファイルより優先する
+	// 連番のキー（CustomField1, ...）は "Filter.CustomField" に改行区切りで指定し、設定ファイルの値をすべて置き換える
+	Overrides map[string]string
+
+	// FileOnly は設定ファイルの値だけを使う（環境変数・コマンドラインの値と ${VAR} の展開を適用しない）
+	FileOnly bool
+}
+
+// EnvName はキーに対応する環境変数名を返す
+// [Redmine] は REDMINE_<キー>、それ以外は REDMINE_<セクション>_<キー>（キーは大文字のスネークケース）
+// 例: Redmine.ApiKey → REDMINE_API_KEY, Output.CSVEncoding → REDMINE_OUTPUT_CSV_ENCODING
+func EnvName(section, key string) string {
+	if section == "Redmine" {
+		return "REDMINE_" + toUpperSnake(key)
+	}
+	return "REDMINE_" + toUpperSnake(section) + "_" + toUpperSnake(key)
+}
+
+// toUpperSnake はキャメルケースを大文字のスネークケースに変換（CSVEncoding → CSV_ENCODING）
+func toUpperSnake(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// envVarPattern は設定値の中の ${VAR} 形式の環境変数参照
+// 正規表現のパターンで使う $ と区別するため、波括弧付きの形式のみ展開する
+var envVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnvVars は設定ファイルの値の ${VAR} を環境変数の値に展開する（他のプロファイルのセクションは対象外）
+// 展開したキー（「セクション.キー」）と参照した環境変数名を返す
+func expandEnvVars(cfg *ini.File, profile string) (map[string][]string, error) {
+	expanded := make(map[string][]string)
+	for _, sec := range cfg.Sections() {
+		if _, p, ok := strings.Cut(sec.Name(), "."); ok && p != profile {
+			continue
+		}
+		for _, name := range sec.KeyStrings() {
+			key := sec.Key(name)
+			value := key.Value()
+			if !strings.Contains(value, "${") {
+				continue
+			}
+
+			var missing []string
+			var vars []string
+			result := envVarPattern.ReplaceAllStringFunc(value, func(m string) string {
+				v := envVarPattern.FindStringSubmatch(m)[1]
+				vars = append(vars, v)
+				env, ok := os.LookupEnv(v)
+				if !ok {
+					missing = append(missing, v)
+				}
+				return env
+			})
+			if len(missing) > 0 {
+				return nil, fmt.Errorf("[%s] %s: 環境変数が設定されていません: %s", sec.Name(), name, strings.Join(missing, ", "))
+			}
+			key.SetValue(result)
+			expanded[sec.Name()+"."+name] = vars
+		}
+	}
+	return expanded, nil
+}
+
+// valueSource は設定値の取得元
+type valueSource struct {
+	rank    int
+	desc    string // 表示用の説明（値そのものは含めない）
+	section string // 設定ファイルの場合は値を定義しているセクション
+}
+
+// applySources は環境変数とコマンドラインの値をプロファイルのセクションに反映し、キーごとの取得元を返す
+func applySources(cfg *ini.File, sectionName func(string) string, opts LoadOptions, expanded map[string][]string) map[string]valueSource {
+	sources := make(map[string]valueSource)
+	for _, s := range settings {
+		if s.numbered() {
+			continue // 連番のキーは個別に読み込む
+		}
+		target := cfg.Section(sectionName(s.Section))
+		id := s.id()
+
+		// 設定ファイル（プロファイルのセクション → デフォルトのセクションの順）
+		if defined := definedSection(cfg, sectionName(s.Section), s.Key); defined != "" {
+			desc := fmt.Sprintf("設定ファイル [%s]", defined)
+			if vars := expanded[defined+"."+s.Key]; len(vars) > 0 {
+				desc += fmt.Sprintf("（環境変数 %s を展開）", strings.Join(vars, ", "))
+			}
+			sources[id] = valueSource{rank: rankFile, desc: desc, section: defined}
+		}
+		if opts.FileOnly {
+			continue
+		}
+
+		// 環境変数
+		env := EnvName(s.Section, s.Key)
+		if v, ok := os.LookupEnv(env); ok && v != "" {
+			target.NewKey(s.Key, v)
+			sources[id] = valueSource{rank: rankEnv, desc: "環境変数 " + env}
+		}
+
+		// コマンドライン
+		if v, ok := opts.Overrides[id]; ok && v != "" {
+			target.NewKey(s.Key, v)
+			sources[id] = valueSource{rank: rankCLI, desc: "コマンドライン"}
+		}
+	}
+	return sources
+}
+
+// definedSection はキーが定義されているセクション名を返す（子セクションから親セクションの順に探す）
+func definedSection(cfg *ini.File, name, key string) string {
+	for {
+		if sec, err := cfg.GetSection(name); err == nil {
+			for _, k := range sec.KeyStrings() {
+				if k == key {
+					return name
+				}
+			}
+		}
+		i := strings.LastIndex(name, ".")
+		if i < 0 {
+			return ""
+		}
+		name = name[:i]
+	}
+}
+
+// readSecretFile はシークレットファイルを読み込み、前後の空白・改行を除いた値を返す
+// 相対パスは baseDir からの相対パスとして扱う
+func readSecretFile(path, baseDir string) (string, error) {
+	if !filepath.IsAbs(path) && baseDir != "" {
+		path = filepath.Join(baseDir, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// MaskSecret はシークレットを表示用に伏せ字にする（十分な長さがある場合のみ末尾4文字を残す）
+func MaskSecret(secret string) string {
+	if secret == "" {
+		return "(未設定)"
+	}
+	if len(secret) < 16 {
+		return "********"
+	}
+	return "********" + secret[len(secret)-4:]
+}
@@ -0,0 +1,206 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	tests := []struct {
+		section string
+		key     string
+		want    string
+	}{
+		{section: "Redmine", key: "ApiKey", want: "REDMINE_API_KEY"},
+		{section: "Redmine", key: "ApiKeyFile", want: "REDMINE_API_KEY_FILE"},
+		{section: "Redmine", key: "BaseUrl", want: "REDMINE_BASE_URL"},
+		{section: "Redmine", key: "RetryMaxBackoff", want: "REDMINE_RETRY_MAX_BACKOFF"},
+		{section: "Output", key: "CSVEncoding", want: "REDMINE_OUTPUT_CSV_ENCODING"},
+		{section: "Output", key: "TagNames", want: "REDMINE_OUTPUT_TAG_NAMES"},
+	}
+
+	for _, tt := range tests {
+		if got := EnvName(tt.section, tt.key); got != tt.want {
+			t.Errorf("EnvName(%s, %s) = %q; want %q", tt.section, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestLoadWithOptions_Sources(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "api.key"), []byte("file_secret_key\n"), 0600); err != nil {
+		t.Fatalf("APIキーファイルの作成に失敗: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "cli.key"), []byte("cli_secret_key"), 0600); err != nil {
+		t.Fatalf("APIキーファイルの作成に失敗: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		config     string
+		env        map[string]string
+		overrides  map[string]string
+		wantKey    string
+		wantSource string // 取得元の説明に含まれる文字列
+		wantMode   string
+		wantErr    string // エラーメッセージに含まれる文字列
+	}{
+		{
+			name:       "設定ファイル",
+			config:     "ApiKey=plain_key\n",
+			wantKey:    "plain_key",
+			wantSource: "設定ファイル [Redmine]",
+		},
+		{
+			name:       "${VAR}の展開",
+			config:     "ApiKey=${TEST_REDMINE_KEY}\n",
+			env:        map[string]string{"TEST_REDMINE_KEY": "expanded_key"},
+			wantKey:    "expanded_key",
+			wantSource: "環境変数 TEST_REDMINE_KEY を展開",
+		},
+		{
+			name:    "${VAR}の環境変数が未設定",
+			config:  "ApiKey=${TEST_REDMINE_UNSET}\n",
+			wantErr: "TEST_REDMINE_UNSET",
+		},
+		{
+			name:       "環境変数は設定ファイルより優先",
+			config:     "ApiKey=plain_key\n",
+			env:        map[string]string{"REDMINE_API_KEY": "env_key"},
+			wantKey:    "env_key",
+			wantSource: "環境変数 REDMINE_API_KEY",
+		},
+		{
+			name:       "ApiKeyFile（設定ファイルからの相対パス）",
+			config:     "ApiKey=plain_key\nApiKeyFile=api.key\n",
+			wantKey:    "file_secret_key",
+			wantSource: "ApiKeyFile api.key",
+		},
+		{
+			name:       "環境変数のApiKeyは設定ファイルのApiKeyFileより優先",
+			config:     "ApiKeyFile=api.key\n",
+			env:        map[string]string{"REDMINE_API_KEY": "env_key"},
+			wantKey:    "env_key",
+			wantSource: "環境変数 REDMINE_API_KEY",
+		},
+		{
+			name:       "コマンドラインは環境変数より優先",
+			config:     "ApiKey=plain_key\n",
+			env:        map[string]string{"REDMINE_API_KEY": "env_key"},
+			overrides:  map[string]string{"Redmine.ApiKeyFile": filepath.Join(tmpDir, "cli.key")},
+			wantKey:    "cli_secret_key",
+			wantSource: "コマンドライン",
+		},
+		{
+			name:    "ApiKeyFileが存在しない",
+			config:  "ApiKeyFile=missing.key\n",
+			wantErr: "ApiKeyFile",
+		},
+		{
+			name:    "環境変数が空のApiKeyを指定",
+			config:  "ApiKey=${TEST_REDMINE_EMPTY}\n",
+			env:     map[string]string{"TEST_REDMINE_EMPTY": ""},
+			wantErr: "取得元: 設定ファイル [Redmine]",
+		},
+		{
+			name:       "Outputセクションも環境変数で上書き",
+			config:     "ApiKey=plain_key\n",
+			env:        map[string]string{"REDMINE_OUTPUT_MODE": "full"},
+			wantKey:    "plain_key",
+			wantSource: "設定ファイル [Redmine]",
+			wantMode:   "full",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			configPath := filepath.Join(tmpDir, "test.config")
+			content := "[Redmine]\nBaseUrl=https://redmine.example.com\nFilterUrl=/issues.json\n" + tt.config
+			if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+				t.Fatalf("設定ファイルの作成に失敗: %v", err)
+			}
+
+			cfg, err := LoadWithOptions(configPath, LoadOptions{Overrides: tt.overrides})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("エラー = %v; want %q を含むエラー", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadWithOptions()でエラー: %v", err)
+			}
+
+			if cfg.Redmine.APIKey != tt.wantKey {
+				t.Errorf("APIKey = %q; want %q", cfg.Redmine.APIKey, tt.wantKey)
+			}
+			if src := cfg.Source("Redmine.ApiKey"); !strings.Contains(src, tt.wantSource) {
+				t.Errorf("取得元 = %q; want %q を含む", src, tt.wantSource)
+			}
+			if strings.Contains(cfg.Source("Redmine.ApiKey"), cfg.Redmine.APIKey) {
+				t.Errorf("取得元にAPIキーが含まれている: %q", cfg.Source("Redmine.ApiKey"))
+			}
+			if tt.wantMode != "" && cfg.Output.Mode != tt.wantMode {
+				t.Errorf("Mode = %q; want %q", cfg.Output.Mode, tt.wantMode)
+			}
+		})
+	}
+}
+
+func TestLoadWithOptions_ProfileEnvExpansion(t *testing.T) {
+	// 他のプロファイルの未設定の環境変数はエラーにしない
+	configPath := filepath.Join(t.TempDir(), "test.config")
+	content := `[Redmine]
+BaseUrl=https://redmine.example.com
+ApiKey=default_key
+FilterUrl=/issues.json
+
+[Redmine.teamA]
+ApiKey=${TEST_TEAMA_KEY}
+
+[Redmine.teamB]
+ApiKey=${TEST_TEAMB_KEY_UNSET}
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("設定ファイルの作成に失敗: %v", err)
+	}
+	t.Setenv("TEST_TEAMA_KEY", "teama_key")
+
+	cfg, err := LoadWithOptions(configPath, LoadOptions{Profile: "teamA"})
+	if err != nil {
+		t.Fatalf("LoadWithOptions()でエラー: %v", err)
+	}
+	if cfg.Redmine.APIKey != "teama_key" {
+		t.Errorf("APIKey = %q; want teama_key", cfg.Redmine.APIKey)
+	}
+	if src := cfg.Source("Redmine.ApiKey"); !strings.Contains(src, "[Redmine.teamA]") {
+		t.Errorf("取得元 = %q; want [Redmine.teamA] を含む", src)
+	}
+
+	if _, err := LoadWithOptions(configPath, LoadOptions{Profile: "teamB"}); err == nil {
+		t.Error("teamBの未設定の環境変数でエラーが発生しなかった")
+	}
+}
+
+func TestMaskSecret(t *testing.T) {
+	tests := []struct {
+		secret string
+		want   string
+	}{
+		{secret: "", want: "(未設定)"},
+		{secret: "short", want: "********"},
+		{secret: "0123456789abcdef0123", want: "********0123"},
+	}
+
+	for _, tt := range tests {
+		if got := MaskSecret(tt.secret); got != tt.want {
+			t.Errorf("MaskSecret(%q) = %q; want %q", tt.secret, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,421 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// Severity は設定の問題の重要度
+type Severity int
+
+const (
+	SeverityError   Severity = iota // 実行できない設定
+	SeverityWarning                 // 実行はできるが、意図しない動作になる可能性がある設定
+)
+
+// String は重要度の表示名
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "警告"
+	}
+	return "エラー"
+}
+
+// Diagnostic は設定の問題（どのキーの値か、設定ファイルの何行目かを含む）
+type Diagnostic struct {
+	Severity Severity
+	Section  string // 値を定義しているセクション（[Output.teamA] など）
+	Key      string // キー名（連番のキーは Pattern2 のように番号付き）
+	File     string // 設定ファイルのパス（値の取得元が設定ファイルの場合のみ）
+	Line     int    // 設定ファイルの行番号（不明な場合は0）
+	Source   string // 設定ファイル以外の取得元（環境変数・コマンドライン）
+	Message  string
+}
+
+// String は「ファイル:行: [セクション] キー: メッセージ」の形式で返す
+func (d Diagnostic) String() string {
+	var b strings.Builder
+	if d.File != "" {
+		b.WriteString(d.File)
+		if d.Line > 0 {
+			fmt.Fprintf(&b, ":%d", d.Line)
+		}
+		b.WriteString(": ")
+	}
+	if d.Section != "" {
+		fmt.Fprintf(&b, "[%s]", d.Section)
+		if d.Key != "" {
+			b.WriteString(" ")
+		}
+	}
+	b.WriteString(d.Key)
+	if d.Source != "" {
+		fmt.Fprintf(&b, "（%s）", d.Source)
+	}
+	if d.Section != "" || d.Key != "" {
+		b.WriteString(": ")
+	}
+	b.WriteString(d.Message)
+	return b.String()
+}
+
+// ValidationError は設定のエラー（警告は含まない）
+type ValidationError struct {
+	Diagnostics []Diagnostic
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Diagnostics) == 1 {
+		return e.Diagnostics[0].String()
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "設定に %d 件のエラーがあります:", len(e.Diagnostics))
+	for _, d := range e.Diagnostics {
+		b.WriteString("\n  - ")
+		b.WriteString(d.String())
+	}
+	return b.String()
+}
+
+// Errors は診断結果のうちエラーのみを返す
+func Errors(diags []Diagnostic) []Diagnostic {
+	var errs []Diagnostic
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			errs = append(errs, d)
+		}
+	}
+	return errs
+}
+
+// Diagnose はキー（"Output.Mode"、連番のキーは "TitleCleaning.Pattern2"）の問題を作る
+// 値が設定ファイルのものであればファイル名と行番号、それ以外は取得元（環境変数・コマンドライン）を付ける
+func (c *Config) Diagnose(id string, severity Severity, format string, args ...interface{}) Diagnostic {
+	section, key, _ := strings.Cut(id, ".")
+	d := Diagnostic{Severity: severity, Section: section, Key: key, Message: fmt.Sprintf(format, args...)}
+
+	baseID := section + "." + strings.TrimRight(key, "0123456789")
+	if defined := c.defined[baseID]; defined != "" {
+		d.Section = defined
+		d.File = c.file
+		d.Line = c.lineIndex().lookup(defined, key)
+	} else if src := c.Source(baseID); src != "" {
+		d.Source = src
+	}
+	return d
+}
+
+// diagnoseAt は設定ファイルのセクション・キーの問題を作る（設定の一覧にないキー用）
+func (c *Config) diagnoseAt(section, key string, severity Severity, format string, args ...interface{}) Diagnostic {
+	return Diagnostic{
+		Severity: severity,
+		Section:  section,
+		Key:      key,
+		File:     c.file,
+		Line:     c.lineIndex().lookup(section, key),
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+// lineIndex は設定ファイルの行番号の対応を返す（初回のみ読み込む）
+func (c *Config) lineIndex() lineIndex {
+	if c.lines == nil {
+		if c.file == "" {
+			c.lines = lineIndex{}
+		} else {
+			c.lines = readLineIndex(c.file)
+		}
+	}
+	return c.lines
+}
+
+// Validate は設定値の妥当性をチェックし、エラーがあれば *ValidationError を返す
+// 必須項目が空の場合は、どの取得元の値が空だったかをエラーに含める（値そのものは表示しない）
+func (c *Config) Validate() error {
+	if errs := Errors(c.Check()); len(errs) > 0 {
+		return &ValidationError{Diagnostics: errs}
+	}
+	return nil
+}
+
+// Check は設定値をチェックし、エラーと警告をすべて返す
+// 読み込み時に見つかった問題（未知のキー、数値・真偽値の形式エラーなど）も含む
+func (c *Config) Check() []Diagnostic {
+	diags := append([]Diagnostic(nil), c.problems...)
+	add := func(id string, severity Severity, format string, args ...interface{}) {
+		diags = append(diags, c.Diagnose(id, severity, format, args...))
+	}
+
+	// [Redmine] 必須項目と接続先
+	required := []struct{ key, value string }{
+		{"BaseUrl", c.Redmine.BaseURL},
+		{"ApiKey", c.Redmine.APIKey},
+		{"FilterUrl", c.Redmine.FilterURL},
+	}
+	for _, r := range required {
+		if r.value == "" {
+			d := c.Diagnose("Redmine."+r.key, SeverityError, "%v", c.missingError("Redmine", r.key))
+			d.Source = "" // 取得元はメッセージに含まれる
+			diags = append(diags, d)
+		}
+	}
+	if c.Redmine.BaseURL != "" {
+		u, err := url.Parse(c.Redmine.BaseURL)
+		switch {
+		case err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "":
+			add("Redmine.BaseUrl", SeverityError, "URLの形式が不正です: %s（http:// または https:// で始まるRedmineのURLを指定してください）", c.Redmine.BaseURL)
+		case u.RawQuery != "" || strings.HasSuffix(u.Path, ".json"):
+			add("Redmine.BaseUrl", SeverityError, "RedmineのURLのみを指定してください: %s（/issues.json 以降は FilterUrl に指定）", c.Redmine.BaseURL)
+		}
+	}
+	if c.Redmine.FilterURL != "" && !strings.HasPrefix(c.Redmine.FilterURL, "/issues.json") {
+		add("Redmine.FilterUrl", SeverityError, "/issues.json で始まるパスを指定してください: %s（例: /issues.json?project_id=1&status_id=*）", c.Redmine.FilterURL)
+	}
+	if c.Redmine.Concurrency < 0 {
+		add("Redmine.Concurrency", SeverityError, "0以上を指定してください: %d", c.Redmine.Concurrency)
+	}
+	if c.Redmine.MaxRetries < 0 {
+		add("Redmine.MaxRetries", SeverityError, "0以上を指定してください: %d", c.Redmine.MaxRetries)
+	}
+
+	// [TitleCleaning] 正規表現（VBA版と異なり、不正なパターンは読み飛ばさずにエラーにする）
+	for i, pattern := range c.TitleCleaning.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			add(fmt.Sprintf("TitleCleaning.Pattern%d", i+1), SeverityError, "正規表現の構文エラー: %s (%v)", pattern, err)
+		}
+	}
+
+	// [Output]
+	checkChoice := func(id, value string, choices ...string) {
+		if value == "" {
+			return
+		}
+		for _, choice := range choices {
+			if value == choice {
+				return
+			}
+		}
+		add(id, SeverityError, "未対応の値です: %s (%s)", value, strings.Join(choices, ", "))
+	}
+	checkChoice("Output.Mode", c.Output.Mode, "summary", "full", "tags")
+	checkChoice("Output.TagsOrder", c.Output.TagsOrder, "newest", "oldest")
+	diags = append(diags, c.checkTagNames()...)
+	if c.Output.Stdout && c.Output.Path != "" {
+		add("Output.Stdout", SeverityWarning, "標準出力に出力するため、Path (%s) には出力しません", c.Output.Path)
+	}
+
+	// [Period]
+	checkChoice("Period.WeekStart", c.Period.WeekStart, "mon", "sun")
+	checkChoice("Period.DateField", c.Period.DateField, "updated_on", "created_on", "start_date", "due_date")
+	for _, p := range []struct{ key, value string }{{"Since", c.Period.Since}, {"Until", c.Period.Until}} {
+		if p.value == "" || p.value == "auto" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", p.value); err != nil {
+			add("Period."+p.key, SeverityError, "日付の形式が不正です: %s (auto または YYYY-MM-DD)", p.value)
+		}
+	}
+	if c.Period.Since == "auto" && c.State.File == "" {
+		add("Period.Since", SeverityError, "auto を使用するには [State] File（--state）でStateファイルを指定してください")
+	}
+	if c.Period.Weeks < 0 {
+		add("Period.Weeks", SeverityError, "0以上を指定してください: %d", c.Period.Weeks)
+	}
+	if c.Period.Weeks > 0 && c.Period.FromWeek != "" {
+		add("Period.Weeks", SeverityError, "FromWeek（--from-week）と同時に指定できません（週数または開始週のどちらかを指定してください）")
+	}
+	if c.Period.Trend() && (c.Period.Since != "" || c.Period.Until != "") {
+		add("Period.Since", SeverityError, "推移（Weeks / FromWeek）と同時に指定できません")
+	}
+	if c.Period.ToWeek != "" && !c.Period.Trend() {
+		add("Period.ToWeek", SeverityWarning, "Weeks（--weeks）または FromWeek（--from-week）が指定されていないため無視されます")
+	}
+	if c.Period.Range != "" && c.Period.Week != "" {
+		add("Period.Range", SeverityError, "Week（--week）と同時に指定できません")
+	}
+	if c.Period.Range != "" && c.Period.Trend() {
+		add("Period.Range", SeverityError, "推移（Weeks / FromWeek）と同時に指定できません")
+	}
+	if c.Period.Range != "" && (c.Period.FiscalYearStart < 1 || c.Period.FiscalYearStart > 12) {
+		add("Period.FiscalYearStart", SeverityError, "1〜12を指定してください: %d", c.Period.FiscalYearStart)
+	}
+
+	// [Filter]
+	if !c.Filter.Subprojects && c.Filter.Project == "" && c.Source("Filter.Subprojects") != "" {
+		add("Filter.Subprojects", SeverityWarning, "Project（--project）が指定されていないため無視されます")
+	}
+	if c.Filter.Query != "" && c.State.Offline {
+		add("Filter.Query", SeverityWarning, "Offline（--offline）では保存済みクエリを使用できないため無視されます")
+	} else if c.Filter.Query != "" && c.hasFilterConditions() {
+		add("Filter.Query", SeverityWarning, "保存済みクエリの条件が優先され、[Filter] の他の絞り込み条件は無視されます")
+	}
+
+	// [State]
+	if c.State.Offline && c.State.File == "" && c.State.CacheDir == "" {
+		add("State.Offline", SeverityError, "キャッシュの場所が指定されていません（[State] File（--state）または CacheDir（--cache-dir）を指定してください）")
+	}
+
+	return diags
+}
+
+// checkTagNames はタグの指定（"要約:3"）の形式と重複をチェックする
+func (c *Config) checkTagNames() []Diagnostic {
+	var diags []Diagnostic
+	seen := make(map[string]bool)
+	for _, item := range c.Output.TagNames {
+		name, limit, hasLimit := strings.Cut(item, ":")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			diags = append(diags, c.Diagnose("Output.TagNames", SeverityError, "タグ名が空です: %s", item))
+			continue
+		}
+		if hasLimit {
+			if n, err := strconv.Atoi(strings.TrimSpace(limit)); err != nil || n < 0 {
+				diags = append(diags, c.Diagnose("Output.TagNames", SeverityError, "タグ %s の件数は0以上の整数で指定してください: %s", name, limit))
+			}
+		}
+		if seen[name] {
+			diags = append(diags, c.Diagnose("Output.TagNames", SeverityError, "タグ %s が重複しています", name))
+		}
+		seen[name] = true
+	}
+	return diags
+}
+
+// hasFilterConditions は保存済みクエリ以外の絞り込み条件が指定されているかを判定
+func (c *Config) hasFilterConditions() bool {
+	f := c.Filter
+	return f.Project != "" || f.Tracker != "" || f.Status != "" || f.Assignee != "" ||
+		f.TargetVersion != "" || f.Category != "" || f.Author != "" || len(f.CustomFields) > 0
+}
+
+// checkValueTypes は数値・真偽値・時間のキーの値の形式をチェックする（不正な値はデフォルト値として読み込まれる）
+func (c *Config) checkValueTypes(section func(string) *ini.Section) {
+	for _, s := range settings {
+		if s.numbered() {
+			continue
+		}
+		value := section(s.Section).Key(s.Key).String()
+		if value == "" {
+			continue
+		}
+		var err error
+		var want string
+		switch s.kind {
+		case kindBool:
+			_, err = strconv.ParseBool(value)
+			want = "true / false "
+		case kindInt:
+			_, err = strconv.Atoi(value)
+			want = "整数"
+		case kindDuration:
+			_, err = time.ParseDuration(value)
+			want = "時間（例: 1s, 500ms）"
+		default:
+			continue
+		}
+		if err != nil {
+			c.problems = append(c.problems, c.Diagnose(s.id(), SeverityError, "%sで指定してください: %s", want, value))
+		}
+	}
+}
+
+// checkKeys は設定ファイルの未知のセクション・キーと、番号が途切れた連番のキーをチェックする
+// 対象はデフォルトと指定されたプロファイルのセクション（未知のセクションはすべて）
+func (c *Config) checkKeys(file *ini.File, profile string) {
+	for _, sec := range file.Sections() {
+		name := sec.Name()
+		if name == ini.DefaultSection {
+			for _, key := range sec.KeyStrings() {
+				c.problems = append(c.problems, c.diagnoseAt(name, key, SeverityWarning, "セクションの外にあるキーは使用されません"))
+			}
+			continue
+		}
+
+		base, p, _ := strings.Cut(name, ".")
+		if !isProfileSection(base) {
+			c.problems = append(c.problems, c.diagnoseAt(name, "", SeverityWarning, "未知のセクションです%s", suggestion(profileSections, base)))
+			continue
+		}
+		if p != "" && p != profile {
+			continue
+		}
+
+		for _, key := range sec.KeyStrings() {
+			s, known := lookupKey(base, key)
+			if !known {
+				c.problems = append(c.problems, c.diagnoseAt(name, key, SeverityWarning, "未知のキーです%s", suggestion(sectionKeys(base), key)))
+				continue
+			}
+			if !s.numbered() {
+				continue
+			}
+			// 連番は番号が途切れたところで読み込みを終了する
+			n, _ := strconv.Atoi(strings.TrimPrefix(key, s.Key))
+			if n < 1 || (n > 1 && !sec.HasKey(fmt.Sprintf("%s%d", s.Key, n-1))) {
+				c.problems = append(c.problems, c.diagnoseAt(name, key, SeverityWarning, "%s1 からの番号が連続していないため読み込まれません", s.Key))
+			}
+		}
+	}
+}
+
+// lookupKey はINIのキー名（連番のキーは番号付き）に対応する設定を探す
+func lookupKey(section, key string) (setting, bool) {
+	for _, s := range settings {
+		if s.Section != section {
+			continue
+		}
+		if s.Key == key {
+			return s, !s.numbered()
+		}
+		if s.numbered() {
+			if n := strings.TrimPrefix(key, s.Key); n != key && n != "" && strings.Trim(n, "0123456789") == "" {
+				return s, true
+			}
+		}
+	}
+	return setting{}, false
+}
+
+// isProfileSection は設定のセクション名かどうかを判定
+func isProfileSection(name string) bool {
+	for _, s := range profileSections {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sectionKeys はセクションのキー名の一覧（連番のキーは Pattern1 のように番号付き）
+func sectionKeys(section string) []string {
+	var keys []string
+	for _, s := range settings {
+		if s.Section != section {
+			continue
+		}
+		if s.numbered() {
+			keys = append(keys, s.Key+"1")
+			continue
+		}
+		keys = append(keys, s.Key)
+	}
+	return keys
+}
+
+// suggestion は大文字小文字・アンダースコアの違いだけで一致する名前があれば候補として返す
+func suggestion(candidates []string, name string) string {
+	n := normalizeName(strings.TrimRight(name, "0123456789"))
+	for _, c := range candidates {
+		if normalizeName(strings.TrimRight(c, "0123456789")) == n {
+			return fmt.Sprintf("（%s の誤り？）", c)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,263 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// findDiagnostic はキーに一致する問題を探す
+func findDiagnostic(diags []Diagnostic, key string) (Diagnostic, bool) {
+	for _, d := range diags {
+		if d.Key == key {
+			return d, true
+		}
+	}
+	return Diagnostic{}, false
+}
+
+func TestCheck(t *testing.T) {
+	const valid = "[Redmine]\nBaseUrl=https://redmine.example.com\nApiKey=key\nFilterUrl=/issues.json\n"
+
+	tests := []struct {
+		name     string
+		content  string
+		opts     LoadOptions
+		key      string   // 問題のキー
+		severity Severity // 問題の重要度
+		line     int      // 行番号（0は設定ファイル以外）
+		contains string   // メッセージに含まれる文字列
+	}{
+		{
+			name:     "不正な正規表現はパターンの番号付きでエラー",
+			content:  valid + "[TitleCleaning]\nPattern1=^\\[.*?\\]\nPattern2=(abc\n",
+			key:      "Pattern2",
+			severity: SeverityError,
+			line:     7,
+			contains: "正規表現の構文エラー: (abc",
+		},
+		{
+			name:     "未対応の出力モード",
+			content:  valid + "[Output]\nMode=detail\n",
+			key:      "Mode",
+			severity: SeverityError,
+			line:     6,
+			contains: "未対応の値です: detail",
+		},
+		{
+			name:     "BaseUrlの形式",
+			content:  "[Redmine]\nBaseUrl=redmine.example.com\nApiKey=key\nFilterUrl=/issues.json\n",
+			key:      "BaseUrl",
+			severity: SeverityError,
+			line:     2,
+			contains: "URLの形式が不正です",
+		},
+		{
+			name:     "BaseUrlにAPIのパス",
+			content:  "[Redmine]\nBaseUrl=https://redmine.example.com/issues.json\nApiKey=key\nFilterUrl=/issues.json\n",
+			key:      "BaseUrl",
+			severity: SeverityError,
+			line:     2,
+			contains: "RedmineのURLのみ",
+		},
+		{
+			name:     "FilterUrlが/issues.jsonで始まらない",
+			content:  "[Redmine]\nBaseUrl=https://redmine.example.com\nApiKey=key\nFilterUrl=/projects/a/issues.json\n",
+			key:      "FilterUrl",
+			severity: SeverityError,
+			line:     4,
+			contains: "/issues.json で始まるパス",
+		},
+		{
+			name:     "タグ名の重複",
+			content:  valid + "[Output]\nTagNames=要約,進捗:3,要約\n",
+			key:      "TagNames",
+			severity: SeverityError,
+			line:     6,
+			contains: "タグ 要約 が重複しています",
+		},
+		{
+			name:     "整数の形式エラー",
+			content:  valid + "MaxRetries=three\n",
+			key:      "MaxRetries",
+			severity: SeverityError,
+			line:     5,
+			contains: "整数で指定してください: three",
+		},
+		{
+			name:     "未知のキーは候補付きで警告",
+			content:  valid + "[Output]\ntagnames=要約\n",
+			key:      "tagnames",
+			severity: SeverityWarning,
+			line:     6,
+			contains: "TagNames の誤り？",
+		},
+		{
+			name:     "番号が途切れた連番のキー",
+			content:  valid + "[TitleCleaning]\nPattern1=a\nPattern3=b\n",
+			key:      "Pattern3",
+			severity: SeverityWarning,
+			line:     7,
+			contains: "番号が連続していない",
+		},
+		{
+			name:     "プロファイルのセクションの行番号",
+			content:  valid + "[Output]\nMode=full\n\n[Output.teamA]\nMode=fulll\n",
+			opts:     LoadOptions{Profile: "teamA"},
+			key:      "Mode",
+			severity: SeverityError,
+			line:     9,
+			contains: "未対応の値です: fulll",
+		},
+		{
+			name:     "矛盾する指定（Offlineでキャッシュなし）",
+			content:  valid + "[State]\nOffline=true\n",
+			key:      "Offline",
+			severity: SeverityError,
+			line:     6,
+			contains: "キャッシュの場所が指定されていません",
+		},
+		{
+			name:     "推移の週数と開始週の同時指定",
+			content:  valid + "[Period]\nWeeks=8\nFromWeek=2025-01\n",
+			key:      "Weeks",
+			severity: SeverityError,
+			line:     6,
+			contains: "同時に指定できません",
+		},
+		{
+			name:     "推移なしの終了週",
+			content:  valid + "[Period]\nToWeek=2025-10\n",
+			key:      "ToWeek",
+			severity: SeverityWarning,
+			line:     6,
+			contains: "無視されます",
+		},
+		{
+			name:     "期間指定と週指定の同時指定",
+			content:  valid + "[Period]\nWeek=last\nRange=month:last\n",
+			key:      "Range",
+			severity: SeverityError,
+			line:     7,
+			contains: "Week（--week）と同時に指定できません",
+		},
+		{
+			name:     "年度の開始月",
+			content:  valid + "[Period]\nFiscalYearStart=13\nRange=Q1\n",
+			key:      "FiscalYearStart",
+			severity: SeverityError,
+			line:     6,
+			contains: "1〜12を指定してください",
+		},
+		{
+			name:     "コマンドラインの値は取得元を表示",
+			content:  valid,
+			opts:     LoadOptions{Overrides: map[string]string{"Period.Since": "auto"}},
+			key:      "Since",
+			severity: SeverityError,
+			contains: "[State] File",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfigFile(t, "check.config", tt.content)
+			cfg, err := ReadConfig(path, tt.opts)
+			if err != nil {
+				t.Fatalf("ReadConfig()でエラー: %v", err)
+			}
+
+			d, ok := findDiagnostic(cfg.Check(), tt.key)
+			if !ok {
+				t.Fatalf("キー %s の問題が見つからない: %v", tt.key, cfg.Check())
+			}
+			if d.Severity != tt.severity {
+				t.Errorf("Severity = %v; want %v", d.Severity, tt.severity)
+			}
+			if d.Line != tt.line {
+				t.Errorf("Line = %d; want %d (%s)", d.Line, tt.line, d)
+			}
+			if tt.line == 0 && d.Source != "コマンドライン" {
+				t.Errorf("Source = %q; want コマンドライン", d.Source)
+			}
+			if !strings.Contains(d.String(), tt.contains) {
+				t.Errorf("String() = %q; want %q を含む", d.String(), tt.contains)
+			}
+
+			// エラーは Validate でも返される
+			err = cfg.Validate()
+			if (err != nil) != (tt.severity == SeverityError) {
+				t.Errorf("Validate() = %v", err)
+			}
+		})
+	}
+}
+
+func TestCheck_Valid(t *testing.T) {
+	path := writeConfigFile(t, "valid.config", runConfig)
+	cfg, err := ReadConfig(path, LoadOptions{Profile: "teamA"})
+	if err != nil {
+		t.Fatalf("ReadConfig()でエラー: %v", err)
+	}
+	if diags := cfg.Check(); len(diags) > 0 {
+		t.Errorf("Check() = %v; want 問題なし", diags)
+	}
+}
+
+func TestCheck_StructuredLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		key     string
+		line    int
+	}{
+		{
+			name:    "YAMLの配列の要素",
+			file:    "check.yaml",
+			content: "Redmine:\n  BaseUrl: https://r.example.com\n  ApiKey: k\n  FilterUrl: /issues.json\nTitleCleaning:\n  Patterns:\n    - a\n    - '(b'\n",
+			key:     "Pattern2",
+			line:    8,
+		},
+		{
+			name:    "YAMLのプロファイル",
+			file:    "check.yaml",
+			content: "Redmine:\n  BaseUrl: https://r.example.com\n  ApiKey: k\n  FilterUrl: /issues.json\nProfiles:\n  teamA:\n    Output:\n      mode: fulll\n",
+			key:     "Mode",
+			line:    8,
+		},
+		{
+			name:    "TOMLのキー",
+			file:    "check.toml",
+			content: "[Redmine]\nBaseUrl = \"https://r.example.com\"\nApiKey = \"k\"\nFilterUrl = \"/issues.json\"\n\n[Profiles.teamA.Output]\nMode = \"fulll\"\n",
+			key:     "Mode",
+			line:    7,
+		},
+		{
+			name:    "TOMLの配列は配列のキーの行",
+			file:    "check.toml",
+			content: "[Redmine]\nBaseUrl = \"https://r.example.com\"\nApiKey = \"k\"\nFilterUrl = \"/issues.json\"\n\n[TitleCleaning]\nPatterns = [\"a\", \"(b\"]\n",
+			key:     "Pattern2",
+			line:    7,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfigFile(t, tt.file, tt.content)
+			cfg, err := ReadConfig(path, LoadOptions{Profile: "teamA"})
+			if err != nil {
+				cfg, err = ReadConfig(path, LoadOptions{})
+			}
+			if err != nil {
+				t.Fatalf("ReadConfig()でエラー: %v", err)
+			}
+			d, ok := findDiagnostic(cfg.Check(), tt.key)
+			if !ok {
+				t.Fatalf("キー %s の問題が見つからない: %v", tt.key, cfg.Check())
+			}
+			if d.Line != tt.line {
+				t.Errorf("Line = %d; want %d (%s)", d.Line, tt.line, d)
+			}
+		})
+	}
+}
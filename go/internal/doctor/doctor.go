@@ -0,0 +1,286 @@
+package doctor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tktomaru/redmine-exporter/internal/redmine"
+)
+
+// MaxClockSkew はRedmineサーバーとの時刻のずれの許容範囲
+// 超える場合は --week / --since の期間の境界でチケットの取りこぼしが起きる可能性がある
+const MaxClockSkew = 5 * time.Minute
+
+// Status はチェック結果
+type Status int
+
+const (
+	StatusPass Status = iota // 成功
+	StatusWarn               // 警告（実行はできるが確認が必要）
+	StatusFail               // 失敗
+	StatusSkip               // 前のチェックの失敗により未実施
+)
+
+// String はチェック結果の表示用ラベル
+func (s Status) String() string {
+	switch s {
+	case StatusPass:
+		return "OK"
+	case StatusWarn:
+		return "WARN"
+	case StatusFail:
+		return "FAIL"
+	}
+	return "SKIP"
+}
+
+// Check は1項目のチェック結果
+type Check struct {
+	Name    string // 項目名
+	Status  Status
+	Message string // 結果の詳細
+	Hint    string // 失敗・警告時の対処方法（空可）
+}
+
+// Report はチェック結果の一覧
+type Report struct {
+	Checks []Check
+}
+
+// Add はチェック結果を追加する
+func (r *Report) Add(name string, status Status, hint, format string, args ...interface{}) {
+	r.Checks = append(r.Checks, Check{Name: name, Status: status, Message: fmt.Sprintf(format, args...), Hint: hint})
+}
+
+// Count は指定した結果のチェックの件数を返す
+func (r *Report) Count(status Status) int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Failed は失敗したチェックがあるかどうかを返す
+func (r *Report) Failed() bool {
+	return r.Count(StatusFail) > 0
+}
+
+// Print はチェックリストと集計を表示する
+func (r *Report) Print(w io.Writer) {
+	for _, c := range r.Checks {
+		fmt.Fprintf(w, "[%-4s] %s: %s\n", c.Status, c.Name, c.Message)
+		if c.Hint != "" && (c.Status == StatusFail || c.Status == StatusWarn) {
+			fmt.Fprintf(w, "       → %s\n", c.Hint)
+		}
+	}
+	fmt.Fprintf(w, "\n成功 %d 件、警告 %d 件、失敗 %d 件、未実施 %d 件\n",
+		r.Count(StatusPass), r.Count(StatusWarn), r.Count(StatusFail), r.Count(StatusSkip))
+}
+
+// Options はチェックの対象
+type Options struct {
+	BaseURL   string                 // [Redmine] BaseUrl（表示用）
+	FilterURL string                 // [Redmine] FilterUrl
+	Filter    *redmine.FilterBuilder // 絞り込み条件（nilは条件なし）
+	Query     string                 // 保存済みクエリ（IDまたは名前、空は使用しない）
+	Location  *time.Location         // 週・期間の計算に使うタイムゾーン
+	Now       func() time.Time       // 現在時刻（テストで差し替え可能、nilはtime.Now）
+}
+
+// Run はRedmineへの接続・権限・時刻をチェックする
+// 接続・認証に失敗した場合、それ以降のチェックは未実施（StatusSkip）とする
+func Run(client *redmine.Client, opts Options) *Report {
+	now := opts.Now
+	if now == nil {
+		now = time.Now
+	}
+	r := &Report{}
+
+	// 1〜3. 接続・REST API・認証（/users/current.json）
+	localTime := now()
+	user, serverTime, err := client.FetchCurrentUser()
+	if !r.checkAccount(opts.BaseURL, user, err) {
+		for _, name := range []string{"FilterUrl", "コメント", "時刻"} {
+			r.Add(name, StatusSkip, "", "Redmineに接続・認証できないため確認できません")
+		}
+		return r
+	}
+
+	// 4. FilterUrl（保存済みクエリ・絞り込み条件を含む）に一致するチケット
+	issue := r.checkIssues(client, opts)
+
+	// 5. コメント（journals）の取得
+	if issue != nil {
+		r.checkJournals(client, issue.ID)
+	} else {
+		r.Add("コメント", StatusSkip, "", "一致するチケットがないため確認できません")
+	}
+
+	// 6. 時刻・タイムゾーン
+	r.checkClock(serverTime, localTime, opts.Location)
+	return r
+}
+
+// checkAccount は接続・REST API・認証の結果を追加し、認証まで成功したかどうかを返す
+func (r *Report) checkAccount(baseURL string, user *redmine.User, err error) bool {
+	if err == nil {
+		r.Add("接続", StatusPass, "", "%s に接続できました", baseURL)
+		r.Add("REST API", StatusPass, "", "有効")
+		admin := ""
+		if user.Admin {
+			admin = "、管理者"
+		}
+		r.Add("認証", StatusPass, "", "%s（ログインID: %s、ID: %d%s）", user.Name(), user.Login, user.ID, admin)
+		return true
+	}
+
+	if redmine.IsNetworkError(err) {
+		r.Add("接続", StatusFail, "[Redmine] BaseUrl のホスト名・ポート、プロキシ、ネットワークの接続を確認してください", "%s に接続できません: %v", baseURL, err)
+		r.Add("REST API", StatusSkip, "", "接続できないため確認できません")
+		r.Add("認証", StatusSkip, "", "接続できないため確認できません")
+		return false
+	}
+	r.Add("接続", StatusPass, "", "%s に接続できました", baseURL)
+
+	var httpErr *redmine.HTTPError
+	if !errors.As(err, &httpErr) {
+		// HTMLのログイン画面やプロキシのエラーページなど
+		r.Add("REST API", StatusFail, "[Redmine] BaseUrl がRedmineのURL（例: https://redmine.example.com）になっているか確認してください", "REST APIの応答ではありません: %v", err)
+		r.Add("認証", StatusSkip, "", "REST APIを確認できないため未実施")
+		return false
+	}
+
+	switch httpErr.StatusCode {
+	case http.StatusUnauthorized:
+		r.Add("REST API", StatusPass, "", "有効")
+		r.Add("認証", StatusFail, "個人設定の「APIアクセスキー」を確認し、[Redmine] ApiKey / ApiKeyFile または REDMINE_API_KEY に設定してください", "APIキーが正しくありません (HTTP 401)")
+	case http.StatusForbidden:
+		r.Add("REST API", StatusFail, "管理者に「管理 → 設定 → API → RESTによるWebサービスを有効にする」をオンにしてもらってください", "REST APIが無効か、ユーザーがロックされています (HTTP 403)")
+		r.Add("認証", StatusSkip, "", "REST APIを確認できないため未実施")
+	case http.StatusNotFound:
+		r.Add("REST API", StatusFail, "[Redmine] BaseUrl がRedmineのURL（例: https://redmine.example.com）になっているか確認してください", "/users/current.json が見つかりません (HTTP 404)")
+		r.Add("認証", StatusSkip, "", "REST APIを確認できないため未実施")
+	default:
+		r.Add("REST API", StatusFail, "", "予期しない応答です: HTTP %d", httpErr.StatusCode)
+		r.Add("認証", StatusSkip, "", "REST APIを確認できないため未実施")
+	}
+	return false
+}
+
+// checkIssues はFilterUrlに一致するチケットの件数をチェックし、先頭のチケットを返す
+func (r *Report) checkIssues(client *redmine.Client, opts Options) *redmine.Issue {
+	filter := redmine.NewFilterBuilder()
+	if opts.Filter != nil {
+		filter = opts.Filter.Clone()
+	}
+
+	if opts.Query != "" {
+		query, err := client.ResolveQuery(opts.Query)
+		if err != nil {
+			r.Add("保存済みクエリ", StatusFail, "[Filter] Query のIDまたは名前と、クエリの公開範囲を確認してください", "%v", err)
+		} else {
+			filter.SetQuery(query)
+			r.Add("保存済みクエリ", StatusPass, "", "%s", query)
+		}
+	}
+
+	filterURL := opts.FilterURL
+	if !filter.IsEmpty() {
+		merged, err := filter.Merge(filterURL)
+		if err != nil {
+			r.Add("FilterUrl", StatusFail, "", "%v", err)
+			return nil
+		}
+		filterURL = merged
+	}
+
+	total, issue, err := client.ProbeIssues(filterURL)
+	if err != nil {
+		var httpErr *redmine.HTTPError
+		hint := ""
+		if errors.As(err, &httpErr) {
+			switch httpErr.StatusCode {
+			case http.StatusForbidden:
+				hint = "プロジェクトのメンバーか、ロールに「チケットの閲覧」の権限があるか確認してください"
+			case http.StatusNotFound:
+				hint = "FilterUrl・[Filter] Project のプロジェクト識別子が正しいか確認してください"
+			case http.StatusUnprocessableEntity:
+				hint = "FilterUrl のパラメータ（ステータス・トラッカーなどのID）を確認してください"
+			}
+		}
+		r.Add("FilterUrl", StatusFail, hint, "%s の取得に失敗しました: %v", filterURL, err)
+		return nil
+	}
+	if total == 0 {
+		r.Add("FilterUrl", StatusWarn, "FilterUrl・絞り込み条件と、プロジェクトの閲覧権限を確認してください", "%s に一致するチケットがありません", filterURL)
+		return nil
+	}
+	r.Add("FilterUrl", StatusPass, "", "%s に一致するチケット: %d 件", filterURL, total)
+	return issue
+}
+
+// checkJournals はチケットのコメント（journals）を取得できるかチェックする
+func (r *Report) checkJournals(client *redmine.Client, issueID int) {
+	journals, included, err := client.FetchIssueJournals(issueID)
+	switch {
+	case err != nil:
+		r.Add("コメント", StatusFail, "チケットの閲覧権限を確認してください", "チケット #%d の取得に失敗しました: %v", issueID, err)
+	case !included:
+		r.Add("コメント", StatusFail, "Redmineのバージョンやリバースプロキシが include=journals を除去していないか確認してください", "チケット #%d の応答にコメント（journals）が含まれていません", issueID)
+	default:
+		notes := 0
+		for _, j := range journals {
+			if strings.TrimSpace(j.Notes) != "" {
+				notes++
+			}
+		}
+		if len(journals) == 0 {
+			r.Add("コメント", StatusPass, "", "チケット #%d のコメントを取得できました（履歴なし）", issueID)
+			return
+		}
+		r.Add("コメント", StatusPass, "", "チケット #%d の履歴 %d 件（コメント %d 件）を取得できました", issueID, len(journals), notes)
+	}
+}
+
+// checkClock はRedmineサーバーとの時刻のずれと、期間の計算に使うタイムゾーンをチェックする
+func (r *Report) checkClock(serverTime, localTime time.Time, loc *time.Location) {
+	if serverTime.IsZero() {
+		r.Add("時刻", StatusWarn, "", "Redmineの応答にDateヘッダーがないため確認できません")
+	} else {
+		// Dateヘッダーは秒単位のため1秒未満のずれは無視する
+		skew := localTime.Sub(serverTime).Truncate(time.Second)
+		direction := "進んで"
+		if skew < 0 {
+			skew, direction = -skew, "遅れて"
+		}
+		switch {
+		case skew <= time.Second:
+			r.Add("時刻", StatusPass, "", "Redmineサーバーと一致しています（%s）", serverTime.In(time.UTC).Format("2006-01-02 15:04:05 MST"))
+		case skew <= MaxClockSkew:
+			r.Add("時刻", StatusPass, "", "このPCの時計はRedmineサーバーより %v %sいます", skew, direction)
+		default:
+			r.Add("時刻", StatusFail, "PCの時刻を同期（NTP）してください。--week / --since の期間の境界でチケットを取りこぼす可能性があります",
+				"このPCの時計はRedmineサーバーより %v %sいます（許容範囲: %v）", skew, direction, MaxClockSkew)
+		}
+	}
+
+	if loc == nil {
+		return
+	}
+	_, reportOffset := localTime.In(loc).Zone()
+	_, localOffset := localTime.Zone()
+	if reportOffset != localOffset {
+		r.Add("タイムゾーン", StatusWarn, "週の区切りはこのPCのタイムゾーンではなく "+loc.String()+" で計算されます",
+			"このPCのタイムゾーン（%s）は週・期間の計算に使うタイムゾーン（%s %s）と異なります",
+			localTime.Format("MST -07:00"), loc, localTime.In(loc).Format("-07:00"))
+		return
+	}
+	r.Add("タイムゾーン", StatusPass, "", "%s（%s）", loc, localTime.In(loc).Format("-07:00"))
+}
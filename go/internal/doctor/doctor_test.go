@@ -0,0 +1,254 @@
+package doctor
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tktomaru/redmine-exporter/internal/redmine"
+)
+
+// fakeRedmine はテスト用のRedmineの設定
+type fakeRedmine struct {
+	userStatus int       // /users/current.json のステータス（0は200）
+	html       bool      // /users/current.json がHTMLを返す（REST APIではない）
+	issues     int       // /issues.json に一致するチケット数
+	project    string    // 存在するプロジェクト（指定時は他のproject_idに404）
+	noJournals bool      // /issues/<id>.json がjournalsを含まない
+	date       time.Time // Dateヘッダー（ゼロ値はtestNow）
+}
+
+// testNow はテストの現在時刻
+var testNow = time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+
+// newServer はfakeRedmineの設定で応答するhttptestサーバーを作成
+func (f fakeRedmine) newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	date := f.date
+	if date.IsZero() {
+		date = testNow
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", date.Format(http.TimeFormat))
+		if r.Header.Get("X-Redmine-API-Key") != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case r.URL.Path == "/users/current.json":
+			if f.userStatus != 0 {
+				w.WriteHeader(f.userStatus)
+				return
+			}
+			if f.html {
+				w.Header().Set("Content-Type", "text/html")
+				w.Write([]byte("<html><body>ログイン</body></html>"))
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"user": map[string]interface{}{"id": 5, "login": "tanaka", "firstname": "太郎", "lastname": "田中"},
+			})
+
+		case r.URL.Path == "/issues.json":
+			if p := r.URL.Query().Get("project_id"); f.project != "" && p != f.project {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			issues := []map[string]interface{}{}
+			if f.issues > 0 {
+				issues = append(issues, map[string]interface{}{"id": 1, "subject": "チケット1"})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"issues": issues, "total_count": f.issues})
+
+		case r.URL.Path == "/issues/1.json":
+			issue := map[string]interface{}{"id": 1, "subject": "チケット1"}
+			if !f.noJournals && r.URL.Query().Get("include") == "journals" {
+				issue["journals"] = []map[string]interface{}{
+					{"id": 10, "notes": "コメント"},
+					{"id": 11, "notes": "", "details": []map[string]interface{}{{"property": "attr", "name": "status_id"}}},
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"issue": issue})
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// statuses はチェック名ごとの結果
+func statuses(r *Report) map[string]Status {
+	m := make(map[string]Status)
+	for _, c := range r.Checks {
+		m[c.Name] = c.Status
+	}
+	return m
+}
+
+func TestRun(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("タイムゾーンの読み込みに失敗: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		fake     fakeRedmine
+		apiKey   string
+		filter   *redmine.FilterBuilder
+		location *time.Location
+		want     map[string]Status
+		contains string // 出力に含まれるべき文字列
+	}{
+		{
+			name:     "すべて成功",
+			fake:     fakeRedmine{issues: 42},
+			location: time.UTC,
+			want: map[string]Status{
+				"接続": StatusPass, "REST API": StatusPass, "認証": StatusPass,
+				"FilterUrl": StatusPass, "コメント": StatusPass, "時刻": StatusPass, "タイムゾーン": StatusPass,
+			},
+			contains: "一致するチケット: 42 件",
+		},
+		{
+			name: "APIキーの誤り",
+			fake: fakeRedmine{issues: 1}, apiKey: "wrong",
+			want: map[string]Status{
+				"接続": StatusPass, "REST API": StatusPass, "認証": StatusFail,
+				"FilterUrl": StatusSkip, "コメント": StatusSkip, "時刻": StatusSkip,
+			},
+			contains: "APIキーが正しくありません",
+		},
+		{
+			name: "REST APIが無効",
+			fake: fakeRedmine{userStatus: http.StatusForbidden},
+			want: map[string]Status{
+				"接続": StatusPass, "REST API": StatusFail, "認証": StatusSkip, "FilterUrl": StatusSkip,
+			},
+			contains: "RESTによるWebサービスを有効にする",
+		},
+		{
+			name: "JSON以外の応答",
+			fake: fakeRedmine{html: true},
+			want: map[string]Status{
+				"接続": StatusPass, "REST API": StatusFail, "認証": StatusSkip,
+			},
+			contains: "REST APIの応答ではありません",
+		},
+		{
+			name: "一致するチケットなし",
+			fake: fakeRedmine{},
+			want: map[string]Status{
+				"認証": StatusPass, "FilterUrl": StatusWarn, "コメント": StatusSkip, "時刻": StatusPass,
+			},
+			contains: "一致するチケットがありません",
+		},
+		{
+			name: "存在しないプロジェクト",
+			fake: fakeRedmine{issues: 3, project: "alpha"},
+			filter: func() *redmine.FilterBuilder {
+				fb := redmine.NewFilterBuilder()
+				fb.SetProject("beta", true)
+				return fb
+			}(),
+			want: map[string]Status{
+				"FilterUrl": StatusFail, "コメント": StatusSkip,
+			},
+			contains: "プロジェクト識別子が正しいか",
+		},
+		{
+			name: "journalsが含まれない",
+			fake: fakeRedmine{issues: 3, noJournals: true},
+			want: map[string]Status{
+				"FilterUrl": StatusPass, "コメント": StatusFail,
+			},
+			contains: "コメント（journals）が含まれていません",
+		},
+		{
+			name: "時計のずれ",
+			fake: fakeRedmine{issues: 1, date: testNow.Add(-10 * time.Minute)},
+			want: map[string]Status{
+				"コメント": StatusPass, "時刻": StatusFail,
+			},
+			contains: "Redmineサーバーより 10m0s 進んでいます",
+		},
+		{
+			name:     "期間の計算と異なるタイムゾーン",
+			fake:     fakeRedmine{issues: 1},
+			location: tokyo,
+			want: map[string]Status{
+				"時刻": StatusPass, "タイムゾーン": StatusWarn,
+			},
+			contains: "Asia/Tokyo +09:00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := tt.fake.newServer(t)
+			apiKey := tt.apiKey
+			if apiKey == "" {
+				apiKey = "test-key"
+			}
+			client := redmine.NewClient(server.URL, apiKey)
+			client.SetRetryPolicy(redmine.RetryPolicy{})
+
+			report := Run(client, Options{
+				BaseURL:   server.URL,
+				FilterURL: "/issues.json?status_id=*",
+				Filter:    tt.filter,
+				Location:  tt.location,
+				Now:       func() time.Time { return testNow },
+			})
+
+			got := statuses(report)
+			for name, want := range tt.want {
+				if got[name] != want {
+					t.Errorf("%s = %v; want %v", name, got[name], want)
+				}
+			}
+			var b bytes.Buffer
+			report.Print(&b)
+			if !strings.Contains(b.String(), tt.contains) {
+				t.Errorf("出力に %q が含まれていない:\n%s", tt.contains, b.String())
+			}
+			wantFailed := false
+			for _, s := range tt.want {
+				if s == StatusFail {
+					wantFailed = true
+				}
+			}
+			if report.Failed() != wantFailed {
+				t.Errorf("Failed() = %v; want %v", report.Failed(), wantFailed)
+			}
+		})
+	}
+}
+
+func TestRun_ConnectionError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close() // 接続できないURL
+
+	client := redmine.NewClient(url, "test-key")
+	client.SetRetryPolicy(redmine.RetryPolicy{})
+	report := Run(client, Options{BaseURL: url, FilterURL: "/issues.json"})
+
+	got := statuses(report)
+	if got["接続"] != StatusFail {
+		t.Errorf("接続 = %v; want FAIL", got["接続"])
+	}
+	for _, name := range []string{"REST API", "認証", "FilterUrl", "コメント", "時刻"} {
+		if got[name] != StatusSkip {
+			t.Errorf("%s = %v; want SKIP", name, got[name])
+		}
+	}
+}
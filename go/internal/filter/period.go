@@ -5,6 +5,18 @@ import (
 	"time"
 )
 
+// TimeZone は週・期間の計算と、レポートに出力する日時のタイムゾーン
+const TimeZone = "Asia/Tokyo"
+
+// Location はTimeZoneのタイムゾーンを返す（読み込めない場合はローカルタイムゾーン）
+func Location() *time.Location {
+	loc, err := time.LoadLocation(TimeZone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
 // WeekCalculator は週の期間を計算する
 type WeekCalculator struct {
 	weekStart time.Weekday   // 週の起点（Sunday〜Saturday）
@@ -127,3 +139,58 @@ func (wc *WeekCalculator) parseWeekSpec(spec string) (start, end time.Time, err
 
 	return start, end, nil
 }
+
+// maxTrendWeeks は推移（GetWeekRanges）で指定できる最大の週数
+const maxTrendWeeks = 104
+
+// WeekRange は1週間の期間
+type WeekRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// GetWeekRanges はfromの週からtoの週までの各週の期間を古い順に返す
+// from・toはGetWeekRangeと同じ形式（last, this, YYYY-WW）
+func (wc *WeekCalculator) GetWeekRanges(from, to string) ([]WeekRange, error) {
+	first, _, err := wc.GetWeekRange(from)
+	if err != nil {
+		return nil, err
+	}
+	last, _, err := wc.GetWeekRange(to)
+	if err != nil {
+		return nil, err
+	}
+	if first.After(last) {
+		return nil, fmt.Errorf("開始週 (%s) が終了週 (%s) より後です", from, to)
+	}
+
+	var ranges []WeekRange
+	for start := first; !start.After(last); start = start.AddDate(0, 0, 7) {
+		if len(ranges) == maxTrendWeeks {
+			return nil, fmt.Errorf("週数が多すぎます: %s 〜 %s (最大%d週)", from, to, maxTrendWeeks)
+		}
+		end := start.AddDate(0, 0, 6)
+		end = time.Date(end.Year(), end.Month(), end.Day(), 23, 59, 59, 0, wc.location)
+		ranges = append(ranges, WeekRange{Start: start, End: end})
+	}
+	return ranges, nil
+}
+
+// GetLastWeeks はtoの週までのn週間の各週の期間を古い順に返す
+func (wc *WeekCalculator) GetLastWeeks(to string, n int) ([]WeekRange, error) {
+	if n < 1 || n > maxTrendWeeks {
+		return nil, fmt.Errorf("週数は1〜%dで指定してください: %d", maxTrendWeeks, n)
+	}
+	last, _, err := wc.GetWeekRange(to)
+	if err != nil {
+		return nil, err
+	}
+	ranges := make([]WeekRange, 0, n)
+	for i := n - 1; i >= 0; i-- {
+		start := last.AddDate(0, 0, -7*i)
+		end := start.AddDate(0, 0, 6)
+		end = time.Date(end.Year(), end.Month(), end.Day(), 23, 59, 59, 0, wc.location)
+		ranges = append(ranges, WeekRange{Start: start, End: end})
+	}
+	return ranges, nil
+}
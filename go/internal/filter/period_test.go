@@ -332,3 +332,60 @@ func TestGetWeekRange_Integration(t *testing.T) {
 		})
 	}
 }
+
+func TestGetWeekRanges(t *testing.T) {
+	wc, err := NewWeekCalculator("mon", "Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("NewWeekCalculator() failed: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		from, to  string
+		weeks     int // 0の場合はGetWeekRanges(from, to)、それ以外はGetLastWeeks(to, weeks)
+		wantCount int
+		wantFirst string // 最初の週の開始日
+		wantErr   bool
+	}{
+		{name: "from〜to", from: "2025-02", to: "2025-05", wantCount: 4, wantFirst: "2025-01-06"},
+		{name: "同じ週", from: "2025-02", to: "2025-02", wantCount: 1, wantFirst: "2025-01-06"},
+		{name: "年をまたぐ", from: "2024-52", to: "2025-02", wantCount: 3, wantFirst: "2024-12-23"},
+		{name: "開始週が後", from: "2025-05", to: "2025-02", wantErr: true},
+		{name: "不正な週", from: "invalid", to: "2025-02", wantErr: true},
+		{name: "週数が多すぎる", from: "2020-01", to: "2025-02", wantErr: true},
+		{name: "直近n週", to: "2025-05", weeks: 3, wantCount: 3, wantFirst: "2025-01-13"},
+		{name: "週数が0", to: "2025-05", weeks: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ranges []WeekRange
+			var err error
+			if tt.weeks != 0 {
+				ranges, err = wc.GetLastWeeks(tt.to, tt.weeks)
+			} else {
+				ranges, err = wc.GetWeekRanges(tt.from, tt.to)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(ranges) != tt.wantCount {
+				t.Fatalf("週数 = %d, want %d", len(ranges), tt.wantCount)
+			}
+			if got := ranges[0].Start.Format("2006-01-02"); got != tt.wantFirst {
+				t.Errorf("最初の週の開始日 = %s, want %s", got, tt.wantFirst)
+			}
+			for i, r := range ranges {
+				if r.End.Sub(r.Start) != 7*24*time.Hour-time.Second {
+					t.Errorf("ranges[%d] = %v 〜 %v; 7日間ではない", i, r.Start, r.End)
+				}
+				if i > 0 && !r.Start.Equal(ranges[i-1].End.Add(time.Second)) {
+					t.Errorf("ranges[%d] の開始 %v が前の週の終了 %v と連続していない", i, r.Start, ranges[i-1].End)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SprintResolver はバージョン（IDまたは名前）からスプリントの期間を返す
+type SprintResolver func(version string) (start, end time.Time, err error)
+
+// PeriodCalculator は週・月・四半期・半期・年度・相対期間・スプリントの期間を計算する
+type PeriodCalculator struct {
+	weeks           *WeekCalculator
+	fiscalYearStart time.Month       // 年度の開始月
+	sprint          SprintResolver   // sprint: の期間の取得（未設定の場合は sprint: を使用できない）
+	now             func() time.Time // 現在時刻（テスト用に差し替え可能）
+}
+
+// 期間指定の形式
+var (
+	monthSpecPattern    = regexp.MustCompile(`^(\d{4})-(\d{1,2})$`)
+	quarterSpecPattern  = regexp.MustCompile(`^(?:FY(\d{4})-)?Q([1-4])$`)
+	halfSpecPattern     = regexp.MustCompile(`^(?:FY(\d{4})-)?H([12])$`)
+	fyearSpecPattern    = regexp.MustCompile(`^FY(\d{4})$`)
+	relativeSpecPattern = regexp.MustCompile(`^(\d+)([dwm])$`)
+)
+
+// NewPeriodCalculator は新しいPeriodCalculatorを作成
+// fiscalYearStartは年度の開始月（1〜12、4なら4月始まり）
+func NewPeriodCalculator(weekStart string, timezone string, fiscalYearStart int) (*PeriodCalculator, error) {
+	wc, err := NewWeekCalculator(weekStart, timezone)
+	if err != nil {
+		return nil, err
+	}
+	if fiscalYearStart < 1 || fiscalYearStart > 12 {
+		return nil, fmt.Errorf("不正な年度の開始月: %d (1〜12)", fiscalYearStart)
+	}
+
+	return &PeriodCalculator{
+		weeks:           wc,
+		fiscalYearStart: time.Month(fiscalYearStart),
+		now:             time.Now,
+	}, nil
+}
+
+// SetSprintResolver は sprint:<バージョン> の期間の取得方法を設定
+func (pc *PeriodCalculator) SetSprintResolver(resolver SprintResolver) {
+	pc.sprint = resolver
+}
+
+// Location は期間の計算に使うタイムゾーンを返す
+func (pc *PeriodCalculator) Location() *time.Location {
+	return pc.weeks.location
+}
+
+// GetRange は期間指定から期間を計算
+// spec:
+//
+//	week:last, week:this, week:YYYY-WW         週
+//	month:last, month:this, YYYY-MM            月
+//	quarter:last, quarter:this, Q3, FY2025-Q3  四半期（年度の開始月が起点）
+//	H1, H2, FY2025-H1                          半期
+//	fy:last, fy:this, FY2025                   年度（FY2025は2025年の開始月から）
+//	last:14d, last:2w, last:3m                 今日までの日数・週数・月数
+//	sprint:<バージョン>                        Redmineのバージョンの期間
+func (pc *PeriodCalculator) GetRange(spec string) (start, end time.Time, err error) {
+	spec = strings.TrimSpace(spec)
+	now := pc.now().In(pc.weeks.location)
+
+	kind, value, hasKind := strings.Cut(spec, ":")
+	if hasKind {
+		switch strings.ToLower(kind) {
+		case "week":
+			switch value {
+			case "last":
+				return pc.weeks.getLastWeek(now)
+			case "this":
+				return pc.weeks.getThisWeek(now)
+			}
+			return pc.weeks.parseWeekSpec(value)
+		case "month":
+			switch value {
+			case "last":
+				return pc.months(time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, pc.weeks.location), 1)
+			case "this":
+				return pc.months(time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, pc.weeks.location), 1)
+			}
+			return pc.parseMonth(value, spec)
+		case "quarter":
+			switch value {
+			case "last":
+				start, _, _ := pc.thisQuarter(now)
+				return pc.months(start.AddDate(0, -3, 0), 3)
+			case "this":
+				return pc.thisQuarter(now)
+			}
+		case "fy":
+			switch value {
+			case "last":
+				return pc.fiscalYear(pc.fiscalYearOf(now) - 1)
+			case "this":
+				return pc.fiscalYear(pc.fiscalYearOf(now))
+			}
+		case "last":
+			return pc.relative(now, value, spec)
+		case "sprint":
+			if strings.TrimSpace(value) == "" {
+				return time.Time{}, time.Time{}, fmt.Errorf("バージョンが指定されていません: %s (sprint:<バージョンのIDまたは名前>)", spec)
+			}
+			if pc.sprint == nil {
+				return time.Time{}, time.Time{}, fmt.Errorf("sprint: の期間を取得できません: %s", spec)
+			}
+			return pc.sprint(strings.TrimSpace(value))
+		}
+		return time.Time{}, time.Time{}, fmt.Errorf("期間の指定が不正です: %s", spec)
+	}
+
+	upper := strings.ToUpper(spec)
+	if monthSpecPattern.MatchString(spec) {
+		return pc.parseMonth(spec, spec)
+	}
+	if m := quarterSpecPattern.FindStringSubmatch(upper); m != nil {
+		year := pc.fiscalYearOf(now)
+		if m[1] != "" {
+			year, _ = strconv.Atoi(m[1])
+		}
+		quarter, _ := strconv.Atoi(m[2])
+		return pc.months(pc.fiscalYearStartOf(year).AddDate(0, 3*(quarter-1), 0), 3)
+	}
+	if m := halfSpecPattern.FindStringSubmatch(upper); m != nil {
+		year := pc.fiscalYearOf(now)
+		if m[1] != "" {
+			year, _ = strconv.Atoi(m[1])
+		}
+		half, _ := strconv.Atoi(m[2])
+		return pc.months(pc.fiscalYearStartOf(year).AddDate(0, 6*(half-1), 0), 6)
+	}
+	if m := fyearSpecPattern.FindStringSubmatch(upper); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		return pc.fiscalYear(year)
+	}
+
+	return time.Time{}, time.Time{}, fmt.Errorf("期間の指定が不正です: %s (month:last, YYYY-MM, Q3, FY2025-H1, last:14d, sprint:<バージョン> など)", spec)
+}
+
+// parseMonth は月（YYYY-MM形式）の期間を計算
+func (pc *PeriodCalculator) parseMonth(value, spec string) (start, end time.Time, err error) {
+	m := monthSpecPattern.FindStringSubmatch(value)
+	if m == nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("月の形式エラー: %s (YYYY-MM形式で指定)", spec)
+	}
+	year, _ := strconv.Atoi(m[1])
+	month, _ := strconv.Atoi(m[2])
+	if month < 1 || month > 12 {
+		return time.Time{}, time.Time{}, fmt.Errorf("月の形式エラー: %s (月は01〜12)", spec)
+	}
+	return pc.months(time.Date(year, time.Month(month), 1, 0, 0, 0, 0, pc.weeks.location), 1)
+}
+
+// thisQuarter は現在の四半期（年度の開始月が起点）の期間を返す
+func (pc *PeriodCalculator) thisQuarter(now time.Time) (start, end time.Time, err error) {
+	elapsed := (int(now.Month()) - int(pc.fiscalYearStart) + 12) % 12
+	start = pc.fiscalYearStartOf(pc.fiscalYearOf(now)).AddDate(0, elapsed/3*3, 0)
+	return pc.months(start, 3)
+}
+
+// fiscalYear は年度の期間を返す
+func (pc *PeriodCalculator) fiscalYear(year int) (start, end time.Time, err error) {
+	return pc.months(pc.fiscalYearStartOf(year), 12)
+}
+
+// fiscalYearOf は日時が属する年度（開始月の年）を返す
+func (pc *PeriodCalculator) fiscalYearOf(t time.Time) int {
+	if t.Month() < pc.fiscalYearStart {
+		return t.Year() - 1
+	}
+	return t.Year()
+}
+
+// fiscalYearStartOf は年度の開始日を返す
+func (pc *PeriodCalculator) fiscalYearStartOf(year int) time.Time {
+	return time.Date(year, pc.fiscalYearStart, 1, 0, 0, 0, 0, pc.weeks.location)
+}
+
+// months はstartからnか月間の期間を返す（終了は最終日の23:59:59）
+func (pc *PeriodCalculator) months(start time.Time, n int) (time.Time, time.Time, error) {
+	last := start.AddDate(0, n, -1)
+	end := time.Date(last.Year(), last.Month(), last.Day(), 23, 59, 59, 0, pc.weeks.location)
+	return start, end, nil
+}
+
+// relative は今日までの日数・週数・月数（14d, 2w, 3m）の期間を計算
+func (pc *PeriodCalculator) relative(now time.Time, value, spec string) (start, end time.Time, err error) {
+	m := relativeSpecPattern.FindStringSubmatch(value)
+	if m == nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("相対期間の形式エラー: %s (last:14d, last:2w, last:3m)", spec)
+	}
+	n, _ := strconv.Atoi(m[1])
+	if n < 1 {
+		return time.Time{}, time.Time{}, fmt.Errorf("相対期間は1以上を指定してください: %s", spec)
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, pc.weeks.location)
+	switch m[2] {
+	case "d":
+		start = today.AddDate(0, 0, -(n - 1))
+	case "w":
+		start = today.AddDate(0, 0, -(7*n - 1))
+	case "m":
+		start = today.AddDate(0, -n, 1)
+	}
+	end = time.Date(today.Year(), today.Month(), today.Day(), 23, 59, 59, 0, pc.weeks.location)
+	return start, end, nil
+}
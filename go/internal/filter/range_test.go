@@ -0,0 +1,126 @@
+package filter
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPeriodCalculator_GetRange(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("LoadLocation() failed: %v", err)
+	}
+	// 2025/11/20（木）
+	defaultNow := time.Date(2025, 11, 20, 15, 0, 0, 0, loc)
+
+	tests := []struct {
+		name            string
+		spec            string
+		fiscalYearStart int       // 0の場合は4（4月始まり）
+		now             time.Time // ゼロ値の場合は2025/11/20
+		wantStart       string
+		wantEnd         string
+		wantErr         bool
+	}{
+		{name: "先月", spec: "month:last", wantStart: "2025-10-01", wantEnd: "2025-10-31"},
+		{name: "今月", spec: "month:this", wantStart: "2025-11-01", wantEnd: "2025-11-30"},
+		{name: "年をまたぐ先月", spec: "month:last", now: time.Date(2026, 1, 5, 0, 0, 0, 0, loc), wantStart: "2025-12-01", wantEnd: "2025-12-31"},
+		{name: "YYYY-MM", spec: "2024-02", wantStart: "2024-02-01", wantEnd: "2024-02-29"},
+		{name: "month:YYYY-MM", spec: "month:2025-10", wantStart: "2025-10-01", wantEnd: "2025-10-31"},
+		{name: "不正な月", spec: "2025-13", wantErr: true},
+		{name: "今四半期", spec: "quarter:this", wantStart: "2025-10-01", wantEnd: "2025-12-31"},
+		{name: "前四半期", spec: "quarter:last", wantStart: "2025-07-01", wantEnd: "2025-09-30"},
+		{name: "前四半期（年度をまたぐ）", spec: "quarter:last", now: time.Date(2026, 4, 10, 0, 0, 0, 0, loc), wantStart: "2026-01-01", wantEnd: "2026-03-31"},
+		{name: "今年度のQ3", spec: "Q3", wantStart: "2025-10-01", wantEnd: "2025-12-31"},
+		{name: "今年度のQ4（翌年）", spec: "q4", wantStart: "2026-01-01", wantEnd: "2026-03-31"},
+		{name: "年度指定のQ1", spec: "FY2024-Q1", wantStart: "2024-04-01", wantEnd: "2024-06-30"},
+		{name: "暦年のQ1", spec: "Q1", fiscalYearStart: 1, wantStart: "2025-01-01", wantEnd: "2025-03-31"},
+		{name: "上期", spec: "H1", wantStart: "2025-04-01", wantEnd: "2025-09-30"},
+		{name: "年度指定の下期", spec: "FY2025-H2", wantStart: "2025-10-01", wantEnd: "2026-03-31"},
+		{name: "年度", spec: "FY2024", wantStart: "2024-04-01", wantEnd: "2025-03-31"},
+		{name: "今年度", spec: "fy:this", wantStart: "2025-04-01", wantEnd: "2026-03-31"},
+		{name: "今年度（1月）", spec: "fy:this", now: time.Date(2026, 1, 15, 0, 0, 0, 0, loc), wantStart: "2025-04-01", wantEnd: "2026-03-31"},
+		{name: "前年度", spec: "fy:last", wantStart: "2024-04-01", wantEnd: "2025-03-31"},
+		{name: "直近14日", spec: "last:14d", wantStart: "2025-11-07", wantEnd: "2025-11-20"},
+		{name: "直近2週間", spec: "last:2w", wantStart: "2025-11-07", wantEnd: "2025-11-20"},
+		{name: "直近1か月", spec: "last:1m", wantStart: "2025-10-21", wantEnd: "2025-11-20"},
+		{name: "相対期間が0", spec: "last:0d", wantErr: true},
+		{name: "不正な相対期間", spec: "last:3y", wantErr: true},
+		{name: "先週", spec: "week:last", wantStart: "2025-11-10", wantEnd: "2025-11-16"},
+		{name: "週番号", spec: "week:2025-02", wantStart: "2025-01-06", wantEnd: "2025-01-12"},
+		{name: "スプリント", spec: "sprint:Sprint 12", wantStart: "2025-11-03", wantEnd: "2025-11-14"},
+		{name: "スプリントのバージョンなし", spec: "sprint:", wantErr: true},
+		{name: "スプリントの取得エラー", spec: "sprint:unknown", wantErr: true},
+		{name: "不明な種類", spec: "year:this", wantErr: true},
+		{name: "不正な指定", spec: "invalid", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fiscalYearStart := tt.fiscalYearStart
+			if fiscalYearStart == 0 {
+				fiscalYearStart = 4
+			}
+			pc, err := NewPeriodCalculator("mon", "Asia/Tokyo", fiscalYearStart)
+			if err != nil {
+				t.Fatalf("NewPeriodCalculator() failed: %v", err)
+			}
+			now := tt.now
+			if now.IsZero() {
+				now = defaultNow
+			}
+			pc.now = func() time.Time { return now }
+			pc.SetSprintResolver(func(version string) (time.Time, time.Time, error) {
+				if version != "Sprint 12" {
+					return time.Time{}, time.Time{}, errors.New("バージョンが見つかりません")
+				}
+				return time.Date(2025, 11, 3, 0, 0, 0, 0, loc), time.Date(2025, 11, 14, 23, 59, 59, 0, loc), nil
+			})
+
+			start, end, err := pc.GetRange(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetRange(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := start.Format("2006-01-02 15:04:05"); got != tt.wantStart+" 00:00:00" {
+				t.Errorf("start = %s, want %s 00:00:00", got, tt.wantStart)
+			}
+			if got := end.Format("2006-01-02 15:04:05"); got != tt.wantEnd+" 23:59:59" {
+				t.Errorf("end = %s, want %s 23:59:59", got, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestNewPeriodCalculator(t *testing.T) {
+	tests := []struct {
+		name            string
+		fiscalYearStart int
+		wantErr         bool
+	}{
+		{name: "4月始まり", fiscalYearStart: 4},
+		{name: "1月始まり", fiscalYearStart: 1},
+		{name: "0月", fiscalYearStart: 0, wantErr: true},
+		{name: "13月", fiscalYearStart: 13, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPeriodCalculator("mon", "Asia/Tokyo", tt.fiscalYearStart)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewPeriodCalculator() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	pc, err := NewPeriodCalculator("mon", "Asia/Tokyo", 4)
+	if err != nil {
+		t.Fatalf("NewPeriodCalculator() failed: %v", err)
+	}
+	if _, _, err := pc.GetRange("sprint:Sprint 1"); err == nil {
+		t.Error("SetSprintResolver() 前の sprint: でエラーにならない")
+	}
+}
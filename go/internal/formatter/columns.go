@@ -0,0 +1,219 @@
+package formatter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tktomaru/redmine-exporter/internal/processor"
+	"github.com/tktomaru/redmine-exporter/internal/redmine"
+)
+
+// ColumnsSetter は出力列を指定できる表形式のフォーマッター（オプション）
+type ColumnsSetter interface {
+	SetColumns(spec []string) error
+}
+
+// Column は表形式出力（Excel/CSV/TSV）の1列
+type Column struct {
+	Key    string // 列の指定名（status, cf:顧客, tag:進捗 など）
+	Header string // ヘッダー行に表示する名前
+	value  func(r tableRow) interface{}
+}
+
+// Value は行に対応するセルの値を返す
+// 日付列は *redmine.Date / *redmine.DateTime を返す（Excelでは日付セル、CSVでは文字列にする）
+func (c Column) Value(r tableRow) interface{} {
+	return c.value(r)
+}
+
+// tableRow は表形式出力の1行分（親タスク名＋チケット）
+type tableRow struct {
+	ParentSubject string
+	Issue         *redmine.Issue
+	Standalone    bool // 子を持たないルートチケット（タスク名列は空欄）
+}
+
+// tableRows はチケットツリーを表形式の行に展開
+// 子チケットは親タスク名付きで1行ずつ、子を持たないチケットは親タスクとして1行出力する
+func tableRows(roots []*redmine.Issue) []tableRow {
+	var rows []tableRow
+	for _, parent := range roots {
+		if len(parent.Children) > 0 {
+			for _, child := range parent.Children {
+				rows = append(rows, tableRow{ParentSubject: parent.CleanedSubject, Issue: child})
+			}
+		} else {
+			rows = append(rows, tableRow{ParentSubject: parent.CleanedSubject, Issue: parent, Standalone: true})
+		}
+	}
+	return rows
+}
+
+// fieldColumns は指定可能な標準列（キー → 列定義）
+var fieldColumns = map[string]Column{
+	"parent": {Header: "親タスク", value: func(r tableRow) interface{} { return r.ParentSubject }},
+	"subject": {Header: "タスク名", value: func(r tableRow) interface{} {
+		if r.Standalone {
+			return ""
+		}
+		return r.Issue.CleanedSubject
+	}},
+	"id":          {Header: "ID", value: func(r tableRow) interface{} { return r.Issue.ID }},
+	"project":     {Header: "プロジェクト", value: func(r tableRow) interface{} { return r.Issue.Project.Name }},
+	"tracker":     {Header: "トラッカー", value: func(r tableRow) interface{} { return r.Issue.Tracker.Name }},
+	"status":      {Header: "ステータス", value: func(r tableRow) interface{} { return r.Issue.Status.Name }},
+	"priority":    {Header: "優先度", value: func(r tableRow) interface{} { return r.Issue.Priority.Name }},
+	"start_date":  {Header: "開始日", value: func(r tableRow) interface{} { return r.Issue.StartDate }},
+	"due_date":    {Header: "終了日", value: func(r tableRow) interface{} { return r.Issue.DueDate }},
+	"assignee":    {Header: "担当者", value: func(r tableRow) interface{} { return processor.GetAssignee(r.Issue) }},
+	"description": {Header: "説明", value: func(r tableRow) interface{} { return r.Issue.Description }},
+	"comments":    {Header: "コメント数", value: func(r tableRow) interface{} { return len(r.Issue.Journals) }},
+	"summary":     {Header: "要約", value: func(r tableRow) interface{} { return r.Issue.Summary }},
+	"created_on":  {Header: "作成日時", value: func(r tableRow) interface{} { return r.Issue.CreatedOn }},
+	"updated_on":  {Header: "更新日時", value: func(r tableRow) interface{} { return r.Issue.UpdatedOn }},
+	"spent_hours": {Header: "作業時間", value: func(r tableRow) interface{} { return r.Issue.SpentHours() }},
+}
+
+// ColumnKeys は指定可能な標準列のキー一覧（ヘルプ表示用）
+var ColumnKeys = []string{
+	"parent", "subject", "id", "project", "tracker", "status", "priority",
+	"start_date", "due_date", "assignee", "description", "comments", "summary",
+	"created_on", "updated_on", "spent_hours",
+}
+
+// ParseColumns は列の指定（キーのリスト）を列定義に変換
+// 標準列のキーに加えて、cf:<カスタムフィールド名> と tag:<タグ名> を指定できる
+func ParseColumns(spec []string) ([]Column, error) {
+	columns := make([]Column, 0, len(spec))
+	for _, key := range spec {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+
+		col, err := parseColumn(key)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, col)
+	}
+
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("出力列が指定されていません")
+	}
+	return columns, nil
+}
+
+// parseColumn は1列分の指定を列定義に変換
+func parseColumn(key string) (Column, error) {
+	switch {
+	case strings.HasPrefix(key, "cf:"):
+		name := strings.TrimPrefix(key, "cf:")
+		if name == "" {
+			return Column{}, fmt.Errorf("カスタムフィールド名が指定されていません: %s", key)
+		}
+		return customFieldColumn(name), nil
+
+	case strings.HasPrefix(key, "tag:"):
+		name := strings.TrimPrefix(key, "tag:")
+		if name == "" {
+			return Column{}, fmt.Errorf("タグ名が指定されていません: %s", key)
+		}
+		return tagColumn(name), nil
+	}
+
+	col, ok := fieldColumns[key]
+	if !ok {
+		return Column{}, fmt.Errorf("未対応の列: %s (%s, cf:<名前>, tag:<名前> のみ対応)", key, strings.Join(ColumnKeys, ", "))
+	}
+	col.Key = key
+	return col, nil
+}
+
+// customFieldColumn はカスタムフィールドの列
+func customFieldColumn(name string) Column {
+	return Column{
+		Key:    "cf:" + name,
+		Header: name,
+		value:  func(r tableRow) interface{} { return r.Issue.CustomFieldValue(name) },
+	}
+}
+
+// tagColumn は抽出タグの列（複数ある場合は番号付きリストで改行区切り）
+func tagColumn(name string) Column {
+	return Column{
+		Key:    "tag:" + name,
+		Header: name,
+		value: func(r tableRow) interface{} {
+			contents := r.Issue.ExtractedTags[name]
+			switch len(contents) {
+			case 0:
+				return ""
+			case 1:
+				return contents[0]
+			}
+			lines := make([]string, 0, len(contents))
+			for i, content := range contents {
+				lines = append(lines, fmt.Sprintf("%d. %s", i+1, content))
+			}
+			return strings.Join(lines, "\n")
+		},
+	}
+}
+
+// defaultColumnKeys はモードごとの標準の列構成
+func defaultColumnKeys(mode string, tagNames, customFields []string) []string {
+	switch mode {
+	case "full":
+		// フルモード：すべてのフィールドを含む（カスタムフィールドは末尾に追加）
+		keys := []string{"parent", "subject", "id", "project", "tracker", "status", "priority", "start_date", "due_date", "assignee", "description", "comments", "summary"}
+		for _, name := range customFields {
+			keys = append(keys, "cf:"+name)
+		}
+		return keys
+
+	case "tags":
+		// タグモード：指定されたタグごとに列を追加
+		keys := []string{"parent", "subject", "status", "start_date", "due_date", "assignee"}
+		for _, name := range tagNames {
+			keys = append(keys, "tag:"+name)
+		}
+		return keys
+
+	default:
+		// summaryモード：デフォルトの列構成
+		return []string{"parent", "subject", "status", "start_date", "due_date", "assignee", "summary"}
+	}
+}
+
+// resolveColumns は列の指定があればそれを、なければモードに応じた標準の列構成を返す
+func resolveColumns(spec []string, mode string, tagNames []string, roots []*redmine.Issue) ([]Column, error) {
+	if len(spec) > 0 {
+		return ParseColumns(spec)
+	}
+
+	var customFields []string
+	if mode == "full" {
+		customFields = collectCustomFieldNames(roots)
+	}
+	return ParseColumns(defaultColumnKeys(mode, tagNames, customFields))
+}
+
+// cellString はセルの値を文字列に変換（CSV/TSV用）
+func cellString(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case *redmine.Date:
+		return formatDate(val)
+	case *redmine.DateTime:
+		return formatDateTime(val)
+	case int:
+		return strconv.Itoa(val)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		return fmt.Sprint(val)
+	}
+}
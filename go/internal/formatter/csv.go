@@ -0,0 +1,135 @@
+package formatter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tktomaru/redmine-exporter/internal/redmine"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/transform"
+)
+
+// CSV/TSVの文字コード
+const (
+	EncodingUTF8     = "utf-8"     // UTF-8（BOMなし、デフォルト）
+	EncodingUTF8BOM  = "utf-8-bom" // UTF-8（BOM付き、Excelで文字化けしない）
+	EncodingShiftJIS = "sjis"      // Shift_JIS（レガシーツール向け）
+)
+
+// utf8BOM はUTF-8のバイトオーダーマーク
+const utf8BOM = "\xEF\xBB\xBF"
+
+// CSVFormatter はCSV形式（.csv）またはTSV形式（.tsv）で出力
+// 列構成はExcelFormatterと共通
+type CSVFormatter struct {
+	delimiter  rune // ',' または '\t'
+	mode       string
+	tagNames   []string
+	columnSpec []string
+	encoding   string
+}
+
+// Format はCSV/TSV形式で出力
+func (f *CSVFormatter) Format(roots []*redmine.Issue, w io.Writer) error {
+	columns, err := resolveColumns(f.columnSpec, f.mode, f.tagNames, roots)
+	if err != nil {
+		return err
+	}
+
+	out, closeOut, err := encodedWriter(w, f.encoding)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(out)
+	if f.delimiter != 0 {
+		cw.Comma = f.delimiter
+	}
+
+	// ヘッダー行
+	record := make([]string, len(columns))
+	for i, col := range columns {
+		record[i] = col.Header
+	}
+	if err := cw.Write(record); err != nil {
+		return fmt.Errorf("CSV出力エラー: %w", err)
+	}
+
+	// データ行
+	for _, r := range tableRows(roots) {
+		for i, col := range columns {
+			record[i] = cellString(col.Value(r))
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("CSV出力エラー: %w", err)
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("CSV出力エラー: %w", err)
+	}
+	return closeOut()
+}
+
+// SetMode はモードとタグ名を設定
+func (f *CSVFormatter) SetMode(mode string, tagNames []string) {
+	f.mode = mode
+	f.tagNames = tagNames
+}
+
+// SetColumns は出力列を設定
+func (f *CSVFormatter) SetColumns(spec []string) error {
+	if _, err := ParseColumns(spec); err != nil {
+		return err
+	}
+	f.columnSpec = spec
+	return nil
+}
+
+// SetEncoding は文字コードを設定（utf-8, utf-8-bom, sjis）
+func (f *CSVFormatter) SetEncoding(name string) error {
+	enc, err := ParseEncoding(name)
+	if err != nil {
+		return err
+	}
+	f.encoding = enc
+	return nil
+}
+
+// ParseEncoding は文字コード名を正規化する（空の場合はUTF-8）
+func ParseEncoding(name string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "utf-8", "utf8":
+		return EncodingUTF8, nil
+	case "utf-8-bom", "utf8-bom", "utf-8bom", "utf8bom":
+		return EncodingUTF8BOM, nil
+	case "sjis", "shift_jis", "shift-jis", "cp932", "windows-31j":
+		return EncodingShiftJIS, nil
+	}
+	return "", fmt.Errorf("未対応の文字コード: %s (utf-8, utf-8-bom, sjis のみ対応)", name)
+}
+
+// encodedWriter は文字コードに応じたWriterと、書き込み完了時に呼ぶ関数を返す
+// Shift_JISで表現できない文字は「?」に置き換える
+func encodedWriter(w io.Writer, name string) (io.Writer, func() error, error) {
+	enc, err := ParseEncoding(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch enc {
+	case EncodingUTF8BOM:
+		if _, err := io.WriteString(w, utf8BOM); err != nil {
+			return nil, nil, fmt.Errorf("CSV出力エラー: %w", err)
+		}
+	case EncodingShiftJIS:
+		tw := transform.NewWriter(w, encoding.ReplaceUnsupported(japanese.ShiftJIS.NewEncoder()))
+		return tw, tw.Close, nil
+	}
+
+	return w, func() error { return nil }, nil
+}
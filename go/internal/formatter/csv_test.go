@@ -0,0 +1,195 @@
+package formatter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tktomaru/redmine-exporter/internal/redmine"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestCSVFormatter_Modes(t *testing.T) {
+	tests := []struct {
+		name      string
+		delimiter rune
+		mode      string
+		tagNames  []string
+		columns   []string
+		want      string
+	}{
+		{
+			name:      "summaryモード",
+			delimiter: ',',
+			mode:      "summary",
+			want:      "親タスク,タスク名,ステータス,開始日,終了日,担当者,要約\n親タスクA,タスクB,進行中,2026/01/02,2025/12/31,佐藤,ひとこと整形で変更しました\n",
+		},
+		{
+			name:      "tagsモード（複数値は番号付きで改行）",
+			delimiter: ',',
+			mode:      "tags",
+			tagNames:  []string{"進捗"},
+			want:      "親タスク,タスク名,ステータス,開始日,終了日,担当者,進捗\n親タスクA,タスクB,進行中,2026/01/02,2025/12/31,佐藤,\"1. 設計完了\n2. 実装中\"\n",
+		},
+		{
+			name:      "列指定（カスタムフィールド・タグ）",
+			delimiter: ',',
+			mode:      "summary",
+			columns:   []string{"id", "subject", "cf:顧客", "tag:進捗"},
+			want:      "ID,タスク名,顧客,進捗\n2,タスクB,\"A社, B社\",\"1. 設計完了\n2. 実装中\"\n",
+		},
+		{
+			name:      "TSV",
+			delimiter: '\t',
+			mode:      "summary",
+			columns:   []string{"id", "status", "assignee"},
+			want:      "ID\tステータス\t担当者\n2\t進行中\t佐藤\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roots := createTestData()
+			child := roots[0].Children[0]
+			child.ExtractedTags = map[string][]string{"進捗": {"設計完了", "実装中"}}
+			child.CustomFields = []redmine.CustomField{{ID: 1, Name: "顧客", Multiple: true, Values: []string{"A社", "B社"}}}
+
+			formatter := &CSVFormatter{delimiter: tt.delimiter}
+			formatter.SetMode(tt.mode, tt.tagNames)
+			if tt.columns != nil {
+				if err := formatter.SetColumns(tt.columns); err != nil {
+					t.Fatalf("SetColumns()でエラー: %v", err)
+				}
+			}
+
+			var buf bytes.Buffer
+			if err := formatter.Format(roots, &buf); err != nil {
+				t.Fatalf("Format()でエラー: %v", err)
+			}
+
+			if buf.String() != tt.want {
+				t.Errorf("出力 =\n%q\nwant\n%q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVFormatter_Encoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding string
+		check    func(t *testing.T, out []byte)
+	}{
+		{
+			name:     "UTF-8（BOMなし）",
+			encoding: "utf-8",
+			check: func(t *testing.T, out []byte) {
+				if bytes.HasPrefix(out, []byte(utf8BOM)) {
+					t.Error("BOMが付いている")
+				}
+				if !strings.Contains(string(out), "佐藤") {
+					t.Errorf("UTF-8で出力されていない: %q", out)
+				}
+			},
+		},
+		{
+			name:     "UTF-8（BOM付き）",
+			encoding: "utf-8-bom",
+			check: func(t *testing.T, out []byte) {
+				if !bytes.HasPrefix(out, []byte(utf8BOM)) {
+					t.Error("BOMが付いていない")
+				}
+			},
+		},
+		{
+			name:     "Shift_JIS",
+			encoding: "Shift_JIS",
+			check: func(t *testing.T, out []byte) {
+				decoded, err := japanese.ShiftJIS.NewDecoder().Bytes(out)
+				if err != nil {
+					t.Fatalf("Shift_JISとしてデコードできない: %v", err)
+				}
+				if !strings.Contains(string(decoded), "ID,担当者\n2,佐藤\n") {
+					t.Errorf("デコード結果 = %q", decoded)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatter := &CSVFormatter{delimiter: ','}
+			formatter.SetMode("summary", nil)
+			formatter.SetColumns([]string{"id", "assignee"})
+			if err := formatter.SetEncoding(tt.encoding); err != nil {
+				t.Fatalf("SetEncoding()でエラー: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := formatter.Format(createTestData(), &buf); err != nil {
+				t.Fatalf("Format()でエラー: %v", err)
+			}
+			tt.check(t, buf.Bytes())
+		})
+	}
+}
+
+func TestParseColumns(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        []string
+		wantHeaders []string
+		wantErr     bool
+	}{
+		{name: "標準列", spec: []string{"id", " status ", "spent_hours"}, wantHeaders: []string{"ID", "ステータス", "作業時間"}},
+		{name: "カスタムフィールドとタグ", spec: []string{"cf:顧客", "tag:進捗"}, wantHeaders: []string{"顧客", "進捗"}},
+		{name: "未対応の列", spec: []string{"id", "unknown"}, wantErr: true},
+		{name: "名前なしのcf", spec: []string{"cf:"}, wantErr: true},
+		{name: "空", spec: []string{"", " "}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns, err := ParseColumns(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseColumns() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(columns) != len(tt.wantHeaders) {
+				t.Fatalf("len(columns) = %d; want %d", len(columns), len(tt.wantHeaders))
+			}
+			for i, want := range tt.wantHeaders {
+				if columns[i].Header != want {
+					t.Errorf("columns[%d].Header = %q; want %q", i, columns[i].Header, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseEncoding(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "", want: EncodingUTF8},
+		{name: "UTF8", want: EncodingUTF8},
+		{name: "utf-8-bom", want: EncodingUTF8BOM},
+		{name: "cp932", want: EncodingShiftJIS},
+		{name: "euc-jp", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseEncoding(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseEncoding(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseEncoding(%q) = %q; want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -4,17 +4,26 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
-	"github.com/tktomaru/redmine-exporter/internal/processor"
 	"github.com/tktomaru/redmine-exporter/internal/redmine"
+	"github.com/tktomaru/redmine-exporter/internal/stats"
 	"github.com/xuri/excelize/v2"
 )
 
 // ExcelFormatter はExcel形式で出力（VBA版と同じテーブル形式）
 type ExcelFormatter struct {
-	filename string
-	mode     string
-	tagNames []string
+	filename   string
+	mode       string
+	tagNames   []string
+	columnSpec []string // 出力列の指定（空の場合はモードに応じた標準の列構成）
+	columns    []Column // Format時に決定した出力列
+	baseURL    string   // ID列のハイパーリンク先（空の場合はリンクなし）
+	stats      *stats.WeeklyStats
+	weekStart  time.Time
+	weekEnd    time.Time
+	trend      []stats.WeekTrend     // 週ごとの推移（推移シート、未設定の場合は出力しない）
+	statuses   *redmine.Enumerations // 完了の判定に使うステータスの一覧（未設定の場合はステータス名で判定）
 }
 
 // Format はExcel形式で出力
@@ -23,29 +32,33 @@ func (f *ExcelFormatter) Format(roots []*redmine.Issue, w io.Writer) error {
 	file := excelize.NewFile()
 	defer file.Close()
 
-	sheetName := "Sheet1"
+	// 1枚目はチケット一覧（既定のSheet1を改名）
+	sheetName := issueSheetName
+	file.SetSheetName("Sheet1", sheetName)
+
+	// 出力列を決定（fullモードではカスタムフィールドも列として出力）
+	columns, err := resolveColumns(f.columnSpec, f.mode, f.tagNames, roots)
+	if err != nil {
+		return err
+	}
+	f.columns = columns
 
 	// ヘッダー行（モードに応じて列構成を変更）
 	headers := f.buildHeaders()
+	widths := newColumnWidths(len(headers))
 	for i, header := range headers {
 		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
 		file.SetCellValue(sheetName, cell, header)
+		widths.observe(i, header)
 	}
 
-	// データ行
+	// データ行（子チケットは親子形式、子を持たないチケットも親タスクとして出力）
+	styles := newExcelStyles(file)
+	today := truncateToDay(localTime(time.Now()))
 	currentRow := 2
-	for _, parent := range roots {
-		if len(parent.Children) > 0 {
-			// 子チケットがある場合は親子形式で出力
-			for _, child := range parent.Children {
-				f.writeIssueRow(file, sheetName, currentRow, parent.CleanedSubject, child, false)
-				currentRow++
-			}
-		} else {
-			// スタンドアロンチケット（子を持たない）も親タスクとして出力
-			f.writeIssueRow(file, sheetName, currentRow, parent.CleanedSubject, parent, true)
-			currentRow++
-		}
+	for _, r := range tableRows(roots) {
+		f.writeIssueRow(file, sheetName, currentRow, r, styles, widths, today)
+		currentRow++
 	}
 
 	// テーブル化（VBA版と同等）
@@ -62,12 +75,6 @@ func (f *ExcelFormatter) Format(roots []*redmine.Issue, w io.Writer) error {
 			ShowRowStripes: &showStripes,
 		})
 
-		// 列幅の自動調整（VBA版のAutoFitに相当）
-		for i := 1; i <= numCols; i++ {
-			col, _ := excelize.ColumnNumberToName(i)
-			file.SetColWidth(sheetName, col, col, 15)
-		}
-
 		// ヘッダー行を太字に
 		style, _ := file.NewStyle(&excelize.Style{
 			Font: &excelize.Font{Bold: true},
@@ -75,6 +82,22 @@ func (f *ExcelFormatter) Format(roots []*redmine.Issue, w io.Writer) error {
 		file.SetCellStyle(sheetName, "A1", fmt.Sprintf("%s1", lastCol), style)
 	}
 
+	// 列幅を内容に合わせて調整（VBA版のAutoFitに相当）
+	widths.apply(file, sheetName)
+
+	// ヘッダー行を固定（スクロールしても列名が見えるように）
+	file.SetPanes(sheetName, &excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	})
+
+	// 統計・期限・コメント・工数の各シートを追加
+	if err := f.writeReportSheets(file, roots); err != nil {
+		return err
+	}
+
 	// ファイルに書き込み（WriterToを使用）
 	return file.Write(w)
 }
@@ -85,105 +108,85 @@ func (f *ExcelFormatter) SetMode(mode string, tagNames []string) {
 	f.tagNames = tagNames
 }
 
-// buildHeaders はモードに応じたヘッダー行を構築
-func (f *ExcelFormatter) buildHeaders() []string {
-	switch f.mode {
-	case "full":
-		// フルモード：すべてのフィールドを含む
-		return []string{"親タスク", "タスク名", "ID", "プロジェクト", "トラッカー", "ステータス", "優先度", "開始日", "終了日", "担当者", "説明", "コメント数", "要約"}
-
-	case "tags":
-		// タグモード：指定されたタグごとに列を追加
-		headers := []string{"親タスク", "タスク名", "ステータス", "開始日", "終了日", "担当者"}
-		for _, tagName := range f.tagNames {
-			headers = append(headers, tagName)
-		}
-		return headers
-
-	default:
-		// summaryモード：デフォルトの列構成
-		return []string{"親タスク", "タスク名", "ステータス", "開始日", "終了日", "担当者", "要約"}
+// SetColumns は出力列を設定（列名はFormat時ではなくここで検証する）
+func (f *ExcelFormatter) SetColumns(spec []string) error {
+	if _, err := ParseColumns(spec); err != nil {
+		return err
 	}
+	f.columnSpec = spec
+	return nil
+}
+
+// SetStats は統計シートに出力する統計情報を設定
+// 未設定の場合はFormat時に過去7日間を期間として計算する
+func (f *ExcelFormatter) SetStats(stats *stats.WeeklyStats, weekStart, weekEnd time.Time) {
+	f.stats = stats
+	f.weekStart = weekStart
+	f.weekEnd = weekEnd
 }
 
-// writeIssueRow はモードに応じてチケットの行を書き込む
-func (f *ExcelFormatter) writeIssueRow(file *excelize.File, sheetName string, row int, parentSubject string, issue *redmine.Issue, isStandalone bool) {
-	assignee := processor.GetAssignee(issue)
-	startDate := formatDate(issue.StartDate)
-	dueDate := formatDate(issue.DueDate)
-
-	col := 1
-	setCellValue := func(value interface{}) {
-		cell, _ := excelize.CoordinatesToCellName(col, row)
-		file.SetCellValue(sheetName, cell, value)
-		col++
+// SetTrend は推移シートに出力する週ごとの推移を設定
+func (f *ExcelFormatter) SetTrend(trend []stats.WeekTrend) {
+	f.trend = trend
+}
+
+// SetStatuses は完了の判定（行の色分け、統計）に使うステータスの一覧を設定
+func (f *ExcelFormatter) SetStatuses(statuses *redmine.Enumerations) {
+	f.statuses = statuses
+}
+
+// buildHeaders は出力列のヘッダー行を構築
+func (f *ExcelFormatter) buildHeaders() []string {
+	headers := make([]string, 0, len(f.columns))
+	for _, col := range f.columns {
+		headers = append(headers, col.Header)
 	}
+	return headers
+}
 
-	switch f.mode {
-	case "full":
-		// フルモード：すべてのフィールドを出力
-		setCellValue(parentSubject)
-		if isStandalone {
-			setCellValue("")
-		} else {
-			setCellValue(issue.CleanedSubject)
-		}
-		setCellValue(issue.ID)
-		setCellValue(issue.Project.Name)
-		setCellValue(issue.Tracker.Name)
-		setCellValue(issue.Status.Name)
-		setCellValue(issue.Priority.Name)
-		setCellValue(startDate)
-		setCellValue(dueDate)
-		setCellValue(assignee)
-		setCellValue(issue.Description)
-		setCellValue(len(issue.Journals))
-		setCellValue(issue.Summary)
-
-	case "tags":
-		// タグモード：指定されたタグの内容を出力
-		setCellValue(parentSubject)
-		if isStandalone {
-			setCellValue("")
-		} else {
-			setCellValue(issue.CleanedSubject)
-		}
-		setCellValue(issue.Status.Name)
-		setCellValue(startDate)
-		setCellValue(dueDate)
-		setCellValue(assignee)
-
-		// 各タグの内容を出力
-		for _, tagName := range f.tagNames {
-			if contents, ok := issue.ExtractedTags[tagName]; ok && len(contents) > 0 {
-				if len(contents) == 1 {
-					// 1つだけの場合はそのまま
-					setCellValue(contents[0])
-				} else {
-					// 複数ある場合は番号付きリストで結合
-					var lines []string
-					for i, content := range contents {
-						lines = append(lines, fmt.Sprintf("%d. %s", i+1, content))
-					}
-					setCellValue(strings.Join(lines, "\n"))
-				}
-			} else {
-				setCellValue("")
+// SetBaseURL はID列のハイパーリンクに使用するRedmineのURLを設定
+func (f *ExcelFormatter) SetBaseURL(baseURL string) {
+	f.baseURL = strings.TrimRight(baseURL, "/")
+}
+
+// writeIssueRow は出力列に従ってチケットの行を書き込む
+// 日付は日付セル、IDはRedmineへのハイパーリンク、期限切れ・完了の行は色分けして出力する
+func (f *ExcelFormatter) writeIssueRow(file *excelize.File, sheetName string, row int, r tableRow, styles *excelStyles, widths *columnWidths, today time.Time) {
+	kind := issueRowKind(r.Issue, today, f.statuses)
+
+	for i, col := range f.columns {
+		cell, _ := excelize.CoordinatesToCellName(i+1, row)
+		key := excelStyleKey{kind: kind}
+
+		switch v := col.Value(r).(type) {
+		case *redmine.Date:
+			key.numFmt = excelDateFormat
+			if v != nil && !v.IsZero() {
+				file.SetCellValue(sheetName, cell, v.Time)
 			}
+			widths.observe(i, "2006/01/02")
+		case *redmine.DateTime:
+			key.numFmt = excelDateTimeFormat
+			if v != nil && !v.IsZero() {
+				file.SetCellValue(sheetName, cell, localTime(v.Time))
+			}
+			widths.observe(i, "2006/01/02 15:04")
+		case string:
+			file.SetCellValue(sheetName, cell, v)
+			key.wrap = strings.Contains(v, "\n")
+			widths.observe(i, v)
+		default:
+			file.SetCellValue(sheetName, cell, v)
+			widths.observe(i, cellString(v))
+		}
+
+		if col.Key == "id" && f.baseURL != "" {
+			file.SetCellHyperLink(sheetName, cell, fmt.Sprintf("%s/issues/%d", f.baseURL, r.Issue.ID), "External")
+			key.link = true
 		}
 
-	default:
-		// summaryモード：デフォルトの列構成
-		setCellValue(parentSubject)
-		if isStandalone {
-			setCellValue("")
-		} else {
-			setCellValue(issue.CleanedSubject)
+		if key != (excelStyleKey{}) {
+			file.SetCellStyle(sheetName, cell, cell, styles.get(key))
 		}
-		setCellValue(issue.Status.Name)
-		setCellValue(startDate)
-		setCellValue(dueDate)
-		setCellValue(assignee)
-		setCellValue(issue.Summary)
 	}
 }
@@ -0,0 +1,445 @@
+package formatter
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/tktomaru/redmine-exporter/internal/processor"
+	"github.com/tktomaru/redmine-exporter/internal/redmine"
+	"github.com/tktomaru/redmine-exporter/internal/stats"
+	"github.com/xuri/excelize/v2"
+)
+
+// Excelブックのシート名
+const (
+	issueSheetName   = "チケット"
+	statsSheetName   = "統計"
+	dueSheetName     = "期限"
+	commentSheetName = "コメント"
+	timeSheetName    = "工数"
+	historySheetName = "履歴"
+	trendSheetName   = "推移"
+)
+
+// chartRows はグラフ1つ分の高さ（行数）。内訳表が短くてもグラフが重ならないように確保する
+const chartRows = 16
+
+// sheetWriter はシートに上から1行ずつ書き込むヘルパー
+type sheetWriter struct {
+	file      *excelize.File
+	sheet     string
+	row       int
+	boldStyle int
+}
+
+// newSheetWriter はシートを作成して書き込み用のヘルパーを返す
+func newSheetWriter(file *excelize.File, sheet string) (*sheetWriter, error) {
+	if _, err := file.NewSheet(sheet); err != nil {
+		return nil, fmt.Errorf("%sシート作成エラー: %w", sheet, err)
+	}
+	boldStyle, _ := file.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+	})
+	return &sheetWriter{file: file, sheet: sheet, row: 1, boldStyle: boldStyle}, nil
+}
+
+// writeRow は現在行に値を書き込んで次の行に進む
+func (w *sheetWriter) writeRow(values ...interface{}) {
+	for i, v := range values {
+		cell, _ := excelize.CoordinatesToCellName(i+1, w.row)
+		w.file.SetCellValue(w.sheet, cell, v)
+	}
+	w.row++
+}
+
+// writeHeader は見出し行（太字）を書き込んで次の行に進む
+func (w *sheetWriter) writeHeader(values ...interface{}) {
+	w.writeRow(values...)
+	lastCell, _ := excelize.CoordinatesToCellName(len(values), w.row-1)
+	w.file.SetCellStyle(w.sheet, fmt.Sprintf("A%d", w.row-1), lastCell, w.boldStyle)
+}
+
+// skip は空行を入れる
+func (w *sheetWriter) skip() {
+	w.row++
+}
+
+// rangeRef はシート名付きのセル範囲参照（グラフのデータ指定用）を返す
+func (w *sheetWriter) rangeRef(col string, fromRow, toRow int) string {
+	return fmt.Sprintf("'%s'!$%s$%d:$%s$%d", w.sheet, col, fromRow, col, toRow)
+}
+
+// writeReportSheets は統計・推移・期限・コメント・履歴・工数の各シートを追加
+func (f *ExcelFormatter) writeReportSheets(file *excelize.File, roots []*redmine.Issue) error {
+	// 統計が設定されていない場合は過去7日間を期間として計算
+	weeklyStats := f.stats
+	weekStart, weekEnd := f.weekStart, f.weekEnd
+	if weeklyStats == nil {
+		if weekEnd.IsZero() {
+			weekEnd = time.Now()
+		}
+		if weekStart.IsZero() {
+			weekStart = weekEnd.AddDate(0, 0, -7)
+		}
+		weeklyStats = stats.CalculateWithOptions(roots, weekStart, weekEnd, stats.Options{Statuses: f.statuses})
+	}
+
+	if err := writeStatsSheet(file, weeklyStats, weekStart, weekEnd); err != nil {
+		return err
+	}
+	// 週ごとの推移（--weeks / --from-week）がある場合は推移シートを追加
+	if len(f.trend) > 0 {
+		if err := writeTrendSheet(file, f.trend); err != nil {
+			return err
+		}
+	}
+	if err := writeDueSheet(file, weeklyStats, localTime(time.Now())); err != nil {
+		return err
+	}
+	if err := writeCommentSheet(file, roots); err != nil {
+		return err
+	}
+
+	// 変更履歴（--history）がある場合は履歴シートを追加
+	for _, issue := range flattenIssueTree(roots) {
+		if len(issue.History) > 0 {
+			if err := writeHistorySheet(file, roots); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	// 作業時間が紐付いている場合は工数シートを追加
+	ts := weeklyStats.Time
+	if ts == nil {
+		ts = stats.CalculateTime(roots)
+	}
+	if ts.EntryCount > 0 {
+		if err := writeTimeSheet(file, roots, ts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeStatsSheet は集計値とステータス・担当者・トラッカー・優先度別の内訳をグラフ付きで出力
+// --transitions 指定時は期間内に完了・再オープン・着手したチケットの一覧も出力
+func writeStatsSheet(file *excelize.File, weeklyStats *stats.WeeklyStats, weekStart, weekEnd time.Time) error {
+	w, err := newSheetWriter(file, statsSheetName)
+	if err != nil {
+		return err
+	}
+
+	w.writeHeader(fmt.Sprintf("週報統計（%s 〜 %s）", weekStart.Format("2006/01/02"), weekEnd.Format("2006/01/02")))
+	w.skip()
+
+	// 集計値
+	w.writeHeader("項目", "件数")
+	w.writeRow("総チケット数", weeklyStats.TotalIssues)
+	w.writeRow("新規作成", weeklyStats.NewIssues)
+	w.writeRow("更新", weeklyStats.UpdatedIssues)
+	w.writeRow("完了", weeklyStats.ClosedIssues)
+	if weeklyStats.Transitions {
+		w.writeRow("再オープン", len(weeklyStats.ReopenedInPeriod))
+		w.writeRow("着手", len(weeklyStats.StartedInPeriod))
+	}
+	w.writeRow("期限切れ", len(weeklyStats.OverdueTasks))
+	w.writeRow("期限間近（7日以内）", len(weeklyStats.DueSoonTasks))
+	w.writeRow("コメント数", weeklyStats.CommentStats.TotalComments)
+	if weeklyStats.Time != nil {
+		w.writeRow("作業時間（h）", weeklyStats.Time.TotalHours)
+	}
+	w.skip()
+
+	// 内訳（表の右にグラフを配置）
+	breakdowns := []struct {
+		title     string
+		counts    map[string]int
+		chartType excelize.ChartType
+	}{
+		{title: "ステータス別", counts: weeklyStats.ByStatus, chartType: excelize.Pie},
+		{title: "担当者別", counts: weeklyStats.ByAssignee, chartType: excelize.Bar},
+		{title: "トラッカー別", counts: weeklyStats.ByTracker, chartType: excelize.Col},
+		{title: "優先度別", counts: weeklyStats.ByPriority, chartType: excelize.Col},
+	}
+
+	for _, b := range breakdowns {
+		headerRow := w.row
+		w.writeHeader(b.title, "件数")
+		counts := sortedCounts(b.counts)
+		for _, c := range counts {
+			w.writeRow(c.Name, c.Count)
+		}
+
+		if len(counts) > 0 {
+			chart := &excelize.Chart{
+				Type: b.chartType,
+				Series: []excelize.ChartSeries{{
+					Name:       fmt.Sprintf("'%s'!$B$%d", w.sheet, headerRow),
+					Categories: w.rangeRef("A", headerRow+1, w.row-1),
+					Values:     w.rangeRef("B", headerRow+1, w.row-1),
+				}},
+				Title:     []excelize.RichTextRun{{Text: b.title}},
+				Legend:    excelize.ChartLegend{Position: "none"},
+				PlotArea:  excelize.ChartPlotArea{ShowVal: true},
+				Dimension: excelize.ChartDimension{Width: 480, Height: 290},
+			}
+			if b.chartType == excelize.Pie {
+				chart.Legend.Position = "right"
+				chart.PlotArea = excelize.ChartPlotArea{ShowPercent: true}
+			}
+			if err := file.AddChart(w.sheet, fmt.Sprintf("D%d", headerRow), chart); err != nil {
+				return fmt.Errorf("%sグラフ作成エラー: %w", b.title, err)
+			}
+		}
+
+		// 次の内訳がグラフと重ならないように行を確保
+		if w.row < headerRow+chartRows {
+			w.row = headerRow + chartRows
+		} else {
+			w.skip()
+		}
+	}
+
+	// 期間内のステータス変更（--transitions 指定時）
+	if weeklyStats.Transitions {
+		w.writeHeader("期間内のステータス変更", "ID", "チケット", "担当者", "ステータス")
+		groups := []struct {
+			label  string
+			issues []*redmine.Issue
+		}{
+			{label: "完了", issues: weeklyStats.ClosedInPeriod},
+			{label: "再オープン", issues: weeklyStats.ReopenedInPeriod},
+			{label: "着手", issues: weeklyStats.StartedInPeriod},
+		}
+		for _, g := range groups {
+			for _, issue := range g.issues {
+				w.writeRow(g.label, issue.ID, issue.CleanedSubject, processor.GetAssignee(issue), issue.Status.Name)
+			}
+		}
+	}
+
+	file.SetColWidth(w.sheet, "A", "A", 24)
+	file.SetColWidth(w.sheet, "B", "B", 10)
+
+	return nil
+}
+
+// writeTrendSheet は週ごとの作成・完了・未完了・期限切れの件数を折れ線グラフ付きで出力
+func writeTrendSheet(file *excelize.File, trend []stats.WeekTrend) error {
+	w, err := newSheetWriter(file, trendSheetName)
+	if err != nil {
+		return err
+	}
+
+	numFmt := excelDateFormat
+	dateStyle, _ := file.NewStyle(&excelize.Style{CustomNumFmt: &numFmt})
+	w.writeHeader("週", "開始日", "作成", "完了", "未完了（週末時点）", "期限切れ（週末時点）")
+	for _, week := range trend {
+		w.writeRow(week.Label(), truncateToDay(week.Start), week.Created, week.Closed, week.Open, week.Overdue)
+		startCell := fmt.Sprintf("B%d", w.row-1)
+		file.SetCellStyle(w.sheet, startCell, startCell, dateStyle)
+	}
+
+	var series []excelize.ChartSeries
+	for _, col := range []string{"C", "D", "E", "F"} {
+		series = append(series, excelize.ChartSeries{
+			Name:       fmt.Sprintf("'%s'!$%s$1", w.sheet, col),
+			Categories: w.rangeRef("A", 2, w.row-1),
+			Values:     w.rangeRef(col, 2, w.row-1),
+			Marker:     excelize.ChartMarker{Symbol: "circle", Size: 5},
+		})
+	}
+	chart := &excelize.Chart{
+		Type:      excelize.Line,
+		Series:    series,
+		Title:     []excelize.RichTextRun{{Text: "週ごとの推移"}},
+		Legend:    excelize.ChartLegend{Position: "bottom"},
+		Dimension: excelize.ChartDimension{Width: 640, Height: 320},
+	}
+	if err := file.AddChart(w.sheet, "H2", chart); err != nil {
+		return fmt.Errorf("推移グラフ作成エラー: %w", err)
+	}
+
+	file.SetColWidth(w.sheet, "A", "A", 14)
+	file.SetColWidth(w.sheet, "B", "D", 12)
+	file.SetColWidth(w.sheet, "E", "F", 20)
+
+	return nil
+}
+
+// writeDueSheet は期限切れ・期限間近（7日以内）のチケットを期日順に出力
+// nowは超過/残り日数の基準日時（そのタイムゾーンの日付で日数を数える）
+func writeDueSheet(file *excelize.File, weeklyStats *stats.WeeklyStats, now time.Time) error {
+	w, err := newSheetWriter(file, dueSheetName)
+	if err != nil {
+		return err
+	}
+
+	today := truncateToDay(now)
+	numFmt := excelDateFormat
+	dateStyle, _ := file.NewStyle(&excelize.Style{CustomNumFmt: &numFmt})
+	w.writeHeader("区分", "ID", "チケット", "担当者", "ステータス", "期日", "超過/残り日数")
+
+	groups := []struct {
+		label  string
+		issues []*redmine.Issue
+	}{
+		{label: "期限切れ", issues: weeklyStats.OverdueTasks},
+		{label: "期限間近", issues: weeklyStats.DueSoonTasks},
+	}
+	for _, g := range groups {
+		issues := append([]*redmine.Issue(nil), g.issues...)
+		sort.SliceStable(issues, func(i, j int) bool {
+			return issues[i].DueDate.Time.Before(issues[j].DueDate.Time)
+		})
+
+		for _, issue := range issues {
+			// 期限切れは超過日数（正）、期限間近は残り日数（正）
+			days := daysBetween(issue.DueDate, today)
+			if days < 0 {
+				days = -days
+			}
+			w.writeRow(g.label, issue.ID, issue.CleanedSubject, processor.GetAssignee(issue),
+				issue.Status.Name, truncateToDay(issue.DueDate.Time), days)
+			dueCell := fmt.Sprintf("F%d", w.row-1)
+			file.SetCellStyle(w.sheet, dueCell, dueCell, dateStyle)
+		}
+	}
+
+	file.SetColWidth(w.sheet, "A", "G", 12)
+	file.SetColWidth(w.sheet, "C", "C", 40)
+
+	return nil
+}
+
+// writeCommentSheet はコメント（ジャーナルのノート）を1件1行で出力
+func writeCommentSheet(file *excelize.File, roots []*redmine.Issue) error {
+	w, err := newSheetWriter(file, commentSheetName)
+	if err != nil {
+		return err
+	}
+
+	w.writeHeader("ID", "チケット", "日時", "ユーザー", "コメント")
+	for _, issue := range flattenIssueTree(roots) {
+		for _, j := range issue.Journals {
+			if j.Notes == "" {
+				continue
+			}
+			w.writeRow(issue.ID, issue.CleanedSubject, formatJournalTime(j.CreatedOn), j.User.Name, j.Notes)
+		}
+	}
+
+	file.SetColWidth(w.sheet, "A", "A", 8)
+	file.SetColWidth(w.sheet, "B", "B", 40)
+	file.SetColWidth(w.sheet, "C", "D", 18)
+	file.SetColWidth(w.sheet, "E", "E", 80)
+
+	return nil
+}
+
+// writeHistorySheet は変更履歴を変更1件1行（チケット・日時順）で出力
+func writeHistorySheet(file *excelize.File, roots []*redmine.Issue) error {
+	w, err := newSheetWriter(file, historySheetName)
+	if err != nil {
+		return err
+	}
+
+	w.writeHeader("ID", "チケット", "日時", "ユーザー", "項目", "変更前", "変更後")
+	for _, issue := range flattenIssueTree(roots) {
+		for _, h := range issue.History {
+			for _, c := range h.Changes {
+				w.writeRow(issue.ID, issue.CleanedSubject, formatHistoryTime(h.CreatedOn), h.User.Name, c.Field, c.OldValue, c.NewValue)
+			}
+		}
+	}
+
+	file.SetColWidth(w.sheet, "A", "A", 8)
+	file.SetColWidth(w.sheet, "B", "B", 40)
+	file.SetColWidth(w.sheet, "C", "E", 18)
+	file.SetColWidth(w.sheet, "F", "G", 30)
+
+	return nil
+}
+
+// writeTimeSheet は作業時間の明細と集計（チケット×作業者別・作業者別）を工数シートに出力
+func writeTimeSheet(file *excelize.File, roots []*redmine.Issue, ts *stats.TimeStats) error {
+	w, err := newSheetWriter(file, timeSheetName)
+	if err != nil {
+		return err
+	}
+
+	// 明細（日付・チケット順）
+	issues := flattenIssueTree(roots)
+	type entryRow struct {
+		issue *redmine.Issue
+		entry redmine.TimeEntry
+	}
+	var entries []entryRow
+	for _, issue := range issues {
+		for _, te := range issue.TimeEntries {
+			entries = append(entries, entryRow{issue: issue, entry: te})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		di, dj := formatDate(entries[i].entry.SpentOn), formatDate(entries[j].entry.SpentOn)
+		if di != dj {
+			return di < dj
+		}
+		return entries[i].issue.ID < entries[j].issue.ID
+	})
+
+	w.writeHeader("日付", "ID", "チケット", "プロジェクト", "作業者", "作業分類", "時間", "コメント")
+	for _, e := range entries {
+		w.writeRow(formatDate(e.entry.SpentOn), e.issue.ID, e.issue.CleanedSubject, e.entry.Project.Name,
+			e.entry.User.Name, e.entry.Activity.Name, e.entry.Hours, e.entry.Comments)
+	}
+	w.writeRow("", "", "", "", "", "合計", ts.TotalHours)
+	w.skip()
+
+	// チケット×作業者別
+	w.writeHeader("ID", "チケット", "担当者", "作業者", "時間")
+	for _, issue := range issues {
+		for _, h := range stats.SortedHours(ts.ByIssueUser[issue.ID]) {
+			w.writeRow(issue.ID, issue.CleanedSubject, processor.GetAssignee(issue), h.Name, h.Hours)
+		}
+	}
+	w.skip()
+
+	// 作業者別
+	w.writeHeader("作業者", "時間")
+	for _, h := range stats.SortedHours(ts.ByUser) {
+		w.writeRow(h.Name, h.Hours)
+	}
+
+	file.SetColWidth(w.sheet, "A", "H", 15)
+	file.SetColWidth(w.sheet, "C", "C", 40)
+
+	return nil
+}
+
+// truncateToDay は日時をその日の0時に切り捨てる
+func truncateToDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// daysBetween は期日からtodayまでの日数（期日が過去なら正）を返す
+// 期日は日付のみでUTCの0時としてパースされるため、todayのタイムゾーンの同じ日付に揃えて数える
+func daysBetween(due *redmine.Date, today time.Time) int {
+	dueDay := time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, today.Location())
+	return int(math.Round(truncateToDay(today).Sub(dueDay).Hours() / 24))
+}
+
+// formatJournalTime はジャーナルの作成日時（RFC3339文字列）を表示用に整形
+// 解析できない場合はそのまま返す
+func formatJournalTime(createdOn string) string {
+	t, err := time.Parse(time.RFC3339, createdOn)
+	if err != nil {
+		return createdOn
+	}
+	return localTime(t).Format("2006/01/02 15:04")
+}
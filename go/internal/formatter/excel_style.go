@@ -0,0 +1,147 @@
+package formatter
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tktomaru/redmine-exporter/internal/redmine"
+	"github.com/tktomaru/redmine-exporter/internal/stats"
+	"github.com/xuri/excelize/v2"
+)
+
+// Excelの日付セルの表示形式
+const (
+	excelDateFormat     = "yyyy/mm/dd"
+	excelDateTimeFormat = "yyyy/mm/dd hh:mm"
+)
+
+// 列幅の範囲（文字数）
+const (
+	minColumnWidth = 8
+	maxColumnWidth = 60
+)
+
+// 行の色分け
+const (
+	rowNormal  = iota
+	rowOverdue // 期限切れ（未完了で期日が今日より前）
+	rowClosed  // 完了
+)
+
+// issueRowKind はチケットの行の色分けを判定
+// 完了はステータスの一覧の is_closed（statusesがnilの場合はステータス名）で判定する
+func issueRowKind(issue *redmine.Issue, today time.Time, statuses *redmine.Enumerations) int {
+	if stats.IsClosed(statuses, issue.Status) {
+		return rowClosed
+	}
+	if issue.DueDate != nil && !issue.DueDate.IsZero() && daysBetween(issue.DueDate, today) > 0 {
+		return rowOverdue
+	}
+	return rowNormal
+}
+
+// excelStyleKey はセルのスタイルの組み合わせ
+type excelStyleKey struct {
+	kind   int    // 行の色分け
+	numFmt string // 日付の表示形式（日付セル以外は空）
+	wrap   bool   // 折り返し表示（複数行のセル）
+	link   bool   // ハイパーリンク
+}
+
+// excelStyles は組み合わせごとのスタイルIDを作成・キャッシュする
+type excelStyles struct {
+	file  *excelize.File
+	cache map[excelStyleKey]int
+}
+
+// newExcelStyles は新しいexcelStylesを作成
+func newExcelStyles(file *excelize.File) *excelStyles {
+	return &excelStyles{file: file, cache: make(map[excelStyleKey]int)}
+}
+
+// get は組み合わせに対応するスタイルIDを返す
+func (s *excelStyles) get(key excelStyleKey) int {
+	if id, ok := s.cache[key]; ok {
+		return id
+	}
+
+	style := &excelize.Style{
+		Alignment: &excelize.Alignment{Vertical: "top", WrapText: key.wrap},
+	}
+	if key.numFmt != "" {
+		numFmt := key.numFmt
+		style.CustomNumFmt = &numFmt
+	}
+
+	font := &excelize.Font{}
+	switch key.kind {
+	case rowOverdue:
+		font.Color = "9C0006"
+		style.Fill = excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"FFC7CE"}}
+	case rowClosed:
+		font.Color = "808080"
+		style.Fill = excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"EDEDED"}}
+	}
+	if key.link {
+		font.Color = "0563C1"
+		font.Underline = "single"
+	}
+	if *font != (excelize.Font{}) {
+		style.Font = font
+	}
+
+	id, err := s.file.NewStyle(style)
+	if err != nil {
+		id = 0
+	}
+	s.cache[key] = id
+	return id
+}
+
+// columnWidths は内容に合わせた列幅を計算する
+type columnWidths struct {
+	widths []int
+}
+
+// newColumnWidths は新しいcolumnWidthsを作成
+func newColumnWidths(numCols int) *columnWidths {
+	return &columnWidths{widths: make([]int, numCols)}
+}
+
+// observe はセルの値を列幅の計算に反映（複数行は最も長い行で計算）
+func (c *columnWidths) observe(col int, value string) {
+	for _, line := range strings.Split(value, "\n") {
+		if w := displayWidth(line); w > c.widths[col] {
+			c.widths[col] = w
+		}
+	}
+}
+
+// apply は計算した列幅をシートに設定
+func (c *columnWidths) apply(file *excelize.File, sheet string) {
+	for i, w := range c.widths {
+		width := w + 2
+		if width < minColumnWidth {
+			width = minColumnWidth
+		}
+		if width > maxColumnWidth {
+			width = maxColumnWidth
+		}
+		col, _ := excelize.ColumnNumberToName(i + 1)
+		file.SetColWidth(sheet, col, col, float64(width))
+	}
+}
+
+// displayWidth は文字列の表示幅を返す（全角文字は2、半角文字は1として数える）
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		if utf8.RuneLen(r) >= 3 && !(r >= 0xFF61 && r <= 0xFF9F) {
+			width += 2 // 全角（半角カナを除く）
+		} else {
+			width++
+		}
+	}
+	return width
+}
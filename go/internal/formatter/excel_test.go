@@ -0,0 +1,414 @@
+package formatter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tktomaru/redmine-exporter/internal/redmine"
+	"github.com/tktomaru/redmine-exporter/internal/stats"
+	"github.com/xuri/excelize/v2"
+)
+
+// createReportTestData は期限切れ・期限間近・コメントを含むテストデータを作成
+func createReportTestData() []*redmine.Issue {
+	// 期日はRedmineと同じUTCの0時（日付は出力のタイムゾーンの今日から）
+	today := localTime(time.Now())
+	due := func(days int) *redmine.Date {
+		d := today.AddDate(0, 0, days)
+		return &redmine.Date{Time: time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)}
+	}
+	parent := &redmine.Issue{ID: 1, CleanedSubject: "親タスク", Status: redmine.IDName{Name: "進行中"}}
+	parent.Children = []*redmine.Issue{
+		{
+			ID:             2,
+			CleanedSubject: "期限切れタスク",
+			Status:         redmine.IDName{Name: "進行中"},
+			AssignedTo:     &redmine.IDName{Name: "佐藤"},
+			Tracker:        redmine.IDName{Name: "機能"},
+			Priority:       redmine.IDName{Name: "高"},
+			DueDate:        due(-3),
+			Journals: []redmine.Journal{
+				{ID: 1, User: redmine.IDName{Name: "佐藤"}, Notes: "遅延しています", CreatedOn: "2026-01-05T01:00:00Z"},
+				{ID: 2, User: redmine.IDName{Name: "佐藤"}}, // ノートなし（ステータス変更のみ）
+			},
+		},
+		{
+			ID:             3,
+			CleanedSubject: "期限間近タスク",
+			Status:         redmine.IDName{Name: "新規"},
+			AssignedTo:     &redmine.IDName{Name: "鈴木"},
+			Tracker:        redmine.IDName{Name: "バグ"},
+			Priority:       redmine.IDName{Name: "通常"},
+			DueDate:        due(3),
+		},
+	}
+	return []*redmine.Issue{parent}
+}
+
+func TestExcelFormatter_ReportSheets(t *testing.T) {
+	roots := createReportTestData()
+
+	formatter := &ExcelFormatter{}
+	formatter.SetMode("summary", nil)
+	now := time.Now()
+	formatter.SetStats(stats.Calculate(roots, now.AddDate(0, 0, -7), now), now.AddDate(0, 0, -7), now)
+
+	var buf bytes.Buffer
+	if err := formatter.Format(roots, &buf); err != nil {
+		t.Fatalf("Format()でエラー: %v", err)
+	}
+
+	file, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("Excelファイルを開けない: %v", err)
+	}
+	defer file.Close()
+
+	wantSheets := []string{issueSheetName, statsSheetName, dueSheetName, commentSheetName}
+	sheets := file.GetSheetList()
+	if len(sheets) != len(wantSheets) {
+		t.Fatalf("シート = %v; want %v", sheets, wantSheets)
+	}
+	for i, want := range wantSheets {
+		if sheets[i] != want {
+			t.Errorf("シート[%d] = %q; want %q", i, sheets[i], want)
+		}
+	}
+
+	t.Run("統計シート", func(t *testing.T) {
+		rows, _ := file.GetRows(statsSheetName)
+		found := map[string]string{}
+		for _, row := range rows {
+			if len(row) >= 2 {
+				found[row[0]] = row[1]
+			}
+		}
+		for name, want := range map[string]string{"総チケット数": "3", "期限切れ": "1", "期限間近（7日以内）": "1", "佐藤": "1", "バグ": "1"} {
+			if found[name] != want {
+				t.Errorf("%s = %q; want %q", name, found[name], want)
+			}
+		}
+	})
+
+	t.Run("期限シート", func(t *testing.T) {
+		rows, _ := file.GetRows(dueSheetName)
+		if len(rows) != 3 {
+			t.Fatalf("行数 = %d; want 3 (%v)", len(rows), rows)
+		}
+		if rows[1][0] != "期限切れ" || rows[1][1] != "2" || rows[1][6] != "3" {
+			t.Errorf("期限切れ行 = %v", rows[1])
+		}
+		if rows[2][0] != "期限間近" || rows[2][1] != "3" || rows[2][6] != "3" {
+			t.Errorf("期限間近行 = %v", rows[2])
+		}
+	})
+
+	t.Run("コメントシート", func(t *testing.T) {
+		rows, _ := file.GetRows(commentSheetName)
+		if len(rows) != 2 {
+			t.Fatalf("行数 = %d; want 2 (%v)", len(rows), rows)
+		}
+		if rows[1][0] != "2" || rows[1][3] != "佐藤" || rows[1][4] != "遅延しています" {
+			t.Errorf("コメント行 = %v", rows[1])
+		}
+	})
+}
+
+func TestWriteDueSheet_Days(t *testing.T) {
+	// UTCより東のタイムゾーンでも、期日（UTCの0時としてパース）からの日数を日付で数えること
+	loc, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("LoadLocation() failed: %v", err)
+	}
+	now := time.Date(2026, 10, 17, 10, 0, 0, 0, loc)
+	due := func(month, day int) *redmine.Date {
+		return &redmine.Date{Time: time.Date(2026, time.Month(month), day, 0, 0, 0, 0, time.UTC)}
+	}
+	weeklyStats := &stats.WeeklyStats{
+		OverdueTasks: []*redmine.Issue{{ID: 1, CleanedSubject: "期限切れ", DueDate: due(10, 10)}},
+		DueSoonTasks: []*redmine.Issue{{ID: 2, CleanedSubject: "期限間近", DueDate: due(10, 20)}},
+	}
+
+	file := excelize.NewFile()
+	defer file.Close()
+	if err := writeDueSheet(file, weeklyStats, now); err != nil {
+		t.Fatalf("writeDueSheet()でエラー: %v", err)
+	}
+
+	rows, _ := file.GetRows(dueSheetName)
+	if len(rows) != 3 {
+		t.Fatalf("行数 = %d; want 3 (%v)", len(rows), rows)
+	}
+	if rows[1][6] != "7" {
+		t.Errorf("超過日数 = %s; want 7 (%v)", rows[1][6], rows[1])
+	}
+	if rows[2][6] != "3" {
+		t.Errorf("残り日数 = %s; want 3 (%v)", rows[2][6], rows[2])
+	}
+}
+
+func TestIssueRowKind_Statuses(t *testing.T) {
+	today := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	overdue := &redmine.Date{Time: time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC)}
+	statuses := redmine.NewEnumerations()
+	statuses.ClosedKnown = true
+	statuses.Statuses[3] = redmine.IssueStatus{ID: 3, Name: "Resolved"}
+	statuses.Statuses[6] = redmine.IssueStatus{ID: 6, Name: "却下", IsClosed: true}
+
+	tests := []struct {
+		name     string
+		status   redmine.IDName
+		statuses *redmine.Enumerations
+		want     int
+	}{
+		{name: "ステータス名で判定（オフライン）", status: redmine.IDName{ID: 3, Name: "Resolved"}, want: rowClosed},
+		{name: "is_closedがfalse", status: redmine.IDName{ID: 3, Name: "Resolved"}, statuses: statuses, want: rowOverdue},
+		{name: "is_closedがtrue", status: redmine.IDName{ID: 6, Name: "却下"}, statuses: statuses, want: rowClosed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issue := &redmine.Issue{Status: tt.status, DueDate: overdue}
+			if got := issueRowKind(issue, today, tt.statuses); got != tt.want {
+				t.Errorf("issueRowKind() = %d; want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExcelFormatter_StatsWithoutSetStats(t *testing.T) {
+	// SetStatsを呼ばなくても統計シートが作成されること
+	formatter := &ExcelFormatter{}
+	formatter.SetMode("summary", nil)
+
+	var buf bytes.Buffer
+	if err := formatter.Format(createReportTestData(), &buf); err != nil {
+		t.Fatalf("Format()でエラー: %v", err)
+	}
+
+	file, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("Excelファイルを開けない: %v", err)
+	}
+	defer file.Close()
+
+	if index, _ := file.GetSheetIndex(statsSheetName); index == -1 {
+		t.Error("統計シートが作成されていない")
+	}
+}
+
+func TestExcelFormatter_NativeFormatting(t *testing.T) {
+	now := time.Now()
+	roots := []*redmine.Issue{
+		{
+			ID:             10,
+			CleanedSubject: "期限切れタスク",
+			Status:         redmine.IDName{Name: "進行中"},
+			StartDate:      &redmine.Date{Time: time.Date(2026, 1, 2, 0, 0, 0, 0, time.Local)},
+			DueDate:        &redmine.Date{Time: now.AddDate(0, 0, -3)},
+			ExtractedTags:  map[string][]string{"進捗": {"設計完了", "実装中"}},
+		},
+		{
+			ID:             11,
+			CleanedSubject: "完了タスク",
+			Status:         redmine.IDName{Name: "完了"},
+			DueDate:        &redmine.Date{Time: now.AddDate(0, 0, -3)},
+		},
+		{
+			ID:             12,
+			CleanedSubject: "通常タスク",
+			Status:         redmine.IDName{Name: "新規"},
+		},
+	}
+
+	formatter := &ExcelFormatter{}
+	formatter.SetMode("tags", []string{"進捗"})
+	formatter.SetBaseURL("https://redmine.example.com/")
+	if err := formatter.SetColumns([]string{"id", "status", "start_date", "due_date", "tag:進捗"}); err != nil {
+		t.Fatalf("SetColumns()でエラー: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := formatter.Format(roots, &buf); err != nil {
+		t.Fatalf("Format()でエラー: %v", err)
+	}
+
+	file, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("Excelファイルを開けない: %v", err)
+	}
+	defer file.Close()
+
+	// fillColor はセルの背景色を返す（塗りつぶしなしの場合は空）
+	fillColor := func(cell string) string {
+		styleID, err := file.GetCellStyle(issueSheetName, cell)
+		if err != nil {
+			t.Fatalf("GetCellStyle(%s)でエラー: %v", cell, err)
+		}
+		style, err := file.GetStyle(styleID)
+		if err != nil {
+			t.Fatalf("GetStyle(%s)でエラー: %v", cell, err)
+		}
+		if len(style.Fill.Color) == 0 {
+			return ""
+		}
+		return style.Fill.Color[0]
+	}
+
+	t.Run("日付セル", func(t *testing.T) {
+		cellType, _ := file.GetCellType(issueSheetName, "C2")
+		if cellType == excelize.CellTypeSharedString || cellType == excelize.CellTypeInlineString {
+			t.Errorf("開始日が文字列セルになっている")
+		}
+		value, _ := file.GetCellValue(issueSheetName, "C2")
+		if value != "2026/01/02" {
+			t.Errorf("開始日 = %q; want %q", value, "2026/01/02")
+		}
+		// 日付がない場合は空セル
+		if value, _ := file.GetCellValue(issueSheetName, "D4"); value != "" {
+			t.Errorf("終了日なし = %q; want 空", value)
+		}
+	})
+
+	t.Run("ハイパーリンク", func(t *testing.T) {
+		ok, target, _ := file.GetCellHyperLink(issueSheetName, "A2")
+		if !ok || target != "https://redmine.example.com/issues/10" {
+			t.Errorf("リンク = %v, %q", ok, target)
+		}
+	})
+
+	t.Run("行の色分け", func(t *testing.T) {
+		tests := []struct {
+			name string
+			cell string
+			want string
+		}{
+			{name: "期限切れ", cell: "B2", want: "FFC7CE"},
+			{name: "完了", cell: "B3", want: "EDEDED"},
+			{name: "通常", cell: "B4", want: ""},
+		}
+		for _, tt := range tests {
+			if got := fillColor(tt.cell); got != tt.want {
+				t.Errorf("%s: 背景色 = %q; want %q", tt.name, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("折り返し", func(t *testing.T) {
+		styleID, _ := file.GetCellStyle(issueSheetName, "E2")
+		style, _ := file.GetStyle(styleID)
+		if style.Alignment == nil || !style.Alignment.WrapText {
+			t.Error("複数行のタグが折り返し表示になっていない")
+		}
+	})
+
+	t.Run("ヘッダー固定", func(t *testing.T) {
+		panes, err := file.GetPanes(issueSheetName)
+		if err != nil {
+			t.Fatalf("GetPanes()でエラー: %v", err)
+		}
+		if !panes.Freeze || panes.YSplit != 1 {
+			t.Errorf("ペイン = %+v", panes)
+		}
+	})
+}
+
+func TestExcelFormatter_StatsTransitions(t *testing.T) {
+	now := time.Now()
+	roots := []*redmine.Issue{
+		{ID: 1, CleanedSubject: "完了したチケット", Status: redmine.IDName{ID: 5, Name: "完了"},
+			StatusChanges: []redmine.StatusChange{{CreatedOn: now.AddDate(0, 0, -1), From: redmine.IDName{ID: 2, Name: "進行中"}, To: redmine.IDName{ID: 5, Name: "完了"}}}},
+		{ID: 2, CleanedSubject: "再オープンしたチケット", Status: redmine.IDName{ID: 2, Name: "進行中"},
+			StatusChanges: []redmine.StatusChange{{CreatedOn: now.AddDate(0, 0, -2), From: redmine.IDName{ID: 5, Name: "完了"}, To: redmine.IDName{ID: 2, Name: "進行中"}}}},
+	}
+
+	formatter := &ExcelFormatter{}
+	formatter.SetMode("summary", nil)
+	weeklyStats := stats.CalculateWithOptions(roots, now.AddDate(0, 0, -7), now, stats.Options{Transitions: true, StartedStatuses: []string{"進行中"}})
+	formatter.SetStats(weeklyStats, now.AddDate(0, 0, -7), now)
+
+	var buf bytes.Buffer
+	if err := formatter.Format(roots, &buf); err != nil {
+		t.Fatalf("Format()でエラー: %v", err)
+	}
+	file, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("Excelファイルを開けない: %v", err)
+	}
+	defer file.Close()
+
+	rows, _ := file.GetRows(statsSheetName)
+	counts := map[string]string{}
+	var listed []string
+	for _, row := range rows {
+		switch {
+		case len(row) == 2:
+			counts[row[0]] = row[1]
+		case len(row) >= 5 && row[1] != "ID":
+			listed = append(listed, row[0]+":"+row[1])
+		}
+	}
+	for name, want := range map[string]string{"完了": "1", "再オープン": "1", "着手": "1"} {
+		if counts[name] != want {
+			t.Errorf("%s = %q; want %q", name, counts[name], want)
+		}
+	}
+	want := "完了:1,再オープン:2,着手:2"
+	if got := strings.Join(listed, ","); got != want {
+		t.Errorf("期間内のステータス変更 = %s; want %s", got, want)
+	}
+}
+
+func TestExcelFormatter_TrendSheet(t *testing.T) {
+	roots := createTestData()
+	start := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	var periods []stats.Period
+	for i := 0; i < 3; i++ {
+		s := start.AddDate(0, 0, 7*i)
+		periods = append(periods, stats.Period{Start: s, End: s.AddDate(0, 0, 7).Add(-time.Second)})
+	}
+
+	tests := []struct {
+		name      string
+		trend     []stats.WeekTrend
+		wantSheet bool
+	}{
+		{name: "推移なし", trend: nil, wantSheet: false},
+		{name: "3週間の推移", trend: stats.CalculateTrend(roots, periods, stats.Options{}), wantSheet: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatter := &ExcelFormatter{}
+			formatter.SetMode("summary", nil)
+			formatter.SetTrend(tt.trend)
+
+			var buf bytes.Buffer
+			if err := formatter.Format(roots, &buf); err != nil {
+				t.Fatalf("Format()でエラー: %v", err)
+			}
+			file, err := excelize.OpenReader(&buf)
+			if err != nil {
+				t.Fatalf("Excelファイルを開けない: %v", err)
+			}
+			defer file.Close()
+
+			sheets := file.GetSheetList()
+			if got := strings.Contains(strings.Join(sheets, ","), trendSheetName); got != tt.wantSheet {
+				t.Fatalf("推移シートの有無 = %v; want %v (%v)", got, tt.wantSheet, sheets)
+			}
+			if !tt.wantSheet {
+				return
+			}
+			if sheets[2] != trendSheetName {
+				t.Errorf("シートの順序 = %v; want 統計の次に推移", sheets)
+			}
+
+			rows, _ := file.GetRows(trendSheetName)
+			if len(rows) != 4 || rows[0][0] != "週" || rows[1][0] != "01/06〜01/12" {
+				t.Errorf("推移シート = %v", rows)
+			}
+		})
+	}
+}
@@ -3,9 +3,13 @@ package formatter
 import (
 	"fmt"
 	"io"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/tktomaru/redmine-exporter/internal/filter"
 	"github.com/tktomaru/redmine-exporter/internal/redmine"
+	"github.com/tktomaru/redmine-exporter/internal/stats"
 )
 
 // Formatter は出力形式のインターフェース
@@ -14,6 +18,22 @@ type Formatter interface {
 	SetMode(mode string, tagNames []string)
 }
 
+// StatsSetter は統計情報を出力に含められるフォーマッター（オプション）
+type StatsSetter interface {
+	SetStats(stats *stats.WeeklyStats, weekStart, weekEnd time.Time)
+}
+
+// TrendSetter は週ごとの推移を出力に含められるフォーマッター（オプション）
+type TrendSetter interface {
+	SetTrend(trend []stats.WeekTrend)
+}
+
+// StatusesSetter は完了したチケットを色分けするフォーマッター（オプション）
+// ステータスの一覧（/issue_statuses.json の is_closed）で完了を判定する
+type StatusesSetter interface {
+	SetStatuses(statuses *redmine.Enumerations)
+}
+
 // DetectFormatter は拡張子から適切なフォーマッターを返す
 // templatePathが指定されている場合、そちらを優先
 func DetectFormatter(filename string, mode string, tagNames []string, templatePath string) (Formatter, error) {
@@ -42,8 +62,18 @@ func DetectFormatter(filename string, mode string, tagNames []string, templatePa
 			formatter = &TextFormatter{}
 		case strings.HasSuffix(filename, ".xlsx"):
 			formatter = &ExcelFormatter{filename: filename}
+		case strings.HasSuffix(filename, ".html"), strings.HasSuffix(filename, ".htm"):
+			formatter = &HTMLFormatter{}
+		case strings.HasSuffix(filename, ".csv"):
+			formatter = &CSVFormatter{delimiter: ','}
+		case strings.HasSuffix(filename, ".tsv"):
+			formatter = &CSVFormatter{delimiter: '\t'}
+		case strings.HasSuffix(filename, ".json"):
+			formatter = &JSONFormatter{}
+		case strings.HasSuffix(filename, ".jsonl"):
+			formatter = &JSONFormatter{lines: true}
 		default:
-			return nil, fmt.Errorf("未対応の拡張子: %s (.md, .txt, .xlsx, .tmpl のみ対応)", filename)
+			return nil, fmt.Errorf("未対応の拡張子: %s (.md, .txt, .xlsx, .html, .csv, .tsv, .json, .jsonl, .tmpl のみ対応)", filename)
 		}
 	}
 
@@ -58,3 +88,91 @@ func formatDate(d *redmine.Date) string {
 	}
 	return d.Format()
 }
+
+// collectCustomFieldNames はチケットツリーに含まれるカスタムフィールド名を出現順で返す
+func collectCustomFieldNames(roots []*redmine.Issue) []string {
+	var names []string
+	seen := make(map[string]bool)
+
+	var walk func(issues []*redmine.Issue)
+	walk = func(issues []*redmine.Issue) {
+		for _, issue := range issues {
+			for _, cf := range issue.CustomFields {
+				if !seen[cf.Name] {
+					seen[cf.Name] = true
+					names = append(names, cf.Name)
+				}
+			}
+			walk(issue.Children)
+		}
+	}
+	walk(roots)
+
+	return names
+}
+
+// flattenIssueTree はチケットツリーを親→子の順でフラットなリストに変換
+func flattenIssueTree(roots []*redmine.Issue) []*redmine.Issue {
+	var result []*redmine.Issue
+	for _, issue := range roots {
+		result = append(result, issue)
+		result = append(result, flattenIssueTree(issue.Children)...)
+	}
+	return result
+}
+
+// outputLocation は出力する日時のタイムゾーン（週・期間の計算と同じ）
+var outputLocation = filter.Location()
+
+// localTime は日時を出力用のタイムゾーンに変換する
+// RedmineはUTCで日時を返すため、出力する日時はすべてこの関数を通して実行環境によらず同じ表示にする
+func localTime(t time.Time) time.Time {
+	return t.In(outputLocation)
+}
+
+// formatDateTime は日時をフォーマット（未設定の場合は空文字列）
+func formatDateTime(dt *redmine.DateTime) string {
+	if dt == nil || dt.IsZero() {
+		return ""
+	}
+	return localTime(dt.Time).Format("2006/01/02 15:04:05")
+}
+
+// formatHistoryTime は変更履歴の日時を表示用に整形（コメントの日時と同じ形式）
+func formatHistoryTime(dt *redmine.DateTime) string {
+	if dt == nil || dt.IsZero() {
+		return "----/--/-- --:--"
+	}
+	return localTime(dt.Time).Format("2006/01/02 15:04")
+}
+
+// formatChanges は変更履歴1件分の変更内容をカンマ区切りで返す
+// 例: ステータス: 新規 → 進行中, 進捗率: 0% → 50%
+func formatChanges(entry redmine.HistoryEntry) string {
+	changes := make([]string, 0, len(entry.Changes))
+	for _, c := range entry.Changes {
+		changes = append(changes, c.String())
+	}
+	return strings.Join(changes, ", ")
+}
+
+// nameCount は件数表示用の名前と件数の組
+type nameCount struct {
+	Name  string
+	Count int
+}
+
+// sortedCounts は件数の多い順（同数は名前順）に並べた一覧を返す
+func sortedCounts(m map[string]int) []nameCount {
+	counts := make([]nameCount, 0, len(m))
+	for name, count := range m {
+		counts = append(counts, nameCount{Name: name, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Name < counts[j].Name
+	})
+	return counts
+}
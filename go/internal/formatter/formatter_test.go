@@ -2,11 +2,14 @@ package formatter
 
 import (
 	"bytes"
+	"encoding/json"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/tktomaru/redmine-exporter/internal/redmine"
+	"github.com/tktomaru/redmine-exporter/internal/stats"
+	"github.com/xuri/excelize/v2"
 )
 
 func createTestData() []*redmine.Issue {
@@ -133,8 +136,38 @@ func TestDetectFormatter(t *testing.T) {
 			wantErr:  false,
 		},
 		{
-			name:     "未対応の拡張子",
+			name:     "JSON形式",
+			filename: "output.json",
+			wantType: "*formatter.JSONFormatter",
+			wantErr:  false,
+		},
+		{
+			name:     "JSON Lines形式",
+			filename: "output.jsonl",
+			wantType: "*formatter.JSONFormatter",
+			wantErr:  false,
+		},
+		{
+			name:     "HTML形式",
+			filename: "output.html",
+			wantType: "*formatter.HTMLFormatter",
+			wantErr:  false,
+		},
+		{
+			name:     "CSV形式",
 			filename: "output.csv",
+			wantType: "*formatter.CSVFormatter",
+			wantErr:  false,
+		},
+		{
+			name:     "TSV形式",
+			filename: "output.tsv",
+			wantType: "*formatter.CSVFormatter",
+			wantErr:  false,
+		},
+		{
+			name:     "未対応の拡張子",
+			filename: "output.pdf",
 			wantType: "",
 			wantErr:  true,
 		},
@@ -169,6 +202,18 @@ func TestDetectFormatter(t *testing.T) {
 					if _, ok := formatter.(*ExcelFormatter); !ok {
 						t.Errorf("DetectFormatter() type = %T; want %s", formatter, tt.wantType)
 					}
+				case "*formatter.HTMLFormatter":
+					if _, ok := formatter.(*HTMLFormatter); !ok {
+						t.Errorf("DetectFormatter() type = %T; want %s", formatter, tt.wantType)
+					}
+				case "*formatter.CSVFormatter":
+					if _, ok := formatter.(*CSVFormatter); !ok {
+						t.Errorf("DetectFormatter() type = %T; want %s", formatter, tt.wantType)
+					}
+				case "*formatter.JSONFormatter":
+					if _, ok := formatter.(*JSONFormatter); !ok {
+						t.Errorf("DetectFormatter() type = %T; want %s", formatter, tt.wantType)
+					}
 				}
 			}
 		})
@@ -208,6 +253,39 @@ func TestFormatDate(t *testing.T) {
 	}
 }
 
+func TestFormatDateTime(t *testing.T) {
+	tests := []struct {
+		name string
+		dt   *redmine.DateTime
+		want string
+	}{
+		{
+			name: "UTCはAsia/Tokyoで表示",
+			dt:   &redmine.DateTime{Time: time.Date(2026, 1, 6, 1, 0, 0, 0, time.UTC)},
+			want: "2026/01/06 10:00:00",
+		},
+		{
+			name: "Asia/Tokyoで翌日になる",
+			dt:   &redmine.DateTime{Time: time.Date(2026, 1, 6, 20, 30, 0, 0, time.UTC)},
+			want: "2026/01/07 05:30:00",
+		},
+		{
+			name: "nil",
+			dt:   nil,
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatDateTime(tt.dt)
+			if got != tt.want {
+				t.Errorf("formatDateTime() = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestTextFormatterEmptySummary(t *testing.T) {
 	formatter := &TextFormatter{}
 	formatter.SetMode("summary", []string{"要約"})
@@ -240,3 +318,346 @@ func TestTextFormatterEmptySummary(t *testing.T) {
 		t.Error("要約が空の場合は⇒が出力されないはず")
 	}
 }
+
+func TestFormatters_FullModeCustomFields(t *testing.T) {
+	roots := createTestData()
+	roots[0].Children[0].CustomFields = []redmine.CustomField{
+		{ID: 1, Name: "顧客", Values: []string{"A社"}},
+		{ID: 2, Name: "リリース版", Multiple: true, Values: []string{"v1.0", "v1.1"}},
+	}
+
+	tests := []struct {
+		name      string
+		formatter Formatter
+		want      []string
+	}{
+		{
+			name:      "Markdown",
+			formatter: &MarkdownFormatter{},
+			want:      []string{"  - **顧客**: A社", "  - **リリース版**: v1.0, v1.1"},
+		},
+		{
+			name:      "テキスト",
+			formatter: &TextFormatter{},
+			want:      []string{"　顧客: A社", "　リリース版: v1.0, v1.1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.formatter.SetMode("full", nil)
+
+			var buf bytes.Buffer
+			if err := tt.formatter.Format(roots, &buf); err != nil {
+				t.Fatalf("Format()でエラー: %v", err)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("出力に %q が含まれていない:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestExcelFormatter_FullModeCustomFieldColumns(t *testing.T) {
+	roots := createTestData()
+	roots[0].Children[0].CustomFields = []redmine.CustomField{
+		{ID: 1, Name: "顧客", Values: []string{"A社"}},
+	}
+
+	formatter := &ExcelFormatter{}
+	formatter.SetMode("full", nil)
+
+	var buf bytes.Buffer
+	if err := formatter.Format(roots, &buf); err != nil {
+		t.Fatalf("Format()でエラー: %v", err)
+	}
+
+	file, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("Excelファイルを開けない: %v", err)
+	}
+	defer file.Close()
+
+	rows, err := file.GetRows(issueSheetName)
+	if err != nil {
+		t.Fatalf("GetRows()でエラー: %v", err)
+	}
+
+	header := rows[0]
+	if header[len(header)-1] != "顧客" {
+		t.Errorf("最終列のヘッダー = %q; want 顧客", header[len(header)-1])
+	}
+	if got := rows[1][len(header)-1]; got != "A社" {
+		t.Errorf("顧客列の値 = %q; want A社", got)
+	}
+}
+
+func TestExcelFormatter_TimeSheet(t *testing.T) {
+	tests := []struct {
+		name      string
+		withHours bool
+	}{
+		{name: "作業時間あり", withHours: true},
+		{name: "作業時間なし", withHours: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roots := createTestData()
+			if tt.withHours {
+				roots[0].Children[0].TimeEntries = []redmine.TimeEntry{
+					{Hours: 1.5, User: redmine.IDName{Name: "山田"}, Activity: redmine.IDName{Name: "開発"}, SpentOn: &redmine.Date{Time: time.Date(2026, 1, 6, 0, 0, 0, 0, time.UTC)}},
+					{Hours: 2, User: redmine.IDName{Name: "佐藤"}, Activity: redmine.IDName{Name: "レビュー"}, SpentOn: &redmine.Date{Time: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)}},
+				}
+			}
+
+			formatter := &ExcelFormatter{}
+			formatter.SetMode("summary", nil)
+
+			var buf bytes.Buffer
+			if err := formatter.Format(roots, &buf); err != nil {
+				t.Fatalf("Format()でエラー: %v", err)
+			}
+
+			file, err := excelize.OpenReader(&buf)
+			if err != nil {
+				t.Fatalf("Excelファイルを開けない: %v", err)
+			}
+			defer file.Close()
+
+			index, _ := file.GetSheetIndex(timeSheetName)
+			if !tt.withHours {
+				if index != -1 {
+					t.Error("作業時間がないのに工数シートが作成された")
+				}
+				return
+			}
+			if index == -1 {
+				t.Fatal("工数シートが作成されていない")
+			}
+
+			rows, err := file.GetRows(timeSheetName)
+			if err != nil {
+				t.Fatalf("GetRows()でエラー: %v", err)
+			}
+
+			// 明細は日付順
+			if rows[1][0] != "2026/01/05" || rows[1][4] != "佐藤" {
+				t.Errorf("明細1行目 = %v; want 2026/01/05 佐藤", rows[1])
+			}
+			if rows[3][5] != "合計" || rows[3][6] != "3.5" {
+				t.Errorf("合計行 = %v; want 合計 3.5", rows[3])
+			}
+		})
+	}
+}
+
+// createHistory はステータスと進捗率の変更履歴を作成（作成日時はRedmineと同じUTC）
+func createHistory() []redmine.HistoryEntry {
+	return []redmine.HistoryEntry{{
+		JournalID: 10,
+		CreatedOn: &redmine.DateTime{Time: time.Date(2026, 1, 6, 1, 0, 0, 0, time.UTC)},
+		User:      redmine.IDName{ID: 5, Name: "山田"},
+		Changes: []redmine.Change{
+			{Detail: redmine.JournalDetail{Property: "attr", Name: "status_id", OldValue: "1", NewValue: "2"}, Field: "ステータス", OldValue: "新規", NewValue: "進行中"},
+			{Detail: redmine.JournalDetail{Property: "attr", Name: "done_ratio", OldValue: "0", NewValue: "50"}, Field: "進捗率", OldValue: "0%", NewValue: "50%"},
+		},
+	}}
+}
+
+func TestMarkdownFormatter_History(t *testing.T) {
+	roots := createTestData()
+	roots[0].Children[0].History = createHistory()
+
+	formatter := &MarkdownFormatter{}
+	formatter.SetMode("full", nil)
+
+	var buf bytes.Buffer
+	if err := formatter.Format(roots, &buf); err != nil {
+		t.Fatalf("Format()でエラー: %v", err)
+	}
+
+	want := "  - **履歴**:\n    - 2026/01/06 10:00 山田: ステータス: 新規 → 進行中, 進捗率: 0% → 50%\n"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("出力に %q が含まれていない:\n%s", want, buf.String())
+	}
+	if strings.Count(buf.String(), "**履歴**") != 1 {
+		t.Errorf("履歴のないチケットに履歴が出力された:\n%s", buf.String())
+	}
+}
+
+func TestExcelFormatter_HistorySheet(t *testing.T) {
+	tests := []struct {
+		name        string
+		withHistory bool
+	}{
+		{name: "変更履歴あり", withHistory: true},
+		{name: "変更履歴なし", withHistory: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roots := createTestData()
+			if tt.withHistory {
+				roots[0].Children[0].History = createHistory()
+			}
+
+			formatter := &ExcelFormatter{}
+			formatter.SetMode("summary", nil)
+
+			var buf bytes.Buffer
+			if err := formatter.Format(roots, &buf); err != nil {
+				t.Fatalf("Format()でエラー: %v", err)
+			}
+
+			file, err := excelize.OpenReader(&buf)
+			if err != nil {
+				t.Fatalf("Excelファイルを開けない: %v", err)
+			}
+			defer file.Close()
+
+			index, _ := file.GetSheetIndex(historySheetName)
+			if !tt.withHistory {
+				if index != -1 {
+					t.Error("変更履歴がないのに履歴シートが作成された")
+				}
+				return
+			}
+			if index == -1 {
+				t.Fatal("履歴シートが作成されていない")
+			}
+
+			rows, err := file.GetRows(historySheetName)
+			if err != nil {
+				t.Fatalf("GetRows()でエラー: %v", err)
+			}
+			if len(rows) != 3 {
+				t.Fatalf("行数 = %d; want 3（見出し + 変更2件）", len(rows))
+			}
+			want := []string{"2026/01/06 10:00", "山田", "ステータス", "新規", "進行中"}
+			if got := rows[1][2:]; strings.Join(got, "|") != strings.Join(want, "|") {
+				t.Errorf("1行目 = %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestJSONFormatter(t *testing.T) {
+	roots := createTestData()
+	child := roots[0].Children[0]
+	child.ExtractedTags = map[string][]string{"進捗": {"50%"}}
+	child.Journals = []redmine.Journal{{ID: 10, User: redmine.IDName{Name: "佐藤"}, Notes: "確認中", CreatedOn: "2026-01-05T10:00:00Z"}}
+
+	formatter := &JSONFormatter{}
+	formatter.SetMode("tags", []string{"進捗"})
+	now := time.Now()
+	formatter.SetStats(stats.Calculate(roots, now.AddDate(0, 0, -7), now), now.AddDate(0, 0, -7), now)
+
+	var buf bytes.Buffer
+	if err := formatter.Format(roots, &buf); err != nil {
+		t.Fatalf("Format()でエラー: %v", err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("出力がJSONとして不正: %v\n%s", err, buf.String())
+	}
+
+	if doc["schema_version"] != JSONSchemaVersion {
+		t.Errorf("schema_version = %v; want %s", doc["schema_version"], JSONSchemaVersion)
+	}
+	if doc["mode"] != "tags" {
+		t.Errorf("mode = %v; want tags", doc["mode"])
+	}
+	if _, ok := doc["stats"].(map[string]interface{}); !ok {
+		t.Errorf("stats が出力されていない: %v", doc["stats"])
+	}
+
+	issues := doc["issues"].([]interface{})
+	if len(issues) != 1 {
+		t.Fatalf("len(issues) = %d; want 1", len(issues))
+	}
+	children := issues[0].(map[string]interface{})["children"].([]interface{})
+	got := children[0].(map[string]interface{})
+
+	tests := []struct {
+		key  string
+		want interface{}
+	}{
+		{key: "id", want: float64(2)},
+		{key: "parent_id", want: float64(1)},
+		{key: "cleaned_subject", want: "タスクB"},
+		{key: "status", want: "進行中"},
+		{key: "assignee", want: "佐藤"},
+		{key: "start_date", want: "2026-01-02"},
+		{key: "summary", want: "ひとこと整形で変更しました"},
+	}
+	for _, tt := range tests {
+		if got[tt.key] != tt.want {
+			t.Errorf("%s = %v; want %v", tt.key, got[tt.key], tt.want)
+		}
+	}
+
+	if tags := got["tags"].(map[string]interface{}); len(tags["進捗"].([]interface{})) != 1 {
+		t.Errorf("tags = %v", tags)
+	}
+	if journals := got["journals"].([]interface{}); len(journals) != 1 || journals[0].(map[string]interface{})["notes"] != "確認中" {
+		t.Errorf("journals = %v", journals)
+	}
+}
+
+func TestJSONFormatter_Lines(t *testing.T) {
+	tests := []struct {
+		name      string
+		withStats bool
+		wantTypes []string
+	}{
+		{name: "統計なし", withStats: false, wantTypes: []string{"issue", "issue"}},
+		{name: "統計あり", withStats: true, wantTypes: []string{"issue", "issue", "stats"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roots := createTestData()
+			formatter := &JSONFormatter{lines: true}
+			formatter.SetMode("summary", nil)
+			if tt.withStats {
+				now := time.Now()
+				formatter.SetStats(stats.Calculate(roots, now.AddDate(0, 0, -7), now), now.AddDate(0, 0, -7), now)
+			}
+
+			var buf bytes.Buffer
+			if err := formatter.Format(roots, &buf); err != nil {
+				t.Fatalf("Format()でエラー: %v", err)
+			}
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if len(lines) != len(tt.wantTypes) {
+				t.Fatalf("行数 = %d; want %d\n%s", len(lines), len(tt.wantTypes), buf.String())
+			}
+
+			for i, line := range lines {
+				var record map[string]interface{}
+				if err := json.Unmarshal([]byte(line), &record); err != nil {
+					t.Fatalf("%d行目がJSONとして不正: %v", i+1, err)
+				}
+				if record["type"] != tt.wantTypes[i] || record["schema_version"] != JSONSchemaVersion {
+					t.Errorf("%d行目 type/schema_version = %v/%v; want %s/%s", i+1, record["type"], record["schema_version"], tt.wantTypes[i], JSONSchemaVersion)
+				}
+				if _, ok := record["children"]; ok {
+					t.Errorf("%d行目にchildrenが含まれている", i+1)
+				}
+			}
+
+			// 子チケットはparent_idで親を参照する
+			var child map[string]interface{}
+			json.Unmarshal([]byte(lines[1]), &child)
+			if child["id"] != float64(2) || child["parent_id"] != float64(1) {
+				t.Errorf("子チケット id/parent_id = %v/%v; want 2/1", child["id"], child["parent_id"])
+			}
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package formatter
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tktomaru/redmine-exporter/internal/processor"
+	"github.com/tktomaru/redmine-exporter/internal/redmine"
+	"github.com/tktomaru/redmine-exporter/internal/stats"
+)
+
+// BaseURLSetter はチケットへのリンクを出力できるフォーマッター（オプション）
+type BaseURLSetter interface {
+	SetBaseURL(baseURL string)
+}
+
+// HTMLFormatter は1ファイルで完結するHTML形式で出力（メール添付・本文貼り付け用）
+// CSSはインラインで埋め込み、親子関係は折りたたみ可能なセクションで表示する
+type HTMLFormatter struct {
+	mode      string
+	tagNames  []string
+	baseURL   string
+	stats     *stats.WeeklyStats
+	weekStart time.Time
+	weekEnd   time.Time
+	trend     []stats.WeekTrend
+	statuses  *redmine.Enumerations // 完了の判定に使うステータスの一覧（未設定の場合はステータス名で判定）
+}
+
+// htmlData はHTMLテンプレートに渡すデータ
+type htmlData struct {
+	Now       time.Time
+	Issues    []*redmine.Issue
+	Mode      string
+	TagNames  []string
+	Stats     *stats.WeeklyStats
+	WeekStart time.Time
+	WeekEnd   time.Time
+	Trend     []stats.WeekTrend // 週ごとの推移（推移を指定していない場合はnil）
+}
+
+// Format はHTML形式で出力
+func (f *HTMLFormatter) Format(roots []*redmine.Issue, w io.Writer) error {
+	tmpl, err := template.New("report").Funcs(f.funcs()).Parse(htmlTemplate)
+	if err != nil {
+		return fmt.Errorf("HTMLテンプレートエラー: %w", err)
+	}
+
+	data := htmlData{
+		Now:       localTime(time.Now()),
+		Issues:    roots,
+		Mode:      f.mode,
+		TagNames:  f.tagNames,
+		Stats:     f.stats,
+		WeekStart: f.weekStart,
+		WeekEnd:   f.weekEnd,
+		Trend:     f.trend,
+	}
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("HTML出力エラー: %w", err)
+	}
+	return nil
+}
+
+// SetMode はモードとタグ名を設定
+func (f *HTMLFormatter) SetMode(mode string, tagNames []string) {
+	f.mode = mode
+	f.tagNames = tagNames
+}
+
+// SetBaseURL はチケットへのリンクに使用するRedmineのURLを設定
+func (f *HTMLFormatter) SetBaseURL(baseURL string) {
+	f.baseURL = strings.TrimRight(baseURL, "/")
+}
+
+// SetStats は統計情報を設定
+func (f *HTMLFormatter) SetStats(stats *stats.WeeklyStats, weekStart, weekEnd time.Time) {
+	f.stats = stats
+	f.weekStart = weekStart
+	f.weekEnd = weekEnd
+}
+
+// SetTrend は週ごとの推移を設定
+func (f *HTMLFormatter) SetTrend(trend []stats.WeekTrend) {
+	f.trend = trend
+}
+
+// SetStatuses はステータスのバッジの完了の判定に使うステータスの一覧を設定
+func (f *HTMLFormatter) SetStatuses(statuses *redmine.Enumerations) {
+	f.statuses = statuses
+}
+
+// funcs はHTMLテンプレートで使用する関数
+func (f *HTMLFormatter) funcs() template.FuncMap {
+	return template.FuncMap{
+		"issueURL": func(issue *redmine.Issue) string {
+			if f.baseURL == "" {
+				return ""
+			}
+			return fmt.Sprintf("%s/issues/%d", f.baseURL, issue.ID)
+		},
+		"statusClass": func(issue *redmine.Issue) string {
+			return statusBadgeClass(issue.Status, f.statuses)
+		},
+		"status": func(issue *redmine.Issue) string {
+			if issue.Status.Name != "" {
+				return issue.Status.Name
+			}
+			return "未設定"
+		},
+		"assignee":     processor.GetAssignee,
+		"formatDate":   formatDate,
+		"formatHours":  func(hours float64) string { return fmt.Sprintf("%.2f", hours) },
+		"tags":         f.issueTags,
+		"customFields": f.issueCustomFields,
+		"fullMode":     func() bool { return f.mode == "full" },
+		"sortedCounts": sortedCounts,
+		"sortedHours":  stats.SortedHours,
+		"trendChart":   trendChartSVG,
+		"join":         func(sep string, ss []string) string { return strings.Join(ss, sep) },
+		"lines":        func(s string) []string { return strings.Split(strings.TrimSpace(s), "\n") },
+	}
+}
+
+// htmlField は表示用の名前と値（複数値）の組
+type htmlField struct {
+	Name   string
+	Values []string
+}
+
+// issueTags は抽出タグをTagNamesの順に返す（TagNames以外のタグは名前順で後ろに追加）
+func (f *HTMLFormatter) issueTags(issue *redmine.Issue) []htmlField {
+	var fields []htmlField
+	seen := make(map[string]bool)
+	for _, name := range f.tagNames {
+		seen[name] = true
+		if values := issue.ExtractedTags[name]; len(values) > 0 {
+			fields = append(fields, htmlField{Name: name, Values: values})
+		}
+	}
+
+	var rest []string
+	for name, values := range issue.ExtractedTags {
+		if !seen[name] && len(values) > 0 {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	for _, name := range rest {
+		fields = append(fields, htmlField{Name: name, Values: issue.ExtractedTags[name]})
+	}
+
+	return fields
+}
+
+// issueCustomFields は値が設定されているカスタムフィールドを返す（fullモードのみ）
+func (f *HTMLFormatter) issueCustomFields(issue *redmine.Issue) []htmlField {
+	if f.mode != "full" {
+		return nil
+	}
+	var fields []htmlField
+	for _, cf := range issue.CustomFields {
+		if len(cf.Values) > 0 {
+			fields = append(fields, htmlField{Name: cf.Name, Values: cf.Values})
+		}
+	}
+	return fields
+}
+
+// statusBadgeClass はステータスからバッジのCSSクラスを決める
+// 完了はステータスの一覧の is_closed（statusesがnilの場合はステータス名）で判定する
+func statusBadgeClass(status redmine.IDName, statuses *redmine.Enumerations) string {
+	switch {
+	case stats.IsClosed(statuses, status):
+		return "badge-closed"
+	case status.Name == "" || status.Name == "新規" || strings.EqualFold(status.Name, "New"):
+		return "badge-new"
+	default:
+		return "badge-open"
+	}
+}
+
+// htmlTemplate はHTML出力のテンプレート（外部ファイルに依存しないようCSSも埋め込む）
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="ja">
+<head>
+<meta charset="UTF-8">
+<title>週報 {{ .Now.Format "2006/01/02" }}</title>
+<style>
+body { font-family: "Hiragino Sans", "Meiryo", sans-serif; font-size: 14px; color: #222; margin: 24px; }
+h1 { font-size: 20px; border-bottom: 2px solid #2f5597; padding-bottom: 4px; }
+h2 { font-size: 16px; margin-top: 24px; }
+a { color: #2f5597; }
+details { margin: 8px 0; border: 1px solid #d0d7de; border-radius: 4px; }
+details > summary { cursor: pointer; padding: 6px 10px; background: #f3f6fa; font-weight: bold; }
+ul.issues { list-style: none; margin: 0; padding: 0 10px; }
+li.issue { padding: 8px 0; border-bottom: 1px solid #eee; }
+li.issue:last-child { border-bottom: none; }
+.meta { color: #555; font-size: 12px; }
+.badge { display: inline-block; padding: 1px 8px; border-radius: 10px; font-size: 12px; color: #fff; }
+.badge-new { background: #2f75b5; }
+.badge-open { background: #e08a00; }
+.badge-closed { background: #548235; }
+.summary, .description { margin: 4px 0 0 0; }
+.description { color: #444; font-size: 13px; }
+dl.tags { margin: 4px 0 0 0; }
+dl.tags dt { font-weight: bold; font-size: 12px; }
+dl.tags dd { margin: 0 0 4px 16px; }
+table.stats { border-collapse: collapse; margin: 4px 16px 12px 0; display: inline-table; vertical-align: top; }
+table.stats th, table.stats td { border: 1px solid #d0d7de; padding: 2px 8px; text-align: left; }
+table.stats th { background: #f3f6fa; }
+td.num { text-align: right; }
+svg.trend { display: block; margin: 4px 0 12px 0; }
+</style>
+</head>
+<body>
+<h1>週報 ({{ .Now.Format "2006/01/02" }})</h1>
+{{- with .Stats }}
+<section class="stats">
+<h2>統計{{ if not $.WeekStart.IsZero }}（{{ $.WeekStart.Format "2006/01/02" }} 〜 {{ $.WeekEnd.Format "2006/01/02" }}）{{ end }}</h2>
+<table class="stats">
+<tr><th>総チケット数</th><td class="num">{{ .TotalIssues }}</td></tr>
+<tr><th>新規作成</th><td class="num">{{ .NewIssues }}</td></tr>
+<tr><th>更新</th><td class="num">{{ .UpdatedIssues }}</td></tr>
+<tr><th>完了</th><td class="num">{{ .ClosedIssues }}</td></tr>
+{{- if .Transitions }}
+<tr><th>再オープン</th><td class="num">{{ len .ReopenedInPeriod }}</td></tr>
+<tr><th>着手</th><td class="num">{{ len .StartedInPeriod }}</td></tr>
+{{- end }}
+<tr><th>期限切れ</th><td class="num">{{ len .OverdueTasks }}</td></tr>
+<tr><th>期限間近（7日以内）</th><td class="num">{{ len .DueSoonTasks }}</td></tr>
+{{- with .Time }}
+<tr><th>作業時間</th><td class="num">{{ formatHours .TotalHours }}h</td></tr>
+{{- end }}
+</table>
+<table class="stats">
+<tr><th>ステータス</th><th>件数</th></tr>
+{{- range sortedCounts .ByStatus }}
+<tr><td>{{ .Name }}</td><td class="num">{{ .Count }}</td></tr>
+{{- end }}
+</table>
+<table class="stats">
+<tr><th>担当者</th><th>件数</th></tr>
+{{- range sortedCounts .ByAssignee }}
+<tr><td>{{ .Name }}</td><td class="num">{{ .Count }}</td></tr>
+{{- end }}
+</table>
+{{- with .Time }}
+<table class="stats">
+<tr><th>作業者</th><th>作業時間</th></tr>
+{{- range sortedHours .ByUser }}
+<tr><td>{{ .Name }}</td><td class="num">{{ formatHours .Hours }}h</td></tr>
+{{- end }}
+</table>
+{{- end }}
+</section>
+{{- end }}
+{{- with .Trend }}
+<section class="trend">
+<h2>週ごとの推移</h2>
+{{ trendChart . }}
+<table class="stats">
+<tr><th>週</th><th>作成</th><th>完了</th><th>未完了（週末時点）</th><th>期限切れ（週末時点）</th></tr>
+{{- range . }}
+<tr><td>{{ .Label }}</td><td class="num">{{ .Created }}</td><td class="num">{{ .Closed }}</td><td class="num">{{ .Open }}</td><td class="num">{{ .Overdue }}</td></tr>
+{{- end }}
+</table>
+</section>
+{{- end }}
+<h2>チケット</h2>
+{{- range .Issues }}
+{{- if .Children }}
+<details open>
+<summary>{{ template "link" . }} {{ .CleanedSubject }}（{{ len .Children }}件）</summary>
+<ul class="issues">
+{{- range .Children }}
+{{ template "issue" . }}
+{{- end }}
+</ul>
+</details>
+{{- else }}
+<ul class="issues">
+{{ template "issue" . }}
+</ul>
+{{- end }}
+{{- end }}
+</body>
+</html>
+{{- define "link" }}{{ with issueURL . }}<a href="{{ . }}">#{{ $.ID }}</a>{{ else }}#{{ .ID }}{{ end }}{{ end }}
+{{- define "issue" }}<li class="issue">
+<span class="badge {{ statusClass . }}">{{ status . }}</span> {{ template "link" . }} <strong>{{ .CleanedSubject }}</strong>
+<div class="meta">担当: {{ assignee . }} ／ {{ formatDate .StartDate }} 〜 {{ formatDate .DueDate }}{{ if .TimeEntries }} ／ 作業時間: {{ formatHours .SpentHours }}h{{ end }}</div>
+{{- if .Summary }}
+<p class="summary">{{ range $i, $line := lines .Summary }}{{ if $i }}<br>{{ end }}{{ $line }}{{ end }}</p>
+{{- end }}
+{{- if and fullMode .Description }}
+<p class="description">{{ range $i, $line := lines .Description }}{{ if $i }}<br>{{ end }}{{ $line }}{{ end }}</p>
+{{- end }}
+{{- with customFields . }}
+<dl class="tags">
+{{- range . }}
+<dt>{{ .Name }}</dt>
+<dd>{{ join ", " .Values }}</dd>
+{{- end }}
+</dl>
+{{- end }}
+{{- with tags . }}
+<dl class="tags">
+{{- range . }}
+<dt>{{ .Name }}</dt>
+{{- range .Values }}
+<dd>{{ . }}</dd>
+{{- end }}
+{{- end }}
+</dl>
+{{- end }}
+</li>{{ end }}
+`
@@ -0,0 +1,106 @@
+package formatter
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/tktomaru/redmine-exporter/internal/stats"
+)
+
+// 推移グラフ（SVG）の大きさと余白
+const (
+	trendChartWidth  = 640
+	trendChartHeight = 240
+	trendChartLeft   = 40 // 縦軸の目盛り
+	trendChartRight  = 16
+	trendChartTop    = 16
+	trendChartBottom = 48 // 横軸の週・凡例
+)
+
+// trendSeries は推移グラフの系列（名前・色・値）
+type trendSeries struct {
+	name  string
+	color string
+	value func(stats.WeekTrend) int
+}
+
+// trendChartSeries は推移グラフに表示する系列
+var trendChartSeries = []trendSeries{
+	{name: "作成", color: "#2f75b5", value: func(w stats.WeekTrend) int { return w.Created }},
+	{name: "完了", color: "#548235", value: func(w stats.WeekTrend) int { return w.Closed }},
+	{name: "未完了", color: "#e08a00", value: func(w stats.WeekTrend) int { return w.Open }},
+	{name: "期限切れ", color: "#c00000", value: func(w stats.WeekTrend) int { return w.Overdue }},
+}
+
+// trendChartSVG は週ごとの推移の折れ線グラフをSVGで返す（メールでも表示できるようJavaScriptは使わない）
+func trendChartSVG(trend []stats.WeekTrend) template.HTML {
+	if len(trend) == 0 {
+		return ""
+	}
+
+	maxValue := 1
+	for _, w := range trend {
+		for _, s := range trendChartSeries {
+			if v := s.value(w); v > maxValue {
+				maxValue = v
+			}
+		}
+	}
+
+	plotWidth := float64(trendChartWidth - trendChartLeft - trendChartRight)
+	plotHeight := float64(trendChartHeight - trendChartTop - trendChartBottom)
+	x := func(i int) float64 {
+		if len(trend) == 1 {
+			return trendChartLeft + plotWidth/2
+		}
+		return trendChartLeft + plotWidth*float64(i)/float64(len(trend)-1)
+	}
+	y := func(v int) float64 {
+		return trendChartTop + plotHeight*(1-float64(v)/float64(maxValue))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="trend" xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-size="11">`,
+		trendChartWidth, trendChartHeight, trendChartWidth, trendChartHeight)
+
+	// 縦軸の目盛り（0・中間・最大）
+	for i, v := range []int{0, maxValue / 2, maxValue} {
+		if i == 1 && (v == 0 || v == maxValue) {
+			continue
+		}
+		fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#d0d7de"/>`, trendChartLeft, y(v), trendChartWidth-trendChartRight, y(v))
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end" fill="#555">%d</text>`, trendChartLeft-6, y(v)+4, v)
+	}
+
+	// 横軸の週（多い場合は間引く）
+	step := (len(trend) + 11) / 12
+	for i, w := range trend {
+		if i%step != 0 && i != len(trend)-1 {
+			continue
+		}
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" text-anchor="middle" fill="#555">%s</text>`,
+			x(i), trendChartHeight-trendChartBottom+16, w.Start.Format("01/02"))
+	}
+
+	// 系列と凡例
+	for si, s := range trendChartSeries {
+		points := make([]string, len(trend))
+		var markers strings.Builder
+		for i, w := range trend {
+			px, py := x(i), y(s.value(w))
+			points[i] = fmt.Sprintf("%.1f,%.1f", px, py)
+			fmt.Fprintf(&markers, `<circle cx="%.1f" cy="%.1f" r="3" fill="%s"/>`, px, py, s.color)
+		}
+		fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" stroke-width="2" points="%s"/>`, s.color, strings.Join(points, " "))
+		b.WriteString(markers.String())
+
+		legendX := trendChartLeft + si*90
+		legendY := trendChartHeight - 10
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="10" height="10" fill="%s"/>`, legendX, legendY-9, s.color)
+		fmt.Fprintf(&b, `<text x="%d" y="%d" fill="#222">%s</text>`, legendX+14, legendY, s.name)
+	}
+
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
@@ -0,0 +1,175 @@
+package formatter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tktomaru/redmine-exporter/internal/redmine"
+	"github.com/tktomaru/redmine-exporter/internal/stats"
+)
+
+func TestHTMLFormatter(t *testing.T) {
+	tests := []struct {
+		name      string
+		baseURL   string
+		withStats bool
+		contains  []string
+		excludes  []string
+	}{
+		{
+			name:    "リンクあり",
+			baseURL: "https://redmine.example.com/",
+			contains: []string{
+				"<!DOCTYPE html>",
+				"<style>",
+				`<details open>`,
+				`<a href="https://redmine.example.com/issues/2">#2</a>`,
+				`<span class="badge badge-open">進行中</span>`,
+				"親タスクA（1件）",
+				"<dt>進捗</dt>",
+				"<dd>設計完了</dd>",
+				"&lt;script&gt;",
+			},
+			excludes: []string{`class="stats"`, "<script>"},
+		},
+		{
+			name:      "リンクなし・統計あり",
+			withStats: true,
+			contains: []string{
+				"#2 <strong>タスクB</strong>",
+				`<section class="stats">`,
+				"<tr><td>進行中</td><td class=\"num\">1</td></tr>",
+			},
+			excludes: []string{"<a href="},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roots := createTestData()
+			child := roots[0].Children[0]
+			child.ExtractedTags = map[string][]string{"進捗": {"設計完了", "<script>alert(1)</script>"}}
+
+			formatter := &HTMLFormatter{}
+			formatter.SetMode("tags", []string{"進捗"})
+			formatter.SetBaseURL(tt.baseURL)
+			if tt.withStats {
+				now := time.Now()
+				formatter.SetStats(stats.Calculate(roots, now.AddDate(0, 0, -7), now), now.AddDate(0, 0, -7), now)
+			}
+
+			var buf bytes.Buffer
+			if err := formatter.Format(roots, &buf); err != nil {
+				t.Fatalf("Format()でエラー: %v", err)
+			}
+
+			output := buf.String()
+			for _, want := range tt.contains {
+				if !strings.Contains(output, want) {
+					t.Errorf("出力に %q が含まれていない", want)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(output, unwanted) {
+					t.Errorf("出力に %q が含まれている", unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestStatusBadgeClass(t *testing.T) {
+	statuses := redmine.NewEnumerations()
+	statuses.ClosedKnown = true
+	statuses.Statuses[3] = redmine.IssueStatus{ID: 3, Name: "Resolved", IsClosed: false}
+	statuses.Statuses[6] = redmine.IssueStatus{ID: 6, Name: "却下", IsClosed: true}
+
+	tests := []struct {
+		status   redmine.IDName
+		statuses *redmine.Enumerations
+		want     string
+	}{
+		{status: redmine.IDName{Name: "新規"}, want: "badge-new"},
+		{status: redmine.IDName{}, want: "badge-new"},
+		{status: redmine.IDName{Name: "進行中"}, want: "badge-open"},
+		{status: redmine.IDName{Name: "完了"}, want: "badge-closed"},
+		{status: redmine.IDName{Name: "Closed"}, want: "badge-closed"},
+		// ステータスの一覧を取得済みの場合は is_closed で判定
+		{status: redmine.IDName{ID: 3, Name: "Resolved"}, statuses: statuses, want: "badge-open"},
+		{status: redmine.IDName{ID: 6, Name: "却下"}, statuses: statuses, want: "badge-closed"},
+	}
+
+	for _, tt := range tests {
+		if got := statusBadgeClass(tt.status, tt.statuses); got != tt.want {
+			t.Errorf("statusBadgeClass(%q) = %q; want %q", tt.status.Name, got, tt.want)
+		}
+	}
+}
+
+func TestHTMLFormatter_FullMode(t *testing.T) {
+	roots := []*redmine.Issue{{
+		ID:             5,
+		CleanedSubject: "単独タスク",
+		Description:    "1行目\n2行目",
+		CustomFields:   []redmine.CustomField{{Name: "顧客", Values: []string{"A社"}}},
+	}}
+
+	formatter := &HTMLFormatter{}
+	formatter.SetMode("full", nil)
+
+	var buf bytes.Buffer
+	if err := formatter.Format(roots, &buf); err != nil {
+		t.Fatalf("Format()でエラー: %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{"1行目<br>2行目", "<dt>顧客</dt>", "<dd>A社</dd>", "#5 <strong>単独タスク</strong>"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("出力に %q が含まれていない:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "<details") {
+		t.Error("子を持たないチケットが折りたたみセクションになっている")
+	}
+}
+
+func TestHTMLFormatter_Trend(t *testing.T) {
+	start := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	trend := []stats.WeekTrend{
+		{Period: stats.Period{Start: start, End: start.AddDate(0, 0, 7).Add(-time.Second)}, Created: 3, Closed: 1, Open: 5, Overdue: 1},
+		{Period: stats.Period{Start: start.AddDate(0, 0, 7), End: start.AddDate(0, 0, 14).Add(-time.Second)}, Created: 2, Closed: 4, Open: 3, Overdue: 0},
+	}
+
+	formatter := &HTMLFormatter{}
+	formatter.SetMode("summary", nil)
+	formatter.SetTrend(trend)
+
+	var buf bytes.Buffer
+	if err := formatter.Format(createTestData(), &buf); err != nil {
+		t.Fatalf("Format()でエラー: %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{
+		`<section class="trend">`,
+		`<svg class="trend"`,
+		`<polyline fill="none" stroke="#2f75b5"`,
+		`<text x="54" y="230" fill="#222">作成</text>`,
+		`<tr><td>01/13〜01/19</td><td class="num">2</td><td class="num">4</td><td class="num">3</td><td class="num">0</td></tr>`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("出力に %q が含まれていない", want)
+		}
+	}
+	if strings.Contains(output, "&lt;svg") {
+		t.Error("グラフのSVGがエスケープされている")
+	}
+}
+
+func TestTrendChartSVG_Empty(t *testing.T) {
+	if got := trendChartSVG(nil); got != "" {
+		t.Errorf("trendChartSVG(nil) = %q; want 空", got)
+	}
+}
@@ -0,0 +1,313 @@
+package formatter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/tktomaru/redmine-exporter/internal/processor"
+	"github.com/tktomaru/redmine-exporter/internal/redmine"
+	"github.com/tktomaru/redmine-exporter/internal/stats"
+)
+
+// JSONSchemaVersion はJSON/JSONL出力のスキーマバージョン
+// フィールドの追加ではバージョンを上げない（利用側は未知のフィールドを無視すること）
+// フィールドの削除・型や意味の変更を行う場合のみ上げる
+// 2: stats.closed_issues を「完了ステータスのチケット数」から「期間内に完了したチケット数」に変更
+const JSONSchemaVersion = "2"
+
+// JSONL出力の各行の種別（typeフィールド）
+const (
+	jsonlTypeIssue = "issue"
+	jsonlTypeStats = "stats"
+)
+
+// JSONFormatter はJSON形式（.json）またはJSON Lines形式（.jsonl）で出力
+type JSONFormatter struct {
+	lines     bool // trueの場合はJSON Lines形式（1行1チケット、親子はparent_idで表現）
+	mode      string
+	tagNames  []string
+	stats     *stats.WeeklyStats
+	weekStart time.Time
+	weekEnd   time.Time
+}
+
+// jsonDocument はJSON形式の出力全体
+type jsonDocument struct {
+	SchemaVersion string       `json:"schema_version"`
+	GeneratedAt   time.Time    `json:"generated_at"`
+	Mode          string       `json:"mode"`
+	TagNames      []string     `json:"tag_names"`
+	Issues        []*jsonIssue `json:"issues"`
+	Stats         *jsonStats   `json:"stats,omitempty"`
+}
+
+// jsonIssue は1チケット分の出力
+type jsonIssue struct {
+	Type           string                `json:"type,omitempty"`           // JSONLのみ: "issue"
+	SchemaVersion  string                `json:"schema_version,omitempty"` // JSONLのみ
+	ID             int                   `json:"id"`
+	ParentID       *int                  `json:"parent_id"`
+	Subject        string                `json:"subject"`
+	CleanedSubject string                `json:"cleaned_subject"`
+	Project        string                `json:"project"`
+	Tracker        string                `json:"tracker"`
+	Status         string                `json:"status"`
+	Priority       string                `json:"priority"`
+	Assignee       string                `json:"assignee"`
+	StartDate      *redmine.Date         `json:"start_date"`
+	DueDate        *redmine.Date         `json:"due_date"`
+	CreatedOn      *redmine.DateTime     `json:"created_on"`
+	UpdatedOn      *redmine.DateTime     `json:"updated_on"`
+	Description    string                `json:"description"`
+	Summary        string                `json:"summary"`
+	Tags           map[string][]string   `json:"tags"`
+	CustomFields   []redmine.CustomField `json:"custom_fields"`
+	SpentHours     float64               `json:"spent_hours"`
+	Journals       []jsonJournal         `json:"journals"`
+	Children       []*jsonIssue          `json:"children,omitempty"` // JSONのみ
+}
+
+// jsonJournal はコメント（フィルタ適用後）の出力
+type jsonJournal struct {
+	ID        int    `json:"id"`
+	User      string `json:"user"`
+	Notes     string `json:"notes"`
+	CreatedOn string `json:"created_on"`
+}
+
+// jsonStats は統計情報の出力（--stats / --include-metrics 指定時のみ）
+type jsonStats struct {
+	Type             string           `json:"type,omitempty"`           // JSONLのみ: "stats"
+	SchemaVersion    string           `json:"schema_version,omitempty"` // JSONLのみ
+	PeriodStart      time.Time        `json:"period_start"`
+	PeriodEnd        time.Time        `json:"period_end"`
+	TotalIssues      int              `json:"total_issues"`
+	ByStatus         map[string]int   `json:"by_status"`
+	ByAssignee       map[string]int   `json:"by_assignee"`
+	ByTracker        map[string]int   `json:"by_tracker"`
+	ByPriority       map[string]int   `json:"by_priority"`
+	NewIssues        int              `json:"new_issues"`
+	UpdatedIssues    int              `json:"updated_issues"`
+	ClosedIssues     int              `json:"closed_issues"` // 期間内に完了したチケット数（schema_version 1 では完了ステータスのチケット数）
+	ClosedIssueIDs   []int            `json:"closed_issue_ids"`
+	Transitions      bool             `json:"transitions"`        // ステータス変更から判定したか（--transitions）
+	ReopenedIssueIDs []int            `json:"reopened_issue_ids"` // transitions が false の場合は空
+	StartedIssueIDs  []int            `json:"started_issue_ids"`  // transitions が false の場合は空
+	OverdueIssueIDs  []int            `json:"overdue_issue_ids"`
+	DueSoonIssueIDs  []int            `json:"due_soon_issue_ids"`
+	Comments         jsonCommentStats `json:"comments"`
+	Time             *jsonTimeStats   `json:"time,omitempty"`
+}
+
+// jsonCommentStats はコメント統計の出力
+type jsonCommentStats struct {
+	Total              int            `json:"total"`
+	IssuesWithComments int            `json:"issues_with_comments"`
+	ByUser             map[string]int `json:"by_user"`
+}
+
+// jsonTimeStats は作業時間統計の出力
+type jsonTimeStats struct {
+	TotalHours float64            `json:"total_hours"`
+	ByIssue    map[string]float64 `json:"by_issue"` // キーはチケットID（JSONのキーは文字列のため）
+	ByUser     map[string]float64 `json:"by_user"`
+	ByAssignee map[string]float64 `json:"by_assignee"`
+	ByActivity map[string]float64 `json:"by_activity"`
+	ByProject  map[string]float64 `json:"by_project"`
+}
+
+// Format はJSON/JSON Lines形式で出力
+func (f *JSONFormatter) Format(roots []*redmine.Issue, w io.Writer) error {
+	if f.lines {
+		return f.formatLines(roots, w)
+	}
+
+	doc := jsonDocument{
+		SchemaVersion: JSONSchemaVersion,
+		GeneratedAt:   time.Now(),
+		Mode:          f.mode,
+		TagNames:      f.tagNames,
+		Issues:        make([]*jsonIssue, 0, len(roots)),
+		Stats:         f.buildStats(),
+	}
+	if doc.TagNames == nil {
+		doc.TagNames = []string{}
+	}
+	for _, root := range roots {
+		doc.Issues = append(doc.Issues, f.buildIssue(root, nil, true))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("JSON出力エラー: %w", err)
+	}
+	return nil
+}
+
+// formatLines はJSON Lines形式で出力（親→子の順に1行1チケット、統計は最終行）
+func (f *JSONFormatter) formatLines(roots []*redmine.Issue, w io.Writer) error {
+	enc := json.NewEncoder(w)
+
+	var writeIssue func(issue *redmine.Issue, parentID *int) error
+	writeIssue = func(issue *redmine.Issue, parentID *int) error {
+		record := f.buildIssue(issue, parentID, false)
+		record.Type = jsonlTypeIssue
+		record.SchemaVersion = JSONSchemaVersion
+		if err := enc.Encode(record); err != nil {
+			return fmt.Errorf("JSONL出力エラー: %w", err)
+		}
+		for _, child := range issue.Children {
+			id := issue.ID
+			if err := writeIssue(child, &id); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, root := range roots {
+		if err := writeIssue(root, nil); err != nil {
+			return err
+		}
+	}
+
+	if s := f.buildStats(); s != nil {
+		s.Type = jsonlTypeStats
+		s.SchemaVersion = JSONSchemaVersion
+		if err := enc.Encode(s); err != nil {
+			return fmt.Errorf("JSONL出力エラー: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// SetMode はモードとタグ名を設定
+func (f *JSONFormatter) SetMode(mode string, tagNames []string) {
+	f.mode = mode
+	f.tagNames = tagNames
+}
+
+// SetStats は統計情報を設定
+func (f *JSONFormatter) SetStats(stats *stats.WeeklyStats, weekStart, weekEnd time.Time) {
+	f.stats = stats
+	f.weekStart = weekStart
+	f.weekEnd = weekEnd
+}
+
+// buildIssue はチケットを出力用の構造体に変換
+// nestChildrenがtrueの場合は子チケットをchildrenに入れ子で格納
+func (f *JSONFormatter) buildIssue(issue *redmine.Issue, parentID *int, nestChildren bool) *jsonIssue {
+	ji := &jsonIssue{
+		ID:             issue.ID,
+		ParentID:       parentID,
+		Subject:        issue.Subject,
+		CleanedSubject: issue.CleanedSubject,
+		Project:        issue.Project.Name,
+		Tracker:        issue.Tracker.Name,
+		Status:         issue.Status.Name,
+		Priority:       issue.Priority.Name,
+		Assignee:       processor.GetAssignee(issue),
+		StartDate:      issue.StartDate,
+		DueDate:        issue.DueDate,
+		CreatedOn:      issue.CreatedOn,
+		UpdatedOn:      issue.UpdatedOn,
+		Description:    issue.Description,
+		Summary:        issue.Summary,
+		Tags:           issue.ExtractedTags,
+		CustomFields:   issue.CustomFields,
+		SpentHours:     issue.SpentHours(),
+		Journals:       make([]jsonJournal, 0, len(issue.Journals)),
+	}
+	if ji.ParentID == nil && issue.Parent != nil {
+		id := issue.Parent.ID
+		ji.ParentID = &id
+	}
+	if ji.Tags == nil {
+		ji.Tags = map[string][]string{}
+	}
+	if ji.CustomFields == nil {
+		ji.CustomFields = []redmine.CustomField{}
+	}
+
+	for _, j := range issue.Journals {
+		ji.Journals = append(ji.Journals, jsonJournal{
+			ID:        j.ID,
+			User:      j.User.Name,
+			Notes:     j.Notes,
+			CreatedOn: j.CreatedOn,
+		})
+	}
+
+	if nestChildren {
+		for _, child := range issue.Children {
+			id := issue.ID
+			ji.Children = append(ji.Children, f.buildIssue(child, &id, true))
+		}
+	}
+
+	return ji
+}
+
+// buildStats は統計情報を出力用の構造体に変換（未設定の場合はnil）
+func (f *JSONFormatter) buildStats() *jsonStats {
+	if f.stats == nil {
+		return nil
+	}
+
+	s := f.stats
+	js := &jsonStats{
+		PeriodStart:      f.weekStart,
+		PeriodEnd:        f.weekEnd,
+		TotalIssues:      s.TotalIssues,
+		ByStatus:         s.ByStatus,
+		ByAssignee:       s.ByAssignee,
+		ByTracker:        s.ByTracker,
+		ByPriority:       s.ByPriority,
+		NewIssues:        s.NewIssues,
+		UpdatedIssues:    s.UpdatedIssues,
+		ClosedIssues:     s.ClosedIssues,
+		ClosedIssueIDs:   issueIDs(s.ClosedInPeriod),
+		Transitions:      s.Transitions,
+		ReopenedIssueIDs: issueIDs(s.ReopenedInPeriod),
+		StartedIssueIDs:  issueIDs(s.StartedInPeriod),
+		OverdueIssueIDs:  issueIDs(s.OverdueTasks),
+		DueSoonIssueIDs:  issueIDs(s.DueSoonTasks),
+		Comments: jsonCommentStats{
+			Total:              s.CommentStats.TotalComments,
+			IssuesWithComments: s.CommentStats.IssuesWithComments,
+			ByUser:             s.CommentStats.ByUser,
+		},
+	}
+
+	if s.Time != nil {
+		byIssue := make(map[string]float64, len(s.Time.ByIssue))
+		for id, hours := range s.Time.ByIssue {
+			byIssue[fmt.Sprint(id)] = hours
+		}
+		js.Time = &jsonTimeStats{
+			TotalHours: s.Time.TotalHours,
+			ByIssue:    byIssue,
+			ByUser:     s.Time.ByUser,
+			ByAssignee: s.Time.ByAssignee,
+			ByActivity: s.Time.ByActivity,
+			ByProject:  s.Time.ByProject,
+		}
+	}
+
+	return js
+}
+
+// issueIDs はチケットのIDを昇順で返す
+func issueIDs(issues []*redmine.Issue) []int {
+	ids := make([]int, 0, len(issues))
+	for _, issue := range issues {
+		ids = append(ids, issue.ID)
+	}
+	sort.Ints(ids)
+	return ids
+}
@@ -66,6 +66,11 @@ func (f *MarkdownFormatter) printIssueDetails(w io.Writer, issue *redmine.Issue,
 		fmt.Fprintf(w, "  - **プロジェクト**: %s\n", issue.Project.Name)
 		fmt.Fprintf(w, "  - **トラッカー**: %s\n", issue.Tracker.Name)
 		fmt.Fprintf(w, "  - **優先度**: %s\n", issue.Priority.Name)
+		for _, cf := range issue.CustomFields {
+			if value := cf.Value(); value != "" {
+				fmt.Fprintf(w, "  - **%s**: %s\n", cf.Name, value)
+			}
+		}
 
 		if issue.Description != "" {
 			fmt.Fprintf(w, "  - **説明**: %s\n", issue.Description)
@@ -73,6 +78,12 @@ func (f *MarkdownFormatter) printIssueDetails(w io.Writer, issue *redmine.Issue,
 		if len(issue.Journals) > 0 {
 			fmt.Fprintf(w, "  - **コメント数**: %d\n", len(issue.Journals))
 		}
+		if len(issue.History) > 0 {
+			fmt.Fprintf(w, "  - **履歴**:\n")
+			for _, h := range issue.History {
+				fmt.Fprintf(w, "    - %s %s: %s\n", formatHistoryTime(h.CreatedOn), h.User.Name, formatChanges(h))
+			}
+		}
 		fmt.Fprintln(w)
 
 	case "tags":
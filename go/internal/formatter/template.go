@@ -3,7 +3,9 @@ package formatter
 import (
 	"fmt"
 	"io"
+	"math"
 	"os"
+	"strconv"
 	"strings"
 	"text/template"
 	"time"
@@ -75,6 +77,90 @@ func (f *TemplateFormatter) Format(roots []*redmine.Issue, w io.Writer) error {
 	return nil
 }
 
+// LintTemplate はテンプレートを読み込み、サンプルのチケット・統計で実行してエラーを返す
+// 構文エラーに加えて、存在しないフィールドの参照や関数の引数の誤りを検出する
+// （サンプルで実行されない分岐の中はチェックされない）
+func LintTemplate(tmplPath string) error {
+	f, err := NewTemplateFormatter(tmplPath)
+	if err != nil {
+		return err
+	}
+	roots, start, end := sampleIssues()
+	f.SetMode("summary", []string{"要約", "進捗"})
+	f.SetStats(stats.Calculate(roots, start, end), start, end)
+	return f.Format(roots, io.Discard)
+}
+
+// sampleIssues はテンプレートのチェック用のチケット（子チケットを持つ親と単独のチケット）と集計期間を返す
+// 日付・コメント・作業時間・タグ・カスタムフィールドなど、すべてのフィールドに値を設定する
+func sampleIssues() ([]*redmine.Issue, time.Time, time.Time) {
+	end := time.Date(2025, 1, 12, 23, 59, 59, 0, time.UTC)
+	start := end.AddDate(0, 0, -6).Truncate(24 * time.Hour)
+	date := func(days int) *redmine.Date { return &redmine.Date{Time: start.AddDate(0, 0, days)} }
+	dateTime := func(days int) *redmine.DateTime { return &redmine.DateTime{Time: start.AddDate(0, 0, days)} }
+
+	issue := func(id int, subject string) *redmine.Issue {
+		return &redmine.Issue{
+			ID:          id,
+			Project:     redmine.IDName{ID: 1, Name: "サンプル"},
+			Tracker:     redmine.IDName{ID: 1, Name: "タスク"},
+			Status:      redmine.IDName{ID: 2, Name: "進行中"},
+			Priority:    redmine.IDName{ID: 2, Name: "通常"},
+			Subject:     "[WIP] " + subject,
+			Description: "[要約]" + subject + "の要約[/要約]",
+			StartDate:   date(0),
+			DueDate:     date(4),
+			AssignedTo:  &redmine.IDName{ID: 5, Name: "田中"},
+			Journals: []redmine.Journal{{
+				ID:              id * 10,
+				User:            redmine.IDName{ID: 5, Name: "田中"},
+				Notes:           "[進捗]" + subject + "の進捗[/進捗]",
+				CreatedOn:       start.AddDate(0, 0, 2).Format(time.RFC3339),
+				Details:         []redmine.JournalDetail{{Property: "attr", Name: "status_id", OldValue: "1", NewValue: "2"}},
+				ParsedCreatedOn: dateTime(2),
+			}},
+			History: []redmine.HistoryEntry{{
+				JournalID: id * 10,
+				CreatedOn: dateTime(2),
+				User:      redmine.IDName{ID: 5, Name: "田中"},
+				Notes:     "[進捗]" + subject + "の進捗[/進捗]",
+				Changes: []redmine.Change{{
+					Detail: redmine.JournalDetail{Property: "attr", Name: "status_id", OldValue: "1", NewValue: "2"},
+					Field:  "ステータス", OldValue: "新規", NewValue: "進行中",
+				}},
+			}},
+			UpdatedOn:    dateTime(2),
+			CreatedOn:    dateTime(0),
+			CustomFields: []redmine.CustomField{{ID: 1, Name: "顧客", Values: []string{"A社"}}},
+			TimeEntries: []redmine.TimeEntry{{
+				ID: id, Project: redmine.IDName{ID: 1, Name: "サンプル"}, Issue: &redmine.IssueRef{ID: id},
+				User: redmine.IDName{ID: 5, Name: "田中"}, Activity: redmine.IDName{ID: 9, Name: "開発"},
+				Hours: 1.5, SpentOn: date(2),
+			}},
+			CleanedSubject: subject,
+			Summary:        subject + "の要約",
+			ExtractedTags:  map[string][]string{"要約": {subject + "の要約"}, "進捗": {subject + "の進捗"}},
+		}
+	}
+
+	parent := issue(1, "親チケット")
+	for _, id := range []int{2, 3} {
+		child := issue(id, fmt.Sprintf("子チケット%d", id-1))
+		child.Parent = &redmine.IssueRef{ID: parent.ID}
+		parent.Children = append(parent.Children, child)
+	}
+
+	// 単独のチケットは期間内に完了（リードタイム・サイクルタイムの集計対象）
+	single := issue(4, "単独のチケット")
+	single.Status = redmine.IDName{ID: 5, Name: "完了"}
+	single.ClosedOn = dateTime(4)
+	single.StatusChanges = []redmine.StatusChange{
+		{CreatedOn: start.AddDate(0, 0, 2), User: redmine.IDName{ID: 5, Name: "田中"}, From: redmine.IDName{ID: 1, Name: "新規"}, To: redmine.IDName{ID: 2, Name: "進行中"}},
+		{CreatedOn: start.AddDate(0, 0, 4), User: redmine.IDName{ID: 5, Name: "田中"}, From: redmine.IDName{ID: 2, Name: "進行中"}, To: redmine.IDName{ID: 5, Name: "完了"}},
+	}
+	return []*redmine.Issue{parent, single}, start, end
+}
+
 // SetMode はモードとタグ名を設定
 func (f *TemplateFormatter) SetMode(mode string, tagNames []string) {
 	f.mode = mode
@@ -205,6 +291,68 @@ func templateFuncs() template.FuncMap {
 			return len(v) > 0
 		},
 
+		// カスタムフィールドの値（名前またはIDで指定、複数値はカンマ区切り）
+		"customField": func(issue *redmine.Issue, name string) string {
+			if issue == nil {
+				return ""
+			}
+			return issue.CustomFieldValue(name)
+		},
+
+		// カスタムフィールドの値の配列（複数値フィールド用）
+		"customFieldValues": func(issue *redmine.Issue, name string) []string {
+			if issue == nil {
+				return nil
+			}
+			if cf, ok := issue.CustomField(name); ok {
+				return cf.Values
+			}
+			return nil
+		},
+
+		// チケットの作業時間合計
+		"spentHours": func(issue *redmine.Issue) float64 {
+			if issue == nil {
+				return 0
+			}
+			return issue.SpentHours()
+		},
+
+		// 作業時間の表示（小数点以下は必要な桁のみ）
+		"formatHours": func(hours float64) string {
+			return strconv.FormatFloat(math.Round(hours*100)/100, 'f', -1, 64)
+		},
+
+		// 作業時間の多い順に並べた一覧（.Stats.Time.ByUser など）
+		"sortedHours": func(m map[string]float64) []stats.HoursEntry {
+			return stats.SortedHours(m)
+		},
+
+		// 変更履歴1件分の変更内容（例: ステータス: 新規 → 進行中, 進捗率: 0% → 50%）
+		"changes": func(entry redmine.HistoryEntry) string {
+			return formatChanges(entry)
+		},
+
+		// ステータスの遷移（例: 新規→進行中 (01/12), 進行中→完了 (01/13)）
+		"statusTimeline": func(issue *redmine.Issue) string {
+			if issue == nil {
+				return ""
+			}
+			var transitions []string
+			for _, h := range issue.History {
+				c, ok := h.StatusChange()
+				if !ok {
+					continue
+				}
+				t := c.OldValue + "→" + c.NewValue
+				if h.CreatedOn != nil {
+					t += " (" + localTime(h.CreatedOn.Time).Format("01/02") + ")"
+				}
+				transitions = append(transitions, t)
+			}
+			return strings.Join(transitions, ", ")
+		},
+
 		// 文字列結合（必要なら）
 		"join": func(sep string, ss []string) string { return strings.Join(ss, sep) },
 	}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/tktomaru/redmine-exporter/internal/redmine"
+	"github.com/tktomaru/redmine-exporter/internal/stats"
 )
 
 func TestNewTemplateFormatter(t *testing.T) {
@@ -299,3 +300,166 @@ func TestTemplateFuncs_LatestComment(t *testing.T) {
 		t.Errorf("Output = %s, should contain 'Latest comment'", buf.String())
 	}
 }
+
+func TestTemplateFuncs_CustomField(t *testing.T) {
+	tmpDir := t.TempDir()
+	tmplFile := filepath.Join(tmpDir, "test.tmpl")
+
+	tmplContent := `{{ range .Issues }}{{ customField . "顧客" }}|{{ range customFieldValues . "リリース版" }}[{{ . }}]{{ end }}|{{ customField . "なし" }}{{ end }}`
+	if err := os.WriteFile(tmplFile, []byte(tmplContent), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	fmtr, err := NewTemplateFormatter(tmplFile)
+	if err != nil {
+		t.Fatalf("NewTemplateFormatter() error = %v", err)
+	}
+
+	issues := []*redmine.Issue{
+		{
+			CustomFields: []redmine.CustomField{
+				{ID: 1, Name: "顧客", Values: []string{"A社"}},
+				{ID: 2, Name: "リリース版", Multiple: true, Values: []string{"v1.0", "v1.1"}},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := fmtr.Format(issues, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	if buf.String() != "A社|[v1.0][v1.1]|" {
+		t.Errorf("Output = %q, want %q", buf.String(), "A社|[v1.0][v1.1]|")
+	}
+}
+
+func TestTemplateFuncs_StatusTimeline(t *testing.T) {
+	tmpDir := t.TempDir()
+	tmplFile := filepath.Join(tmpDir, "test.tmpl")
+
+	tmplContent := `{{ range .Issues }}#{{ .ID }}: {{ statusTimeline . }}{{ range .History }} / {{ changes . }}{{ end }};{{ end }}`
+	if err := os.WriteFile(tmplFile, []byte(tmplContent), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	fmtr, err := NewTemplateFormatter(tmplFile)
+	if err != nil {
+		t.Fatalf("NewTemplateFormatter() error = %v", err)
+	}
+
+	status := func(from, to string) redmine.Change {
+		return redmine.Change{Detail: redmine.JournalDetail{Property: "attr", Name: "status_id"}, Field: "ステータス", OldValue: from, NewValue: to}
+	}
+	at := func(day int) *redmine.DateTime {
+		return &redmine.DateTime{Time: time.Date(2025, 10, day, 1, 0, 0, 0, time.UTC)}
+	}
+	issues := []*redmine.Issue{
+		{ID: 1, History: []redmine.HistoryEntry{
+			{CreatedOn: at(10), Changes: []redmine.Change{status("新規", "進行中")}},
+			{CreatedOn: at(11), Changes: []redmine.Change{{Field: "担当者", NewValue: "山田"}}},
+			{CreatedOn: at(12), Changes: []redmine.Change{status("進行中", "完了")}},
+		}},
+		{ID: 2},
+	}
+
+	var buf bytes.Buffer
+	if err := fmtr.Format(issues, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	want := "#1: 新規→進行中 (10/10), 進行中→完了 (10/12) / ステータス: 新規 → 進行中 / 担当者: （なし） → 山田 / ステータス: 進行中 → 完了;#2: ;"
+	if buf.String() != want {
+		t.Errorf("Output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestTemplateFormatter_TimeEntries(t *testing.T) {
+	tmpDir := t.TempDir()
+	tmplFile := filepath.Join(tmpDir, "test.tmpl")
+
+	tmplContent := `{{ range .Issues }}#{{ .ID }}={{ formatHours (spentHours .) }};{{ end }}{{ with .Stats.Time }}合計={{ formatHours .TotalHours }}{{ range sortedHours .ByUser }};{{ .Name }}={{ formatHours .Hours }}{{ end }}{{ end }}`
+	if err := os.WriteFile(tmplFile, []byte(tmplContent), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	fmtr, err := NewTemplateFormatter(tmplFile)
+	if err != nil {
+		t.Fatalf("NewTemplateFormatter() error = %v", err)
+	}
+
+	issues := []*redmine.Issue{
+		{ID: 1, TimeEntries: []redmine.TimeEntry{
+			{Hours: 1.25, User: redmine.IDName{Name: "山田"}},
+			{Hours: 1.0 / 3, User: redmine.IDName{Name: "佐藤"}},
+		}},
+		{ID: 2},
+	}
+	now := time.Now()
+	fmtr.SetStats(stats.Calculate(issues, now.AddDate(0, 0, -7), now), now.AddDate(0, 0, -7), now)
+
+	var buf bytes.Buffer
+	if err := fmtr.Format(issues, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	want := "#1=1.58;#2=0;合計=1.58;山田=1.25;佐藤=0.33"
+	if buf.String() != want {
+		t.Errorf("Output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestLintTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "問題なし",
+			content: "{{ range .Issues }}{{ range .Children }}{{ .CleanedSubject }} {{ index (tagValues . \"要約\") 0 }}{{ end }}{{ end }}{{ .Stats.TotalIssues }}",
+		},
+		{
+			name:    "構文エラー",
+			content: "{{ range .Issues }}",
+			wantErr: "テンプレート読み込みエラー",
+		},
+		{
+			name:    "存在しないフィールド",
+			content: "{{ range .Issues }}{{ .Title }}{{ end }}",
+			wantErr: "can't evaluate field Title",
+		},
+		{
+			name:    "rangeの中のトップレベルのフィールド",
+			content: "{{ range .Issues }}{{ if gt (len .Journals) 0 }}{{ range .TagNames }}{{ . }}{{ end }}{{ end }}{{ end }}",
+			wantErr: "can't evaluate field TagNames",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "lint.tmpl")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("テンプレートの作成に失敗: %v", err)
+			}
+			err := LintTemplate(path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("LintTemplate() = %v; want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LintTemplate() = %v; want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// 同梱のテンプレートはすべて問題なし
+	files, _ := filepath.Glob("../../templates/*.tmpl")
+	for _, file := range files {
+		if err := LintTemplate(file); err != nil {
+			t.Errorf("%s: %v", file, err)
+		}
+	}
+}
@@ -63,6 +63,11 @@ func (f *TextFormatter) printIssueDetails(w io.Writer, issue *redmine.Issue, iss
 		fmt.Fprintf(w, "　開始日: %s\n", startDate)
 		fmt.Fprintf(w, "　終了日: %s\n", dueDate)
 		fmt.Fprintf(w, "　担当者: %s\n", assignee)
+		for _, cf := range issue.CustomFields {
+			if value := cf.Value(); value != "" {
+				fmt.Fprintf(w, "　%s: %s\n", cf.Name, value)
+			}
+		}
 		if issue.Description != "" {
 			fmt.Fprintf(w, "　説明: %s\n", issue.Description)
 		}
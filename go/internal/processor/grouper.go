@@ -1,6 +1,8 @@
 package processor
 
 import (
+	"strings"
+
 	"github.com/tktomaru/redmine-exporter/internal/redmine"
 )
 
@@ -16,7 +18,16 @@ type Grouper interface {
 }
 
 // NewGrouper は指定されたグルーピング方法に応じたGrouperを作成
+// "cf:<名前>" でカスタムフィールドの値ごとにグルーピング
 func NewGrouper(groupBy string) Grouper {
+	if strings.HasPrefix(groupBy, "cf:") {
+		name := strings.TrimSpace(strings.TrimPrefix(groupBy, "cf:"))
+		if name == "" {
+			return nil
+		}
+		return &CustomFieldGrouper{Name: name}
+	}
+
 	switch groupBy {
 	case "assignee":
 		return &AssigneeGrouper{}
@@ -171,6 +182,37 @@ func (g *PriorityGrouper) Group(issues []*redmine.Issue) *GroupedIssues {
 	return result
 }
 
+// CustomFieldGrouper はカスタムフィールドの値別にグルーピング
+// 複数値のフィールドは値の組み合わせ（カンマ区切り）を1つのグループとする
+type CustomFieldGrouper struct {
+	Name string // カスタムフィールド名（またはID）
+}
+
+func (g *CustomFieldGrouper) Group(issues []*redmine.Issue) *GroupedIssues {
+	result := &GroupedIssues{
+		Groups: make(map[string][]*redmine.Issue),
+		Keys:   []string{},
+	}
+
+	keyOrder := make(map[string]bool)
+
+	for _, issue := range issues {
+		key := issue.CustomFieldValue(g.Name)
+		if key == "" {
+			key = g.Name + "未設定"
+		}
+
+		if !keyOrder[key] {
+			result.Keys = append(result.Keys, key)
+			keyOrder[key] = true
+		}
+
+		result.Groups[key] = append(result.Groups[key], issue)
+	}
+
+	return result
+}
+
 // FlattenGroupedIssues はグルーピングされたチケットをフラットなリストに戻す
 // グループの順序とグループ内のチケットの順序を保持
 func FlattenGroupedIssues(grouped *GroupedIssues) []*redmine.Issue {
@@ -250,3 +250,37 @@ func TestFlattenGroupedIssues(t *testing.T) {
 		}
 	}
 }
+
+func TestCustomFieldGrouper_Group(t *testing.T) {
+	issues := []*redmine.Issue{
+		{ID: 1, CustomFields: []redmine.CustomField{{ID: 1, Name: "顧客", Values: []string{"A社"}}}},
+		{ID: 2, CustomFields: []redmine.CustomField{{ID: 1, Name: "顧客", Values: []string{"B社"}}}},
+		{ID: 3},
+		{ID: 4, CustomFields: []redmine.CustomField{{ID: 1, Name: "顧客", Values: []string{"A社"}}}},
+	}
+
+	grouper := NewGrouper("cf:顧客")
+	if _, ok := grouper.(*CustomFieldGrouper); !ok {
+		t.Fatalf("NewGrouper(cf:顧客) = %T; want *CustomFieldGrouper", grouper)
+	}
+
+	result := grouper.Group(issues)
+
+	wantKeys := []string{"A社", "B社", "顧客未設定"}
+	if len(result.Keys) != len(wantKeys) {
+		t.Fatalf("Keys = %v; want %v", result.Keys, wantKeys)
+	}
+	for i, key := range wantKeys {
+		if result.Keys[i] != key {
+			t.Errorf("Keys[%d] = %s; want %s", i, result.Keys[i], key)
+		}
+	}
+
+	if len(result.Groups["A社"]) != 2 {
+		t.Errorf("A社のチケット数 = %d; want 2", len(result.Groups["A社"]))
+	}
+
+	if NewGrouper("cf:") != nil {
+		t.Error("NewGrouper(cf:) はnilを返すべき")
+	}
+}
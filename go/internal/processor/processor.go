@@ -33,10 +33,9 @@ func NewProcessor(patterns []string, tagConfigs []TagConfig, mode string, prefer
 			continue
 		}
 
-		// VBA版はエラーをスキップするのでここでも同様に
+		// 不正な正規表現は起動時の設定チェック（config validate）でエラーにするため、ここではスキップのみ（VBA版と同じ動作）
 		re, err := regexp.Compile(pattern)
 		if err != nil {
-			// 不正な正規表現はスキップ（VBA版と同じ動作）
 			continue
 		}
 		regexps = append(regexps, re)
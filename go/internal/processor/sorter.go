@@ -2,6 +2,7 @@ package processor
 
 import (
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/tktomaru/redmine-exporter/internal/redmine"
@@ -15,7 +16,52 @@ type Sorter interface {
 // NewSorter は指定されたソート方法に応じたSorterを作成
 // 形式: "field" または "field:order" または "field_order"
 // 例: "updated_on", "updated_on:asc", "updated_on_desc"
+// カスタムフィールドは "cf:<名前>" または "cf:<名前>:desc"（デフォルト：昇順）
+// カンマ区切りで複数指定すると先頭のキーから順に比較する（例: "due_date,priority:desc"）
+// 未対応のキーが含まれる場合はnilを返す
 func NewSorter(sortBy string) Sorter {
+	if !strings.Contains(sortBy, ",") {
+		return newFieldSorter(strings.TrimSpace(sortBy))
+	}
+
+	var chain ChainSorter
+	for _, spec := range strings.Split(sortBy, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		sorter := newFieldSorter(spec)
+		if sorter == nil {
+			return nil
+		}
+		chain = append(chain, sorter)
+	}
+
+	switch len(chain) {
+	case 0:
+		return nil
+	case 1:
+		return chain[0]
+	}
+	return chain
+}
+
+// ChainSorter は複数のキーでソート（先頭のキーを優先し、値が同じ場合は次のキーで比較）
+type ChainSorter []Sorter
+
+func (c ChainSorter) Sort(issues []*redmine.Issue) {
+	// 各キーのソートは安定ソートのため、優先度の低いキーから順に適用する
+	for i := len(c) - 1; i >= 0; i-- {
+		c[i].Sort(issues)
+	}
+}
+
+// newFieldSorter は1つのキーの指定からSorterを作成
+func newFieldSorter(sortBy string) Sorter {
+	if strings.HasPrefix(sortBy, "cf:") {
+		return newCustomFieldSorter(strings.TrimPrefix(sortBy, "cf:"))
+	}
+
 	// コロン区切りの形式をパース (例: "updated_on:asc")
 	field := sortBy
 	order := "" // デフォルトはフィールドごとに異なる
@@ -85,7 +131,7 @@ type UpdatedOnSorter struct {
 }
 
 func (s *UpdatedOnSorter) Sort(issues []*redmine.Issue) {
-	sort.Slice(issues, func(i, j int) bool {
+	sort.SliceStable(issues, func(i, j int) bool {
 		// UpdatedOnがnilの場合は最後尾に
 		if issues[i].UpdatedOn == nil {
 			return false
@@ -107,7 +153,7 @@ type CreatedOnSorter struct {
 }
 
 func (s *CreatedOnSorter) Sort(issues []*redmine.Issue) {
-	sort.Slice(issues, func(i, j int) bool {
+	sort.SliceStable(issues, func(i, j int) bool {
 		if issues[i].CreatedOn == nil {
 			return false
 		}
@@ -128,7 +174,7 @@ type DueDateSorter struct {
 }
 
 func (s *DueDateSorter) Sort(issues []*redmine.Issue) {
-	sort.Slice(issues, func(i, j int) bool {
+	sort.SliceStable(issues, func(i, j int) bool {
 		// DueDateがnilの場合は最後尾に
 		if issues[i].DueDate == nil || issues[i].DueDate.IsZero() {
 			return false
@@ -150,7 +196,7 @@ type StartDateSorter struct {
 }
 
 func (s *StartDateSorter) Sort(issues []*redmine.Issue) {
-	sort.Slice(issues, func(i, j int) bool {
+	sort.SliceStable(issues, func(i, j int) bool {
 		if issues[i].StartDate == nil || issues[i].StartDate.IsZero() {
 			return false
 		}
@@ -171,7 +217,7 @@ type PrioritySorter struct {
 }
 
 func (s *PrioritySorter) Sort(issues []*redmine.Issue) {
-	sort.Slice(issues, func(i, j int) bool {
+	sort.SliceStable(issues, func(i, j int) bool {
 		// 優先度IDが大きいほど優先度が高いと仮定
 		if s.Desc {
 			return issues[i].Priority.ID > issues[j].Priority.ID
@@ -186,10 +232,73 @@ type IDSorter struct {
 }
 
 func (s *IDSorter) Sort(issues []*redmine.Issue) {
-	sort.Slice(issues, func(i, j int) bool {
+	sort.SliceStable(issues, func(i, j int) bool {
 		if s.Desc {
 			return issues[i].ID > issues[j].ID
 		}
 		return issues[i].ID < issues[j].ID
 	})
 }
+
+// newCustomFieldSorter は "名前" または "名前:asc/desc" からCustomFieldSorterを作成
+func newCustomFieldSorter(spec string) Sorter {
+	name := spec
+	desc := false
+	if i := strings.LastIndex(spec, ":"); i >= 0 {
+		switch strings.TrimSpace(spec[i+1:]) {
+		case "asc":
+			name = spec[:i]
+		case "desc":
+			name = spec[:i]
+			desc = true
+		}
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil
+	}
+	return &CustomFieldSorter{Name: name, Desc: desc}
+}
+
+// CustomFieldSorter はカスタムフィールドの値でソート
+// 数値の値を文字列の値より前にまとめ、数値同士は数値として、文字列同士は文字列として比較する
+// （数値と文字列が混在しても順序が一貫するように、昇順・降順とも数値が先）
+type CustomFieldSorter struct {
+	Name string // カスタムフィールド名（またはID）
+	Desc bool
+}
+
+func (s *CustomFieldSorter) Sort(issues []*redmine.Issue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		vi := issues[i].CustomFieldValue(s.Name)
+		vj := issues[j].CustomFieldValue(s.Name)
+
+		// 値が未設定の場合は最後尾に
+		if vi == "" {
+			return false
+		}
+		if vj == "" {
+			return true
+		}
+
+		ni, errI := strconv.ParseFloat(vi, 64)
+		nj, errJ := strconv.ParseFloat(vj, 64)
+		switch {
+		case errI == nil && errJ == nil:
+			if s.Desc {
+				return ni > nj
+			}
+			return ni < nj
+		case errI == nil:
+			return true
+		case errJ == nil:
+			return false
+		}
+
+		if s.Desc {
+			return vi > vj
+		}
+		return vi < vj
+	})
+}
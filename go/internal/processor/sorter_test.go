@@ -1,6 +1,7 @@
 package processor
 
 import (
+	"reflect"
 	"testing"
 	"time"
 
@@ -73,6 +74,21 @@ func TestNewSorter(t *testing.T) {
 			sortBy: "invalid",
 			want:   nil,
 		},
+		{
+			name:   "複数キー",
+			sortBy: "due_date, priority:desc",
+			want:   ChainSorter{&DueDateSorter{Desc: false}, &PrioritySorter{Desc: true}},
+		},
+		{
+			name:   "複数キー（1つだけ有効な指定）",
+			sortBy: "id:desc,",
+			want:   &IDSorter{Desc: true},
+		},
+		{
+			name:   "複数キーに未対応のキー",
+			sortBy: "due_date,invalid",
+			want:   nil,
+		},
 		{
 			name:   "empty",
 			sortBy: "",
@@ -86,6 +102,9 @@ func TestNewSorter(t *testing.T) {
 			if (got == nil) != (tt.want == nil) {
 				t.Errorf("NewSorter() = %v, want %v", got, tt.want)
 			}
+			if got != nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewSorter() = %#v, want %#v", got, tt.want)
+			}
 		})
 	}
 }
@@ -238,3 +257,133 @@ func TestIDSorter_Sort(t *testing.T) {
 		}
 	}
 }
+
+func TestCustomFieldSorter_Sort(t *testing.T) {
+	cf := func(value string) []redmine.CustomField {
+		return []redmine.CustomField{{ID: 5, Name: "工数見積", Values: []string{value}}}
+	}
+
+	tests := []struct {
+		name    string
+		sortBy  string
+		issues  []*redmine.Issue
+		wantIDs []int
+	}{
+		{
+			name:   "数値として昇順",
+			sortBy: "cf:工数見積",
+			issues: []*redmine.Issue{
+				{ID: 1, CustomFields: cf("10")},
+				{ID: 2, CustomFields: cf("2.5")},
+				{ID: 3},
+				{ID: 4, CustomFields: cf("8")},
+			},
+			wantIDs: []int{2, 4, 1, 3},
+		},
+		{
+			name:   "数値として降順",
+			sortBy: "cf:工数見積:desc",
+			issues: []*redmine.Issue{
+				{ID: 1, CustomFields: cf("10")},
+				{ID: 2, CustomFields: cf("2.5")},
+				{ID: 3},
+				{ID: 4, CustomFields: cf("8")},
+			},
+			wantIDs: []int{1, 4, 2, 3},
+		},
+		{
+			name:   "文字列として比較（IDで指定）",
+			sortBy: "cf:5:asc",
+			issues: []*redmine.Issue{
+				{ID: 1, CustomFields: cf("v2.0")},
+				{ID: 2, CustomFields: cf("v1.0")},
+				{ID: 3, CustomFields: cf("v1.5")},
+			},
+			wantIDs: []int{2, 3, 1},
+		},
+		{
+			// "9" < "10"（数値）、"10" < "1a"・"1a" < "9"（文字列）の循環にならないこと
+			name:   "数値と文字列の混在（数値が先）",
+			sortBy: "cf:工数見積",
+			issues: []*redmine.Issue{
+				{ID: 1, CustomFields: cf("1a")},
+				{ID: 2, CustomFields: cf("10")},
+				{ID: 3},
+				{ID: 4, CustomFields: cf("9")},
+				{ID: 5, CustomFields: cf("未定")},
+			},
+			wantIDs: []int{4, 2, 1, 5, 3},
+		},
+		{
+			name:   "数値と文字列の混在（降順も数値が先）",
+			sortBy: "cf:工数見積:desc",
+			issues: []*redmine.Issue{
+				{ID: 1, CustomFields: cf("9")},
+				{ID: 2, CustomFields: cf("未定")},
+				{ID: 3, CustomFields: cf("1a")},
+				{ID: 4, CustomFields: cf("10")},
+			},
+			wantIDs: []int{4, 1, 2, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorter := NewSorter(tt.sortBy)
+			if sorter == nil {
+				t.Fatalf("NewSorter(%q) = nil", tt.sortBy)
+			}
+			sorter.Sort(tt.issues)
+
+			for i, id := range tt.wantIDs {
+				if tt.issues[i].ID != id {
+					t.Errorf("issues[%d].ID = %d; want %d", i, tt.issues[i].ID, id)
+				}
+			}
+		})
+	}
+}
+
+func TestCustomFieldSorter_MixedValuesOrderIndependent(t *testing.T) {
+	// 数値と文字列が混在しても、入力の順序によらず同じ並びになること
+	values := []string{"1a", "10", "9", "b", "2"}
+	want := []string{"2", "9", "10", "1a", "b"}
+
+	for shift := range values {
+		issues := make([]*redmine.Issue, len(values))
+		for i := range values {
+			v := values[(i+shift)%len(values)]
+			issues[i] = &redmine.Issue{CustomFields: []redmine.CustomField{{ID: 5, Name: "見積", Values: []string{v}}}}
+		}
+		(&CustomFieldSorter{Name: "見積"}).Sort(issues)
+
+		for i, issue := range issues {
+			if got := issue.CustomFieldValue("見積"); got != want[i] {
+				t.Errorf("開始位置%d: [%d] = %q; want %q", shift, i, got, want[i])
+			}
+		}
+	}
+}
+
+func TestChainSorter_Sort(t *testing.T) {
+	date1 := &redmine.Date{Time: time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)}
+	date2 := &redmine.Date{Time: time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)}
+
+	issues := []*redmine.Issue{
+		{ID: 1, DueDate: date2, Priority: redmine.IDName{ID: 2}},
+		{ID: 2, DueDate: date1, Priority: redmine.IDName{ID: 1}},
+		{ID: 3, DueDate: date2, Priority: redmine.IDName{ID: 4}},
+		{ID: 4, DueDate: date1, Priority: redmine.IDName{ID: 3}},
+		{ID: 5, DueDate: nil, Priority: redmine.IDName{ID: 5}},
+	}
+
+	// 期日の昇順、同じ期日の中では優先度の降順
+	NewSorter("due_date,priority:desc").Sort(issues)
+
+	want := []int{4, 2, 3, 1, 5}
+	for i, id := range want {
+		if issues[i].ID != id {
+			t.Errorf("Sort()[%d].ID = %d, want %d", i, issues[i].ID, id)
+		}
+	}
+}
@@ -5,8 +5,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/tktomaru/redmine-exporter/internal/logger"
@@ -14,9 +18,21 @@ import (
 
 // Client はRedmine APIクライアント
 type Client struct {
-	baseURL    string
-	apiKey     string
-	httpClient *http.Client
+	baseURL     string
+	apiKey      string
+	httpClient  *http.Client
+	concurrency int                 // ジャーナル取得の並列数
+	retry       RetryPolicy         // 一時的なエラーのリトライ方針
+	sleep       func(time.Duration) // リトライ待機（テストで差し替え可能）
+	cache       IssueCache          // チケットのキャッシュ（nilは無効）
+}
+
+// IssueCache はチケットのキャッシュ
+// Get/Putはjournals付きのチケット、PutListは一覧の取得結果（オフライン実行用）を扱う
+type IssueCache interface {
+	Get(issueID int) (*Issue, bool)
+	Put(issue *Issue) error
+	PutList(scope string, issues []*Issue) error
 }
 
 // NewClient は新しいAPIクライアントを作成
@@ -27,14 +43,59 @@ func NewClient(baseURL, apiKey string) *Client {
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		concurrency: 1,
+		retry:       DefaultRetryPolicy(),
+		sleep:       time.Sleep,
 	}
 }
 
+// SetRetryPolicy はAPI呼び出しのリトライ方針を設定
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
+
+// SetConcurrency はジャーナル取得の並列数を設定（1未満は1として扱う）
+func (c *Client) SetConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+	c.concurrency = n
+}
+
+// SetCache はチケットのキャッシュを設定
+// キャッシュのupdated_onが一覧取得時のupdated_on以降であれば、journalsを再取得しない
+// 一覧の取得結果はCacheScope(filterURL)をキーに保存する
+func (c *Client) SetCache(cache IssueCache) {
+	c.cache = cache
+}
+
+// CacheScope は一覧の取得結果をキャッシュするときのキー（接続先とFilterURL）を返す
+func (c *Client) CacheScope(filterURL string) string {
+	return c.baseURL + " " + filterURL
+}
+
+// IssueError は個別チケットの取得エラー
+type IssueError struct {
+	IssueID int
+	Err     error
+}
+
+// PartialFetchError は一部のチケットでジャーナル取得に失敗したことを表す
+// FetchAllIssuesはこのエラーと一緒に取得できた分のチケットを返す
+type PartialFetchError struct {
+	Errors []IssueError // 失敗したチケット（元の並び順）
+}
+
+func (e *PartialFetchError) Error() string {
+	return fmt.Sprintf("%d件のチケットでジャーナル取得に失敗しました", len(e.Errors))
+}
+
 // FetchAllIssues は全チケットを取得（ページネーション対応）
 // VBA版のFetchAllIssues関数に相当
 //
 // Redmine APIの制限により、複数チケット取得時はinclude=journalsが機能しないため、
-// includeJournals=trueの場合は各チケットを個別に再取得します。
+// includeJournals=trueの場合は各チケットを個別に再取得します（SetConcurrencyの並列数で実行）。
+// 一部のチケットで取得に失敗した場合は、全チケットと*PartialFetchErrorを返します。
 func (c *Client) FetchAllIssues(filterURL string, includeJournals bool, dateFilter *DateFilter, progress func(current, total int)) ([]*Issue, error) {
 	const limit = 100
 	offset := 0
@@ -46,7 +107,9 @@ func (c *Client) FetchAllIssues(filterURL string, includeJournals bool, dateFilt
 	logger.Info("FilterURL: %s", filterURL)
 	logger.Info("ページサイズ: %d件", limit)
 	logger.Info("ジャーナル取得: %v", includeJournals)
-	if dateFilter != nil {
+	if dateFilter != nil && dateFilter.Start.IsZero() {
+		logger.Info("日付フィルタ: %s 〜 %s", dateFilter.Field, dateFilter.End.Format("2006/01/02 15:04:05"))
+	} else if dateFilter != nil {
 		logger.Info("日付フィルタ: %s %s 〜 %s",
 			dateFilter.Field,
 			dateFilter.Start.Format("2006/01/02 15:04:05"),
@@ -96,108 +159,335 @@ func (c *Client) FetchAllIssues(filterURL string, includeJournals bool, dateFilt
 
 	logger.Info("チケット取得完了: %d件 (%dページ)", len(allIssues), pageCount)
 
+	// 保存済みクエリ使用時はRedmineが日時フィルタを無視するため、ローカルで適用する
+	if dateFilter != nil && usesSavedQuery(filterURL) {
+		filtered := make([]*Issue, 0, len(allIssues))
+		for _, issue := range allIssues {
+			if dateFilter.Match(issue) {
+				filtered = append(filtered, issue)
+			}
+		}
+		logger.Info("保存済みクエリ: %d件中%d件が期間フィルタに一致", len(allIssues), len(filtered))
+		allIssues = filtered
+	}
+
 	// Step 2: journalsが必要な場合、各チケットを個別に再取得
 	// Redmine APIの制限: 複数チケット取得時はinclude=journalsが機能しない
+	var err error
 	if includeJournals && len(allIssues) > 0 {
-		logger.Section("ジャーナル（コメント）取得")
-		logger.Info("各チケットを個別取得中...")
-		fmt.Fprintf(os.Stderr, "[INFO] ジャーナル取得中（各チケットを個別取得）...\n")
-		journalCount := 0
-		errorCount := 0
-
-		for i, issue := range allIssues {
-			if progress != nil {
-				progress(i+1, len(allIssues))
-			}
+		err = c.fetchJournals(allIssues, progress)
+	}
 
-			// 個別チケットを取得
-			detailedIssue, err := c.FetchIssue(issue.ID)
-			if err != nil {
-				// エラーが発生してもスキップして続行
-				errorCount++
-				logger.Warn("Issue #%d のジャーナル取得失敗: %v", issue.ID, err)
-				fmt.Fprintf(os.Stderr, "[WARN] Issue #%d のジャーナル取得失敗: %v\n", issue.ID, err)
-				continue
-			}
+	// 一覧の取得結果をキャッシュ（--offline で使用）
+	if c.cache != nil {
+		if cacheErr := c.cache.PutList(c.CacheScope(filterURL), allIssues); cacheErr != nil {
+			logger.Warn("一覧のキャッシュ保存失敗: %v", cacheErr)
+		}
+	}
 
-			// journalsを既存のissueにコピー
-			allIssues[i].Journals = detailedIssue.Journals
-			journalCount += len(detailedIssue.Journals)
+	return allIssues, err
+}
+
+// fetchJournals は各チケットのjournalsをワーカープールで並列に取得
+// 結果は元のチケットにコピーするため並び順は変わらない
+// 失敗したチケットはスキップせずPartialFetchErrorとして返す
+func (c *Client) fetchJournals(issues []*Issue, progress func(current, total int)) error {
+	workers := c.concurrency
+	if workers > len(issues) {
+		workers = len(issues)
+	}
+
+	logger.Section("ジャーナル（コメント）取得")
+	logger.Info("各チケットを個別取得中... (並列数: %d)", workers)
+
+	errs := make([]error, len(issues))
+	jobs := make(chan int)
+	var cacheHits int32
+
+	// 進捗コールバックは完了件数の順に1つずつ呼び出す
+	var mu sync.Mutex
+	done := 0
+	reportDone := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		done++
+		if progress != nil {
+			progress(done, len(issues))
 		}
+	}
 
-		logger.Info("ジャーナル取得完了: %d件のジャーナル (エラー: %d件)", journalCount, errorCount)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if cached, ok := c.cachedIssue(issues[i]); ok {
+					issues[i].Journals = cached.Journals
+					atomic.AddInt32(&cacheHits, 1)
+					reportDone()
+					continue
+				}
+
+				detailedIssue, err := c.FetchIssue(issues[i].ID)
+				if err != nil {
+					errs[i] = err
+					logger.Warn("Issue #%d のジャーナル取得失敗: %v", issues[i].ID, err)
+				} else {
+					// journalsを既存のissueにコピー
+					issues[i].Journals = detailedIssue.Journals
+					if c.cache != nil {
+						if err := c.cache.Put(detailedIssue); err != nil {
+							logger.Warn("Issue #%d のキャッシュ保存失敗: %v", issues[i].ID, err)
+						}
+					}
+				}
+				reportDone()
+			}
+		}()
+	}
+
+	for i := range issues {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	journalCount := 0
+	var failed []IssueError
+	for i, issue := range issues {
+		if errs[i] != nil {
+			failed = append(failed, IssueError{IssueID: issue.ID, Err: errs[i]})
+			continue
+		}
+		journalCount += len(issue.Journals)
 	}
 
-	return allIssues, nil
+	logger.Info("ジャーナル取得完了: %d件のジャーナル (キャッシュ利用: %d件, エラー: %d件)", journalCount, cacheHits, len(failed))
+
+	if len(failed) > 0 {
+		return &PartialFetchError{Errors: failed}
+	}
+	return nil
 }
 
-// FetchIssue は単一のチケットをjournals付きで取得
-func (c *Client) FetchIssue(issueID int) (*Issue, error) {
-	url := fmt.Sprintf("%s/issues/%d.json?include=journals", c.baseURL, issueID)
+// FetchTimeEntries は期間内の作業時間を全件取得（ページネーション対応）
+// from/toは日単位で両端を含む
+// filterURLのプロジェクト（project_id, subproject_id）で絞り込む（プロジェクト指定がない場合は全プロジェクト）
+func (c *Client) FetchTimeEntries(from, to time.Time, filterURL string) ([]TimeEntry, error) {
+	const limit = 100
+	offset := 0
+	var entries []TimeEntry
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("リクエスト作成エラー: %w", err)
+	scope := timeEntryScope(filterURL)
+
+	logger.Section("作業時間取得")
+	logger.Info("期間: %s 〜 %s", from.Format("2006/01/02"), to.Format("2006/01/02"))
+	if project := scope.Get("project_id"); project != "" {
+		logger.Info("プロジェクト: %s", project)
+	} else {
+		logger.Warn("FilterURLにproject_idがないため、全プロジェクトの作業時間を取得します")
 	}
 
-	req.Header.Set("X-Redmine-API-Key", c.apiKey)
-	req.Header.Set("Accept", "application/json")
+	for {
+		params := url.Values{}
+		for key, values := range scope {
+			params[key] = values
+		}
+		params.Set("from", from.Format("2006-01-02"))
+		params.Set("to", to.Format("2006-01-02"))
+		params.Set("limit", strconv.Itoa(limit))
+		params.Set("offset", strconv.Itoa(offset))
+		requestURL := c.baseURL + "/time_entries.json?" + params.Encode()
+
+		var resp TimeEntryResponse
+		if err := c.getJSON(requestURL, &resp); err != nil {
+			return nil, err
+		}
 
-	resp, err := c.httpClient.Do(req)
+		entries = append(entries, resp.TimeEntries...)
+		logger.Debug("作業時間: %d件取得 (累計: %d/%d)", len(resp.TimeEntries), len(entries), resp.TotalCount)
+
+		if len(resp.TimeEntries) == 0 || len(entries) >= resp.TotalCount {
+			break
+		}
+		offset += limit
+	}
+
+	logger.Info("作業時間取得完了: %d件", len(entries))
+	return entries, nil
+}
+
+// timeEntryScope はFilterURLから作業時間の絞り込みに使うプロジェクトの条件を取り出す
+// サブプロジェクトの扱い（subproject_id）もチケットの取得と同じにする
+func timeEntryScope(filterURL string) url.Values {
+	scope := url.Values{}
+	u, err := url.Parse(filterURL)
 	if err != nil {
-		return nil, fmt.Errorf("HTTPリクエストエラー: %w", err)
+		return scope
 	}
-	defer resp.Body.Close()
+	query := u.Query()
+	if project := query.Get("project_id"); project != "" {
+		scope.Set("project_id", project)
+		if subproject := query.Get("subproject_id"); subproject != "" {
+			scope.Set("subproject_id", subproject)
+		}
+	}
+	return scope
+}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+// cachedIssue はキャッシュが最新であればキャッシュ済みのチケットを返す
+// 一覧取得時のupdated_onがキャッシュより新しい場合はfalse（再取得が必要）
+func (c *Client) cachedIssue(issue *Issue) (*Issue, bool) {
+	if c.cache == nil || issue.UpdatedOn == nil || issue.UpdatedOn.IsZero() {
+		return nil, false
+	}
+	cached, ok := c.cache.Get(issue.ID)
+	if !ok || cached.UpdatedOn == nil || issue.UpdatedOn.After(cached.UpdatedOn.Time) {
+		return nil, false
 	}
+	return cached, true
+}
+
+// FetchIssue は単一のチケットをjournals付きで取得
+func (c *Client) FetchIssue(issueID int) (*Issue, error) {
+	url := fmt.Sprintf("%s/issues/%d.json?include=journals", c.baseURL, issueID)
 
 	// JSONパース（単一チケットのレスポンス形式）
 	var result struct {
 		Issue *Issue `json:"issue"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("JSON解析エラー: %w", err)
+	if err := c.getJSON(url, &result); err != nil {
+		return nil, err
 	}
 
 	return result.Issue, nil
 }
 
-// buildURL はVBA版と同じロジックでURLを構築
-func (c *Client) buildURL(filterURL string, limit, offset int, includeJournals bool, dateFilter *DateFilter) string {
-	baseURL := c.baseURL + filterURL
+// ProbeIssues はFilterUrlに一致するチケットの件数と先頭のチケット（一致しない場合はnil）を返す
+// チケットは1件だけ取得する（doctorコマンドの確認用）
+func (c *Client) ProbeIssues(filterURL string) (int, *Issue, error) {
+	resp, err := c.fetch(c.buildURL(filterURL, 1, 0, false, nil))
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(resp.Issues) == 0 {
+		return resp.TotalCount, nil, nil
+	}
+	return resp.TotalCount, resp.Issues[0], nil
+}
 
-	// URLにクエリパラメータが既に含まれているかチェック
-	separator := "&"
-	if !strings.Contains(filterURL, "?") {
-		separator = "?"
+// FetchIssueJournals は単一チケットのjournalsを取得する
+// レスポンスにjournalsが含まれていたかどうか（include=journalsが有効か）も返す
+func (c *Client) FetchIssueJournals(issueID int) ([]Journal, bool, error) {
+	url := fmt.Sprintf("%s/issues/%d.json?include=journals", c.baseURL, issueID)
+
+	var result struct {
+		Issue struct {
+			Journals *[]Journal `json:"journals"`
+		} `json:"issue"`
+	}
+	if err := c.getJSON(url, &result); err != nil {
+		return nil, false, err
 	}
+	if result.Issue.Journals == nil {
+		return nil, false, nil
+	}
+	return *result.Issue.Journals, true, nil
+}
 
-	url := fmt.Sprintf("%s%slimit=%d&offset=%d", baseURL, separator, limit, offset)
+// buildURL はVBA版と同じロジックでURLを構築
+// FilterUrlにlimit/offsetや日時フィルタと同じパラメータが含まれている場合は重複させずに上書きする
+func (c *Client) buildURL(filterURL string, limit, offset int, includeJournals bool, dateFilter *DateFilter) string {
+	fb := NewFilterBuilder()
+	fb.params.Set("limit", strconv.Itoa(limit))
+	fb.params.Set("offset", strconv.Itoa(offset))
 
 	// ジャーナル（コメント）を含める場合
 	if includeJournals {
-		url += "&include=journals"
+		fb.params.Set("include", "journals")
 	}
 
 	// 日時フィルタを追加
 	if dateFilter != nil {
-		url += "&" + dateFilter.ToQueryString()
+		fb.AddDateRange(dateFilter.Field, dateFilter.Start, dateFilter.End)
+	}
+
+	requestURL, err := mergeQuery(c.baseURL+filterURL, fb.params)
+	if err != nil {
+		// 解析できないクエリはそのまま付与する（従来の動作）
+		logger.Debug("%v", err)
+		separator := "&"
+		if !strings.Contains(filterURL, "?") {
+			separator = "?"
+		}
+		requestURL = c.baseURL + filterURL + separator + fb.Build()
 	}
 
 	// デバッグ: 構築したURLを表示（APIキーは除く）
-	logger.Debug("Request URL: %s (includeJournals=%v)", url, includeJournals)
+	logger.Debug("Request URL: %s (includeJournals=%v)", requestURL, includeJournals)
 
-	return url
+	return requestURL
 }
 
-// fetch はHTTP GETリクエストを実行
+// fetch はチケット一覧のHTTP GETリクエストを実行
 func (c *Client) fetch(url string) (*APIResponse, error) {
+	var apiResp APIResponse
+	if err := c.getJSON(url, &apiResp); err != nil {
+		return nil, err
+	}
+
+	// デバッグ: レスポンスのジャーナル情報を表示
+	if len(apiResp.Issues) > 0 {
+		totalJournals := 0
+		for _, issue := range apiResp.Issues {
+			totalJournals += len(issue.Journals)
+		}
+		logger.Debug("API Response: %d issues, %d journals total",
+			len(apiResp.Issues), totalJournals)
+	}
+
+	return &apiResp, nil
+}
+
+// getJSON はGETリクエストを実行してJSONをvにデコードする
+// 一時的なエラー（429/502/503/504、接続リセットなど）はリトライ方針に従って再試行する
+func (c *Client) getJSON(url string, v interface{}) error {
+	_, err := c.getJSONHeader(url, v)
+	return err
+}
+
+// getJSONHeader はgetJSONと同じ処理を行い、レスポンスヘッダーも返す
+func (c *Client) getJSONHeader(url string, v interface{}) (http.Header, error) {
+	var body []byte
+	var header http.Header
+	var err error
+	for attempt := 0; ; attempt++ {
+		body, header, err = c.get(url)
+		if err == nil {
+			break
+		}
+		if !isRetryable(err) || attempt >= c.retry.MaxRetries {
+			return nil, err
+		}
+
+		wait := c.retry.retryWait(attempt, err)
+		logger.Warn("リクエスト失敗（%d/%d回目のリトライを%v後に実行）: %v", attempt+1, c.retry.MaxRetries, wait, err)
+		fmt.Fprintf(os.Stderr, "[WARN] リクエスト失敗、%v後にリトライします (%d/%d): %v\n", wait.Round(time.Millisecond), attempt+1, c.retry.MaxRetries, err)
+		c.sleep(wait)
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return header, fmt.Errorf("JSON解析エラー: %w", err)
+	}
+	return header, nil
+}
+
+// get はHTTP GETリクエストを1回実行してレスポンスボディとヘッダーを返す
+func (c *Client) get(url string) ([]byte, http.Header, error) {
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("リクエスト作成エラー: %w", err)
+		return nil, nil, fmt.Errorf("リクエスト作成エラー: %w", err)
 	}
 
 	// VBA版と同じヘッダーを設定
@@ -206,31 +496,24 @@ func (c *Client) fetch(url string) (*APIResponse, error) {
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("HTTPリクエストエラー: %w", err)
+		return nil, nil, fmt.Errorf("HTTPリクエストエラー: %w", &networkError{err: err})
 	}
 	defer resp.Body.Close()
 
 	// VBA版と同じエラーハンドリング
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
-	}
-
-	// JSONパース
-	var apiResp APIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return nil, fmt.Errorf("JSON解析エラー: %w", err)
-	}
-
-	// デバッグ: レスポンスのジャーナル情報を表示
-	if len(apiResp.Issues) > 0 {
-		totalJournals := 0
-		for _, issue := range apiResp.Issues {
-			totalJournals += len(issue.Journals)
+		return nil, nil, &HTTPError{
+			StatusCode: resp.StatusCode,
+			Body:       string(body),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
 		}
-		logger.Debug("API Response: %d issues, %d journals total",
-			len(apiResp.Issues), totalJournals)
 	}
 
-	return &apiResp, nil
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		// ボディ受信中の切断も通信エラーとして扱う
+		return nil, nil, fmt.Errorf("レスポンス読み込みエラー: %w", &networkError{err: err})
+	}
+	return body, resp.Header, nil
 }
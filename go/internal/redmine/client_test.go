@@ -0,0 +1,439 @@
+package redmine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newFakeRedmine はテスト用のRedmine APIサーバーを作成
+// /issues.json は指定件数のチケット一覧を、/issues/<id>.json はjournals付きの単一チケットを返す
+// failIDs に含まれるチケットの個別取得は404を返す
+func newFakeRedmine(t *testing.T, count int, failIDs map[int]bool) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Redmine-API-Key") != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case r.URL.Path == "/issues.json":
+			offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			issues := []map[string]interface{}{}
+			for id := offset + 1; id <= count && id <= offset+limit; id++ {
+				issues = append(issues, map[string]interface{}{"id": id, "subject": fmt.Sprintf("チケット%d", id)})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"issues":      issues,
+				"total_count": count,
+				"offset":      offset,
+				"limit":       limit,
+			})
+
+		case strings.HasPrefix(r.URL.Path, "/issues/"):
+			id, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/issues/"), ".json"))
+			if failIDs[id] {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"issue": map[string]interface{}{
+					"id":      id,
+					"subject": fmt.Sprintf("チケット%d", id),
+					"journals": []map[string]interface{}{
+						{"id": id * 10, "notes": fmt.Sprintf("コメント%d", id)},
+					},
+				},
+			})
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestFetchAllIssues_ConcurrentJournals(t *testing.T) {
+	tests := []struct {
+		name        string
+		count       int
+		concurrency int
+	}{
+		{name: "逐次取得", count: 5, concurrency: 1},
+		{name: "並列取得", count: 250, concurrency: 8},
+		{name: "並列数がチケット数より多い", count: 3, concurrency: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeRedmine(t, tt.count, nil)
+			defer server.Close()
+
+			client := NewClient(server.URL, "test-key")
+			client.SetConcurrency(tt.concurrency)
+
+			var mu sync.Mutex
+			var journalProgress []int
+			inJournalPhase := false
+			issues, err := client.FetchAllIssues("/issues.json?status_id=*", true, nil, func(current, total int) {
+				mu.Lock()
+				defer mu.Unlock()
+				// ジャーナル取得フェーズ（1件目の完了以降）の進捗のみ記録
+				if current == 1 && total == tt.count {
+					inJournalPhase = true
+				}
+				if inJournalPhase {
+					journalProgress = append(journalProgress, current)
+				}
+			})
+			if err != nil {
+				t.Fatalf("FetchAllIssues()でエラー: %v", err)
+			}
+
+			if len(issues) != tt.count {
+				t.Fatalf("len(issues) = %d; want %d", len(issues), tt.count)
+			}
+
+			// 元の並び順が保持されていること
+			for i, issue := range issues {
+				if issue.ID != i+1 {
+					t.Fatalf("issues[%d].ID = %d; want %d", i, issue.ID, i+1)
+				}
+				if len(issue.Journals) != 1 || issue.Journals[0].Notes != fmt.Sprintf("コメント%d", issue.ID) {
+					t.Errorf("Issue #%d のjournalsが正しくない: %+v", issue.ID, issue.Journals)
+				}
+			}
+
+			// 進捗は1件ずつ増加して最後に全件に達すること
+			if len(journalProgress) != tt.count {
+				t.Fatalf("進捗の呼び出し回数 = %d; want %d", len(journalProgress), tt.count)
+			}
+			for i, current := range journalProgress {
+				if current != i+1 {
+					t.Errorf("進捗[%d] = %d; want %d", i, current, i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchAllIssues_PartialFailure(t *testing.T) {
+	server := newFakeRedmine(t, 10, map[int]bool{3: true, 7: true})
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	client.SetConcurrency(4)
+
+	issues, err := client.FetchAllIssues("/issues.json", true, nil, nil)
+
+	var partialErr *PartialFetchError
+	if !errors.As(err, &partialErr) {
+		t.Fatalf("err = %v; want *PartialFetchError", err)
+	}
+
+	if len(issues) != 10 {
+		t.Fatalf("len(issues) = %d; want 10", len(issues))
+	}
+
+	wantIDs := []int{3, 7}
+	if len(partialErr.Errors) != len(wantIDs) {
+		t.Fatalf("失敗件数 = %d; want %d", len(partialErr.Errors), len(wantIDs))
+	}
+	for i, id := range wantIDs {
+		if partialErr.Errors[i].IssueID != id {
+			t.Errorf("Errors[%d].IssueID = %d; want %d", i, partialErr.Errors[i].IssueID, id)
+		}
+		if partialErr.Errors[i].Err == nil {
+			t.Errorf("Errors[%d].Err がnil", i)
+		}
+	}
+
+	// 失敗したチケットもリストに残り、他のチケットのjournalsは取得されていること
+	if len(issues[2].Journals) != 0 {
+		t.Errorf("失敗したIssue #3 にjournalsがある: %+v", issues[2].Journals)
+	}
+	if len(issues[3].Journals) != 1 {
+		t.Errorf("Issue #4 のjournals件数 = %d; want 1", len(issues[3].Journals))
+	}
+}
+
+func TestSetConcurrency(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+		want int
+	}{
+		{name: "通常", n: 4, want: 4},
+		{name: "ゼロは1", n: 0, want: 1},
+		{name: "負数は1", n: -3, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient("https://example.com", "key")
+			client.SetConcurrency(tt.n)
+			if client.concurrency != tt.want {
+				t.Errorf("concurrency = %d; want %d", client.concurrency, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildURL(t *testing.T) {
+	start := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		filterURL  string
+		journals   bool
+		dateFilter *DateFilter
+		want       url.Values
+	}{
+		{
+			name:      "クエリなし",
+			filterURL: "/issues.json",
+			want:      url.Values{"limit": {"100"}, "offset": {"0"}},
+		},
+		{
+			name:      "FilterUrlのlimit/offsetは重複させない",
+			filterURL: "/issues.json?project_id=1&limit=25&offset=50",
+			journals:  true,
+			want:      url.Values{"project_id": {"1"}, "limit": {"100"}, "offset": {"0"}, "include": {"journals"}},
+		},
+		{
+			name:       "日時フィルタは同じフィールドを上書き",
+			filterURL:  "/issues.json?status_id=*&updated_on=>=2025-01-01",
+			dateFilter: &DateFilter{Field: "updated_on", Start: start, End: end},
+			want:       url.Values{"status_id": {"*"}, "updated_on": {"><2026-01-05|2026-01-11"}, "limit": {"100"}, "offset": {"0"}},
+		},
+		{
+			name:       "開始なしの日時フィルタ",
+			filterURL:  "/issues.json",
+			dateFilter: &DateFilter{Field: "created_on", End: end},
+			want:       url.Values{"created_on": {"<=2026-01-11"}, "limit": {"100"}, "offset": {"0"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient("https://example.com", "key")
+			got, err := url.Parse(client.buildURL(tt.filterURL, 100, 0, tt.journals, tt.dateFilter))
+			if err != nil {
+				t.Fatalf("URL解析エラー: %v", err)
+			}
+			if got.Path != "/issues.json" {
+				t.Errorf("Path = %q; want /issues.json", got.Path)
+			}
+			if query := got.Query(); !reflect.DeepEqual(query, tt.want) {
+				t.Errorf("Query = %v; want %v", query, tt.want)
+			}
+		})
+	}
+}
+
+// memoryCache はテスト用のIssueCache
+type memoryCache struct {
+	mu     sync.Mutex
+	issues map[int]*Issue
+	lists  map[string][]int
+}
+
+func (m *memoryCache) Get(issueID int) (*Issue, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	issue, ok := m.issues[issueID]
+	return issue, ok
+}
+
+func (m *memoryCache) Put(issue *Issue) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.issues[issue.ID] = issue
+	return nil
+}
+
+func (m *memoryCache) PutList(scope string, issues []*Issue) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ids := make([]int, 0, len(issues))
+	for _, issue := range issues {
+		ids = append(ids, issue.ID)
+	}
+	if m.lists == nil {
+		m.lists = make(map[string][]int)
+	}
+	m.lists[scope] = ids
+	return nil
+}
+
+func TestFetchAllIssues_Cache(t *testing.T) {
+	listUpdatedOn := "2026-01-10T00:00:00Z"
+	var detailRequests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/issues.json":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"issues": []map[string]interface{}{
+					{"id": 1, "updated_on": listUpdatedOn},
+					{"id": 2, "updated_on": listUpdatedOn},
+				},
+				"total_count": 2,
+			})
+		case strings.HasPrefix(r.URL.Path, "/issues/"):
+			atomic.AddInt32(&detailRequests, 1)
+			id, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/issues/"), ".json"))
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"issue": map[string]interface{}{
+					"id":         id,
+					"updated_on": listUpdatedOn,
+					"journals":   []map[string]interface{}{{"id": 1, "notes": "APIから取得"}},
+				},
+			})
+		}
+	}))
+	defer server.Close()
+
+	cache := &memoryCache{issues: map[int]*Issue{
+		// #1: キャッシュが最新 → 再取得しない
+		1: {ID: 1, UpdatedOn: &DateTime{Time: time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)}, Journals: []Journal{{ID: 1, Notes: "キャッシュから取得"}}},
+		// #2: キャッシュが古い → 再取得してキャッシュを更新
+		2: {ID: 2, UpdatedOn: &DateTime{Time: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}, Journals: []Journal{{ID: 1, Notes: "古いキャッシュ"}}},
+	}}
+
+	client := NewClient(server.URL, "key")
+	client.SetCache(cache)
+
+	issues, err := client.FetchAllIssues("/issues.json", true, nil, nil)
+	if err != nil {
+		t.Fatalf("FetchAllIssues()でエラー: %v", err)
+	}
+
+	if got := atomic.LoadInt32(&detailRequests); got != 1 {
+		t.Errorf("個別取得の回数 = %d; want 1", got)
+	}
+	if issues[0].Journals[0].Notes != "キャッシュから取得" {
+		t.Errorf("#1 Notes = %q; want キャッシュから取得", issues[0].Journals[0].Notes)
+	}
+	if issues[1].Journals[0].Notes != "APIから取得" {
+		t.Errorf("#2 Notes = %q; want APIから取得", issues[1].Journals[0].Notes)
+	}
+	if cached, _ := cache.Get(2); cached.Journals[0].Notes != "APIから取得" {
+		t.Errorf("#2 のキャッシュが更新されていない: %q", cached.Journals[0].Notes)
+	}
+}
+
+func TestFetchAllIssues_CacheListWithoutJournals(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issues":      []map[string]interface{}{{"id": 3}, {"id": 1}},
+			"total_count": 2,
+		})
+	}))
+	defer server.Close()
+
+	cache := &memoryCache{issues: map[int]*Issue{}}
+	client := NewClient(server.URL, "key")
+	client.SetCache(cache)
+
+	if _, err := client.FetchAllIssues("/issues.json?project_id=1", false, nil, nil); err != nil {
+		t.Fatalf("FetchAllIssues()でエラー: %v", err)
+	}
+
+	// journalsなしの取得でも一覧をキャッシュする（--offline で使用）
+	if got := cache.lists[client.CacheScope("/issues.json?project_id=1")]; !reflect.DeepEqual(got, []int{3, 1}) {
+		t.Errorf("キャッシュした一覧 = %v; want [3 1]", got)
+	}
+	if _, ok := cache.lists[client.CacheScope("/issues.json?project_id=2")]; ok {
+		t.Error("別のFilterURLの一覧がキャッシュされている")
+	}
+}
+
+func TestFetchTimeEntries(t *testing.T) {
+	const total = 150
+	var gotFrom, gotTo string
+	var gotQuery url.Values
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/time_entries.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		gotFrom, gotTo = r.URL.Query().Get("from"), r.URL.Query().Get("to")
+		gotQuery = r.URL.Query()
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+		entries := []map[string]interface{}{}
+		for id := offset + 1; id <= total && id <= offset+limit; id++ {
+			entries = append(entries, map[string]interface{}{
+				"id":       id,
+				"issue":    map[string]interface{}{"id": id%3 + 1},
+				"user":     map[string]interface{}{"id": 1, "name": "山田"},
+				"activity": map[string]interface{}{"id": 9, "name": "開発"},
+				"hours":    0.5,
+				"spent_on": "2026-01-05",
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"time_entries": entries,
+			"total_count":  total,
+			"offset":       offset,
+			"limit":        limit,
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key")
+	from := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 1, 11, 23, 59, 59, 0, time.UTC)
+
+	entries, err := client.FetchTimeEntries(from, to, "/issues.json?project_id=web&subproject_id=!*&status_id=*")
+	if err != nil {
+		t.Fatalf("FetchTimeEntries()でエラー: %v", err)
+	}
+	if len(entries) != total {
+		t.Fatalf("len(entries) = %d; want %d", len(entries), total)
+	}
+	if gotFrom != "2026-01-05" || gotTo != "2026-01-11" {
+		t.Errorf("from/to = %s/%s; want 2026-01-05/2026-01-11", gotFrom, gotTo)
+	}
+	// プロジェクトの条件だけをFilterURLから引き継ぐ
+	if gotQuery.Get("project_id") != "web" || gotQuery.Get("subproject_id") != "!*" || gotQuery.Has("status_id") {
+		t.Errorf("Query = %v; want project_id=web, subproject_id=!*", gotQuery)
+	}
+	if entries[0].Issue == nil || entries[0].Issue.ID != 2 || entries[0].Activity.Name != "開発" || entries[0].SpentOn == nil {
+		t.Errorf("entries[0] = %+v", entries[0])
+	}
+}
+
+func TestTimeEntryScope(t *testing.T) {
+	tests := []struct {
+		filterURL string
+		want      url.Values
+	}{
+		{filterURL: "/issues.json?project_id=1&status_id=*", want: url.Values{"project_id": {"1"}}},
+		{filterURL: "/issues.json?project_id=web&subproject_id=*", want: url.Values{"project_id": {"web"}, "subproject_id": {"*"}}},
+		{filterURL: "/issues.json?status_id=open", want: url.Values{}},
+		{filterURL: "/issues.json?subproject_id=!*", want: url.Values{}},
+	}
+
+	for _, tt := range tests {
+		if got := timeEntryScope(tt.filterURL); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("timeEntryScope(%q) = %v; want %v", tt.filterURL, got, tt.want)
+		}
+	}
+}
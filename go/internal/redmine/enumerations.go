@@ -0,0 +1,146 @@
+package redmine
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/tktomaru/redmine-exporter/internal/logger"
+)
+
+// IssueStatus はRedmineのチケットのステータス（/issue_statuses.json）
+type IssueStatus struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	IsClosed bool   `json:"is_closed"` // 終了したチケットのステータス
+}
+
+// Enumerations はジャーナルの変更詳細（status_id など）のIDを名前に変換するための一覧
+// マスタAPIで取得できないユーザー・バージョンなどは、取得したチケットから補完する（Learn）
+type Enumerations struct {
+	ClosedKnown bool // ステータスの is_closed を取得済み（FetchEnumerations）
+	Statuses    map[int]IssueStatus
+	Trackers    map[int]string
+	Priorities  map[int]string
+	Users       map[int]string
+	Projects    map[int]string
+	Versions    map[int]string
+	Categories  map[int]string
+}
+
+// NewEnumerations は空の一覧を作成
+func NewEnumerations() *Enumerations {
+	return &Enumerations{
+		Statuses:   make(map[int]IssueStatus),
+		Trackers:   make(map[int]string),
+		Priorities: make(map[int]string),
+		Users:      make(map[int]string),
+		Projects:   make(map[int]string),
+		Versions:   make(map[int]string),
+		Categories: make(map[int]string),
+	}
+}
+
+// FetchEnumerations はステータス・トラッカー・優先度の一覧を取得する
+func (c *Client) FetchEnumerations() (*Enumerations, error) {
+	logger.Section("マスタ取得")
+	e := NewEnumerations()
+
+	var statuses struct {
+		IssueStatuses []IssueStatus `json:"issue_statuses"`
+	}
+	if err := c.getJSON(fmt.Sprintf("%s/issue_statuses.json", c.baseURL), &statuses); err != nil {
+		return nil, fmt.Errorf("ステータス一覧の取得に失敗: %w", err)
+	}
+	for _, s := range statuses.IssueStatuses {
+		e.Statuses[s.ID] = s
+	}
+	e.ClosedKnown = true
+
+	var trackers struct {
+		Trackers []IDName `json:"trackers"`
+	}
+	if err := c.getJSON(fmt.Sprintf("%s/trackers.json", c.baseURL), &trackers); err != nil {
+		return nil, fmt.Errorf("トラッカー一覧の取得に失敗: %w", err)
+	}
+	for _, t := range trackers.Trackers {
+		e.Trackers[t.ID] = t.Name
+	}
+
+	var priorities struct {
+		IssuePriorities []IDName `json:"issue_priorities"`
+	}
+	if err := c.getJSON(fmt.Sprintf("%s/enumerations/issue_priorities.json", c.baseURL), &priorities); err != nil {
+		return nil, fmt.Errorf("優先度一覧の取得に失敗: %w", err)
+	}
+	for _, p := range priorities.IssuePriorities {
+		e.Priorities[p.ID] = p.Name
+	}
+
+	logger.Info("ステータス: %d件, トラッカー: %d件, 優先度: %d件", len(e.Statuses), len(e.Trackers), len(e.Priorities))
+	return e, nil
+}
+
+// Learn はチケットに含まれるID+名前（ステータス・担当者・バージョンなど）を一覧に追加する
+// マスタAPIで取得済みの名前は上書きしない
+func (e *Enumerations) Learn(issues []*Issue) {
+	learn := func(m map[int]string, v *IDName) {
+		if v == nil || v.ID == 0 || v.Name == "" {
+			return
+		}
+		if _, ok := m[v.ID]; !ok {
+			m[v.ID] = v.Name
+		}
+	}
+
+	for _, issue := range issues {
+		if _, ok := e.Statuses[issue.Status.ID]; !ok && issue.Status.ID != 0 {
+			e.Statuses[issue.Status.ID] = IssueStatus{ID: issue.Status.ID, Name: issue.Status.Name}
+		}
+		learn(e.Trackers, &issue.Tracker)
+		learn(e.Priorities, &issue.Priority)
+		learn(e.Projects, &issue.Project)
+		learn(e.Users, issue.AssignedTo)
+		learn(e.Versions, issue.FixedVersion)
+		learn(e.Categories, issue.Category)
+		for i := range issue.Journals {
+			learn(e.Users, &issue.Journals[i].User)
+		}
+		for i := range issue.TimeEntries {
+			learn(e.Users, &issue.TimeEntries[i].User)
+		}
+	}
+}
+
+// StatusName はステータスIDの名前を返す（不明な場合は "#ID"）
+func (e *Enumerations) StatusName(id string) string {
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return id
+	}
+	if s, ok := e.Statuses[n]; ok && s.Name != "" {
+		return s.Name
+	}
+	return "#" + id
+}
+
+// Closed はステータスIDが終了ステータスかどうかを返す
+// ステータス一覧を取得していない場合（オフラインなど）や不明なIDはokがfalse
+func (e *Enumerations) Closed(id int) (closed, ok bool) {
+	if e == nil || !e.ClosedKnown {
+		return false, false
+	}
+	s, ok := e.Statuses[id]
+	return s.IsClosed, ok
+}
+
+// lookup はIDの名前を返す（不明な場合は "#ID"）
+func lookup(m map[int]string, id string) string {
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return id
+	}
+	if name, ok := m[n]; ok && name != "" {
+		return name
+	}
+	return "#" + id
+}
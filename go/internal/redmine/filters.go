@@ -1,15 +1,18 @@
 package redmine
 
 import (
+	"fmt"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 )
 
 // DateFilter は日時範囲でのフィルタリング条件
 type DateFilter struct {
 	Field string    // "updated_on", "created_on", "start_date", "due_date"
-	Start time.Time // 開始日時
-	End   time.Time // 終了日時
+	Start time.Time // 開始日時（ゼロ値は「終了日以前」）
+	End   time.Time // 終了日時（ゼロ値は「開始日以降」）
 }
 
 // ToQueryString はRedmine APIのクエリパラメータ文字列を生成
@@ -20,6 +23,52 @@ func (df *DateFilter) ToQueryString() string {
 	return fb.Build()
 }
 
+// Match はチケットが日時範囲に含まれるかを判定（オフライン時にAPIの代わりに使用）
+// APIの範囲指定と同じく日単位で比較し、終了日は1日全体を含む
+func (df *DateFilter) Match(issue *Issue) bool {
+	loc := df.Start.Location()
+	if df.Start.IsZero() {
+		loc = df.End.Location()
+	}
+
+	// 比較用の日付文字列（日時フィールドは期間のタイムゾーンに合わせる）
+	var day string
+	switch df.Field {
+	case "updated_on":
+		if issue.UpdatedOn != nil && !issue.UpdatedOn.IsZero() {
+			day = issue.UpdatedOn.In(loc).Format("2006-01-02")
+		}
+	case "created_on":
+		if issue.CreatedOn != nil && !issue.CreatedOn.IsZero() {
+			day = issue.CreatedOn.In(loc).Format("2006-01-02")
+		}
+	case "start_date":
+		if issue.StartDate != nil && !issue.StartDate.IsZero() {
+			day = issue.StartDate.Time.Format("2006-01-02")
+		}
+	case "due_date":
+		if issue.DueDate != nil && !issue.DueDate.IsZero() {
+			day = issue.DueDate.Time.Format("2006-01-02")
+		}
+	}
+	if day == "" {
+		return false
+	}
+
+	startStr := df.Start.Format("2006-01-02")
+	if df.End.IsZero() {
+		return day >= startStr
+	}
+	endStr := df.End.In(loc).Format("2006-01-02")
+	if df.Start.IsZero() {
+		return day <= endStr
+	}
+	if startStr > endStr {
+		startStr, endStr = endStr, startStr
+	}
+	return day >= startStr && day <= endStr
+}
+
 // FilterBuilder はRedmine APIのクエリパラメータを構築する
 type FilterBuilder struct {
 	params url.Values
@@ -36,6 +85,12 @@ func NewFilterBuilder() *FilterBuilder {
 // Redmine REST API: field=><YYYY-MM-DD|YYYY-MM-DD（範囲）
 // 例: created_on=%3E%3C2012-03-01|2012-03-07 :contentReference[oaicite:3]{index=3}
 func (fb *FilterBuilder) AddDateRange(field string, start, end time.Time) {
+	// start がゼロなら「以前」だけ（<=）
+	if start.IsZero() {
+		fb.params.Set(field, "<="+end.Format("2006-01-02"))
+		return
+	}
+
 	startStr := start.Format("2006-01-02")
 
 	// end がゼロなら「以降」だけ（>=）
@@ -55,7 +110,171 @@ func (fb *FilterBuilder) AddDateRange(field string, start, end time.Time) {
 	fb.params.Set(field, "><"+startStr+"|"+endStr)
 }
 
+// ステータス・担当者などに指定できるID以外の値
+const (
+	StatusOpen   = "open"   // 未完了のステータス
+	StatusClosed = "closed" // 完了のステータス
+	StatusAll    = "*"      // すべてのステータス
+	UserMe       = "me"     // APIキーのユーザー自身
+)
+
+// pagingParams はクライアントがページネーション時に付与するパラメータ（FilterUrlの指定は無視する）
+var pagingParams = []string{"limit", "offset"}
+
+// SetProject はプロジェクト（IDまたは識別子）で絞り込む
+// includeSubprojectsがfalseの場合はサブプロジェクトのチケットを除外する
+func (fb *FilterBuilder) SetProject(project string, includeSubprojects bool) error {
+	project = strings.TrimSpace(project)
+	if project == "" {
+		return fmt.Errorf("プロジェクトが指定されていません")
+	}
+	fb.params.Set("project_id", project)
+	if includeSubprojects {
+		fb.params.Set("subproject_id", "*")
+	} else {
+		fb.params.Set("subproject_id", "!*")
+	}
+	return nil
+}
+
+// SetTrackers はトラッカーIDで絞り込む（複数指定はいずれかに一致）
+func (fb *FilterBuilder) SetTrackers(ids ...string) error {
+	return fb.setIDs("tracker_id", "トラッカー", ids)
+}
+
+// SetStatus はステータスで絞り込む
+// open（未完了）, closed（完了）, *（すべて）のいずれか、またはステータスIDを指定する
+func (fb *FilterBuilder) SetStatus(values ...string) error {
+	values = trimValues(values)
+	if len(values) == 1 {
+		switch values[0] {
+		case StatusOpen, StatusClosed, StatusAll:
+			fb.params.Set("status_id", values[0])
+			return nil
+		}
+	}
+	if err := fb.setIDs("status_id", "ステータス", values); err != nil {
+		return fmt.Errorf("%w (%s, %s, %s またはIDを指定)", err, StatusOpen, StatusClosed, StatusAll)
+	}
+	return nil
+}
+
+// SetAssignees は担当者（ユーザーIDまたはme）で絞り込む
+func (fb *FilterBuilder) SetAssignees(ids ...string) error {
+	return fb.setIDs("assigned_to_id", "担当者", ids, UserMe)
+}
+
+// SetVersions は対象バージョンIDで絞り込む
+func (fb *FilterBuilder) SetVersions(ids ...string) error {
+	return fb.setIDs("fixed_version_id", "対象バージョン", ids)
+}
+
+// SetCategories はカテゴリIDで絞り込む
+func (fb *FilterBuilder) SetCategories(ids ...string) error {
+	return fb.setIDs("category_id", "カテゴリ", ids)
+}
+
+// SetAuthors は作成者（ユーザーIDまたはme）で絞り込む
+func (fb *FilterBuilder) SetAuthors(ids ...string) error {
+	return fb.setIDs("author_id", "作成者", ids, UserMe)
+}
+
+// SetCustomField はカスタムフィールドの値で絞り込む（複数指定はいずれかに一致）
+// Redmine REST API: cf_<カスタムフィールドID>=値
+func (fb *FilterBuilder) SetCustomField(id int, values ...string) error {
+	if id <= 0 {
+		return fmt.Errorf("カスタムフィールドIDは1以上を指定してください: %d", id)
+	}
+	values = trimValues(values)
+	if len(values) == 0 {
+		return fmt.Errorf("カスタムフィールド %d の値が指定されていません", id)
+	}
+	fb.params.Set(fmt.Sprintf("cf_%d", id), strings.Join(values, "|"))
+	return nil
+}
+
+// setIDs はIDのリストをパラメータに設定（複数の場合は|区切り）
+// keywordsに含まれる値（meなど）はIDの代わりに指定できる
+func (fb *FilterBuilder) setIDs(key, label string, ids []string, keywords ...string) error {
+	ids = trimValues(ids)
+	if len(ids) == 0 {
+		return fmt.Errorf("%sが指定されていません", label)
+	}
+	for _, id := range ids {
+		if isKeyword(id, keywords) {
+			continue
+		}
+		if n, err := strconv.Atoi(id); err != nil || n <= 0 {
+			return fmt.Errorf("%sのIDが不正です: %s", label, id)
+		}
+	}
+	fb.params.Set(key, strings.Join(ids, "|"))
+	return nil
+}
+
+// isKeyword は値がキーワードのいずれかに一致するかを判定
+func isKeyword(value string, keywords []string) bool {
+	for _, k := range keywords {
+		if value == k {
+			return true
+		}
+	}
+	return false
+}
+
+// trimValues は値をトリムして空の値を除く
+func trimValues(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// Clone は同じ条件を持つFilterBuilderを返す（プロファイルごとに条件を追加する場合に使用）
+func (fb *FilterBuilder) Clone() *FilterBuilder {
+	params := make(url.Values, len(fb.params))
+	for key, v := range fb.params {
+		params[key] = append([]string(nil), v...)
+	}
+	return &FilterBuilder{params: params}
+}
+
+// IsEmpty は条件が1つも設定されていないかを判定
+func (fb *FilterBuilder) IsEmpty() bool {
+	return len(fb.params) == 0
+}
+
 // Build はクエリパラメータ文字列を返す
 func (fb *FilterBuilder) Build() string {
 	return fb.params.Encode()
 }
+
+// Merge は既存のFilterUrl（パス＋クエリ）に条件を追加したFilterUrlを返す
+// 同じパラメータはFilterBuilderの条件で上書きし、limit/offsetはクライアントが付与するため取り除く
+func (fb *FilterBuilder) Merge(filterURL string) (string, error) {
+	return mergeQuery(filterURL, fb.params, pagingParams...)
+}
+
+// mergeQuery はURL（パス＋クエリ）のクエリにparamsを上書きで追加し、dropのパラメータを取り除く
+func mergeQuery(rawURL string, params url.Values, drop ...string) (string, error) {
+	path, query, _ := strings.Cut(rawURL, "?")
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return "", fmt.Errorf("FilterUrlのクエリが不正です: %w", err)
+	}
+
+	for key, v := range params {
+		values[key] = v
+	}
+	for _, key := range drop {
+		values.Del(key)
+	}
+
+	if len(values) == 0 {
+		return path, nil
+	}
+	return path + "?" + values.Encode(), nil
+}
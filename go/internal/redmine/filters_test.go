@@ -0,0 +1,258 @@
+package redmine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDateFilterMatch(t *testing.T) {
+	jst, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("タイムゾーン読み込みエラー: %v", err)
+	}
+
+	// 2026/01/05(月) 〜 2026/01/11(日) JST
+	start := time.Date(2026, 1, 5, 0, 0, 0, 0, jst)
+	end := time.Date(2026, 1, 11, 23, 59, 59, 0, jst)
+
+	tests := []struct {
+		name   string
+		filter DateFilter
+		issue  *Issue
+		want   bool
+	}{
+		{
+			name:   "更新日時が期間内",
+			filter: DateFilter{Field: "updated_on", Start: start, End: end},
+			issue:  &Issue{UpdatedOn: &DateTime{Time: time.Date(2026, 1, 7, 3, 0, 0, 0, time.UTC)}},
+			want:   true,
+		},
+		{
+			name:   "UTCでは前日だがJSTでは開始日",
+			filter: DateFilter{Field: "updated_on", Start: start, End: end},
+			issue:  &Issue{UpdatedOn: &DateTime{Time: time.Date(2026, 1, 4, 16, 0, 0, 0, time.UTC)}},
+			want:   true,
+		},
+		{
+			name:   "終了日の夜",
+			filter: DateFilter{Field: "updated_on", Start: start, End: end},
+			issue:  &Issue{UpdatedOn: &DateTime{Time: time.Date(2026, 1, 11, 14, 0, 0, 0, time.UTC)}},
+			want:   true,
+		},
+		{
+			name:   "期間外",
+			filter: DateFilter{Field: "updated_on", Start: start, End: end},
+			issue:  &Issue{UpdatedOn: &DateTime{Time: time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC)}},
+			want:   false,
+		},
+		{
+			name:   "作成日時",
+			filter: DateFilter{Field: "created_on", Start: start, End: end},
+			issue:  &Issue{CreatedOn: &DateTime{Time: time.Date(2026, 1, 6, 0, 0, 0, 0, time.UTC)}},
+			want:   true,
+		},
+		{
+			name:   "期日",
+			filter: DateFilter{Field: "due_date", Start: start, End: end},
+			issue:  &Issue{DueDate: &Date{Time: time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC)}},
+			want:   true,
+		},
+		{
+			name:   "開始日が未設定",
+			filter: DateFilter{Field: "start_date", Start: start, End: end},
+			issue:  &Issue{},
+			want:   false,
+		},
+		{
+			name:   "終了なし（以降）",
+			filter: DateFilter{Field: "updated_on", Start: start},
+			issue:  &Issue{UpdatedOn: &DateTime{Time: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)}},
+			want:   true,
+		},
+		{
+			name:   "開始なし（以前）",
+			filter: DateFilter{Field: "created_on", End: end},
+			issue:  &Issue{CreatedOn: &DateTime{Time: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}},
+			want:   true,
+		},
+		{
+			name:   "開始なし（終了日より後）",
+			filter: DateFilter{Field: "created_on", End: end},
+			issue:  &Issue{CreatedOn: &DateTime{Time: time.Date(2026, 1, 11, 15, 0, 0, 0, time.UTC)}},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(tt.issue); got != tt.want {
+				t.Errorf("Match() = %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterBuilder(t *testing.T) {
+	tests := []struct {
+		name    string
+		build   func(fb *FilterBuilder) error
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "プロジェクト（サブプロジェクトを含む）",
+			build: func(fb *FilterBuilder) error { return fb.SetProject("myproj", true) },
+			want:  "project_id=myproj&subproject_id=%2A",
+		},
+		{
+			name:  "プロジェクト（サブプロジェクトを除く）",
+			build: func(fb *FilterBuilder) error { return fb.SetProject("1", false) },
+			want:  "project_id=1&subproject_id=%21%2A",
+		},
+		{
+			name:  "トラッカー複数",
+			build: func(fb *FilterBuilder) error { return fb.SetTrackers("1", " 2 ") },
+			want:  "tracker_id=1%7C2",
+		},
+		{
+			name:  "ステータス open",
+			build: func(fb *FilterBuilder) error { return fb.SetStatus("open") },
+			want:  "status_id=open",
+		},
+		{
+			name:  "ステータス *",
+			build: func(fb *FilterBuilder) error { return fb.SetStatus("*") },
+			want:  "status_id=%2A",
+		},
+		{
+			name:  "ステータスID",
+			build: func(fb *FilterBuilder) error { return fb.SetStatus("3", "5") },
+			want:  "status_id=3%7C5",
+		},
+		{
+			name:    "ステータス不正",
+			build:   func(fb *FilterBuilder) error { return fb.SetStatus("done") },
+			wantErr: true,
+		},
+		{
+			name:  "担当者 me",
+			build: func(fb *FilterBuilder) error { return fb.SetAssignees("me") },
+			want:  "assigned_to_id=me",
+		},
+		{
+			name:    "担当者 不正",
+			build:   func(fb *FilterBuilder) error { return fb.SetAssignees("yamada") },
+			wantErr: true,
+		},
+		{
+			name: "バージョン・カテゴリ・作成者",
+			build: func(fb *FilterBuilder) error {
+				if err := fb.SetVersions("10"); err != nil {
+					return err
+				}
+				if err := fb.SetCategories("4"); err != nil {
+					return err
+				}
+				return fb.SetAuthors("me", "7")
+			},
+			want: "author_id=me%7C7&category_id=4&fixed_version_id=10",
+		},
+		{
+			name:    "バージョン 空",
+			build:   func(fb *FilterBuilder) error { return fb.SetVersions(" ") },
+			wantErr: true,
+		},
+		{
+			name:  "カスタムフィールド",
+			build: func(fb *FilterBuilder) error { return fb.SetCustomField(3, "A社", "B社") },
+			want:  "cf_3=A%E7%A4%BE%7CB%E7%A4%BE",
+		},
+		{
+			name:    "カスタムフィールドID不正",
+			build:   func(fb *FilterBuilder) error { return fb.SetCustomField(0, "A") },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := NewFilterBuilder()
+			err := tt.build(fb)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("エラーが返されなかった (%s)", fb.Build())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if got := fb.Build(); got != tt.want {
+				t.Errorf("Build() = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterBuilderMerge(t *testing.T) {
+	tests := []struct {
+		name      string
+		filterURL string
+		build     func(fb *FilterBuilder) error
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "条件なし",
+			filterURL: "/issues.json",
+			build:     func(fb *FilterBuilder) error { return nil },
+			want:      "/issues.json",
+		},
+		{
+			name:      "既存のクエリに追加",
+			filterURL: "/issues.json?project_id=1&sort=updated_on:desc",
+			build:     func(fb *FilterBuilder) error { return fb.SetStatus("open") },
+			want:      "/issues.json?project_id=1&sort=updated_on%3Adesc&status_id=open",
+		},
+		{
+			name:      "同じパラメータは上書き",
+			filterURL: "/issues.json?status_id=*&tracker_id=2",
+			build:     func(fb *FilterBuilder) error { return fb.SetStatus("closed") },
+			want:      "/issues.json?status_id=closed&tracker_id=2",
+		},
+		{
+			name:      "limit/offsetは取り除く",
+			filterURL: "/issues.json?limit=25&offset=50&project_id=1",
+			build:     func(fb *FilterBuilder) error { return fb.SetAssignees("me") },
+			want:      "/issues.json?assigned_to_id=me&project_id=1",
+		},
+		{
+			name:      "不正なクエリ",
+			filterURL: "/issues.json?a=%zz",
+			build:     func(fb *FilterBuilder) error { return nil },
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := NewFilterBuilder()
+			if err := tt.build(fb); err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			got, err := fb.Merge(tt.filterURL)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("エラーが返されなかった (%s)", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Merge() = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package redmine
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxChangeValueLength は変更履歴の表示で省略せずに表示する値の最大文字数
+const maxChangeValueLength = 40
+
+// attrLabels はジャーナルの変更詳細（property: attr）の項目名
+var attrLabels = map[string]string{
+	"project_id":       "プロジェクト",
+	"tracker_id":       "トラッカー",
+	"subject":          "題名",
+	"description":      "説明",
+	"status_id":        "ステータス",
+	"priority_id":      "優先度",
+	"assigned_to_id":   "担当者",
+	"author_id":        "作成者",
+	"category_id":      "カテゴリ",
+	"fixed_version_id": "対象バージョン",
+	"parent_id":        "親チケット",
+	"start_date":       "開始日",
+	"due_date":         "期日",
+	"done_ratio":       "進捗率",
+	"estimated_hours":  "予定工数",
+	"is_private":       "プライベート",
+}
+
+// relationLabels は関連（property: relation）の種類
+var relationLabels = map[string]string{
+	"relates":     "関連",
+	"blocks":      "ブロック先",
+	"blocked":     "ブロック元",
+	"duplicates":  "重複先",
+	"duplicated":  "重複元",
+	"precedes":    "後続",
+	"follows":     "先行",
+	"copied_to":   "コピー先",
+	"copied_from": "コピー元",
+}
+
+// Change はジャーナルの変更詳細1件（IDを名前に変換済み）
+type Change struct {
+	Detail   JournalDetail // 元の変更詳細
+	Field    string        // 項目名（例: ステータス）
+	OldValue string        // 変更前の値（例: 新規、値がない場合は空）
+	NewValue string        // 変更後の値
+}
+
+// String は「ステータス: 新規 → 進行中」の形式で返す
+// 長い値・複数行の値（説明など）は省略する
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s → %s", c.Field, shortenValue(c.OldValue), shortenValue(c.NewValue))
+}
+
+// IsStatus はステータスの変更かどうかを返す
+func (c Change) IsStatus() bool {
+	return c.Detail.Property == "attr" && c.Detail.Name == "status_id"
+}
+
+// shortenValue は変更履歴の表示用に値を1行に縮める（空の場合は「（なし）」）
+func shortenValue(v string) string {
+	if v == "" {
+		return "（なし）"
+	}
+	line, _, multiLine := strings.Cut(v, "\n")
+	line = strings.TrimSpace(line)
+	if utf8.RuneCountInString(line) > maxChangeValueLength {
+		return string([]rune(line)[:maxChangeValueLength]) + "…"
+	}
+	if multiLine {
+		return line + "…"
+	}
+	return line
+}
+
+// HistoryEntry はチケットの変更履歴1件（ジャーナル1件分）
+type HistoryEntry struct {
+	JournalID int
+	CreatedOn *DateTime // 日時（不明な場合はnil）
+	User      IDName
+	Notes     string // 同じジャーナルのコメント（空可）
+	Changes   []Change
+}
+
+// StatusChange はステータスの変更を返す（ステータスを変更していない場合はfalse）
+func (h HistoryEntry) StatusChange() (Change, bool) {
+	for _, c := range h.Changes {
+		if c.IsStatus() {
+			return c, true
+		}
+	}
+	return Change{}, false
+}
+
+// StatusChange はジャーナルのステータス変更
+type StatusChange struct {
+	CreatedOn time.Time
+	User      IDName
+	From      IDName // 変更前のステータス（名前が不明な場合は "#ID"）
+	To        IDName
+}
+
+// StatusChanges はチケットのジャーナルからステータスの変更を古い順に抽出する
+// 日時を解析できないジャーナルは除外する
+func (e *Enumerations) StatusChanges(issue *Issue) []StatusChange {
+	var changes []StatusChange
+	for i := range issue.Journals {
+		j := &issue.Journals[i]
+		for _, d := range j.Details {
+			if d.Property != "attr" || d.Name != "status_id" {
+				continue
+			}
+			createdOn, err := time.Parse(time.RFC3339, j.CreatedOn)
+			if err != nil {
+				continue
+			}
+			from, _ := strconv.Atoi(d.OldValue)
+			to, _ := strconv.Atoi(d.NewValue)
+			changes = append(changes, StatusChange{
+				CreatedOn: createdOn,
+				User:      j.User,
+				From:      IDName{ID: from, Name: e.StatusName(d.OldValue)},
+				To:        IDName{ID: to, Name: e.StatusName(d.NewValue)},
+			})
+		}
+	}
+	sort.SliceStable(changes, func(a, b int) bool { return changes[a].CreatedOn.Before(changes[b].CreatedOn) })
+	return changes
+}
+
+// ResolveDetail はジャーナルの変更詳細の項目名・IDを表示用の名前に変換する
+// 一覧にないIDは "#ID" とする
+func (e *Enumerations) ResolveDetail(issue *Issue, d JournalDetail) Change {
+	c := Change{Detail: d, Field: d.Name, OldValue: d.OldValue, NewValue: d.NewValue}
+	resolve := func(f func(string) string) {
+		if c.OldValue != "" {
+			c.OldValue = f(c.OldValue)
+		}
+		if c.NewValue != "" {
+			c.NewValue = f(c.NewValue)
+		}
+	}
+
+	switch d.Property {
+	case "attr":
+		if label, ok := attrLabels[d.Name]; ok {
+			c.Field = label
+		}
+		switch d.Name {
+		case "status_id":
+			resolve(e.StatusName)
+		case "tracker_id":
+			resolve(func(id string) string { return lookup(e.Trackers, id) })
+		case "priority_id":
+			resolve(func(id string) string { return lookup(e.Priorities, id) })
+		case "assigned_to_id", "author_id":
+			resolve(func(id string) string { return lookup(e.Users, id) })
+		case "project_id":
+			resolve(func(id string) string { return lookup(e.Projects, id) })
+		case "fixed_version_id":
+			resolve(func(id string) string { return lookup(e.Versions, id) })
+		case "category_id":
+			resolve(func(id string) string { return lookup(e.Categories, id) })
+		case "parent_id":
+			resolve(func(id string) string { return "#" + id })
+		case "start_date", "due_date":
+			resolve(func(v string) string {
+				if t, err := time.Parse("2006-01-02", v); err == nil {
+					return t.Format("2006/01/02")
+				}
+				return v
+			})
+		case "done_ratio":
+			resolve(func(v string) string { return v + "%" })
+		case "estimated_hours":
+			resolve(func(v string) string { return v + "h" })
+		case "is_private":
+			resolve(func(v string) string {
+				if v == "1" || v == "true" {
+					return "はい"
+				}
+				return "いいえ"
+			})
+		}
+	case "cf":
+		c.Field = "カスタムフィールド#" + d.Name
+		for _, cf := range issue.CustomFields {
+			if strconv.Itoa(cf.ID) == d.Name {
+				c.Field = cf.Name
+				break
+			}
+		}
+	case "attachment":
+		c.Field = "添付ファイル"
+	case "relation":
+		c.Field = "関連"
+		if label, ok := relationLabels[d.Name]; ok {
+			c.Field = "関連（" + label + "）"
+		}
+		resolve(func(id string) string { return "#" + id })
+	}
+	return c
+}
+
+// BuildHistory はチケットのジャーナルから変更履歴（変更詳細のあるジャーナルのみ）を作成する
+// from・toがゼロ値でない場合は、その期間内のジャーナルのみを対象とする
+func (e *Enumerations) BuildHistory(issue *Issue, from, to time.Time) []HistoryEntry {
+	var history []HistoryEntry
+	for i := range issue.Journals {
+		j := &issue.Journals[i]
+		if len(j.Details) == 0 {
+			continue
+		}
+		createdOn := j.ParsedCreatedOn
+		if createdOn == nil {
+			if t, err := time.Parse(time.RFC3339, j.CreatedOn); err == nil {
+				createdOn = &DateTime{Time: t}
+			}
+		}
+		if createdOn != nil {
+			if !from.IsZero() && createdOn.Time.Before(from) {
+				continue
+			}
+			if !to.IsZero() && createdOn.Time.After(to) {
+				continue
+			}
+		}
+
+		entry := HistoryEntry{JournalID: j.ID, CreatedOn: createdOn, User: j.User, Notes: j.Notes}
+		for _, d := range j.Details {
+			entry.Changes = append(entry.Changes, e.ResolveDetail(issue, d))
+		}
+		history = append(history, entry)
+	}
+	return history
+}
@@ -0,0 +1,169 @@
+package redmine
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newEnumerationServer はステータス・トラッカー・優先度の一覧を返すテスト用サーバーを作成
+func newEnumerationServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/issue_statuses.json":
+			json.NewEncoder(w).Encode(map[string]interface{}{"issue_statuses": []map[string]interface{}{
+				{"id": 1, "name": "新規", "is_closed": false},
+				{"id": 2, "name": "進行中", "is_closed": false},
+				{"id": 5, "name": "完了", "is_closed": true},
+			}})
+		case "/trackers.json":
+			json.NewEncoder(w).Encode(map[string]interface{}{"trackers": []map[string]interface{}{
+				{"id": 1, "name": "バグ"}, {"id": 2, "name": "機能"},
+			}})
+		case "/enumerations/issue_priorities.json":
+			json.NewEncoder(w).Encode(map[string]interface{}{"issue_priorities": []map[string]interface{}{
+				{"id": 2, "name": "通常"}, {"id": 3, "name": "高め"},
+			}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchEnumerations(t *testing.T) {
+	server := newEnumerationServer(t)
+	client := NewClient(server.URL, "key")
+
+	e, err := client.FetchEnumerations()
+	if err != nil {
+		t.Fatalf("FetchEnumerations()でエラー: %v", err)
+	}
+	if closed, ok := e.Closed(5); e.StatusName("5") != "完了" || !closed || !ok {
+		t.Errorf("Statuses = %v", e.Statuses)
+	}
+	if closed, ok := e.Closed(2); closed || !ok {
+		t.Errorf("Closed(2) = %v, %v; want false, true", closed, ok)
+	}
+	if _, ok := e.Closed(9); ok {
+		t.Error("不明なステータスでClosed()のokがtrue")
+	}
+	if _, ok := NewEnumerations().Closed(5); ok {
+		t.Error("ステータス一覧を取得していないのにClosed()のokがtrue")
+	}
+	if e.Trackers[2] != "機能" || e.Priorities[3] != "高め" {
+		t.Errorf("Trackers = %v, Priorities = %v", e.Trackers, e.Priorities)
+	}
+	if e.StatusName("9") != "#9" {
+		t.Errorf("StatusName(9) = %q; want #9", e.StatusName("9"))
+	}
+}
+
+func TestResolveDetail(t *testing.T) {
+	e := NewEnumerations()
+	e.Statuses[1] = IssueStatus{ID: 1, Name: "新規"}
+	e.Statuses[2] = IssueStatus{ID: 2, Name: "進行中"}
+	e.Priorities[3] = "高め"
+	e.Learn([]*Issue{{
+		ID:           1,
+		Status:       IDName{ID: 5, Name: "完了"},
+		AssignedTo:   &IDName{ID: 7, Name: "鈴木"},
+		FixedVersion: &IDName{ID: 4, Name: "v1.0"},
+		Journals:     []Journal{{User: IDName{ID: 8, Name: "田中"}}},
+	}})
+	issue := &Issue{CustomFields: []CustomField{{ID: 3, Name: "顧客"}}}
+
+	tests := []struct {
+		name   string
+		detail JournalDetail
+		want   string
+	}{
+		{"ステータス", JournalDetail{Property: "attr", Name: "status_id", OldValue: "2", NewValue: "5"}, "ステータス: 進行中 → 完了"},
+		{"担当者の設定", JournalDetail{Property: "attr", Name: "assigned_to_id", NewValue: "7"}, "担当者: （なし） → 鈴木"},
+		{"不明なユーザー", JournalDetail{Property: "attr", Name: "assigned_to_id", OldValue: "8", NewValue: "99"}, "担当者: 田中 → #99"},
+		{"進捗率", JournalDetail{Property: "attr", Name: "done_ratio", OldValue: "0", NewValue: "50"}, "進捗率: 0% → 50%"},
+		{"優先度", JournalDetail{Property: "attr", Name: "priority_id", OldValue: "2", NewValue: "3"}, "優先度: #2 → 高め"},
+		{"対象バージョン", JournalDetail{Property: "attr", Name: "fixed_version_id", NewValue: "4"}, "対象バージョン: （なし） → v1.0"},
+		{"期日", JournalDetail{Property: "attr", Name: "due_date", OldValue: "2025-01-10", NewValue: "2025-01-17"}, "期日: 2025/01/10 → 2025/01/17"},
+		{"説明（複数行）", JournalDetail{Property: "attr", Name: "description", OldValue: "1行目\n2行目", NewValue: "新しい説明"}, "説明: 1行目… → 新しい説明"},
+		{"カスタムフィールド", JournalDetail{Property: "cf", Name: "3", OldValue: "A社", NewValue: "B社"}, "顧客: A社 → B社"},
+		{"不明なカスタムフィールド", JournalDetail{Property: "cf", Name: "9", NewValue: "x"}, "カスタムフィールド#9: （なし） → x"},
+		{"添付ファイル", JournalDetail{Property: "attachment", Name: "12", NewValue: "log.txt"}, "添付ファイル: （なし） → log.txt"},
+		{"関連", JournalDetail{Property: "relation", Name: "blocks", NewValue: "42"}, "関連（ブロック先）: （なし） → #42"},
+		{"未知の項目", JournalDetail{Property: "attr", Name: "foo", OldValue: "a", NewValue: "b"}, "foo: a → b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := e.ResolveDetail(issue, tt.detail).String(); got != tt.want {
+				t.Errorf("ResolveDetail() = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildHistory(t *testing.T) {
+	e := NewEnumerations()
+	e.Statuses[1] = IssueStatus{ID: 1, Name: "新規"}
+	e.Statuses[2] = IssueStatus{ID: 2, Name: "進行中"}
+
+	issue := &Issue{ID: 1, Journals: []Journal{
+		{ID: 1, CreatedOn: "2025-01-06T10:00:00Z", Details: []JournalDetail{{Property: "attr", Name: "status_id", OldValue: "1", NewValue: "2"}}},
+		{ID: 2, CreatedOn: "2025-01-07T10:00:00Z", Notes: "コメントのみ"},
+		{ID: 3, CreatedOn: "2025-01-14T10:00:00Z", Notes: "担当変更", Details: []JournalDetail{
+			{Property: "attr", Name: "done_ratio", OldValue: "0", NewValue: "30"},
+		}},
+	}}
+
+	history := e.BuildHistory(issue, time.Time{}, time.Time{})
+	if len(history) != 2 || history[0].JournalID != 1 || history[1].JournalID != 3 {
+		t.Fatalf("BuildHistory() = %+v; want journals 1, 3", history)
+	}
+	if history[1].Notes != "担当変更" || history[1].CreatedOn == nil {
+		t.Errorf("history[1] = %+v", history[1])
+	}
+	if c, ok := history[0].StatusChange(); !ok || c.OldValue != "新規" || c.NewValue != "進行中" {
+		t.Errorf("StatusChange() = %+v, %v", c, ok)
+	}
+	if _, ok := history[1].StatusChange(); ok {
+		t.Error("進捗率のみの変更でStatusChange()がtrue")
+	}
+
+	// 期間内のジャーナルのみ
+	from := time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 19, 23, 59, 59, 0, time.UTC)
+	history = e.BuildHistory(issue, from, to)
+	if len(history) != 1 || history[0].JournalID != 3 {
+		t.Errorf("期間指定のBuildHistory() = %+v; want journal 3", history)
+	}
+}
+
+func TestStatusChanges(t *testing.T) {
+	e := NewEnumerations()
+	e.Statuses[1] = IssueStatus{ID: 1, Name: "新規"}
+	e.Statuses[2] = IssueStatus{ID: 2, Name: "進行中"}
+
+	issue := &Issue{ID: 1, Journals: []Journal{
+		{ID: 2, CreatedOn: "2025-01-08T10:00:00Z", User: IDName{ID: 5, Name: "田中"}, Details: []JournalDetail{
+			{Property: "attr", Name: "done_ratio", OldValue: "0", NewValue: "50"},
+			{Property: "attr", Name: "status_id", OldValue: "2", NewValue: "5"},
+		}},
+		{ID: 1, CreatedOn: "2025-01-06T10:00:00Z", Details: []JournalDetail{{Property: "attr", Name: "status_id", OldValue: "1", NewValue: "2"}}},
+		{ID: 3, CreatedOn: "不正な日時", Details: []JournalDetail{{Property: "attr", Name: "status_id", OldValue: "5", NewValue: "2"}}},
+	}}
+
+	changes := e.StatusChanges(issue)
+	if len(changes) != 2 {
+		t.Fatalf("StatusChanges() = %+v; want 2件", changes)
+	}
+	if changes[0].From.Name != "新規" || changes[0].To.ID != 2 {
+		t.Errorf("changes[0] = %+v; want 新規 → 進行中", changes[0])
+	}
+	if changes[1].From.ID != 2 || changes[1].To != (IDName{ID: 5, Name: "#5"}) || changes[1].User.Name != "田中" {
+		t.Errorf("changes[1] = %+v; want 進行中 → #5（田中）", changes[1])
+	}
+}
@@ -1,6 +1,9 @@
 package redmine
 
 import (
+	"encoding/json"
+	"fmt"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -15,26 +18,201 @@ type APIResponse struct {
 
 // Issue はRedmineのチケット
 type Issue struct {
-	ID          int        `json:"id"`
-	Project     IDName     `json:"project"`
-	Tracker     IDName     `json:"tracker"`
-	Status      IDName     `json:"status"`
-	Priority    IDName     `json:"priority"`
-	Subject     string     `json:"subject"`
-	Description string     `json:"description"`
-	StartDate   *Date      `json:"start_date"`
-	DueDate     *Date      `json:"due_date"`
-	AssignedTo  *IDName    `json:"assigned_to"`
-	Parent      *IssueRef  `json:"parent"`
-	Journals    []Journal  `json:"journals"`
-	UpdatedOn   *DateTime  `json:"updated_on"` // 更新日時（週報機能用）
-	CreatedOn   *DateTime  `json:"created_on"` // 作成日時（週報機能用）
+	ID           int           `json:"id"`
+	Project      IDName        `json:"project"`
+	Tracker      IDName        `json:"tracker"`
+	Status       IDName        `json:"status"`
+	Priority     IDName        `json:"priority"`
+	Subject      string        `json:"subject"`
+	Description  string        `json:"description"`
+	StartDate    *Date         `json:"start_date"`
+	DueDate      *Date         `json:"due_date"`
+	AssignedTo   *IDName       `json:"assigned_to"`
+	FixedVersion *IDName       `json:"fixed_version"` // 対象バージョン
+	Category     *IDName       `json:"category"`
+	Parent       *IssueRef     `json:"parent"`
+	Journals     []Journal     `json:"journals"`
+	UpdatedOn    *DateTime     `json:"updated_on"` // 更新日時（週報機能用）
+	CreatedOn    *DateTime     `json:"created_on"` // 作成日時（週報機能用）
+	ClosedOn     *DateTime     `json:"closed_on"`  // 最後に終了ステータスにした日時（未終了の場合はnil）
+	CustomFields []CustomField `json:"custom_fields"`
+	TimeEntries  []TimeEntry   `json:"-"` // 期間内の作業時間（AttachTimeEntriesで設定）
 
 	// 処理用フィールド（APIレスポンスには含まれない）
 	CleanedSubject string              `json:"-"`
 	Summary        string              `json:"-"`
 	ExtractedTags  map[string][]string `json:"-"` // タグ名 -> 抽出内容の配列（複数値対応）
 	Children       []*Issue            `json:"-"`
+	History        []HistoryEntry      `json:"-"` // 変更履歴（BuildHistoryで設定）
+	StatusChanges  []StatusChange      `json:"-"` // ステータスの変更（StatusChangesで設定）
+}
+
+// SpentHours は紐付いた作業時間の合計を返す
+func (i *Issue) SpentHours() float64 {
+	total := 0.0
+	for _, te := range i.TimeEntries {
+		total += te.Hours
+	}
+	return total
+}
+
+// CustomFieldValue は名前（またはID）で指定したカスタムフィールドの値を返す
+// 複数値の場合はカンマ区切りで連結し、見つからない場合は空文字列を返す
+func (i *Issue) CustomFieldValue(name string) string {
+	if cf, ok := i.CustomField(name); ok {
+		return cf.Value()
+	}
+	return ""
+}
+
+// CustomField は名前（またはID）でカスタムフィールドを検索
+func (i *Issue) CustomField(name string) (CustomField, bool) {
+	for _, cf := range i.CustomFields {
+		if cf.Name == name || strconv.Itoa(cf.ID) == name {
+			return cf, true
+		}
+	}
+	return CustomField{}, false
+}
+
+// CustomField はチケットのカスタムフィールド
+// Redmineは単一値を文字列、複数値（multiple=true）を配列で返すため、どちらもValuesに格納する
+type CustomField struct {
+	ID       int
+	Name     string
+	Multiple bool
+	Values   []string
+}
+
+// customFieldJSON はカスタムフィールドのJSON表現
+type customFieldJSON struct {
+	ID       int             `json:"id"`
+	Name     string          `json:"name"`
+	Multiple bool            `json:"multiple,omitempty"`
+	Value    json.RawMessage `json:"value"`
+}
+
+// UnmarshalJSON は単一値（文字列）と複数値（配列）の両方をパース
+func (cf *CustomField) UnmarshalJSON(b []byte) error {
+	var raw customFieldJSON
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	cf.ID = raw.ID
+	cf.Name = raw.Name
+	cf.Multiple = raw.Multiple
+	cf.Values = nil
+
+	if len(raw.Value) == 0 || string(raw.Value) == "null" {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(raw.Value, &value); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		cf.Multiple = true
+		for _, item := range v {
+			if s := customFieldString(item); s != "" {
+				cf.Values = append(cf.Values, s)
+			}
+		}
+	default:
+		if s := customFieldString(v); s != "" {
+			cf.Values = []string{s}
+		}
+	}
+	return nil
+}
+
+// MarshalJSON はRedmineと同じ形式（単一値は文字列、複数値は配列）で出力
+func (cf CustomField) MarshalJSON() ([]byte, error) {
+	var value interface{}
+	if cf.Multiple {
+		values := cf.Values
+		if values == nil {
+			values = []string{}
+		}
+		value = values
+	} else {
+		value = cf.Value()
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(customFieldJSON{
+		ID:       cf.ID,
+		Name:     cf.Name,
+		Multiple: cf.Multiple,
+		Value:    b,
+	})
+}
+
+// Value は値をカンマ区切りで返す
+func (cf CustomField) Value() string {
+	return strings.Join(cf.Values, ", ")
+}
+
+// customFieldString はカスタムフィールドの値を文字列に変換
+func customFieldString(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// TimeEntryResponse はRedmine API /time_entries.jsonのレスポンス
+type TimeEntryResponse struct {
+	TimeEntries []TimeEntry `json:"time_entries"`
+	TotalCount  int         `json:"total_count"`
+	Offset      int         `json:"offset"`
+	Limit       int         `json:"limit"`
+}
+
+// TimeEntry は作業時間の記録
+type TimeEntry struct {
+	ID       int       `json:"id"`
+	Project  IDName    `json:"project"`
+	Issue    *IssueRef `json:"issue"` // チケットに紐付かない作業時間はnil
+	User     IDName    `json:"user"`
+	Activity IDName    `json:"activity"`
+	Hours    float64   `json:"hours"`
+	Comments string    `json:"comments"`
+	SpentOn  *Date     `json:"spent_on"`
+}
+
+// AttachTimeEntries は作業時間を対応するチケットのTimeEntriesに設定し、紐付けた件数を返す
+// 対象チケットに含まれない作業時間は無視する
+func AttachTimeEntries(issues []*Issue, entries []TimeEntry) int {
+	byID := make(map[int]*Issue, len(issues))
+	for _, issue := range issues {
+		issue.TimeEntries = nil
+		byID[issue.ID] = issue
+	}
+
+	attached := 0
+	for _, te := range entries {
+		if te.Issue == nil {
+			continue
+		}
+		if issue, ok := byID[te.Issue.ID]; ok {
+			issue.TimeEntries = append(issue.TimeEntries, te)
+			attached++
+		}
+	}
+	return attached
 }
 
 // IDName はID+名前を持つRedmineオブジェクト
@@ -50,10 +228,10 @@ type IssueRef struct {
 
 // Journal はチケットのコメント（更新履歴）
 type Journal struct {
-	ID        int     `json:"id"`
-	User      IDName  `json:"user"`
-	Notes     string  `json:"notes"`
-	CreatedOn string  `json:"created_on"`
+	ID        int             `json:"id"`
+	User      IDName          `json:"user"`
+	Notes     string          `json:"notes"`
+	CreatedOn string          `json:"created_on"`
 	Details   []JournalDetail `json:"details"`
 
 	// 処理用フィールド（APIレスポンスには含まれない）
@@ -102,6 +280,14 @@ func (d *Date) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// MarshalJSON はRedmineと同じYYYY-MM-DD形式で出力（キャッシュの読み書き用）
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.Time.IsZero() {
+		return []byte("null"), nil
+	}
+	return []byte(`"` + d.Time.Format("2006-01-02") + `"`), nil
+}
+
 // Format は日付を指定フォーマットで返す（VBA版互換）
 func (d *Date) Format() string {
 	if d == nil || d.Time.IsZero() {
@@ -132,6 +318,14 @@ func (dt *DateTime) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// MarshalJSON はISO 8601形式で出力（キャッシュの読み書き用）
+func (dt DateTime) MarshalJSON() ([]byte, error) {
+	if dt.Time.IsZero() {
+		return []byte("null"), nil
+	}
+	return []byte(`"` + dt.Time.Format(time.RFC3339) + `"`), nil
+}
+
 // Format は日時を指定フォーマットで返す
 func (dt *DateTime) Format() string {
 	if dt == nil || dt.Time.IsZero() {
@@ -187,3 +187,111 @@ func TestAPIResponseUnmarshal(t *testing.T) {
 		t.Errorf("Issues[1].Subject = %q; want 'チケット2'", resp.Issues[1].Subject)
 	}
 }
+
+func TestCustomFieldUnmarshal(t *testing.T) {
+	jsonData := `{
+		"id": 1,
+		"custom_fields": [
+			{"id": 1, "name": "顧客", "value": "A社"},
+			{"id": 2, "name": "リリース版", "multiple": true, "value": ["v1.0", "v1.1"]},
+			{"id": 3, "name": "工数見積", "value": ""},
+			{"id": 4, "name": "備考", "value": null}
+		]
+	}`
+
+	var issue Issue
+	if err := json.Unmarshal([]byte(jsonData), &issue); err != nil {
+		t.Fatalf("Unmarshal()でエラー: %v", err)
+	}
+
+	if len(issue.CustomFields) != 4 {
+		t.Fatalf("CustomFields length = %d; want 4", len(issue.CustomFields))
+	}
+
+	tests := []struct {
+		name      string
+		want      string
+		wantMulti bool
+	}{
+		{name: "顧客", want: "A社"},
+		{name: "リリース版", want: "v1.0, v1.1", wantMulti: true},
+		{name: "2", want: "v1.0, v1.1", wantMulti: true}, // IDでも参照可能
+		{name: "工数見積", want: ""},
+		{name: "備考", want: ""},
+		{name: "存在しない", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := issue.CustomFieldValue(tt.name); got != tt.want {
+				t.Errorf("CustomFieldValue(%q) = %q; want %q", tt.name, got, tt.want)
+			}
+			if cf, ok := issue.CustomField(tt.name); ok && cf.Multiple != tt.wantMulti {
+				t.Errorf("CustomField(%q).Multiple = %v; want %v", tt.name, cf.Multiple, tt.wantMulti)
+			}
+		})
+	}
+}
+
+func TestCustomFieldMarshalRoundTrip(t *testing.T) {
+	original := []CustomField{
+		{ID: 1, Name: "顧客", Values: []string{"A社"}},
+		{ID: 2, Name: "リリース版", Multiple: true, Values: []string{"v1.0", "v1.1"}},
+		{ID: 3, Name: "工数見積"},
+	}
+
+	data, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("Marshal()でエラー: %v", err)
+	}
+
+	want := `[{"id":1,"name":"顧客","value":"A社"},{"id":2,"name":"リリース版","multiple":true,"value":["v1.0","v1.1"]},{"id":3,"name":"工数見積","value":""}]`
+	if string(data) != want {
+		t.Errorf("Marshal() = %s; want %s", data, want)
+	}
+
+	var decoded []CustomField
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal()でエラー: %v", err)
+	}
+	for i := range original {
+		if decoded[i].Value() != original[i].Value() || decoded[i].Multiple != original[i].Multiple {
+			t.Errorf("decoded[%d] = %+v; want %+v", i, decoded[i], original[i])
+		}
+	}
+}
+
+func TestAttachTimeEntries(t *testing.T) {
+	issues := []*Issue{
+		{ID: 1},
+		{ID: 2, TimeEntries: []TimeEntry{{ID: 99, Hours: 10}}}, // 前回の紐付けは置き換える
+	}
+	entries := []TimeEntry{
+		{ID: 1, Issue: &IssueRef{ID: 1}, Hours: 1.5},
+		{ID: 2, Issue: &IssueRef{ID: 1}, Hours: 2},
+		{ID: 3, Issue: &IssueRef{ID: 2}, Hours: 0.25},
+		{ID: 4, Issue: &IssueRef{ID: 5}, Hours: 8}, // 対象外のチケット
+		{ID: 5, Hours: 3}, // チケットに紐付かない作業時間
+	}
+
+	if got := AttachTimeEntries(issues, entries); got != 3 {
+		t.Errorf("AttachTimeEntries() = %d; want 3", got)
+	}
+
+	tests := []struct {
+		issue     *Issue
+		wantCount int
+		wantHours float64
+	}{
+		{issue: issues[0], wantCount: 2, wantHours: 3.5},
+		{issue: issues[1], wantCount: 1, wantHours: 0.25},
+	}
+	for _, tt := range tests {
+		if len(tt.issue.TimeEntries) != tt.wantCount {
+			t.Errorf("#%d len(TimeEntries) = %d; want %d", tt.issue.ID, len(tt.issue.TimeEntries), tt.wantCount)
+		}
+		if got := tt.issue.SpentHours(); got != tt.wantHours {
+			t.Errorf("#%d SpentHours() = %v; want %v", tt.issue.ID, got, tt.wantHours)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package redmine
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/tktomaru/redmine-exporter/internal/logger"
+)
+
+// QueryResponse はRedmine API /queries.jsonのレスポンス
+type QueryResponse struct {
+	Queries    []Query `json:"queries"`
+	TotalCount int     `json:"total_count"`
+	Offset     int     `json:"offset"`
+	Limit      int     `json:"limit"`
+}
+
+// Query はRedmineの保存済みクエリ（カスタムクエリ）
+type Query struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	IsPublic  bool   `json:"is_public"`
+	ProjectID *int   `json:"project_id"` // 全プロジェクト共通のクエリはnil
+}
+
+// String はクエリの表示用文字列（エラーメッセージ用）
+func (q Query) String() string {
+	if q.ProjectID == nil {
+		return fmt.Sprintf("#%d %s（全プロジェクト）", q.ID, q.Name)
+	}
+	return fmt.Sprintf("#%d %s（プロジェクト %d）", q.ID, q.Name, *q.ProjectID)
+}
+
+// FetchQueries は参照可能な保存済みクエリを全件取得（ページネーション対応）
+func (c *Client) FetchQueries() ([]Query, error) {
+	const limit = 100
+	offset := 0
+	var queries []Query
+
+	logger.Section("保存済みクエリ取得")
+
+	for {
+		url := fmt.Sprintf("%s/queries.json?limit=%d&offset=%d", c.baseURL, limit, offset)
+
+		var resp QueryResponse
+		if err := c.getJSON(url, &resp); err != nil {
+			return nil, err
+		}
+
+		queries = append(queries, resp.Queries...)
+		logger.Debug("保存済みクエリ: %d件取得 (累計: %d/%d)", len(resp.Queries), len(queries), resp.TotalCount)
+
+		if len(resp.Queries) == 0 || len(queries) >= resp.TotalCount {
+			break
+		}
+		offset += limit
+	}
+
+	logger.Info("保存済みクエリ取得完了: %d件", len(queries))
+	return queries, nil
+}
+
+// ResolveQuery は保存済みクエリをIDまたは名前で検索する
+func (c *Client) ResolveQuery(spec string) (*Query, error) {
+	queries, err := c.FetchQueries()
+	if err != nil {
+		return nil, fmt.Errorf("保存済みクエリ取得エラー: %w", err)
+	}
+	return FindQuery(queries, spec)
+}
+
+// FindQuery はクエリの一覧からIDまたは名前に一致するクエリを返す
+// 数値の場合はID、それ以外は名前（完全一致）で検索し、同名のクエリが複数ある場合はエラーとする
+func FindQuery(queries []Query, spec string) (*Query, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("クエリが指定されていません")
+	}
+
+	if id, err := strconv.Atoi(spec); err == nil {
+		for i := range queries {
+			if queries[i].ID == id {
+				return &queries[i], nil
+			}
+		}
+		return nil, fmt.Errorf("保存済みクエリが見つかりません: ID %d", id)
+	}
+
+	var matches []*Query
+	for i := range queries {
+		if queries[i].Name == spec {
+			matches = append(matches, &queries[i])
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		names := make([]string, 0, len(queries))
+		for _, q := range queries {
+			names = append(names, q.String())
+		}
+		return nil, fmt.Errorf("保存済みクエリが見つかりません: %s (参照可能なクエリ: %s)", spec, strings.Join(names, ", "))
+	case 1:
+		return matches[0], nil
+	}
+
+	candidates := make([]string, 0, len(matches))
+	for _, q := range matches {
+		candidates = append(candidates, q.String())
+	}
+	return nil, fmt.Errorf("同名の保存済みクエリが複数あります: %s (IDで指定してください: %s)", spec, strings.Join(candidates, ", "))
+}
+
+// SetQuery は保存済みクエリでチケットを取得する
+// プロジェクトのクエリの場合はproject_idも設定する（--projectなどで指定済みの場合はそちらを優先）
+func (fb *FilterBuilder) SetQuery(q *Query) {
+	fb.params.Set("query_id", strconv.Itoa(q.ID))
+	if q.ProjectID != nil && fb.params.Get("project_id") == "" {
+		fb.params.Set("project_id", strconv.Itoa(*q.ProjectID))
+	}
+}
+
+// usesSavedQuery はFilterUrlが保存済みクエリ（query_id）を使用しているかを判定
+// query_idを指定するとRedmineはクエリ以外の絞り込み条件（日時範囲など）を無視する
+func usesSavedQuery(filterURL string) bool {
+	_, query, _ := strings.Cut(filterURL, "?")
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return false
+	}
+	return values.Get("query_id") != ""
+}
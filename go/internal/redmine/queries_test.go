@@ -0,0 +1,139 @@
+package redmine
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFindQuery(t *testing.T) {
+	project := 5
+	queries := []Query{
+		{ID: 1, Name: "今週の対応中", ProjectID: &project},
+		{ID: 2, Name: "期限切れ"},
+		{ID: 3, Name: "重複"},
+		{ID: 4, Name: "重複", ProjectID: &project},
+	}
+
+	tests := []struct {
+		name    string
+		spec    string
+		wantID  int
+		wantErr bool
+	}{
+		{name: "名前で検索", spec: "今週の対応中", wantID: 1},
+		{name: "前後の空白は無視", spec: " 期限切れ ", wantID: 2},
+		{name: "IDで検索", spec: "4", wantID: 4},
+		{name: "存在しない名前", spec: "来週", wantErr: true},
+		{name: "存在しないID", spec: "99", wantErr: true},
+		{name: "同名のクエリが複数", spec: "重複", wantErr: true},
+		{name: "空", spec: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindQuery(queries, tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("エラーが返されなかった (%v)", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if got.ID != tt.wantID {
+				t.Errorf("ID = %d; want %d", got.ID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestFilterBuilder_SetQuery(t *testing.T) {
+	project := 5
+
+	tests := []struct {
+		name    string
+		project string
+		query   Query
+		want    string
+	}{
+		{name: "全プロジェクト共通のクエリ", query: Query{ID: 2}, want: "query_id=2"},
+		{name: "プロジェクトのクエリ", query: Query{ID: 1, ProjectID: &project}, want: "project_id=5&query_id=1"},
+		{name: "プロジェクト指定済み", project: "myproj", query: Query{ID: 1, ProjectID: &project}, want: "project_id=myproj&query_id=1&subproject_id=%2A"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := NewFilterBuilder()
+			if tt.project != "" {
+				fb.SetProject(tt.project, true)
+			}
+			fb.SetQuery(&tt.query)
+			if got := fb.Build(); got != tt.want {
+				t.Errorf("Build() = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveQuery(t *testing.T) {
+	// 2ページに分かれたクエリ一覧
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/queries.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		queries := []map[string]interface{}{{"id": 1, "name": "期限切れ", "is_public": true}}
+		if r.URL.Query().Get("offset") != "0" {
+			queries = []map[string]interface{}{{"id": 7, "name": "今週の対応中", "is_public": true, "project_id": 3}}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"queries": queries, "total_count": 2})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key")
+	query, err := client.ResolveQuery("今週の対応中")
+	if err != nil {
+		t.Fatalf("ResolveQuery()でエラー: %v", err)
+	}
+	if query.ID != 7 || query.ProjectID == nil || *query.ProjectID != 3 {
+		t.Errorf("クエリ = %v", query)
+	}
+}
+
+func TestFetchAllIssues_SavedQueryDateFilter(t *testing.T) {
+	// query_id指定時はRedmineが日時フィルタを無視するため、範囲外のチケットも返ってくる
+	var gotQueryID string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQueryID = r.URL.Query().Get("query_id")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issues": []map[string]interface{}{
+				{"id": 1, "updated_on": "2026-01-06T00:00:00Z"},
+				{"id": 2, "updated_on": "2025-12-01T00:00:00Z"},
+			},
+			"total_count": 2,
+		})
+	}))
+	defer server.Close()
+
+	dateFilter := &DateFilter{
+		Field: "updated_on",
+		Start: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2026, 1, 11, 23, 59, 59, 0, time.UTC),
+	}
+
+	client := NewClient(server.URL, "key")
+	issues, err := client.FetchAllIssues("/issues.json?query_id=7&project_id=3", false, dateFilter, nil)
+	if err != nil {
+		t.Fatalf("FetchAllIssues()でエラー: %v", err)
+	}
+	if gotQueryID != "7" {
+		t.Errorf("query_id = %q; want 7", gotQueryID)
+	}
+	if len(issues) != 1 || issues[0].ID != 1 {
+		t.Errorf("チケット = %d件; want #1 のみ", len(issues))
+	}
+}
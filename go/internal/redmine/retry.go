@@ -0,0 +1,129 @@
+package redmine
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy はAPI呼び出しのリトライ方針
+type RetryPolicy struct {
+	MaxRetries     int           // 最大リトライ回数（0はリトライなし）
+	InitialBackoff time.Duration // 1回目のリトライまでの待機時間
+	MaxBackoff     time.Duration // 待機時間の上限（Retry-Afterには適用しない）
+	Multiplier     float64       // リトライごとの待機時間の倍率
+	Jitter         float64       // 待機時間に加えるランダム幅の割合（0〜1）
+}
+
+// DefaultRetryPolicy はデフォルトのリトライ方針を返す
+// 1s → 2s → 4s（±20%）の間隔で最大3回リトライ
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:     3,
+		InitialBackoff: 1 * time.Second,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// backoff はattempt回目（0始まり）のリトライ前の待機時間を計算
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	wait := float64(p.InitialBackoff)
+	for i := 0; i < attempt; i++ {
+		wait *= p.Multiplier
+	}
+	if p.MaxBackoff > 0 && wait > float64(p.MaxBackoff) {
+		wait = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		// [-Jitter, +Jitter] の範囲で揺らす（同時実行時の再試行集中を避ける）
+		wait += wait * p.Jitter * (2*rand.Float64() - 1)
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return time.Duration(wait)
+}
+
+// HTTPError はRedmine APIが2xx以外を返したときのエラー
+type HTTPError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration // Retry-Afterヘッダーの値（指定がない場合は0）
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Body)
+}
+
+// Temporary は再試行で回復する可能性があるステータスかどうかを返す
+// 401/403/404などの4xxは認証・権限・指定ミスなので再試行しない
+func (e *HTTPError) Temporary() bool {
+	switch e.StatusCode {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// networkError は接続リセットやタイムアウトなどの通信エラー
+type networkError struct {
+	err error
+}
+
+func (e *networkError) Error() string { return e.err.Error() }
+func (e *networkError) Unwrap() error { return e.err }
+
+// IsNetworkError はエラーが通信エラー（接続できない、タイムアウトなど）かどうかを判定
+func IsNetworkError(err error) bool {
+	var netErr *networkError
+	return errors.As(err, &netErr)
+}
+
+// isRetryable はエラーが再試行対象かどうかを判定
+func isRetryable(err error) bool {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Temporary()
+	}
+	var netErr *networkError
+	return errors.As(err, &netErr)
+}
+
+// retryWait はエラーに応じた待機時間を返す（Retry-Afterがあれば優先）
+func (p RetryPolicy) retryWait(attempt int, err error) time.Duration {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.RetryAfter > 0 {
+		return httpErr.RetryAfter
+	}
+	return p.backoff(attempt)
+}
+
+// parseRetryAfter はRetry-Afterヘッダー（秒数またはHTTP日付）を解釈
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
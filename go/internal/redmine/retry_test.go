@@ -0,0 +1,230 @@
+package redmine
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{
+		MaxRetries:     5,
+		InitialBackoff: 1 * time.Second,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0,
+	}
+
+	want := []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for attempt, w := range want {
+		if got := policy.backoff(attempt); got != w {
+			t.Errorf("backoff(%d) = %v; want %v", attempt, got, w)
+		}
+	}
+}
+
+func TestRetryPolicyBackoff_Jitter(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 1 * time.Second, Multiplier: 2, Jitter: 0.2}
+
+	for i := 0; i < 100; i++ {
+		got := policy.backoff(0)
+		if got < 800*time.Millisecond || got > 1200*time.Millisecond {
+			t.Fatalf("backoff(0) = %v; want 0.8s〜1.2s", got)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{name: "秒数", value: "120", want: 120 * time.Second},
+		{name: "HTTP日付", value: "Fri, 02 Jan 2026 10:00:30 GMT", want: 30 * time.Second},
+		{name: "過去の日付", value: "Fri, 02 Jan 2026 09:00:00 GMT", want: 0},
+		{name: "空", value: "", want: 0},
+		{name: "不正な値", value: "soon", want: 0},
+		{name: "負数", value: "-5", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.value, now); got != tt.want {
+				t.Errorf("parseRetryAfter(%q) = %v; want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPErrorTemporary(t *testing.T) {
+	tests := []struct {
+		status int
+		want   bool
+	}{
+		{status: 401, want: false},
+		{status: 403, want: false},
+		{status: 404, want: false},
+		{status: 422, want: false},
+		{status: 429, want: true},
+		{status: 500, want: false},
+		{status: 502, want: true},
+		{status: 503, want: true},
+		{status: 504, want: true},
+	}
+
+	for _, tt := range tests {
+		err := &HTTPError{StatusCode: tt.status}
+		if got := err.Temporary(); got != tt.want {
+			t.Errorf("HTTP %d Temporary() = %v; want %v", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestGetJSON_Retry(t *testing.T) {
+	tests := []struct {
+		name         string
+		responses    []int // 順に返すステータスコード（尽きたら200）
+		retryAfter   string
+		maxRetries   int
+		wantErr      bool
+		wantStatus   int // wantErr時に期待するHTTPステータス
+		wantRequests int32
+		wantWaits    []time.Duration
+	}{
+		{
+			name:         "503の後に成功",
+			responses:    []int{503, 503},
+			maxRetries:   3,
+			wantRequests: 3,
+			wantWaits:    []time.Duration{1 * time.Second, 2 * time.Second},
+		},
+		{
+			name:         "Retry-Afterを優先",
+			responses:    []int{429},
+			retryAfter:   "7",
+			maxRetries:   3,
+			wantRequests: 2,
+			wantWaits:    []time.Duration{7 * time.Second},
+		},
+		{
+			name:         "リトライ上限で失敗",
+			responses:    []int{502, 502, 502},
+			maxRetries:   2,
+			wantErr:      true,
+			wantStatus:   502,
+			wantRequests: 3,
+			wantWaits:    []time.Duration{1 * time.Second, 2 * time.Second},
+		},
+		{
+			name:         "401は再試行しない",
+			responses:    []int{401},
+			maxRetries:   3,
+			wantErr:      true,
+			wantStatus:   401,
+			wantRequests: 1,
+		},
+		{
+			name:         "403は再試行しない",
+			responses:    []int{403},
+			maxRetries:   3,
+			wantErr:      true,
+			wantStatus:   403,
+			wantRequests: 1,
+		},
+		{
+			name:         "リトライ無効",
+			responses:    []int{503},
+			maxRetries:   0,
+			wantErr:      true,
+			wantStatus:   503,
+			wantRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&requests, 1)
+				if int(n) <= len(tt.responses) {
+					if tt.retryAfter != "" {
+						w.Header().Set("Retry-After", tt.retryAfter)
+					}
+					w.WriteHeader(tt.responses[n-1])
+					return
+				}
+				w.Write([]byte(`{"issue": {"id": 1, "subject": "OK"}}`))
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, "key")
+			client.SetRetryPolicy(RetryPolicy{
+				MaxRetries:     tt.maxRetries,
+				InitialBackoff: 1 * time.Second,
+				MaxBackoff:     30 * time.Second,
+				Multiplier:     2,
+			})
+			var waits []time.Duration
+			client.sleep = func(d time.Duration) { waits = append(waits, d) }
+
+			issue, err := client.FetchIssue(1)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FetchIssue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				var httpErr *HTTPError
+				if !errors.As(err, &httpErr) || httpErr.StatusCode != tt.wantStatus {
+					t.Errorf("err = %v; want HTTP %d", err, tt.wantStatus)
+				}
+			} else if issue == nil || issue.Subject != "OK" {
+				t.Errorf("issue = %+v; want subject OK", issue)
+			}
+
+			if got := atomic.LoadInt32(&requests); got != tt.wantRequests {
+				t.Errorf("リクエスト回数 = %d; want %d", got, tt.wantRequests)
+			}
+
+			if len(waits) != len(tt.wantWaits) {
+				t.Fatalf("待機回数 = %d (%v); want %d", len(waits), waits, len(tt.wantWaits))
+			}
+			for i, w := range tt.wantWaits {
+				if waits[i] != w {
+					t.Errorf("waits[%d] = %v; want %v", i, waits[i], w)
+				}
+			}
+		})
+	}
+}
+
+func TestGetJSON_RetryOnConnectionReset(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			// 1回目はレスポンスを返さずに接続を切断
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+			return
+		}
+		w.Write([]byte(`{"issues": [], "total_count": 0}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key")
+	client.sleep = func(time.Duration) {}
+
+	if _, err := client.fetch(server.URL + "/issues.json"); err != nil {
+		t.Fatalf("fetch()でエラー: %v", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("リクエスト回数 = %d; want 2", got)
+	}
+}
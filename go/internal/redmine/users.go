@@ -0,0 +1,46 @@
+package redmine
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// User はRedmineのユーザー（/users/current.json）
+type User struct {
+	ID        int    `json:"id"`
+	Login     string `json:"login"`
+	Firstname string `json:"firstname"`
+	Lastname  string `json:"lastname"`
+	Admin     bool   `json:"admin"`
+}
+
+// Name はユーザーの表示名（姓 名、未設定の場合はログインID）
+func (u User) Name() string {
+	if name := strings.TrimSpace(u.Lastname + " " + u.Firstname); name != "" {
+		return name
+	}
+	return u.Login
+}
+
+// FetchCurrentUser はAPIキーのユーザーを取得する
+// レスポンスのDateヘッダー（Redmineサーバーの時刻、ない場合はゼロ値）も返す
+func (c *Client) FetchCurrentUser() (*User, time.Time, error) {
+	var resp struct {
+		User *User `json:"user"`
+	}
+	header, err := c.getJSONHeader(fmt.Sprintf("%s/users/current.json", c.baseURL), &resp)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if resp.User == nil {
+		return nil, time.Time{}, fmt.Errorf("JSON解析エラー: user が含まれていません")
+	}
+
+	var serverTime time.Time
+	if t, err := http.ParseTime(header.Get("Date")); err == nil {
+		serverTime = t
+	}
+	return resp.User, serverTime, nil
+}
@@ -0,0 +1,110 @@
+package redmine
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tktomaru/redmine-exporter/internal/logger"
+)
+
+// Version はRedmineのバージョン（/projects/:id/versions.json）
+type Version struct {
+	ID        int       `json:"id"`
+	Project   IDName    `json:"project"`
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`     // open, locked, closed
+	DueDate   *Date     `json:"due_date"`   // 期日（effective_date）
+	CreatedOn *DateTime `json:"created_on"` // 作成日時
+}
+
+// String はバージョンの表示用文字列（エラーメッセージ用）
+func (v Version) String() string {
+	return fmt.Sprintf("#%d %s", v.ID, v.Name)
+}
+
+// FetchVersions はプロジェクトのバージョン（他のプロジェクトから共有されたバージョンを含む）を取得
+func (c *Client) FetchVersions(project string) ([]Version, error) {
+	logger.Section("バージョン取得")
+
+	var resp struct {
+		Versions []Version `json:"versions"`
+	}
+	if err := c.getJSON(fmt.Sprintf("%s/projects/%s/versions.json", c.baseURL, url.PathEscape(project)), &resp); err != nil {
+		return nil, fmt.Errorf("バージョン一覧の取得に失敗: %w", err)
+	}
+
+	logger.Info("バージョン: %d件", len(resp.Versions))
+	return resp.Versions, nil
+}
+
+// SprintRange はバージョンをスプリントとみなした期間を返す
+// specは数値の場合はID、それ以外は名前（完全一致）で検索する
+// 終了はバージョンの期日、開始は同じプロジェクトで期日が直前のバージョンの期日の翌日
+// （直前のバージョンがない場合はバージョンの作成日）とする
+func SprintRange(versions []Version, spec string, loc *time.Location) (start, end time.Time, v *Version, err error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return time.Time{}, time.Time{}, nil, fmt.Errorf("バージョンが指定されていません")
+	}
+	if id, convErr := strconv.Atoi(spec); convErr == nil {
+		for i := range versions {
+			if versions[i].ID == id {
+				v = &versions[i]
+				break
+			}
+		}
+	} else {
+		for i := range versions {
+			if versions[i].Name != spec {
+				continue
+			}
+			if v != nil {
+				return time.Time{}, time.Time{}, nil, fmt.Errorf("同名のバージョンが複数あります: %s (IDで指定してください: %s, %s)", spec, v, versions[i])
+			}
+			v = &versions[i]
+		}
+	}
+	if v == nil {
+		names := make([]string, 0, len(versions))
+		for _, other := range versions {
+			names = append(names, other.String())
+		}
+		return time.Time{}, time.Time{}, nil, fmt.Errorf("バージョンが見つかりません: %s (バージョン: %s)", spec, strings.Join(names, ", "))
+	}
+	if v.DueDate == nil || v.DueDate.IsZero() {
+		return time.Time{}, time.Time{}, nil, fmt.Errorf("バージョン %s に期日が設定されていません", v)
+	}
+
+	due := v.DueDate.Time
+	end = time.Date(due.Year(), due.Month(), due.Day(), 23, 59, 59, 0, loc)
+
+	// 同じプロジェクトで期日が直前のバージョン
+	var previous *Version
+	for i := range versions {
+		p := &versions[i]
+		if p.ID == v.ID || p.Project.ID != v.Project.ID || p.DueDate == nil || p.DueDate.IsZero() || !p.DueDate.Before(due) {
+			continue
+		}
+		if previous == nil || p.DueDate.After(previous.DueDate.Time) {
+			previous = p
+		}
+	}
+
+	switch {
+	case previous != nil:
+		next := previous.DueDate.Time.AddDate(0, 0, 1)
+		start = time.Date(next.Year(), next.Month(), next.Day(), 0, 0, 0, 0, loc)
+	case v.CreatedOn != nil && !v.CreatedOn.IsZero():
+		created := v.CreatedOn.Time.In(loc)
+		start = time.Date(created.Year(), created.Month(), created.Day(), 0, 0, 0, 0, loc)
+	default:
+		return time.Time{}, time.Time{}, nil, fmt.Errorf("バージョン %s の開始日を判定できません（直前のバージョン・作成日がありません）", v)
+	}
+	if start.After(end) {
+		return time.Time{}, time.Time{}, nil, fmt.Errorf("バージョン %s の開始日 (%s) が期日より後です", v, start.Format("2006/01/02"))
+	}
+	return start, end, v, nil
+}
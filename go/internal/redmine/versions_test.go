@@ -0,0 +1,97 @@
+package redmine
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSprintRange(t *testing.T) {
+	date := func(month, day int) *Date {
+		return &Date{Time: time.Date(2025, time.Month(month), day, 0, 0, 0, 0, time.UTC)}
+	}
+	project := IDName{ID: 1, Name: "本体"}
+	versions := []Version{
+		{ID: 3, Project: project, Name: "Sprint 12", DueDate: date(11, 14)},
+		{ID: 1, Project: project, Name: "Sprint 10", DueDate: date(10, 17), CreatedOn: &DateTime{Time: time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)}},
+		{ID: 2, Project: project, Name: "Sprint 11", DueDate: date(10, 31)},
+		// 他のプロジェクトから共有されたバージョン（直前のバージョンとしては扱わない）
+		{ID: 9, Project: IDName{ID: 2, Name: "共通"}, Name: "共通 11月", DueDate: date(11, 7)},
+		{ID: 4, Project: project, Name: "Backlog"},
+		{ID: 5, Project: project, Name: "重複", DueDate: date(12, 1)},
+		{ID: 6, Project: project, Name: "重複", DueDate: date(12, 15)},
+	}
+
+	tests := []struct {
+		name      string
+		spec      string
+		wantStart string
+		wantEnd   string
+		wantErr   bool
+	}{
+		{name: "直前のバージョンの期日の翌日から", spec: "Sprint 12", wantStart: "2025-11-01", wantEnd: "2025-11-14"},
+		{name: "IDで指定", spec: "2", wantStart: "2025-10-18", wantEnd: "2025-10-31"},
+		{name: "直前のバージョンがない場合は作成日から", spec: "Sprint 10", wantStart: "2025-10-01", wantEnd: "2025-10-17"},
+		{name: "作成日もない", spec: "共通 11月", wantErr: true},
+		{name: "期日なし", spec: "Backlog", wantErr: true},
+		{name: "同名のバージョンが複数", spec: "重複", wantErr: true},
+		{name: "存在しない名前", spec: "Sprint 99", wantErr: true},
+		{name: "空", spec: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, v, err := SprintRange(versions, tt.spec, time.UTC)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("エラーが返されなかった (%v)", v)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if got := start.Format("2006-01-02 15:04:05"); got != tt.wantStart+" 00:00:00" {
+				t.Errorf("start = %s; want %s 00:00:00", got, tt.wantStart)
+			}
+			if got := end.Format("2006-01-02 15:04:05"); got != tt.wantEnd+" 23:59:59" {
+				t.Errorf("end = %s; want %s 23:59:59", got, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestFetchVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/projects/myproj/versions.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"versions": []map[string]interface{}{
+				{"id": 1, "project": map[string]interface{}{"id": 3, "name": "本体"}, "name": "Sprint 1", "status": "closed",
+					"due_date": "2025-10-17", "created_on": "2025-10-01T00:00:00Z"},
+				{"id": 2, "project": map[string]interface{}{"id": 3, "name": "本体"}, "name": "Backlog", "status": "open", "due_date": nil},
+			},
+			"total_count": 2,
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key")
+	versions, err := client.FetchVersions("myproj")
+	if err != nil {
+		t.Fatalf("FetchVersions()でエラー: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("バージョン = %d件; want 2", len(versions))
+	}
+	if v := versions[0]; v.Name != "Sprint 1" || v.DueDate == nil || v.DueDate.Format() != "2025/10/17" || v.Project.ID != 3 {
+		t.Errorf("versions[0] = %+v", v)
+	}
+	if versions[1].DueDate != nil && !versions[1].DueDate.IsZero() {
+		t.Errorf("versions[1].DueDate = %v; want なし", versions[1].DueDate)
+	}
+}
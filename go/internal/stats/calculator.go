@@ -9,29 +9,40 @@ import (
 
 // WeeklyStats は週報向けの統計情報
 type WeeklyStats struct {
-	TotalIssues   int                  // 総チケット数
-	ByStatus      map[string]int       // ステータス別件数
-	ByAssignee    map[string]int       // 担当者別件数
-	ByTracker     map[string]int       // トラッカー別件数
-	ByPriority    map[string]int       // 優先度別件数
-	NewIssues     int                  // 新規作成チケット数（期間内）
-	UpdatedIssues int                  // 更新チケット数（期間内）
-	ClosedIssues  int                  // 完了チケット数
-	OverdueTasks  []*redmine.Issue     // 期限切れタスク
-	DueSoonTasks  []*redmine.Issue     // 期限間近タスク（7日以内）
-	CommentStats  CommentStats         // コメント統計
+	TotalIssues      int              // 総チケット数
+	ByStatus         map[string]int   // ステータス別件数
+	ByAssignee       map[string]int   // 担当者別件数
+	ByTracker        map[string]int   // トラッカー別件数
+	ByPriority       map[string]int   // 優先度別件数
+	NewIssues        int              // 新規作成チケット数（期間内）
+	UpdatedIssues    int              // 更新チケット数（期間内）
+	ClosedIssues     int              // 完了チケット数（期間内に完了したチケット、判定できない場合は完了ステータスのチケット）
+	ClosedInPeriod   []*redmine.Issue // 期間内に完了したチケット
+	ReopenedInPeriod []*redmine.Issue // 期間内に再オープンしたチケット（Transitions指定時のみ）
+	StartedInPeriod  []*redmine.Issue // 期間内に着手したチケット（Transitions指定時のみ）
+	Transitions      bool             // ステータス変更から期間内の完了・再オープン・着手を判定したか
+	OverdueTasks     []*redmine.Issue // 期限切れタスク
+	DueSoonTasks     []*redmine.Issue // 期限間近タスク（7日以内）
+	CommentStats     CommentStats     // コメント統計
+	Time             *TimeStats       // 作業時間統計（作業時間を取得した場合のみ）
+	Flow             *FlowStats       // リードタイム・サイクルタイム（期間内に完了日時の分かるチケットがある場合のみ）
 }
 
 // CommentStats はコメントの統計情報
 type CommentStats struct {
-	TotalComments    int            // 総コメント数
-	IssuesWithComments int          // コメントのあるチケット数
-	ByUser           map[string]int // ユーザー別コメント数
+	TotalComments      int            // 総コメント数
+	IssuesWithComments int            // コメントのあるチケット数
+	ByUser             map[string]int // ユーザー別コメント数
 }
 
 // Calculate は週報統計を計算
 // weekStart, weekEndは集計期間（期限切れ・期限間近の判定に使用）
 func Calculate(issues []*redmine.Issue, weekStart, weekEnd time.Time) *WeeklyStats {
+	return CalculateWithOptions(issues, weekStart, weekEnd, Options{})
+}
+
+// CalculateWithOptions は完了の判定方法などを指定して週報統計を計算
+func CalculateWithOptions(issues []*redmine.Issue, weekStart, weekEnd time.Time, opts Options) *WeeklyStats {
 	stats := &WeeklyStats{
 		ByStatus:   make(map[string]int),
 		ByAssignee: make(map[string]int),
@@ -40,8 +51,12 @@ func Calculate(issues []*redmine.Issue, weekStart, weekEnd time.Time) *WeeklySta
 		CommentStats: CommentStats{
 			ByUser: make(map[string]int),
 		},
-		OverdueTasks: make([]*redmine.Issue, 0),
-		DueSoonTasks: make([]*redmine.Issue, 0),
+		OverdueTasks:     make([]*redmine.Issue, 0),
+		DueSoonTasks:     make([]*redmine.Issue, 0),
+		ClosedInPeriod:   make([]*redmine.Issue, 0),
+		ReopenedInPeriod: make([]*redmine.Issue, 0),
+		StartedInPeriod:  make([]*redmine.Issue, 0),
+		Transitions:      opts.Transitions,
 	}
 
 	now := time.Now()
@@ -90,9 +105,16 @@ func Calculate(issues []*redmine.Issue, weekStart, weekEnd time.Time) *WeeklySta
 			}
 		}
 
-		// 完了判定（ステータスに「完了」「終了」「クローズ」などが含まれる）
-		if isClosedStatus(statusName) {
-			stats.ClosedIssues++
+		// 期間内の完了・再オープン・着手の判定
+		closed, reopened, started := opts.periodEvents(issue, weekStart, weekEnd)
+		if closed {
+			stats.ClosedInPeriod = append(stats.ClosedInPeriod, issue)
+		}
+		if reopened {
+			stats.ReopenedInPeriod = append(stats.ReopenedInPeriod, issue)
+		}
+		if started {
+			stats.StartedInPeriod = append(stats.StartedInPeriod, issue)
 		}
 
 		// 期限切れ・期限間近の判定
@@ -100,9 +122,9 @@ func Calculate(issues []*redmine.Issue, weekStart, weekEnd time.Time) *WeeklySta
 			dueDate := issue.DueDate.Time
 
 			// 期限切れ（期限が現在より前）
-			if dueDate.Before(now) && !isClosedStatus(statusName) {
+			if dueDate.Before(now) && !opts.isClosed(issue.Status) {
 				stats.OverdueTasks = append(stats.OverdueTasks, issue)
-			} else if dueDate.After(now) && dueDate.Before(dueSoonThreshold) && !isClosedStatus(statusName) {
+			} else if dueDate.After(now) && dueDate.Before(dueSoonThreshold) && !opts.isClosed(issue.Status) {
 				// 期限間近（7日以内）
 				stats.DueSoonTasks = append(stats.DueSoonTasks, issue)
 			}
@@ -129,6 +151,18 @@ func Calculate(issues []*redmine.Issue, weekStart, weekEnd time.Time) *WeeklySta
 		}
 	}
 
+	stats.ClosedIssues = len(stats.ClosedInPeriod)
+
+	// リードタイム・サイクルタイム（期間内に完了したチケット）
+	if fs := CalculateFlow(stats.ClosedInPeriod, opts); fs.LeadTime.Count > 0 {
+		stats.Flow = fs
+	}
+
+	// 作業時間統計（作業時間が紐付いている場合のみ）
+	if ts := CalculateTime(issues); ts.EntryCount > 0 {
+		stats.Time = ts
+	}
+
 	return stats
 }
 
@@ -148,6 +182,20 @@ func flattenIssues(issues []*redmine.Issue) []*redmine.Issue {
 	return result
 }
 
+// IsClosedStatus はステータス名が完了系かどうかを判定
+func IsClosedStatus(status string) bool {
+	return isClosedStatus(status)
+}
+
+// IsClosed はステータスが終了ステータスかどうかを判定
+// statusesで is_closed を取得済みの場合はそれを使い、取得していない場合（オフラインなど）はステータス名で判定する
+func IsClosed(statuses *redmine.Enumerations, status redmine.IDName) bool {
+	if closed, ok := statuses.Closed(status.ID); ok {
+		return closed
+	}
+	return isClosedStatus(status.Name)
+}
+
 // isClosedStatus はステータスが完了系かどうかを判定
 func isClosedStatus(status string) bool {
 	closedKeywords := []string{"完了", "終了", "クローズ", "Closed", "Resolved", "Done"}
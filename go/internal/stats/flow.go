@@ -0,0 +1,181 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/tktomaru/redmine-exporter/internal/processor"
+	"github.com/tktomaru/redmine-exporter/internal/redmine"
+)
+
+// DurationStats は所要時間（日数）の分布
+type DurationStats struct {
+	Count  int     // 対象チケット数
+	Median float64 // 中央値（日）
+	P85    float64 // 85パーセンタイル（日）
+	Max    float64 // 最大（日）
+}
+
+// String は「中央値 3.5日 / 85%値 7日 / 最大 10.2日（12件）」の形式で返す
+func (d DurationStats) String() string {
+	if d.Count == 0 {
+		return "なし"
+	}
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	return fmt.Sprintf("中央値 %s日 / 85%%値 %s日 / 最大 %s日（%d件）", f(d.Median), f(d.P85), f(d.Max), d.Count)
+}
+
+// FlowGroup はトラッカー・担当者ごとのリードタイム・サイクルタイム
+type FlowGroup struct {
+	Name      string
+	LeadTime  DurationStats
+	CycleTime DurationStats
+}
+
+// IssueFlow はチケット1件のリードタイム・サイクルタイム
+type IssueFlow struct {
+	Issue     *redmine.Issue
+	ClosedOn  time.Time // 完了日時
+	LeadTime  float64   // 作成から完了までの日数
+	CycleTime float64   // 最初の着手から完了までの日数（HasCycleTimeがfalseの場合は0）
+	// HasCycleTime はサイクルタイムを計算できたか（着手のステータス変更がない場合はfalse）
+	HasCycleTime bool
+}
+
+// FlowStats は期間内に完了したチケットのリードタイム・サイクルタイムの統計情報
+type FlowStats struct {
+	LeadTime   DurationStats // リードタイム（作成 → 完了）
+	CycleTime  DurationStats // サイクルタイム（最初の着手 → 完了、ステータス変更を取得した場合のみ）
+	ByTracker  []FlowGroup   // トラッカー別（名前順）
+	ByAssignee []FlowGroup   // 担当者別（名前順）
+	Issues     []IssueFlow   // チケット別（完了した順）
+}
+
+// CalculateFlow は完了したチケットのリードタイム・サイクルタイムを集計
+// 完了日時はステータス変更（Issue.StatusChanges）の最後の完了、なければ closed_on を使用する
+// 完了日時が分からないチケットは対象外
+func CalculateFlow(closedIssues []*redmine.Issue, opts Options) *FlowStats {
+	fs := &FlowStats{Issues: make([]IssueFlow, 0)}
+
+	type durations struct{ lead, cycle []float64 }
+	byTracker := make(map[string]*durations)
+	byAssignee := make(map[string]*durations)
+	var all durations
+
+	for _, issue := range closedIssues {
+		f, ok := opts.issueFlow(issue)
+		if !ok {
+			continue
+		}
+		fs.Issues = append(fs.Issues, f)
+
+		tracker := nameOrDefault(issue.Tracker.Name, "未設定")
+		assignee := processor.GetAssignee(issue)
+		if byTracker[tracker] == nil {
+			byTracker[tracker] = &durations{}
+		}
+		if byAssignee[assignee] == nil {
+			byAssignee[assignee] = &durations{}
+		}
+		for _, d := range []*durations{&all, byTracker[tracker], byAssignee[assignee]} {
+			d.lead = append(d.lead, f.LeadTime)
+			if f.HasCycleTime {
+				d.cycle = append(d.cycle, f.CycleTime)
+			}
+		}
+	}
+
+	fs.LeadTime = summarizeDurations(all.lead)
+	fs.CycleTime = summarizeDurations(all.cycle)
+
+	groups := func(m map[string]*durations) []FlowGroup {
+		result := make([]FlowGroup, 0, len(m))
+		for name, d := range m {
+			result = append(result, FlowGroup{
+				Name:      name,
+				LeadTime:  summarizeDurations(d.lead),
+				CycleTime: summarizeDurations(d.cycle),
+			})
+		}
+		sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+		return result
+	}
+	fs.ByTracker = groups(byTracker)
+	fs.ByAssignee = groups(byAssignee)
+
+	sort.SliceStable(fs.Issues, func(i, j int) bool {
+		return fs.Issues[i].ClosedOn.Before(fs.Issues[j].ClosedOn)
+	})
+
+	return fs
+}
+
+// issueFlow はチケットの完了日時と着手日時からリードタイム・サイクルタイムを計算する
+func (o Options) issueFlow(issue *redmine.Issue) (IssueFlow, bool) {
+	if issue.CreatedOn == nil || issue.CreatedOn.IsZero() {
+		return IssueFlow{}, false
+	}
+
+	// 完了日時（再オープン後に再度完了した場合は最後の完了）
+	var closedOn time.Time
+	for _, c := range issue.StatusChanges {
+		if !o.isClosed(c.From) && o.isClosed(c.To) {
+			closedOn = c.CreatedOn
+		}
+	}
+	if closedOn.IsZero() && issue.ClosedOn != nil {
+		closedOn = issue.ClosedOn.Time
+	}
+	if closedOn.IsZero() || closedOn.Before(issue.CreatedOn.Time) {
+		return IssueFlow{}, false
+	}
+
+	f := IssueFlow{Issue: issue, ClosedOn: closedOn, LeadTime: days(closedOn.Sub(issue.CreatedOn.Time))}
+
+	// 最初の着手（着手とみなすステータスへの最初の変更）
+	for _, c := range issue.StatusChanges {
+		if c.CreatedOn.After(closedOn) {
+			break
+		}
+		if o.isStarted(c.To) && !o.isStarted(c.From) {
+			f.CycleTime = days(closedOn.Sub(c.CreatedOn))
+			f.HasCycleTime = true
+			break
+		}
+	}
+	return f, true
+}
+
+// days は所要時間を日数（小数点以下1桁）に変換
+func days(d time.Duration) float64 {
+	return math.Round(d.Hours()/24*10) / 10
+}
+
+// summarizeDurations は日数の中央値・85パーセンタイル・最大を計算
+func summarizeDurations(values []float64) DurationStats {
+	if len(values) == 0 {
+		return DurationStats{}
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return DurationStats{
+		Count:  len(sorted),
+		Median: percentile(sorted, 50),
+		P85:    percentile(sorted, 85),
+		Max:    sorted[len(sorted)-1],
+	}
+}
+
+// percentile は昇順に並べた値のパーセンタイルを線形補間で計算（ExcelのPERCENTILE.INCと同じ）
+func percentile(sorted []float64, p float64) float64 {
+	pos := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	if lower+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	v := sorted[lower] + (sorted[lower+1]-sorted[lower])*(pos-float64(lower))
+	return math.Round(v*10) / 10
+}
@@ -0,0 +1,109 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tktomaru/redmine-exporter/internal/redmine"
+)
+
+func TestSummarizeDurations(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   DurationStats
+	}{
+		{"なし", nil, DurationStats{}},
+		{"1件", []float64{3}, DurationStats{Count: 1, Median: 3, P85: 3, Max: 3}},
+		{"偶数件（中央値は平均）", []float64{4, 1, 2, 3}, DurationStats{Count: 4, Median: 2.5, P85: 3.6, Max: 4}},
+		{"奇数件", []float64{10, 1, 5, 2, 3}, DurationStats{Count: 5, Median: 3, P85: 7, Max: 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizeDurations(tt.values); got != tt.want {
+				t.Errorf("summarizeDurations(%v) = %+v; want %+v", tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateFlow(t *testing.T) {
+	at := func(day, hour int) time.Time { return time.Date(2025, 1, day, hour, 0, 0, 0, time.UTC) }
+	dt := func(day int) *redmine.DateTime { return &redmine.DateTime{Time: at(day, 0)} }
+	status := func(name string) redmine.IDName { return redmine.IDName{Name: name} }
+	change := func(day int, from, to string) redmine.StatusChange {
+		return redmine.StatusChange{CreatedOn: at(day, 0), From: status(from), To: status(to)}
+	}
+
+	issues := []*redmine.Issue{
+		// 着手 → 完了 → 再オープン → 再度完了（最後の完了を使用）
+		{ID: 1, Tracker: status("バグ"), AssignedTo: &redmine.IDName{Name: "田中"}, CreatedOn: dt(1),
+			StatusChanges: []redmine.StatusChange{change(3, "新規", "進行中"), change(5, "進行中", "完了"), change(6, "完了", "進行中"), change(8, "進行中", "完了")}},
+		// ステータス変更なし（closed_onのみ、サイクルタイムなし）
+		{ID: 2, Tracker: status("機能"), AssignedTo: &redmine.IDName{Name: "鈴木"}, CreatedOn: dt(2), ClosedOn: &redmine.DateTime{Time: at(3, 12)}},
+		// 着手 → 完了
+		{ID: 3, Tracker: status("バグ"), AssignedTo: &redmine.IDName{Name: "鈴木"}, CreatedOn: dt(1),
+			StatusChanges: []redmine.StatusChange{change(2, "新規", "進行中"), change(4, "進行中", "完了")}},
+		// 完了日時が不明（対象外）
+		{ID: 4, Tracker: status("バグ"), CreatedOn: dt(1)},
+	}
+
+	fs := CalculateFlow(issues, Options{StartedStatuses: []string{"進行中"}})
+
+	var ids []int
+	for _, f := range fs.Issues {
+		ids = append(ids, f.Issue.ID)
+	}
+	if len(ids) != 3 || ids[0] != 2 || ids[1] != 3 || ids[2] != 1 {
+		t.Fatalf("Issues = %v; want 完了順に [2 3 1]", ids)
+	}
+	if f := fs.Issues[2]; f.LeadTime != 7 || f.CycleTime != 5 || !f.HasCycleTime {
+		t.Errorf("#1 = %+v; want LeadTime 7, CycleTime 5", f)
+	}
+	if f := fs.Issues[0]; f.LeadTime != 1.5 || f.HasCycleTime {
+		t.Errorf("#2 = %+v; want LeadTime 1.5, サイクルタイムなし", f)
+	}
+
+	tests := []struct {
+		name string
+		got  DurationStats
+		want DurationStats
+	}{
+		{"リードタイム", fs.LeadTime, DurationStats{Count: 3, Median: 3, P85: 5.8, Max: 7}},
+		{"サイクルタイム", fs.CycleTime, DurationStats{Count: 2, Median: 3.5, P85: 4.6, Max: 5}},
+		{"トラッカー別（バグ）", fs.ByTracker[0].LeadTime, DurationStats{Count: 2, Median: 5, P85: 6.4, Max: 7}},
+		{"トラッカー別（機能）", fs.ByTracker[1].CycleTime, DurationStats{}},
+		{"担当者別（鈴木）", fs.ByAssignee[1].LeadTime, DurationStats{Count: 2, Median: 2.3, P85: 2.8, Max: 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("%+v; want %+v", tt.got, tt.want)
+			}
+		})
+	}
+	if fs.ByTracker[0].Name != "バグ" || fs.ByAssignee[0].Name != "田中" {
+		t.Errorf("ByTracker = %+v, ByAssignee = %+v; want 名前順", fs.ByTracker, fs.ByAssignee)
+	}
+}
+
+func TestCalculate_Flow(t *testing.T) {
+	weekStart := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	weekEnd := time.Date(2025, 1, 12, 23, 59, 59, 0, time.UTC)
+
+	open := &redmine.Issue{ID: 1, Status: redmine.IDName{Name: "進行中"}, CreatedOn: &redmine.DateTime{Time: weekStart}}
+	if s := Calculate([]*redmine.Issue{open}, weekStart, weekEnd); s.Flow != nil {
+		t.Errorf("完了したチケットがないのにFlow = %+v", s.Flow)
+	}
+
+	closed := &redmine.Issue{ID: 2, Status: redmine.IDName{Name: "完了"},
+		CreatedOn: &redmine.DateTime{Time: weekStart}, ClosedOn: &redmine.DateTime{Time: weekStart.AddDate(0, 0, 2)}}
+	s := Calculate([]*redmine.Issue{open, closed}, weekStart, weekEnd)
+	if s.Flow == nil || s.Flow.LeadTime.Count != 1 || s.Flow.LeadTime.Max != 2 {
+		t.Fatalf("Flow = %+v; want 1件（2日）", s.Flow)
+	}
+	if got, want := s.Flow.LeadTime.String(), "中央値 2日 / 85%値 2日 / 最大 2日（1件）"; got != want {
+		t.Errorf("String() = %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,86 @@
+package stats
+
+import (
+	"sort"
+
+	"github.com/tktomaru/redmine-exporter/internal/processor"
+	"github.com/tktomaru/redmine-exporter/internal/redmine"
+)
+
+// TimeStats は作業時間（工数）の統計情報
+type TimeStats struct {
+	TotalHours  float64                    // 総作業時間
+	EntryCount  int                        // 作業時間の記録件数
+	ByIssue     map[int]float64            // チケット別作業時間
+	ByUser      map[string]float64         // 作業者別作業時間
+	ByAssignee  map[string]float64         // チケット担当者別作業時間
+	ByActivity  map[string]float64         // 作業分類別作業時間
+	ByProject   map[string]float64         // プロジェクト別作業時間
+	ByIssueUser map[int]map[string]float64 // チケット×作業者別作業時間
+}
+
+// HoursEntry は名前と作業時間の組（並び替え済みの一覧表示用）
+type HoursEntry struct {
+	Name  string
+	Hours float64
+}
+
+// CalculateTime はチケットに紐付いた作業時間を集計
+func CalculateTime(issues []*redmine.Issue) *TimeStats {
+	ts := &TimeStats{
+		ByIssue:     make(map[int]float64),
+		ByUser:      make(map[string]float64),
+		ByAssignee:  make(map[string]float64),
+		ByActivity:  make(map[string]float64),
+		ByProject:   make(map[string]float64),
+		ByIssueUser: make(map[int]map[string]float64),
+	}
+
+	for _, issue := range flattenIssues(issues) {
+		assignee := processor.GetAssignee(issue)
+
+		for _, te := range issue.TimeEntries {
+			ts.TotalHours += te.Hours
+			ts.EntryCount++
+
+			user := nameOrDefault(te.User.Name, "不明")
+			ts.ByIssue[issue.ID] += te.Hours
+			ts.ByUser[user] += te.Hours
+			ts.ByAssignee[assignee] += te.Hours
+			ts.ByActivity[nameOrDefault(te.Activity.Name, "未設定")] += te.Hours
+			ts.ByProject[nameOrDefault(te.Project.Name, "未設定")] += te.Hours
+
+			if ts.ByIssueUser[issue.ID] == nil {
+				ts.ByIssueUser[issue.ID] = make(map[string]float64)
+			}
+			ts.ByIssueUser[issue.ID][user] += te.Hours
+		}
+	}
+
+	return ts
+}
+
+// SortedHours は作業時間の多い順（同時間は名前順）に並べた一覧を返す
+func SortedHours(m map[string]float64) []HoursEntry {
+	entries := make([]HoursEntry, 0, len(m))
+	for name, hours := range m {
+		entries = append(entries, HoursEntry{Name: name, Hours: hours})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Hours != entries[j].Hours {
+			return entries[i].Hours > entries[j].Hours
+		}
+		return entries[i].Name < entries[j].Name
+	})
+
+	return entries
+}
+
+// nameOrDefault は名前が空の場合にデフォルト値を返す
+func nameOrDefault(name, def string) string {
+	if name == "" {
+		return def
+	}
+	return name
+}
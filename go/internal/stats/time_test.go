@@ -0,0 +1,93 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tktomaru/redmine-exporter/internal/redmine"
+)
+
+func TestCalculateTime(t *testing.T) {
+	issues := []*redmine.Issue{
+		{
+			ID:         1,
+			AssignedTo: &redmine.IDName{Name: "山田太郎"},
+			TimeEntries: []redmine.TimeEntry{
+				{Hours: 2.5, User: redmine.IDName{Name: "山田太郎"}, Activity: redmine.IDName{Name: "設計"}, Project: redmine.IDName{Name: "PJ-A"}},
+				{Hours: 1, User: redmine.IDName{Name: "佐藤花子"}, Activity: redmine.IDName{Name: "レビュー"}, Project: redmine.IDName{Name: "PJ-A"}},
+			},
+			Children: []*redmine.Issue{
+				{
+					ID: 2,
+					TimeEntries: []redmine.TimeEntry{
+						{Hours: 4, User: redmine.IDName{Name: "山田太郎"}, Activity: redmine.IDName{Name: "開発"}, Project: redmine.IDName{Name: "PJ-B"}},
+						{Hours: 0.5, User: redmine.IDName{Name: "山田太郎"}, Project: redmine.IDName{Name: "PJ-B"}},
+					},
+				},
+			},
+		},
+		{ID: 3},
+	}
+
+	ts := CalculateTime(issues)
+
+	if ts.TotalHours != 8 {
+		t.Errorf("TotalHours = %v; want 8", ts.TotalHours)
+	}
+	if ts.EntryCount != 4 {
+		t.Errorf("EntryCount = %d; want 4", ts.EntryCount)
+	}
+
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{name: "チケット#1", got: ts.ByIssue[1], want: 3.5},
+		{name: "チケット#2", got: ts.ByIssue[2], want: 4.5},
+		{name: "チケット#3", got: ts.ByIssue[3], want: 0},
+		{name: "作業者 山田太郎", got: ts.ByUser["山田太郎"], want: 7},
+		{name: "作業者 佐藤花子", got: ts.ByUser["佐藤花子"], want: 1},
+		{name: "担当者 山田太郎", got: ts.ByAssignee["山田太郎"], want: 3.5},
+		{name: "担当者 未定", got: ts.ByAssignee["担当者未定"], want: 4.5},
+		{name: "作業分類 開発", got: ts.ByActivity["開発"], want: 4},
+		{name: "作業分類 未設定", got: ts.ByActivity["未設定"], want: 0.5},
+		{name: "プロジェクト PJ-B", got: ts.ByProject["PJ-B"], want: 4.5},
+		{name: "#1 × 佐藤花子", got: ts.ByIssueUser[1]["佐藤花子"], want: 1},
+		{name: "#2 × 山田太郎", got: ts.ByIssueUser[2]["山田太郎"], want: 4.5},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v; want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestCalculate_TimeStats(t *testing.T) {
+	now := time.Now()
+
+	withoutEntries := Calculate([]*redmine.Issue{{ID: 1}}, now.AddDate(0, 0, -7), now)
+	if withoutEntries.Time != nil {
+		t.Errorf("作業時間がないのにTimeが設定された: %+v", withoutEntries.Time)
+	}
+
+	withEntries := Calculate([]*redmine.Issue{{ID: 1, TimeEntries: []redmine.TimeEntry{{Hours: 1.5}}}}, now.AddDate(0, 0, -7), now)
+	if withEntries.Time == nil || withEntries.Time.TotalHours != 1.5 {
+		t.Errorf("Time = %+v; want TotalHours 1.5", withEntries.Time)
+	}
+}
+
+func TestSortedHours(t *testing.T) {
+	got := SortedHours(map[string]float64{"佐藤": 2, "山田": 5, "鈴木": 2})
+	want := []HoursEntry{{Name: "山田", Hours: 5}, {Name: "佐藤", Hours: 2}, {Name: "鈴木", Hours: 2}}
+
+	if len(got) != len(want) {
+		t.Fatalf("len = %d; want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("[%d] = %+v; want %+v", i, got[i], want[i])
+		}
+	}
+}
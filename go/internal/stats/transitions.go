@@ -0,0 +1,78 @@
+package stats
+
+import (
+	"strings"
+	"time"
+
+	"github.com/tktomaru/redmine-exporter/internal/redmine"
+)
+
+// Options は統計の計算方法
+type Options struct {
+	// Statuses はステータスの一覧（/issue_statuses.json の is_closed で完了を判定）
+	// nilまたはis_closedを取得していない場合は、ステータス名（「完了」「Closed」など）で判定する
+	Statuses *redmine.Enumerations
+
+	// Transitions はチケットのステータス変更（Issue.StatusChanges）から期間内の完了・再オープン・着手を判定するか
+	// falseの場合、完了は closed_on（ない場合は現在のステータス）で判定し、再オープン・着手は判定しない
+	Transitions bool
+
+	// StartedStatuses は着手とみなすステータス名（例: 進行中）
+	StartedStatuses []string
+}
+
+// isClosed はステータスが終了ステータスかどうかを判定
+func (o Options) isClosed(status redmine.IDName) bool {
+	return IsClosed(o.Statuses, status)
+}
+
+// isStarted はステータスが着手とみなすステータスかどうかを判定
+func (o Options) isStarted(status redmine.IDName) bool {
+	for _, name := range o.StartedStatuses {
+		if strings.EqualFold(strings.TrimSpace(name), status.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// periodEvents はチケットが期間内に完了・再オープン・着手したかを判定する
+func (o Options) periodEvents(issue *redmine.Issue, start, end time.Time) (closed, reopened, started bool) {
+	inPeriod := func(t time.Time) bool {
+		return !t.Before(start) && !t.After(end)
+	}
+
+	if !o.Transitions {
+		if !o.isClosed(issue.Status) {
+			return false, false, false
+		}
+		// closed_on がない場合（古いRedmine）は現在のステータスのみで判定
+		if issue.ClosedOn == nil || issue.ClosedOn.IsZero() {
+			return true, false, false
+		}
+		return inPeriod(issue.ClosedOn.Time), false, false
+	}
+
+	for _, c := range issue.StatusChanges {
+		if !inPeriod(c.CreatedOn) {
+			continue
+		}
+		fromClosed, toClosed := o.isClosed(c.From), o.isClosed(c.To)
+		if !fromClosed && toClosed {
+			closed = true
+		}
+		if fromClosed && !toClosed {
+			reopened = true
+		}
+		if o.isStarted(c.To) && !o.isStarted(c.From) {
+			started = true
+		}
+	}
+
+	// ステータスを変更せずに作成時から終了・着手のステータスのチケット
+	if len(issue.StatusChanges) == 0 && issue.CreatedOn != nil && inPeriod(issue.CreatedOn.Time) {
+		closed = closed || o.isClosed(issue.Status)
+		started = started || o.isStarted(issue.Status)
+	}
+	return closed, reopened, started
+}
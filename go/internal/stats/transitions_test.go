@@ -0,0 +1,120 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tktomaru/redmine-exporter/internal/redmine"
+)
+
+func TestCalculateWithOptions_Transitions(t *testing.T) {
+	weekStart := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	weekEnd := time.Date(2025, 1, 12, 23, 59, 59, 0, time.UTC)
+	at := func(day int) time.Time { return time.Date(2025, 1, day, 10, 0, 0, 0, time.UTC) }
+
+	// 「完了待ち」は名前に「完了」を含むが終了ステータスではない、「却下」は終了ステータス
+	enums := redmine.NewEnumerations()
+	enums.ClosedKnown = true
+	statuses := map[int]redmine.IssueStatus{
+		1: {ID: 1, Name: "新規"},
+		2: {ID: 2, Name: "進行中"},
+		3: {ID: 3, Name: "完了待ち"},
+		5: {ID: 5, Name: "完了", IsClosed: true},
+		6: {ID: 6, Name: "却下", IsClosed: true},
+	}
+	enums.Statuses = statuses
+	status := func(id int) redmine.IDName { return redmine.IDName{ID: id, Name: statuses[id].Name} }
+	change := func(day, from, to int) redmine.StatusChange {
+		return redmine.StatusChange{CreatedOn: at(day), From: status(from), To: status(to)}
+	}
+	created := &redmine.DateTime{Time: at(1)}
+
+	issues := []*redmine.Issue{
+		// 期間内に着手して完了
+		{ID: 1, Status: status(5), CreatedOn: created, StatusChanges: []redmine.StatusChange{change(7, 1, 2), change(9, 2, 5)}},
+		// 先週完了して今週再オープン
+		{ID: 2, Status: status(2), CreatedOn: created, StatusChanges: []redmine.StatusChange{change(3, 2, 5), change(8, 5, 2)}},
+		// 先週完了（期間外）
+		{ID: 3, Status: status(5), CreatedOn: created, StatusChanges: []redmine.StatusChange{change(2, 1, 5)}},
+		// 期間内に却下（is_closedで判定）
+		{ID: 4, Status: status(6), CreatedOn: created, StatusChanges: []redmine.StatusChange{change(10, 1, 6)}},
+		// 完了待ち（名前に「完了」を含むが終了ではない）
+		{ID: 5, Status: status(3), CreatedOn: created, StatusChanges: []redmine.StatusChange{change(10, 2, 3)}},
+		// 期間内に終了ステータスで作成（ステータス変更なし）
+		{ID: 6, Status: status(5), CreatedOn: &redmine.DateTime{Time: at(11)}},
+	}
+
+	s := CalculateWithOptions(issues, weekStart, weekEnd, Options{Statuses: enums, Transitions: true, StartedStatuses: []string{"進行中"}})
+
+	tests := []struct {
+		name   string
+		issues []*redmine.Issue
+		want   []int
+	}{
+		{"完了", s.ClosedInPeriod, []int{1, 4, 6}},
+		{"再オープン", s.ReopenedInPeriod, []int{2}},
+		{"着手", s.StartedInPeriod, []int{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, issue := range tt.issues {
+				got = append(got, issue.ID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("チケット = %v; want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("チケット = %v; want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+	if s.ClosedIssues != 3 || !s.Transitions {
+		t.Errorf("ClosedIssues = %d, Transitions = %v; want 3, true", s.ClosedIssues, s.Transitions)
+	}
+}
+
+func TestCalculateWithOptions_ClosedOn(t *testing.T) {
+	weekStart := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	weekEnd := time.Date(2025, 1, 12, 23, 59, 59, 0, time.UTC)
+	closedOn := func(day int) *redmine.DateTime {
+		return &redmine.DateTime{Time: time.Date(2025, 1, day, 10, 0, 0, 0, time.UTC)}
+	}
+
+	tests := []struct {
+		name  string
+		issue *redmine.Issue
+		want  bool
+	}{
+		{"期間内に完了", &redmine.Issue{Status: redmine.IDName{Name: "完了"}, ClosedOn: closedOn(8)}, true},
+		{"期間外に完了", &redmine.Issue{Status: redmine.IDName{Name: "完了"}, ClosedOn: closedOn(2)}, false},
+		{"完了後に再オープン", &redmine.Issue{Status: redmine.IDName{Name: "進行中"}, ClosedOn: closedOn(8)}, false},
+		{"closed_onなし（現在のステータスで判定）", &redmine.Issue{Status: redmine.IDName{Name: "完了"}}, true},
+		{"is_closedで判定（名前が完了系でない終了ステータス）", &redmine.Issue{Status: redmine.IDName{ID: 6, Name: "却下"}, ClosedOn: closedOn(8)}, true},
+		{"is_closedで判定（名前が完了系の未終了ステータス）", &redmine.Issue{Status: redmine.IDName{ID: 3, Name: "Resolved"}, ClosedOn: closedOn(8)}, false},
+	}
+
+	statuses := redmine.NewEnumerations()
+	statuses.ClosedKnown = true
+	statuses.Statuses[3] = redmine.IssueStatus{ID: 3, Name: "Resolved"}
+	statuses.Statuses[6] = redmine.IssueStatus{ID: 6, Name: "却下", IsClosed: true}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{}
+			if tt.issue.Status.ID != 0 {
+				opts.Statuses = statuses
+			}
+			s := CalculateWithOptions([]*redmine.Issue{tt.issue}, weekStart, weekEnd, opts)
+			if got := s.ClosedIssues == 1; got != tt.want {
+				t.Errorf("期間内の完了 = %v; want %v", got, tt.want)
+			}
+			if len(s.ReopenedInPeriod) != 0 || len(s.StartedInPeriod) != 0 || s.Transitions {
+				t.Errorf("Transitionsなしで再オープン・着手を判定した: %+v", s)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package stats
+
+import (
+	"time"
+
+	"github.com/tktomaru/redmine-exporter/internal/redmine"
+)
+
+// Period は集計期間
+type Period struct {
+	Start time.Time
+	End   time.Time
+}
+
+// WeekTrend は推移の1期間（週）分の集計
+type WeekTrend struct {
+	Period
+	Stats   *WeeklyStats // 期間の統計
+	Created int          // 期間内に作成されたチケット数
+	Closed  int          // 期間内に完了したチケット数（closed_on がない場合は更新日時で判定）
+	Open    int          // 期間の終了時点で未完了のチケット数（作成済みで完了していない）
+	Overdue int          // 期間の終了時点で期日を過ぎて未完了のチケット数
+}
+
+// Label は「01/06〜01/12」の形式で期間を返す
+func (w WeekTrend) Label() string {
+	return w.Start.Format("01/02") + "〜" + w.End.Format("01/02")
+}
+
+// CalculateTrend は期間ごとの統計と、作成・完了・未完了・期限切れの件数の推移を計算
+func CalculateTrend(issues []*redmine.Issue, periods []Period, opts Options) []WeekTrend {
+	allIssues := flattenIssues(issues)
+	trend := make([]WeekTrend, 0, len(periods))
+
+	for _, p := range periods {
+		s := CalculateWithOptions(issues, p.Start, p.End, opts)
+		w := WeekTrend{Period: p, Stats: s, Created: s.NewIssues}
+
+		// 期日は日付のみのため、期間の終了日より前の期日を期限切れとする
+		endDay := time.Date(p.End.Year(), p.End.Month(), p.End.Day(), 0, 0, 0, 0, p.End.Location())
+		for _, issue := range allIssues {
+			if opts.closedIn(issue, p.Start, p.End) {
+				w.Closed++
+			}
+			if issue.CreatedOn == nil || issue.CreatedOn.IsZero() || issue.CreatedOn.After(p.End) {
+				continue
+			}
+			if opts.closedAt(issue, p.End) {
+				continue
+			}
+			w.Open++
+			if issue.DueDate != nil && !issue.DueDate.IsZero() && issue.DueDate.Before(endDay) {
+				w.Overdue++
+			}
+		}
+		trend = append(trend, w)
+	}
+
+	return trend
+}
+
+// closedIn はチケットが推移の期間内に完了したかを判定する
+// 週報の統計（periodEvents）と異なり、closed_on がない場合（古いRedmine）は更新日時で判定する
+// （現在のステータスで判定すると、完了したチケットがすべての週で完了に数えられるため）
+func (o Options) closedIn(issue *redmine.Issue, start, end time.Time) bool {
+	if o.Transitions || (issue.ClosedOn != nil && !issue.ClosedOn.IsZero()) {
+		closed, _, _ := o.periodEvents(issue, start, end)
+		return closed
+	}
+	if !o.isClosed(issue.Status) {
+		return false
+	}
+	if issue.UpdatedOn == nil || issue.UpdatedOn.IsZero() {
+		return true
+	}
+	return !issue.UpdatedOn.Before(start) && !issue.UpdatedOn.After(end)
+}
+
+// closedAt はチケットが時点tで完了していたかを判定する
+// Transitions指定時はステータス変更からtの時点のステータスを求め、
+// それ以外は現在完了しているチケットを closed_on（ない場合は更新日時）以降完了していたとみなす
+func (o Options) closedAt(issue *redmine.Issue, t time.Time) bool {
+	if o.Transitions && len(issue.StatusChanges) > 0 {
+		status := issue.StatusChanges[0].From
+		for _, c := range issue.StatusChanges {
+			if c.CreatedOn.After(t) {
+				break
+			}
+			status = c.To
+		}
+		return o.isClosed(status)
+	}
+
+	if !o.isClosed(issue.Status) {
+		return false
+	}
+	closedOn := issue.ClosedOn
+	if closedOn == nil || closedOn.IsZero() {
+		closedOn = issue.UpdatedOn
+	}
+	return closedOn == nil || closedOn.IsZero() || !closedOn.After(t)
+}
@@ -0,0 +1,85 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tktomaru/redmine-exporter/internal/redmine"
+)
+
+func TestCalculateTrend(t *testing.T) {
+	at := func(day int) time.Time { return time.Date(2025, 1, day, 10, 0, 0, 0, time.UTC) }
+	dt := func(day int) *redmine.DateTime { return &redmine.DateTime{Time: at(day)} }
+	periods := []Period{
+		{Start: time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), End: time.Date(2025, 1, 12, 23, 59, 59, 0, time.UTC)},
+		{Start: time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC), End: time.Date(2025, 1, 19, 23, 59, 59, 0, time.UTC)},
+		{Start: time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC), End: time.Date(2025, 1, 26, 23, 59, 59, 0, time.UTC)},
+	}
+	open, closed := redmine.IDName{ID: 2, Name: "進行中"}, redmine.IDName{ID: 5, Name: "完了"}
+
+	issues := []*redmine.Issue{
+		// 1週目より前に作成、2週目に完了
+		{ID: 1, Status: closed, CreatedOn: dt(1), ClosedOn: dt(15)},
+		// 1週目に作成、期日（1/10）を過ぎて未完了
+		{ID: 2, Status: open, CreatedOn: dt(7), DueDate: &redmine.Date{Time: time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)}},
+		// 2週目に作成、3週目に完了（closed_onなし、更新日時で判定）
+		{ID: 3, Status: closed, CreatedOn: dt(14), UpdatedOn: dt(21)},
+		// 3週目に作成
+		{ID: 4, Status: open, CreatedOn: dt(22)},
+	}
+
+	trend := CalculateTrend(issues, periods, Options{})
+	if len(trend) != 3 {
+		t.Fatalf("len(trend) = %d; want 3", len(trend))
+	}
+
+	tests := []struct {
+		week                           int
+		created, closed, open, overdue int
+	}{
+		{week: 0, created: 1, closed: 0, open: 2, overdue: 1},
+		{week: 1, created: 1, closed: 1, open: 2, overdue: 1},
+		{week: 2, created: 1, closed: 1, open: 2, overdue: 1},
+	}
+	for _, tt := range tests {
+		w := trend[tt.week]
+		if w.Created != tt.created || w.Closed != tt.closed || w.Open != tt.open || w.Overdue != tt.overdue {
+			t.Errorf("週%d（%s）= 作成%d 完了%d 未完了%d 期限切れ%d; want %d %d %d %d", tt.week+1, w.Label(),
+				w.Created, w.Closed, w.Open, w.Overdue, tt.created, tt.closed, tt.open, tt.overdue)
+		}
+		if w.Stats == nil || w.Stats.NewIssues != w.Created {
+			t.Errorf("週%d のStats = %+v", tt.week+1, w.Stats)
+		}
+	}
+	if got := trend[0].Label(); got != "01/06〜01/12" {
+		t.Errorf("Label() = %q; want 01/06〜01/12", got)
+	}
+}
+
+func TestClosedAt_Transitions(t *testing.T) {
+	at := func(day int) time.Time { return time.Date(2025, 1, day, 10, 0, 0, 0, time.UTC) }
+	status := func(name string) redmine.IDName { return redmine.IDName{Name: name} }
+	opts := Options{Transitions: true}
+
+	// 1/5 完了 → 1/10 再オープン → 1/20 完了
+	issue := &redmine.Issue{Status: status("完了"), StatusChanges: []redmine.StatusChange{
+		{CreatedOn: at(5), From: status("進行中"), To: status("完了")},
+		{CreatedOn: at(10), From: status("完了"), To: status("進行中")},
+		{CreatedOn: at(20), From: status("進行中"), To: status("完了")},
+	}}
+
+	tests := []struct {
+		day  int
+		want bool
+	}{
+		{day: 3, want: false},
+		{day: 7, want: true},
+		{day: 15, want: false},
+		{day: 25, want: true},
+	}
+	for _, tt := range tests {
+		if got := opts.closedAt(issue, at(tt.day)); got != tt.want {
+			t.Errorf("closedAt(1/%d) = %v; want %v", tt.day, got, tt.want)
+		}
+	}
+}